@@ -27,7 +27,7 @@ var app = &cli.App{
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:    "token",
-			Usage:   "provide OAuth token for API",
+			Usage:   "provide OAuth token or repository upload token for API",
 			EnvVars: []string{"GATES_TOKEN"},
 		},
 		&cli.StringFlag{
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+)
+
+var exportCommand = &cli.Command{
+	Name:      "export",
+	Usage:     "export a repository with its coverage history to an archive",
+	ArgsUsage: "SCM NAMESPACE NAME",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Usage:    "archive file to write",
+			Required: true,
+		},
+	},
+	Action: export,
+}
+
+var importCommand = &cli.Command{
+	Name:      "import",
+	Usage:     "import a repository archive, importing it again does not duplicate data",
+	ArgsUsage: "ARCHIVE",
+	Action:    importArchive,
+}
+
+func export(c *cli.Context) error {
+	if c.NArg() != 3 {
+		return fmt.Errorf("require SCM, namespace and name of the repository")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	repo, err := app.repoStore.Find(&core.Repo{
+		SCM:       core.SCMProvider(c.Args().Get(0)),
+		NameSpace: c.Args().Get(1),
+		Name:      c.Args().Get(2),
+	})
+	if err != nil {
+		return fmt.Errorf("repository %s/%s not found: %w", c.Args().Get(1), c.Args().Get(2), err)
+	}
+	file, err := os.Create(c.String("output"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := app.archive.Export(c.Context, repo, file); err != nil {
+		return err
+	}
+	log.Printf("%s exported to %s", repo.FullName(), c.String("output"))
+	return nil
+}
+
+func importArchive(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("require archive file")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	repo, err := app.archive.Import(c.Context, file, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("%s imported with report ID %s", repo.FullName(), repo.ReportID)
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/modules/blob"
+)
+
+var checkConfigCommand = &cli.Command{
+	Name:   "check-config",
+	Usage:  "check the configuration, the database schema and the blob store",
+	Action: checkConfig,
+}
+
+func checkConfig(c *cli.Context) error {
+	cfg, err := config.Environ()
+	if err != nil {
+		return err
+	}
+	problems := cfg.Check()
+	for _, problem := range problems {
+		log.Warning(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems found in the configuration", len(problems))
+	}
+	if _, err := blob.NewStore(cfg); err != nil {
+		return fmt.Errorf("fail to open blob store: %w", err)
+	}
+	db, err := connectDatabaseService()
+	if err != nil {
+		return err
+	}
+	if err := db.CheckSchema(); err != nil {
+		return err
+	}
+	log.Println("configuration is valid")
+	return nil
+}
@@ -31,26 +31,40 @@ func provideRouter(
 	repoService core.RepoService,
 	hookService core.HookService,
 	oauthSerice core.OAuthService,
+	permissionService core.PermissionService,
+	oidcService core.OIDCService,
+	archiveService core.ArchiveService,
+	cacheMetrics core.CacheMetrics,
+	cache core.Cache,
 	// store
 	userStore core.UserStore,
 	reportStore core.ReportStore,
 	repoStore core.RepoStore,
 	oauthStore core.OAuthStore,
+	permissionStore core.PermissionStore,
+	auditStore core.AuditStore,
 ) *routers.Routers {
 	return &routers.Routers{
-		Config:          config,
-		Session:         session,
-		LoginMiddleware: login,
-		SCMService:      scmService,
-		CoverageService: coverageService,
-		ChartService:    chartService,
-		RepoService:     repoService,
-		ReportService:   reportService,
-		HookService:     hookService,
-		OAuthService:    oauthSerice,
-		UserStore:       userStore,
-		ReportStore:     reportStore,
-		RepoStore:       repoStore,
-		OAuthStore:      oauthStore,
+		Config:            config,
+		Session:           session,
+		LoginMiddleware:   login,
+		SCMService:        scmService,
+		CoverageService:   coverageService,
+		ChartService:      chartService,
+		RepoService:       repoService,
+		ReportService:     reportService,
+		HookService:       hookService,
+		OAuthService:      oauthSerice,
+		PermissionService: permissionService,
+		OIDCService:       oidcService,
+		ArchiveService:    archiveService,
+		CacheMetrics:      cacheMetrics,
+		Cache:             cache,
+		UserStore:         userStore,
+		ReportStore:       reportStore,
+		RepoStore:         repoStore,
+		OAuthStore:        oauthStore,
+		PermissionStore:   permissionStore,
+		AuditStore:        auditStore,
 	}
 }
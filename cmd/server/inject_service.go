@@ -5,12 +5,16 @@ import (
 
 	"github.com/covergates/covergates/config"
 	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/modules/archive"
 	"github.com/covergates/covergates/modules/charts"
 	"github.com/covergates/covergates/modules/git"
 	"github.com/covergates/covergates/modules/hook"
 	"github.com/covergates/covergates/modules/oauth"
+	"github.com/covergates/covergates/modules/oidc"
+	"github.com/covergates/covergates/modules/permission"
 	"github.com/covergates/covergates/modules/repo"
 	"github.com/covergates/covergates/modules/report"
+	"github.com/covergates/covergates/modules/retention"
 	"github.com/covergates/covergates/modules/scm"
 	"github.com/covergates/covergates/modules/session"
 	"github.com/covergates/covergates/service/coverage"
@@ -27,6 +31,10 @@ var serviceSet = wire.NewSet(
 	provideHookService,
 	provideOAuthService,
 	provideRepoService,
+	providePermissionService,
+	provideOIDCService,
+	provideRetentionService,
+	provideArchiveService,
 )
 
 func provideSCMService(
@@ -84,9 +92,8 @@ func provideHookService(
 func provideOAuthService(
 	config *config.Config,
 	oAuthStore core.OAuthStore,
-	userStore core.UserStore,
 ) core.OAuthService {
-	return oauth.NewService(config, oAuthStore, userStore)
+	return oauth.NewService(config, oAuthStore)
 }
 
 func provideRepoService(
@@ -94,6 +101,35 @@ func provideRepoService(
 	scmService core.SCMService,
 	userStore core.UserStore,
 	repoStore core.RepoStore,
+	permissionStore core.PermissionStore,
 ) core.RepoService {
-	return repo.NewService(config, scmService, userStore, repoStore)
+	return repo.NewService(config, scmService, userStore, repoStore, permissionStore)
+}
+
+func providePermissionService(
+	config *config.Config,
+	scmService core.SCMService,
+	repoStore core.RepoStore,
+	permissionStore core.PermissionStore,
+) core.PermissionService {
+	return permission.NewService(config, scmService, repoStore, permissionStore)
+}
+
+func provideOIDCService(config *config.Config) core.OIDCService {
+	return oidc.NewService(config)
+}
+
+func provideRetentionService(
+	scmService core.SCMService,
+	repoStore core.RepoStore,
+	reportStore core.ReportStore,
+) core.RetentionService {
+	return retention.NewService(scmService, repoStore, reportStore)
+}
+
+func provideArchiveService(
+	repoStore core.RepoStore,
+	reportStore core.ReportStore,
+) core.ArchiveService {
+	return archive.NewService(repoStore, reportStore)
 }
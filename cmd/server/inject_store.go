@@ -4,21 +4,42 @@ import (
 	"github.com/google/wire"
 	"gorm.io/gorm"
 
+	"github.com/covergates/covergates/config"
 	"github.com/covergates/covergates/core"
 	"github.com/covergates/covergates/models"
+	"github.com/covergates/covergates/modules/blob"
+	"github.com/covergates/covergates/modules/cache"
 )
 
 // nolint:deadcode,varcheck,unused
 var storeSet = wire.NewSet(
+	provideBlobStore,
+	provideCache,
+	provideCacheMetrics,
+	wire.Bind(new(core.CacheMetrics), new(*cache.Metrics)),
 	provideDatabaseService,
 	provideUserStore,
 	provideReportStore,
 	provideRepoStore,
 	provideOAuthStore,
+	providePermissionStore,
+	provideAuditStore,
 )
 
-func provideDatabaseService(db *gorm.DB) core.DatabaseService {
-	return models.NewDatabaseService(db)
+func provideBlobStore(config *config.Config) (core.BlobStore, error) {
+	return blob.NewStore(config)
+}
+
+func provideCache(config *config.Config) (core.Cache, error) {
+	return cache.NewCache(config)
+}
+
+func provideCacheMetrics() *cache.Metrics {
+	return cache.NewMetrics()
+}
+
+func provideDatabaseService(db *gorm.DB, blob core.BlobStore) core.DatabaseService {
+	return models.NewDatabaseService(db, blob)
 }
 
 func provideUserStore(db core.DatabaseService) core.UserStore {
@@ -27,16 +48,36 @@ func provideUserStore(db core.DatabaseService) core.UserStore {
 	}
 }
 
-func provideReportStore(db core.DatabaseService) core.ReportStore {
-	return &models.ReportStore{
-		DB: db,
+func provideReportStore(
+	config *config.Config,
+	db core.DatabaseService,
+	blob core.BlobStore,
+	backend core.Cache,
+	metrics *cache.Metrics,
+) core.ReportStore {
+	store := &models.ReportStore{
+		DB:   db,
+		Blob: blob,
 	}
+	if backend == nil {
+		return store
+	}
+	return cache.NewReportStore(store, backend, config.Cache.TTL, metrics)
 }
 
-func provideRepoStore(db core.DatabaseService) core.RepoStore {
-	return &models.RepoStore{
+func provideRepoStore(
+	config *config.Config,
+	db core.DatabaseService,
+	backend core.Cache,
+	metrics *cache.Metrics,
+) core.RepoStore {
+	store := &models.RepoStore{
 		DB: db,
 	}
+	if backend == nil {
+		return store
+	}
+	return cache.NewRepoStore(store, backend, config.Cache.TTL, metrics)
 }
 
 func provideOAuthStore(db core.DatabaseService) core.OAuthStore {
@@ -44,3 +85,15 @@ func provideOAuthStore(db core.DatabaseService) core.OAuthStore {
 		DB: db,
 	}
 }
+
+func providePermissionStore(db core.DatabaseService) core.PermissionStore {
+	return &models.PermissionStore{
+		DB: db,
+	}
+}
+
+func provideAuditStore(db core.DatabaseService) core.AuditStore {
+	return &models.AuditStore{
+		DB: db,
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -83,12 +84,15 @@ func Run(c *cli.Context) error {
 		return err
 	}
 	if cfg.Database.AutoMigrate {
-		go func() {
-			if err := app.db.Migrate(); err != nil {
-				log.Panic(err)
-			}
-			log.Println("migration done")
-		}()
+		if err := app.db.Migrate(); err != nil {
+			return err
+		}
+		log.Println("migration done")
+	} else if err := app.db.CheckSchema(); err != nil {
+		return fmt.Errorf("%w, run migrate up before starting the server", err)
+	}
+	if interval := cfg.Server.PruneInterval; interval > 0 {
+		go runPruner(context.Background(), app.retention, interval)
 	}
 	r := gin.Default()
 	app.routers.RegisterRoutes(r)
@@ -102,6 +106,17 @@ func main() {
 		Name:    "codecover",
 		Version: Version,
 		Action:  Run,
+		Commands: []*cli.Command{
+			migrateCommand,
+			pruneCommand,
+			exportCommand,
+			importCommand,
+			usersCommand,
+			reposCommand,
+			reportsCommand,
+			tokensCommand,
+			checkConfigCommand,
+		},
 	}
 	err := app.Run(os.Args)
 	if err != nil {
@@ -110,16 +125,58 @@ func main() {
 }
 
 type application struct {
-	routers *routers.Routers
-	db      core.DatabaseService
+	routers   *routers.Routers
+	db        core.DatabaseService
+	retention core.RetentionService
+	archive   core.ArchiveService
+	scm       core.SCMService
+	// stores for server commands
+	userStore   core.UserStore
+	repoStore   core.RepoStore
+	reportStore core.ReportStore
+	oauthStore  core.OAuthStore
+	auditStore  core.AuditStore
 }
 
 func newApplication(
 	routers *routers.Routers,
 	db core.DatabaseService,
+	retention core.RetentionService,
+	archive core.ArchiveService,
+	scm core.SCMService,
+	userStore core.UserStore,
+	repoStore core.RepoStore,
+	reportStore core.ReportStore,
+	oauthStore core.OAuthStore,
+	auditStore core.AuditStore,
 ) application {
 	return application{
-		routers: routers,
-		db:      db,
+		routers:     routers,
+		db:          db,
+		retention:   retention,
+		archive:     archive,
+		scm:         scm,
+		userStore:   userStore,
+		repoStore:   repoStore,
+		reportStore: reportStore,
+		oauthStore:  oauthStore,
+		auditStore:  auditStore,
+	}
+}
+
+// initializeCommand application for server commands,
+// which requires the database schema to be up to date
+func initializeCommand() (application, error) {
+	cfg, err := config.Environ()
+	if err != nil {
+		return application{}, err
+	}
+	app, err := InitializeApplication(cfg, connectDatabase(cfg))
+	if err != nil {
+		return application{}, err
+	}
+	if err := app.db.CheckSchema(); err != nil {
+		return application{}, fmt.Errorf("%w, run migrate up first", err)
 	}
+	return app, nil
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/models"
+	"github.com/covergates/covergates/modules/blob"
+)
+
+var migrateCommand = &cli.Command{
+	Name:  "migrate",
+	Usage: "manage versioned database migrations",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "up",
+			Usage:  "apply all pending migrations",
+			Action: migrateUp,
+		},
+		{
+			Name:  "down",
+			Usage: "rollback the latest applied migrations",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "steps",
+					Usage: "number of migrations to rollback",
+					Value: 1,
+				},
+			},
+			Action: migrateDown,
+		},
+		{
+			Name:   "status",
+			Usage:  "list migrations and whether they are applied",
+			Action: migrateStatus,
+		},
+	},
+}
+
+func connectDatabaseService() (core.DatabaseService, error) {
+	cfg, err := config.Environ()
+	if err != nil {
+		return nil, err
+	}
+	store, err := blob.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDatabaseService(connectDatabase(cfg), store), nil
+}
+
+func migrateUp(c *cli.Context) error {
+	db, err := connectDatabaseService()
+	if err != nil {
+		return err
+	}
+	if err := db.Migrate(); err != nil {
+		return err
+	}
+	log.Println("migration done")
+	return nil
+}
+
+func migrateDown(c *cli.Context) error {
+	steps := c.Int("steps")
+	if steps <= 0 {
+		return fmt.Errorf("steps should be positive")
+	}
+	db, err := connectDatabaseService()
+	if err != nil {
+		return err
+	}
+	if err := db.Rollback(steps); err != nil {
+		return err
+	}
+	log.Printf("rollback %d migrations done", steps)
+	return nil
+}
+
+func migrateStatus(c *cli.Context) error {
+	db, err := connectDatabaseService()
+	if err != nil {
+		return err
+	}
+	migrations, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, m := range migrations {
+		applied := "pending"
+		if m.Applied {
+			applied = m.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, applied)
+	}
+	return w.Flush()
+}
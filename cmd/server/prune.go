@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+)
+
+var pruneCommand = &cli.Command{
+	Name:  "prune",
+	Usage: "delete reports in the retention policy of repositories",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "list reports to delete without deleting them",
+		},
+	},
+	Action: prune,
+}
+
+func prune(c *cli.Context) error {
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	dryRun := c.Bool("dry-run")
+	results, pruneErr := app.retention.PruneAll(c.Context, dryRun)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tCOMMIT\tCREATED")
+	count := 0
+	for _, result := range results {
+		for _, report := range result.Reports {
+			fmt.Fprintf(w, "%s/%s\t%s\t%s\n",
+				result.Repo.SCM,
+				result.Repo.FullName(),
+				report.Commit,
+				report.CreatedAt.Format(time.RFC3339),
+			)
+			count++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		log.Printf("%d reports to prune", count)
+	} else {
+		log.Printf("%d reports pruned", count)
+	}
+	return pruneErr
+}
+
+// runPruner prunes reports periodically until the context is done
+func runPruner(ctx context.Context, service core.RetentionService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			results, err := service.PruneAll(ctx, false)
+			if err != nil {
+				log.Error(err)
+			}
+			for _, result := range results {
+				log.Printf("pruned %d reports of %s", len(result.Reports), result.Repo.FullName())
+			}
+		}
+	}
+}
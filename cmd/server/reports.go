@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/service/common"
+)
+
+// recomputePageLimit of reports to recompute at a time, which are fetched with coverage data
+const recomputePageLimit = 10
+
+var reportsCommand = &cli.Command{
+	Name:  "reports",
+	Usage: "manage reports",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "recompute",
+			Usage:  "recompute statement coverage of reports from their statement hits and store it again",
+			Action: recomputeReports,
+		},
+	},
+}
+
+func recomputeReports(c *cli.Context) error {
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	repos, err := app.repoStore.ListActivated()
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, repo := range repos {
+		page := core.Page{Limit: recomputePageLimit}
+		for {
+			reports, next, err := app.reportStore.Finds(&core.Report{ReportID: repo.ReportID}, page)
+			if err != nil {
+				return fmt.Errorf("fail to list reports of %s: %w", repo.FullName(), err)
+			}
+			for _, report := range reports {
+				recompute(report)
+				if err := app.reportStore.Restore(report); err != nil {
+					return fmt.Errorf("fail to update report %s of %s: %w", report.Commit, repo.FullName(), err)
+				}
+				count++
+			}
+			if next == "" {
+				break
+			}
+			page.Cursor = next
+		}
+	}
+	log.Printf("%d reports recomputed", count)
+	return nil
+}
+
+// recompute statement coverage of files with statement hits.
+// Coverage of the report is computed from its files when it is stored.
+func recompute(report *core.Report) {
+	for _, coverage := range report.Coverages {
+		for _, file := range coverage.Files {
+			if len(file.StatementHits) > 0 {
+				file.StatementCoverage = common.ComputeStatementCoverage(file.StatementHits)
+			}
+		}
+		coverage.StatementCoverage = coverage.ComputeStatementCoverage()
+	}
+}
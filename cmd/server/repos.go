@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+)
+
+var reposCommand = &cli.Command{
+	Name:  "repos",
+	Usage: "manage activated repositories",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list activated repositories with their creators",
+			Action: listRepos,
+		},
+		{
+			Name:      "reset-report-id",
+			Usage:     "renew the report ID, reports uploaded with the old report ID are no longer shown",
+			ArgsUsage: "SCM NAMESPACE NAME",
+			Action:    resetReportID,
+		},
+		{
+			Name:      "transfer-creator",
+			Usage:     "transfer the repository to another creator, whose SCM account is used by the server",
+			ArgsUsage: "SCM NAMESPACE NAME LOGIN",
+			Action:    transferCreator,
+		},
+	},
+}
+
+func listRepos(c *cli.Context) error {
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	repos, err := app.repoStore.ListActivated()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCM\tREPOSITORY\tREPORT ID\tCREATOR")
+	for _, repo := range repos {
+		creator := "-"
+		if user, err := app.repoStore.Creator(repo); err == nil {
+			creator = user.Login
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", repo.SCM, repo.FullName(), repo.ReportID, creator)
+	}
+	return w.Flush()
+}
+
+// findRepo of the command arguments SCM NAMESPACE NAME
+func findRepo(app application, c *cli.Context) (*core.Repo, error) {
+	repo, err := app.repoStore.Find(&core.Repo{
+		SCM:       core.SCMProvider(c.Args().Get(0)),
+		NameSpace: c.Args().Get(1),
+		Name:      c.Args().Get(2),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("repository %s/%s not found: %w", c.Args().Get(1), c.Args().Get(2), err)
+	}
+	return repo, nil
+}
+
+// audit server command to the repository
+func audit(app application, repo *core.Repo, action core.AuditAction, before, after interface{}) error {
+	log := &core.AuditLog{Repo: repo, Action: action}
+	var err error
+	if log.Before, err = json.Marshal(before); err != nil {
+		return err
+	}
+	if log.After, err = json.Marshal(after); err != nil {
+		return err
+	}
+	return app.auditStore.Create(log)
+}
+
+func resetReportID(c *cli.Context) error {
+	if c.NArg() != 3 {
+		return fmt.Errorf("require SCM, namespace and name of the repository")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	repo, err := findRepo(app, c)
+	if err != nil {
+		return err
+	}
+	client, err := app.scm.Client(repo.SCM)
+	if err != nil {
+		return err
+	}
+	before := map[string]string{"reportID": repo.ReportID}
+	repo.ReportID = client.Repositories().NewReportID(repo)
+	if err := app.repoStore.Update(repo); err != nil {
+		return err
+	}
+	if err := audit(app, repo, core.AuditReportIDRenew, before, map[string]string{"reportID": repo.ReportID}); err != nil {
+		return err
+	}
+	log.Printf("report ID of %s renewed to %s", repo.FullName(), repo.ReportID)
+	return nil
+}
+
+func transferCreator(c *cli.Context) error {
+	if c.NArg() != 4 {
+		return fmt.Errorf("require SCM, namespace and name of the repository and login of the new creator")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	repo, err := findRepo(app, c)
+	if err != nil {
+		return err
+	}
+	user, err := app.userStore.FindByLogin(c.Args().Get(3))
+	if err != nil {
+		return err
+	}
+	before := map[string]string{"creator": ""}
+	if creator, err := app.repoStore.Creator(repo); err == nil {
+		before["creator"] = creator.Login
+	}
+	if err := app.repoStore.UpdateCreator(repo, user); err != nil {
+		return err
+	}
+	if err := audit(app, repo, core.AuditCreatorUpdate, before, map[string]string{"creator": user.Login}); err != nil {
+		return err
+	}
+	log.Printf("creator of %s transferred to %s", repo.FullName(), user.Login)
+	return nil
+}
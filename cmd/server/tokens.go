@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+)
+
+var tokensCommand = &cli.Command{
+	Name:  "tokens",
+	Usage: "manage API tokens of users",
+	Subcommands: []*cli.Command{
+		{
+			Name:      "revoke",
+			Usage:     "revoke API tokens of the user with the names",
+			ArgsUsage: "LOGIN [NAME...]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "revoke all tokens of the user",
+				},
+			},
+			Action: revokeTokens,
+		},
+	},
+}
+
+func revokeTokens(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("require login of the user")
+	}
+	names := c.Args().Tail()
+	all := c.Bool("all")
+	if len(names) == 0 && !all {
+		return fmt.Errorf("require names of tokens or --all")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	user := &core.User{Login: c.Args().First()}
+	tokens, err := app.oauthStore.List(user)
+	if err != nil {
+		return err
+	}
+	revoke := make(map[string]bool)
+	for _, name := range names {
+		revoke[name] = true
+	}
+	count := 0
+	for _, token := range tokens {
+		if !all && !revoke[token.Name] {
+			continue
+		}
+		if err := app.oauthStore.Delete(token); err != nil {
+			return err
+		}
+		count++
+	}
+	log.Printf("%d tokens of %s revoked", count, user.Login)
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/drone/go-scm/scm"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/covergates/covergates/core"
+)
+
+var usersCommand = &cli.Command{
+	Name:  "users",
+	Usage: "manage users",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list users with their SCM accounts",
+			Action: listUsers,
+		},
+		{
+			Name:      "delete",
+			Usage:     "delete the user with its tokens and roles, repositories created by the user should be transferred first",
+			ArgsUsage: "LOGIN",
+			Action:    deleteUser,
+		},
+		{
+			Name:      "bind",
+			Usage:     "bind an SCM account to the user, the user signs in with the SCM to grant access",
+			ArgsUsage: "LOGIN SCM SCM_LOGIN",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "email",
+					Usage: "email of the SCM account",
+				},
+			},
+			Action: bindUser,
+		},
+	},
+}
+
+func listUsers(c *cli.Context) error {
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	users, err := app.userStore.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LOGIN\tEMAIL\tGITHUB\tGITEA\tGITLAB")
+	for _, user := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			user.Login,
+			user.Email,
+			user.GithubLogin,
+			user.GiteaLogin,
+			user.GitLabLogin,
+		)
+	}
+	return w.Flush()
+}
+
+func deleteUser(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("require login of the user")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	login := c.Args().First()
+	if err := app.userStore.Delete(&core.User{Login: login}); err != nil {
+		return err
+	}
+	log.Printf("user %s deleted", login)
+	return nil
+}
+
+func bindUser(c *cli.Context) error {
+	if c.NArg() != 3 {
+		return fmt.Errorf("require login, SCM and SCM login of the user")
+	}
+	app, err := initializeCommand()
+	if err != nil {
+		return err
+	}
+	user, err := app.userStore.FindByLogin(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	provider := core.SCMProvider(c.Args().Get(1))
+	scmUser := &scm.User{
+		Login: c.Args().Get(2),
+		Email: c.String("email"),
+	}
+	if _, err := app.userStore.Bind(provider, user, scmUser, &core.Token{}); err != nil {
+		return err
+	}
+	log.Printf("%s account %s bound to user %s", provider, scmUser.Login, user.Login)
+	return nil
+}
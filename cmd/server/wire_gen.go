@@ -15,20 +15,35 @@ import (
 func InitializeApplication(config2 *config.Config, db *gorm.DB) (application, error) {
 	session := provideSession()
 	loginMiddleware := provideLogin(config2)
-	databaseService := provideDatabaseService(db)
+	blobStore, err := provideBlobStore(config2)
+	if err != nil {
+		return application{}, err
+	}
+	databaseService := provideDatabaseService(db, blobStore)
+	cache, err := provideCache(config2)
+	if err != nil {
+		return application{}, err
+	}
+	metrics := provideCacheMetrics()
 	userStore := provideUserStore(databaseService)
 	git := provideGit()
 	scmService := provideSCMService(config2, userStore, git)
 	coverageService := provideCoverageService()
 	chartService := provideChartService()
-	repoStore := provideRepoStore(databaseService)
+	repoStore := provideRepoStore(config2, databaseService, cache, metrics)
 	reportService := provideReportService(config2, repoStore)
-	repoService := provideRepoService(config2, scmService, userStore, repoStore)
-	reportStore := provideReportStore(databaseService)
+	permissionStore := providePermissionStore(databaseService)
+	repoService := provideRepoService(config2, scmService, userStore, repoStore, permissionStore)
+	reportStore := provideReportStore(config2, databaseService, blobStore, cache, metrics)
 	hookService := provideHookService(scmService, repoStore, reportStore, reportService)
 	oAuthStore := provideOAuthStore(databaseService)
-	oAuthService := provideOAuthService(config2, oAuthStore, userStore)
-	routers := provideRouter(session, config2, loginMiddleware, scmService, coverageService, chartService, reportService, repoService, hookService, oAuthService, userStore, reportStore, repoStore, oAuthStore)
-	mainApplication := newApplication(routers, databaseService)
+	oAuthService := provideOAuthService(config2, oAuthStore)
+	permissionService := providePermissionService(config2, scmService, repoStore, permissionStore)
+	oidcService := provideOIDCService(config2)
+	archiveService := provideArchiveService(repoStore, reportStore)
+	auditStore := provideAuditStore(databaseService)
+	routers := provideRouter(session, config2, loginMiddleware, scmService, coverageService, chartService, reportService, repoService, hookService, oAuthService, permissionService, oidcService, archiveService, metrics, cache, userStore, reportStore, repoStore, oAuthStore, permissionStore, auditStore)
+	retentionService := provideRetentionService(scmService, repoStore, reportStore)
+	mainApplication := newApplication(routers, databaseService, retentionService, archiveService, scmService, userStore, repoStore, reportStore, oAuthStore, auditStore)
 	return mainApplication, nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 
@@ -16,8 +17,11 @@ type Config struct {
 	Gitea    Gitea
 	Github   Github
 	GitLab   GitLab
+	OIDC     OIDC
 	Database Database
 	CloudRun CloudRun
+	Blob     Blob
+	Cache    Cache
 }
 
 // Server setting
@@ -29,6 +33,14 @@ type Server struct {
 	ServerPort  string `envconfig:"GATES_SERVER_PORT"`
 	CloudPort   string `envconfig:"PORT"`
 	OAuthClient string `default:"client"`
+	// PermissionTTL is how long the repository roles synchronized from SCM are trusted
+	PermissionTTL time.Duration `default:"10m" envconfig:"GATES_PERMISSION_TTL"`
+	// PruneInterval is how often reports are pruned in retention policies, zero to disable
+	PruneInterval time.Duration `default:"24h" envconfig:"GATES_PRUNE_INTERVAL"`
+	// TreeMapCells is the most cells of treemaps in pull request comments
+	TreeMapCells int `default:"60" envconfig:"GATES_TREEMAP_CELLS"`
+	// MaxArchiveSize is the most bytes of repository archives uploaded to import
+	MaxArchiveSize int64 `default:"268435456" envconfig:"GATES_MAX_ARCHIVE_SIZE"`
 }
 
 // Database setting
@@ -42,6 +54,33 @@ type Database struct {
 	Password    string `envconfig:"GATES_DB_PASSWORD"`
 }
 
+// Blob storage setting of coverage data
+type Blob struct {
+	// Driver is filesystem, s3 or database
+	Driver string `default:"filesystem" envconfig:"GATES_BLOB_DRIVER"`
+	Path   string `default:"blobs" envconfig:"GATES_BLOB_PATH"`
+	// S3 compatible storage, such as AWS S3 or MinIO
+	S3Endpoint  string `default:"https://s3.amazonaws.com" envconfig:"GATES_BLOB_S3_ENDPOINT"`
+	S3Region    string `default:"us-east-1" envconfig:"GATES_BLOB_S3_REGION"`
+	S3Bucket    string `envconfig:"GATES_BLOB_S3_BUCKET"`
+	S3Prefix    string `envconfig:"GATES_BLOB_S3_PREFIX"`
+	S3AccessKey string `envconfig:"GATES_BLOB_S3_ACCESS_KEY"`
+	S3SecretKey string `envconfig:"GATES_BLOB_S3_SECRET_KEY"`
+}
+
+// Cache setting of hot repository and report lookups
+type Cache struct {
+	// Driver is memory, redis or none
+	Driver string        `default:"memory" envconfig:"GATES_CACHE_DRIVER"`
+	TTL    time.Duration `default:"1m" envconfig:"GATES_CACHE_TTL"`
+	// Size is the maximum number of entries in memory
+	Size int `default:"1024" envconfig:"GATES_CACHE_SIZE"`
+	// Redis shares the cache across server instances
+	RedisAddr     string `default:"localhost:6379" envconfig:"GATES_CACHE_REDIS_ADDR"`
+	RedisPassword string `envconfig:"GATES_CACHE_REDIS_PASSWORD"`
+	RedisDB       int    `envconfig:"GATES_CACHE_REDIS_DB"`
+}
+
 // CloudRun database setting for google cloud run
 type CloudRun struct {
 	User     string `envconfig:"GATES_DB_USER"`
@@ -79,6 +118,19 @@ type GitLab struct {
 	Scope        []string `default:"api,read_user,read_api,read_repository,profile,email" envconfig:"GATES_GITLAB_SCOPE"`
 }
 
+// OIDC connection setting for generic OpenID Connect login
+type OIDC struct {
+	// DiscoveryURL is the issuer or its /.well-known/openid-configuration URL
+	DiscoveryURL string   `envconfig:"GATES_OIDC_DISCOVERY_URL"`
+	ClientID     string   `envconfig:"GATES_OIDC_CLIENT_ID"`
+	ClientSecret string   `envconfig:"GATES_OIDC_CLIENT_SECRET"`
+	SkipVerity   bool     `envconfig:"GATES_OIDC_SKIP_VERIFY"`
+	Scope        []string `default:"openid,profile,email" envconfig:"GATES_OIDC_SCOPE"`
+	LoginClaim   string   `default:"preferred_username" envconfig:"GATES_OIDC_LOGIN_CLAIM"`
+	EmailClaim   string   `default:"email" envconfig:"GATES_OIDC_EMAIL_CLAIM"`
+	AvatarClaim  string   `default:"picture" envconfig:"GATES_OIDC_AVATAR_CLAIM"`
+}
+
 // Environ setup configure from environment variables
 func Environ() (*Config, error) {
 	cfg := &Config{}
@@ -127,3 +179,105 @@ func (server Server) URL() string {
 	addr += server.BaseURL()
 	return strings.TrimRight(addr, "/")
 }
+
+// Check the configuration and return the problems found, which is empty for a valid configuration
+func (c *Config) Check() []string {
+	var problems []string
+	if c.Server.Secret == "secret" {
+		problems = append(problems, "GATES_SERVER_SECRET should not be the default value")
+	}
+	if u, err := url.Parse(c.Server.Addr); err != nil || u.Scheme == "" || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("GATES_SERVER_ADDR %q should be an absolute URL", c.Server.Addr))
+	}
+	if c.Server.PermissionTTL < 0 {
+		problems = append(problems, "GATES_PERMISSION_TTL should not be negative")
+	}
+	if c.Server.PruneInterval < 0 {
+		problems = append(problems, "GATES_PRUNE_INTERVAL should not be negative")
+	}
+	if c.Server.TreeMapCells < 1 {
+		problems = append(problems, "GATES_TREEMAP_CELLS should be positive")
+	}
+	if c.Server.MaxArchiveSize < 1 {
+		problems = append(problems, "GATES_MAX_ARCHIVE_SIZE should be positive")
+	}
+	problems = append(problems, c.Database.check()...)
+	problems = append(problems, c.Blob.check()...)
+	problems = append(problems, c.Cache.check()...)
+	login := false
+	for _, provider := range []struct {
+		name   string
+		id     string
+		secret string
+	}{
+		{"GATES_GITEA", c.Gitea.ClientID, c.Gitea.ClientSecret},
+		{"GATES_GITHUB", c.Github.ClientID, c.Github.ClientSecret},
+		{"GATES_GITLAB", c.GitLab.ClientID, c.GitLab.ClientSecret},
+		{"GATES_OIDC", c.OIDC.ClientID, c.OIDC.ClientSecret},
+	} {
+		if (provider.id == "") != (provider.secret == "") {
+			problems = append(problems, fmt.Sprintf(
+				"%s_CLIENT_ID and %s_CLIENT_SECRET should be set together", provider.name, provider.name,
+			))
+		}
+		login = login || (provider.id != "" && provider.secret != "")
+	}
+	if c.Gitea.ClientID != "" && c.Gitea.Server == "" {
+		problems = append(problems, "GATES_GITEA_SERVER is required for Gitea login")
+	}
+	if c.OIDC.ClientID != "" && c.OIDC.DiscoveryURL == "" {
+		problems = append(problems, "GATES_OIDC_DISCOVERY_URL is required for OpenID Connect login")
+	}
+	if !login {
+		problems = append(problems, "no login provider is configured")
+	}
+	return problems
+}
+
+func (db Database) check() []string {
+	switch db.Driver {
+	case "sqlite3", "cloudrun":
+		return nil
+	case "postgres", "mysql":
+		if db.Host == "" || db.User == "" {
+			return []string{fmt.Sprintf("GATES_DB_HOST and GATES_DB_USER are required for %s", db.Driver)}
+		}
+		return nil
+	default:
+		return []string{fmt.Sprintf("GATES_DB_DRIVER %q is not supported", db.Driver)}
+	}
+}
+
+func (blob Blob) check() []string {
+	switch blob.Driver {
+	case "filesystem":
+		if blob.Path == "" {
+			return []string{"GATES_BLOB_PATH is required for filesystem blob store"}
+		}
+	case "s3":
+		if blob.S3Bucket == "" {
+			return []string{"GATES_BLOB_S3_BUCKET is required for s3 blob store"}
+		}
+	case "database":
+	default:
+		return []string{fmt.Sprintf("GATES_BLOB_DRIVER %q is not supported", blob.Driver)}
+	}
+	return nil
+}
+
+func (cache Cache) check() []string {
+	switch cache.Driver {
+	case "none":
+		return nil
+	case "memory", "redis":
+		if cache.TTL <= 0 {
+			return []string{"GATES_CACHE_TTL should be positive"}
+		}
+		if cache.Driver == "memory" && cache.Size <= 0 {
+			return []string{"GATES_CACHE_SIZE should be positive"}
+		}
+		return nil
+	default:
+		return []string{fmt.Sprintf("GATES_CACHE_DRIVER %q is not supported", cache.Driver)}
+	}
+}
@@ -109,3 +109,43 @@ func TestConfig(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestConfigCheck(t *testing.T) {
+	cfg, err := Environ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Gitea = Gitea{}
+	expect := []string{
+		"GATES_SERVER_SECRET should not be the default value",
+		"no login provider is configured",
+	}
+	if diff := cmp.Diff(expect, cfg.Check()); diff != "" {
+		t.Fatal(diff)
+	}
+
+	cfg.Server.Secret = "changed"
+	cfg.Github.ClientID = "id"
+	cfg.Github.ClientSecret = "secret"
+	if problems := cfg.Check(); len(problems) != 0 {
+		t.Fatalf("should be valid, got %v", problems)
+	}
+
+	cfg.Server.Addr = "localhost"
+	cfg.Server.TreeMapCells = 0
+	cfg.Server.MaxArchiveSize = 0
+	cfg.Database.Driver = "postgres"
+	cfg.Blob.Driver = "s3"
+	cfg.GitLab.ClientID = "id"
+	expect = []string{
+		`GATES_SERVER_ADDR "localhost" should be an absolute URL`,
+		"GATES_TREEMAP_CELLS should be positive",
+		"GATES_MAX_ARCHIVE_SIZE should be positive",
+		"GATES_DB_HOST and GATES_DB_USER are required for postgres",
+		"GATES_BLOB_S3_BUCKET is required for s3 blob store",
+		"GATES_GITLAB_CLIENT_ID and GATES_GITLAB_CLIENT_SECRET should be set together",
+	}
+	if diff := cmp.Diff(expect, cfg.Check()); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+//go:generate mockgen -package mock -destination ../mock/archive_mock.go . ArchiveService
+
+// ArchiveVersion of the repository archive format
+const ArchiveVersion = 1
+
+// ErrInvalidArchive is returned if the archive is malformed,
+// in an unsupported version or not of the repository to import
+var ErrInvalidArchive = errors.New("invalid repository archive")
+
+// ArchiveService exports and imports coverage history of repositories
+type ArchiveService interface {
+	// Export the repository with its setting, reports, references and comments to w
+	Export(ctx context.Context, repo *Repo, w io.Writer) error
+	// Import the archive to the repository, which is created with the archived repository if nil.
+	// Reports are kept under the report ID of the repository if it is activated.
+	// Importing an archive again does not duplicate data.
+	Import(ctx context.Context, r io.Reader, repo *Repo) (*Repo, error)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+//go:generate mockgen -package mock -destination ../mock/audit_mock.go . AuditStore
+
+// AuditAction is a security-relevant action to a repository or a user account
+type AuditAction string
+
+// Audit actions
+const (
+	AuditSettingUpdate  AuditAction = "setting.update"
+	AuditReportIDRenew  AuditAction = "report.renew"
+	AuditHookCreate     AuditAction = "hook.create"
+	AuditHookDelete     AuditAction = "hook.delete"
+	AuditTokenCreate    AuditAction = "token.create"
+	AuditTokenRenew     AuditAction = "token.renew"
+	AuditTokenDelete    AuditAction = "token.delete"
+	AuditRoleOverride   AuditAction = "role.override"
+	AuditUploadRejected AuditAction = "upload.rejected"
+	AuditRepoExport     AuditAction = "repo.export"
+	AuditRepoImport     AuditAction = "repo.import"
+	AuditCreatorUpdate  AuditAction = "creator.update"
+	// actions to the account of the actor, without repository
+	AuditUserTokenCreate AuditAction = "user.token.create"
+	AuditUserTokenDelete AuditAction = "user.token.delete"
+)
+
+// AuditLog records who did what to a repository or to their account
+type AuditLog struct {
+	ID uint `json:"id"`
+	// Repo is nil for actions to the account of the actor
+	Repo   *Repo       `json:"-"`
+	Action AuditAction `json:"action"`
+	// Actor is the login of the user, empty for anonymous request or server command
+	Actor string `json:"actor"`
+	IP    string `json:"ip"`
+	// Before and After are JSON snapshots of the changed object
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// AuditStore keeps audit logs
+type AuditStore interface {
+	Create(log *AuditLog) error
+	// List audit logs of the repository, the latest first
+	List(repo *Repo) ([]*AuditLog, error)
+	// ListUser audit logs of actions to the account of the user, the latest first
+	ListUser(user *User) ([]*AuditLog, error)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errBadgeThresholds = errors.New("badge thresholds should be 0 <= low <= high <= 100")
+
+// BadgeStyle of the badge look
+type BadgeStyle string
+
+// Badge styles
+const (
+	BadgeFlat        BadgeStyle = "flat"
+	BadgeFlatSquare  BadgeStyle = "flat-square"
+	BadgeForTheBadge BadgeStyle = "for-the-badge"
+)
+
+// Badge colors in the scale from red to green
+const (
+	BadgeRed         = "#e05d44"
+	BadgeOrange      = "#fe7d37"
+	BadgeYellow      = "#dfb317"
+	BadgeYellowGreen = "#a4a61d"
+	BadgeGreen       = "#97ca00"
+	BadgeBrightGreen = "#44cc11"
+	BadgeGrey        = "#9f9f9f"
+)
+
+// Badge shows a label with its message
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+	Style   BadgeStyle
+}
+
+// BadgeSetting of the repository.
+// Badges are red below the low coverage and green at the high coverage, in percentage.
+// Defaults are used if both are zero.
+type BadgeSetting struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Default badge thresholds
+const (
+	DefaultBadgeLow  = 50
+	DefaultBadgeHigh = 80
+)
+
+// ParseBadgeStyle or return error if the style is unknown. Empty style is flat.
+func ParseBadgeStyle(s string) (BadgeStyle, error) {
+	switch style := BadgeStyle(strings.ToLower(s)); style {
+	case "":
+		return BadgeFlat, nil
+	case BadgeFlat, BadgeFlatSquare, BadgeForTheBadge:
+		return style, nil
+	default:
+		return "", fmt.Errorf("badge style %q not support", s)
+	}
+}
+
+// Validate the thresholds
+func (setting *BadgeSetting) Validate() error {
+	if setting.Low < 0 || setting.Low > setting.High || setting.High > 100 {
+		return errBadgeThresholds
+	}
+	return nil
+}
+
+// Color of the coverage, in ratio, on the threshold scale from red through yellow to green
+func (setting *BadgeSetting) Color(coverage float64) string {
+	low, high := setting.Low, setting.High
+	if low == 0 && high == 0 {
+		low, high = DefaultBadgeLow, DefaultBadgeHigh
+	}
+	percentage := coverage * 100
+	switch {
+	case percentage >= high:
+		return BadgeBrightGreen
+	case percentage < low:
+		return BadgeRed
+	}
+	scale := []string{BadgeOrange, BadgeYellow, BadgeYellowGreen, BadgeGreen}
+	i := int((percentage - low) / (high - low) * float64(len(scale)))
+	if i >= len(scale) {
+		i = len(scale) - 1
+	}
+	return scale[i]
+}
@@ -0,0 +1,19 @@
+package core
+
+import "errors"
+
+//go:generate mockgen -package mock -destination ../mock/blob_mock.go . BlobStore
+
+// ErrBlobNotFound if no blob is stored with the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps binary objects addressed by their content,
+// so identical data is stored only once
+type BlobStore interface {
+	// Put data and return its key, which is the SHA-256 hex digest of the data
+	Put(data []byte) (string, error)
+	// Get data of the key, or ErrBlobNotFound
+	Get(key string) ([]byte, error)
+	// Delete data of the key, it is not an error if the key does not exist
+	Delete(key string) error
+}
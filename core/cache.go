@@ -0,0 +1,42 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+//go:generate mockgen -package mock -destination ../mock/cache_mock.go . Cache
+
+// ErrCacheMiss if the key is not cached or expired
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache keeps values for a while, which may be shared by server instances
+type Cache interface {
+	// Get value of the key, or ErrCacheMiss
+	Get(key string) ([]byte, error)
+	// Set value of the key, which expires after ttl
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete the key, it is not an error if the key does not exist
+	Delete(key string) error
+}
+
+// CacheStats of a cached store
+type CacheStats struct {
+	Name   string
+	Hits   uint64
+	Misses uint64
+}
+
+// CacheMetrics reports statistics of cached stores
+type CacheMetrics interface {
+	Stats() []*CacheStats
+}
+
+// HitRate of the cache, zero if nothing is looked up
+func (stats *CacheStats) HitRate() float64 {
+	total := stats.Hits + stats.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(total)
+}
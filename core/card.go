@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CardMetric is a row of repository cards
+type CardMetric string
+
+// Card metrics
+const (
+	CardFiles     CardMetric = "files"
+	CardHits      CardMetric = "hits"
+	CardBuild     CardMetric = "build"
+	CardUncovered CardMetric = "uncovered"
+	CardTrend     CardMetric = "trend"
+	CardCommit    CardMetric = "commit"
+)
+
+// MaxCardMetrics in a card
+const MaxCardMetrics = 5
+
+// DefaultCardMetrics are shown if no metric is selected
+var DefaultCardMetrics = []CardMetric{CardFiles, CardHits, CardBuild}
+
+// Card themes
+const (
+	CardDark         = "dark"
+	CardLight        = "light"
+	CardHighContrast = "high-contrast"
+)
+
+var cardColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// CardTheme is the palette of cards, empty colors are inherited when themes are merged
+type CardTheme struct {
+	Background string `json:"background,omitempty"`
+	// Footer is the color of the brand stripe
+	Footer string `json:"footer,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+	// Accent is the color of the coverage score
+	Accent string `json:"accent,omitempty"`
+}
+
+var cardThemes = map[string]CardTheme{
+	CardDark: {
+		Background: "#333333",
+		Footer:     "#3b726b",
+		Title:      "#80b9a2",
+		Text:       "#e6e6e6",
+		Icon:       "#b9ac38",
+		Accent:     "#3f8167",
+	},
+	CardLight: {
+		Background: "#fffefe",
+		Footer:     "#e4f2ec",
+		Title:      "#2f6f5e",
+		Text:       "#333333",
+		Icon:       "#8a7f1c",
+		Accent:     "#3f8167",
+	},
+	CardHighContrast: {
+		Background: "#000000",
+		Footer:     "#262626",
+		Title:      "#ffff00",
+		Text:       "#ffffff",
+		Icon:       "#00ffff",
+		Accent:     "#00ff00",
+	},
+}
+
+// Card is the content of a repository card
+type Card struct {
+	Title string
+	// Coverage of the score in ratio
+	Coverage float64
+	Theme    CardTheme
+	Items    []*CardItem
+}
+
+// CardItem is a metric row with its label and value
+type CardItem struct {
+	Metric CardMetric
+	Label  string
+	Value  string
+}
+
+// CardSetting of the repository, which is overridden by queries of the card
+type CardSetting struct {
+	Theme   string       `json:"theme"`
+	Colors  CardTheme    `json:"colors"`
+	Metrics []CardMetric `json:"metrics"`
+}
+
+// ParseCardTheme or return error if the theme is unknown. Empty theme is dark.
+func ParseCardTheme(name string) (CardTheme, error) {
+	if name == "" {
+		name = CardDark
+	}
+	theme, ok := cardThemes[strings.ToLower(name)]
+	if !ok {
+		return CardTheme{}, fmt.Errorf("card theme %q not support", name)
+	}
+	return theme, nil
+}
+
+// ParseCardMetrics separated by commas or return error if any metric is unknown
+func ParseCardMetrics(s string) ([]CardMetric, error) {
+	metrics := make([]CardMetric, 0)
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			metrics = append(metrics, CardMetric(strings.ToLower(field)))
+		}
+	}
+	return metrics, ValidateCardMetrics(metrics)
+}
+
+// ValidateCardMetrics are known and fit in cards
+func ValidateCardMetrics(metrics []CardMetric) error {
+	if len(metrics) > MaxCardMetrics {
+		return fmt.Errorf("cards show %d metrics at most", MaxCardMetrics)
+	}
+	for _, metric := range metrics {
+		switch metric {
+		case CardFiles, CardHits, CardBuild, CardUncovered, CardTrend, CardCommit:
+		default:
+			return fmt.Errorf("card metric %q not support", metric)
+		}
+	}
+	return nil
+}
+
+// Merge colors into the theme, empty colors are kept
+func (theme CardTheme) Merge(colors CardTheme) CardTheme {
+	merge := func(a *string, b string) {
+		if b != "" {
+			*a = b
+		}
+	}
+	merge(&theme.Background, colors.Background)
+	merge(&theme.Footer, colors.Footer)
+	merge(&theme.Title, colors.Title)
+	merge(&theme.Text, colors.Text)
+	merge(&theme.Icon, colors.Icon)
+	merge(&theme.Accent, colors.Accent)
+	return theme
+}
+
+// Validate colors of the theme are empty or hex colors, such as #fff
+func (theme *CardTheme) Validate() error {
+	for _, c := range []string{theme.Background, theme.Footer, theme.Title, theme.Text, theme.Icon, theme.Accent} {
+		if c != "" && !cardColor.MatchString(c) {
+			return fmt.Errorf("card color %q should be a hex color", c)
+		}
+	}
+	return nil
+}
+
+// Validate the theme, the colors and the metrics
+func (setting *CardSetting) Validate() error {
+	if _, err := ParseCardTheme(setting.Theme); err != nil {
+		return err
+	}
+	if err := setting.Colors.Validate(); err != nil {
+		return err
+	}
+	return ValidateCardMetrics(setting.Metrics)
+}
@@ -9,9 +9,22 @@ import (
 // ChartService provides charts
 type ChartService interface {
 	CoverageDiffTreeMap(old, new *Report) Chart
-	RepoCard(repo *Repo, report *Report) Chart
+	// CoverageChangeTreeMap of files changed in a pull request with at most cells cells
+	CoverageChangeTreeMap(old, new *Report, changes []*FileChange, cells int) Chart
+	// RepoCard of the repository status in the theme
+	RepoCard(card *Card) Chart
+	Badge(badge *Badge) Chart
+	// CoverageTrend of the reports over time
+	CoverageTrend(reports []*Report) Chart
+	// CoverageHierarchy of the report grouped by directory
+	CoverageHierarchy(report *Report, link ChartLink) Chart
+	// PNG rasterizes the chart to fit in width and height, zero to keep its size or ratio
+	PNG(chart Chart, width, height int) Chart
 }
 
+// ChartLink returns URL of a file or directory path in charts, empty for no link
+type ChartLink func(path string, dir bool) string
+
 // Chart renders image to writer
 type Chart interface {
 	Render(w io.Writer) error
@@ -4,3 +4,6 @@ import "errors"
 
 // ErrNotImplemented function
 var ErrNotImplemented = errors.New("not implement")
+
+// ErrNoRepoAccess if SCM answers the user is not able to access the repository
+var ErrNoRepoAccess = errors.New("no access to the repository")
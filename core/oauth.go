@@ -2,6 +2,7 @@ package core
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -9,25 +10,55 @@ import (
 
 //go:generate mockgen -package mock -destination ../mock/oauth_mock.go . OAuthService,OAuthStore
 
+// TokenScope limits the permission of an OAuth token
+type TokenScope string
+
+const (
+	// ScopeUser allows to manage user account, such as tokens and repositories synchronization
+	ScopeUser TokenScope = "user"
+	// ScopeReportWrite allows to upload reports and leave comments
+	ScopeReportWrite TokenScope = "report:write"
+	// ScopeRepoRead allows to read repositories information
+	ScopeRepoRead TokenScope = "repo:read"
+	// ScopeRepoAdmin allows to manage repositories, which includes ScopeRepoRead
+	ScopeRepoAdmin TokenScope = "repo:admin"
+)
+
+// TokenScopes available to OAuth tokens
+var TokenScopes = []TokenScope{
+	ScopeUser,
+	ScopeReportWrite,
+	ScopeRepoRead,
+	ScopeRepoAdmin,
+}
+
 // OAuthToken holds OAuth2 token information
 type OAuthToken struct {
-	ID        uint
-	Name      string
-	Code      string
-	Access    string
-	Refresh   string
-	Expires   time.Time
-	CreatedAt time.Time
-	Owner     *User
-	Data      []byte
+	ID      uint
+	Name    string
+	Code    string
+	Access  string
+	Refresh string
+	// Expires is zero if the token never expires
+	Expires time.Time
+	// Scopes of the token
+	Scopes []TokenScope
+	// Legacy tokens are created before scopes, which keep all permissions without scopes
+	Legacy     bool
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+	Owner      *User
+	Data       []byte
 }
 
 // OAuthService provide OAuth2 protocol
 type OAuthService interface {
-	CreateToken(ctx context.Context, name string) (*OAuthToken, error)
+	// CreateToken with scopes. The token never expires if expiresIn is zero.
+	CreateToken(ctx context.Context, name string, scopes []TokenScope, expiresIn time.Duration) (*OAuthToken, error)
 	DeleteToken(ctx context.Context, token *OAuthToken) error
 	ListTokens(ctx context.Context) ([]*OAuthToken, error)
-	Validate(r *http.Request) (*User, error)
+	// Validate Bearer token of the request and return the token with its owner
+	Validate(r *http.Request) (*OAuthToken, error)
 	WithUser(ctx context.Context, user *User) context.Context
 }
 
@@ -37,4 +68,42 @@ type OAuthStore interface {
 	Find(token *OAuthToken) (*OAuthToken, error)
 	List(user *User) ([]*OAuthToken, error)
 	Delete(token *OAuthToken) error
+	// UpdateLastUsed time of the token
+	UpdateLastUsed(token *OAuthToken) error
+}
+
+// HasScope checks if the token is granted with the scope
+func (token *OAuthToken) HasScope(scope TokenScope) bool {
+	if len(token.Scopes) == 0 {
+		return token.Legacy
+	}
+	for _, s := range token.Scopes {
+		if s == scope || (s == ScopeRepoAdmin && scope == ScopeRepoRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTokenScopes of the space-delimited scope string, nil if it is empty
+func ParseTokenScopes(scope string) []TokenScope {
+	fields := strings.Fields(scope)
+	if len(fields) == 0 {
+		return nil
+	}
+	scopes := make([]TokenScope, len(fields))
+	for i, field := range fields {
+		scopes[i] = TokenScope(field)
+	}
+	return scopes
+}
+
+// IsValidTokenScope checks if the scope is one of TokenScopes
+func IsValidTokenScope(scope TokenScope) bool {
+	for _, s := range TokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"context"
+
+	"github.com/drone/go-login/login"
+)
+
+//go:generate mockgen -package mock -destination ../mock/oidc_mock.go . OIDCService
+
+// OIDCUser is the identity claimed by an OpenID Connect provider
+type OIDCUser struct {
+	// Subject identifies the user in the provider
+	Subject string
+	Login   string
+	Email   string
+	Avatar  string
+}
+
+// OIDCService provides login with a generic OpenID Connect provider
+type OIDCService interface {
+	// Enabled if the provider is configured
+	Enabled() bool
+	// Handler of the authorization code flow
+	Handler() login.Middleware
+	// User claimed by the access token, ctx should carry the verified ID token from the handler
+	User(ctx context.Context, token *Token) (*OIDCUser, error)
+}
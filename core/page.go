@@ -0,0 +1,32 @@
+package core
+
+import "errors"
+
+// Limits of a page
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// ErrInvalidCursor is returned for a cursor not issued by the listing
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Page to list. The cursor is opaque and returned as the next cursor
+// of the previous page, it is empty for the first page.
+type Page struct {
+	Cursor string
+	Limit  int
+}
+
+// Size of the page, which is DefaultPageLimit if the limit is not positive
+// and at most MaxPageLimit
+func (page Page) Size() int {
+	switch {
+	case page.Limit <= 0:
+		return DefaultPageLimit
+	case page.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return page.Limit
+	}
+}
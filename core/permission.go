@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -package mock -destination ../mock/permission_mock.go . PermissionStore,PermissionService
+
+// Role of a user to a repository
+type Role string
+
+// Roles ordered from the least to the most privileged one.
+// A role includes all permissions of the less privileged roles.
+const (
+	// RoleNone has no access to the repository
+	RoleNone Role = ""
+	// RoleViewer is able to read reports of the repository
+	RoleViewer Role = "viewer"
+	// RoleUploader is able to upload reports and comment on pull requests
+	RoleUploader Role = "uploader"
+	// RoleMaintainer is able to manage setting, hooks and upload tokens
+	RoleMaintainer Role = "maintainer"
+	// RoleAdmin is able to manage roles of other users
+	RoleAdmin Role = "admin"
+)
+
+// Roles available for the repository
+var Roles = []Role{
+	RoleViewer,
+	RoleUploader,
+	RoleMaintainer,
+	RoleAdmin,
+}
+
+// RepoPerm is the permission of a user to a repository on SCM
+type RepoPerm struct {
+	Pull  bool
+	Push  bool
+	Admin bool
+}
+
+// RepoPermission of a user to a repository
+type RepoPermission struct {
+	Repo *Repo `json:"-"`
+	// Login of the user
+	Login string `json:"login"`
+	// Role synchronized from SCM
+	Role Role `json:"role"`
+	// Override is the local role, which takes precedence over the SCM role
+	Override Role      `json:"override,omitempty"`
+	SyncedAt time.Time `json:"syncedAt"`
+}
+
+// PermissionStore keeps roles of users to repositories
+type PermissionStore interface {
+	Find(repo *Repo, user *User) (*RepoPermission, error)
+	List(repo *Repo) ([]*RepoPermission, error)
+	// Synchronize SCM roles of the user, local overrides are kept
+	Synchronize(user *User, permissions []*RepoPermission) error
+	// Override the role of the user locally, RoleNone removes the override
+	Override(repo *Repo, user *User, role Role) error
+}
+
+// PermissionService decides roles of users to repositories
+type PermissionService interface {
+	// Role of the user to the repository, user is nil for anonymous request
+	Role(ctx context.Context, repo *Repo, user *User) (Role, error)
+}
+
+// Includes reports whether the role has all permissions of the other role
+func (role Role) Includes(other Role) bool {
+	return role.level() >= other.level()
+}
+
+// IsValid role to assign
+func (role Role) IsValid() bool {
+	return role.level() > 0
+}
+
+func (role Role) level() int {
+	for i, r := range Roles {
+		if r == role {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Role converts SCM permission to repository role
+func (perm *RepoPerm) Role() Role {
+	switch {
+	case perm == nil:
+		return RoleNone
+	case perm.Admin:
+		return RoleAdmin
+	case perm.Push:
+		return RoleUploader
+	case perm.Pull:
+		return RoleViewer
+	}
+	return RoleNone
+}
+
+// Effective role of the permission
+func (permission *RepoPermission) Effective() Role {
+	if permission.Override != RoleNone {
+		return permission.Override
+	}
+	return permission.Role
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 //go:generate mockgen -package mock -destination ../mock/repo_mock.go . RepoStore,RepoService
@@ -17,6 +18,9 @@ type Repo struct {
 	Branch    string
 	Private   bool
 	SCM       SCMProvider
+	// Perm of the login user, only available when listing from SCM.
+	// Repo is used as query condition of storage, the field is ignored there.
+	Perm *RepoPerm `json:"-" gorm:"-"`
 }
 
 // RepoSetting to customize repository
@@ -26,6 +30,24 @@ type RepoSetting struct {
 	UpdateAction     ReportUpdateAction `json:"updateAction"`
 	// Protected project from unauthorized user upload report
 	Protected bool `json:"protected"`
+	// ReportIDUpload allows anonymous upload with the report ID only,
+	// if the project is neither protected nor has any upload token
+	ReportIDUpload bool            `json:"reportIDUpload"`
+	Retention      RetentionPolicy `json:"retention"`
+	Badge          BadgeSetting    `json:"badge"`
+	Card           CardSetting     `json:"card"`
+	// UpdatedAt of the setting, which is set by the storage and never serialized
+	UpdatedAt time.Time `json:"-"`
+}
+
+// RepoToken grants report upload permission to a single repository
+type RepoToken struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// Token is only available right after it is created or renewed,
+	// storage keeps the hash of the token only.
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 // RepoService provides repository opperations
@@ -42,6 +64,8 @@ type RepoStore interface {
 	BatchUpdateOrCreate(repos []*Repo) error
 	Find(repo *Repo) (*Repo, error)
 	Finds(urls ...string) ([]*Repo, error)
+	// ListActivated repositories, which have report ID
+	ListActivated() ([]*Repo, error)
 	// Creator is the user activated the repository
 	Creator(repo *Repo) (*User, error)
 	UpdateCreator(repo *Repo, user *User) error
@@ -49,6 +73,14 @@ type RepoStore interface {
 	UpdateSetting(repo *Repo, setting *RepoSetting) error
 	FindHook(repo *Repo) (*Hook, error)
 	UpdateHook(repo *Repo, hook *Hook) error
+	// CreateToken generates a new upload token for the repository
+	CreateToken(repo *Repo, name string) (*RepoToken, error)
+	// RenewToken regenerates the secret of an existing upload token
+	RenewToken(repo *Repo, token *RepoToken) (*RepoToken, error)
+	// FindToken of the repository with its plain secret
+	FindToken(repo *Repo, secret string) (*RepoToken, error)
+	ListTokens(repo *Repo) ([]*RepoToken, error)
+	DeleteToken(repo *Repo, token *RepoToken) error
 }
 
 // FullName is namespace+name
@@ -23,7 +23,8 @@ type Report struct {
 
 // ReportComment in the pull request
 type ReportComment struct {
-	Number  int
+	Number int
+	// Comment is the ID of the comment on SCM, zero if it is not posted yet
 	Comment int
 }
 
@@ -40,6 +41,84 @@ type CoverageReportDiff struct {
 	Files                 []*FileDiff
 }
 
+// ReportSummary of a report from aggregates stored at upload, without files and coverage data
+type ReportSummary struct {
+	Commit    string             `json:"commit"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Coverages []*CoverageSummary `json:"coverages"`
+	Files     int                `json:"files"`
+	Lines     int                `json:"lines"`
+	Covered   int                `json:"covered"`
+}
+
+// CoverageSummary of a report type, where lines are statement lines
+type CoverageSummary struct {
+	Type              ReportType `json:"type"`
+	StatementCoverage float64    `json:"statementCoverage"`
+	Files             int        `json:"files"`
+	Lines             int        `json:"lines"`
+	Covered           int        `json:"covered"`
+}
+
+// FileSummary of a file coverage in a report, where lines are statement lines
+type FileSummary struct {
+	Commit    string     `json:"commit"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Type      ReportType `json:"type"`
+	Path      string     `json:"path"`
+	Lines     int        `json:"lines"`
+	Covered   int        `json:"covered"`
+}
+
+// Coverage of the file in ratio, zero if it has no line
+func (summary *FileSummary) Coverage() float64 {
+	if summary.Lines == 0 {
+		return 0
+	}
+	return float64(summary.Covered) / float64(summary.Lines)
+}
+
+// ReportComparison of a head report against a base report
+type ReportComparison struct {
+	Base                  string            `json:"base"`
+	Head                  string            `json:"head"`
+	BaseCoverage          float64           `json:"baseCoverage"`
+	HeadCoverage          float64           `json:"headCoverage"`
+	StatementCoverageDiff float64           `json:"statementCoverageDiff"`
+	Types                 []*TypeComparison `json:"types"`
+	// Files with coverage changed or added in the head report
+	Files []*FileComparison `json:"files"`
+	// NewlyUncoveredFiles have no covered statement in the head report,
+	// but they are covered or absent in the base report
+	NewlyUncoveredFiles []string `json:"newlyUncoveredFiles"`
+	// NewlyCoveredLines are covered in the head report but not in the base report
+	NewlyCoveredLines []*FileLines `json:"newlyCoveredLines"`
+	RemovedFiles      []string     `json:"removedFiles"`
+}
+
+// TypeComparison of coverages in a report type, absent coverages are zero
+type TypeComparison struct {
+	Type                  ReportType `json:"type"`
+	BaseCoverage          float64    `json:"baseCoverage"`
+	HeadCoverage          float64    `json:"headCoverage"`
+	StatementCoverageDiff float64    `json:"statementCoverageDiff"`
+}
+
+// FileComparison of a file coverage
+type FileComparison struct {
+	Name                  string  `json:"name"`
+	BaseCoverage          float64 `json:"baseCoverage"`
+	HeadCoverage          float64 `json:"headCoverage"`
+	StatementCoverageDiff float64 `json:"statementCoverageDiff"`
+	Added                 bool    `json:"added"`
+}
+
+// FileLines are line numbers of a file
+type FileLines struct {
+	Name  string `json:"name"`
+	Lines []int  `json:"lines"`
+}
+
 // CoverageService provides CoverReport
 type CoverageService interface {
 	Report(ctx context.Context, t ReportType, r io.Reader) (*CoverageReport, error)
@@ -53,13 +132,44 @@ type CoverageService interface {
 
 // ReportStore the report in storage
 type ReportStore interface {
+	// Upload the report with its reference and coverages atomically
 	Upload(r *Report) error
+	// Transaction runs fn with the store bound to a database transaction,
+	// changes made through the store are rolled back if fn returns error
+	Transaction(fn func(store ReportStore) error) error
 	Find(r *Report) (*Report, error)
-	Finds(r *Report) ([]*Report, error)
-	// List reports with reference (commit, branch or tag)
-	List(reportID, ref string) ([]*Report, error)
+	// FindSummary of the report as Find, with coverages of statement coverage only.
+	// Coverage data is not fetched, so it is cheap for reports without files.
+	FindSummary(r *Report) (*Report, error)
+	// Finds reports from the latest, returning the cursor of the next page
+	// which is empty at the last page
+	Finds(r *Report, page Page) ([]*Report, string, error)
+	// List reports with reference (commit, branch or tag) from the latest,
+	// returning the cursor of the next page which is empty at the last page
+	List(reportID, ref string, page Page) ([]*Report, string, error)
 	CreateComment(r *Report, comment *ReportComment) error
 	FindComment(r *Report, number int) (*ReportComment, error)
+	// ListReferences of the report ID with their reports
+	ListReferences(reportID string) ([]*Reference, error)
+	// UpdateBranchDeleted sets when the branch of the reference is found deleted, nil if it exists
+	UpdateBranchDeleted(reportID, name string, deletedAt *time.Time) error
+	// Delete reports of the commits with their coverages.
+	// References and comments left without reports are deleted as well.
+	Delete(reportID string, commits ...string) error
+	// ListComments of the report ID
+	ListComments(reportID string) ([]*ReportComment, error)
+	// Restore the report with its created time, the report of the same commit is replaced
+	Restore(r *Report) error
+	// RestoreReference links the reference to its reports of the commits
+	RestoreReference(reportID string, ref *Reference) error
+	// History of the reference from the oldest, created in [from, to).
+	// Zero times are not bounded.
+	History(reportID, ref string, from, to time.Time) ([]*ReportSummary, error)
+	// FileHistory of the reference from the oldest, created in [from, to).
+	// Files of all paths are listed if path is empty.
+	FileHistory(reportID, ref, path string, from, to time.Time) ([]*FileSummary, error)
+	// FileSummaries of files in the report of the commit
+	FileSummaries(reportID, commit string) ([]*FileSummary, error)
 }
 
 // ReportService provides reports operations
@@ -67,6 +177,10 @@ type ReportService interface {
 	DiffReports(source, target *Report) (*CoverageReportDiff, error)
 	MarkdownReport(source, target *Report) (io.Reader, error)
 	MergeReport(from, to *Report, changes []*FileChange) (*Report, error)
+	// CompareReports of the head report against the base report
+	CompareReports(base, head *Report) (*ReportComparison, error)
+	// MarkdownComparison summarizes the comparison in markdown format
+	MarkdownComparison(comparison *ReportComparison) (io.Reader, error)
 }
 
 // StatementCoverage of the report
@@ -81,6 +195,47 @@ func (report *Report) StatementCoverage() float64 {
 	return sum / float64(len(report.Coverages))
 }
 
+// PatchCoverage of statements in files added or changed from the base report.
+// It is false if no file is changed.
+func (report *Report) PatchCoverage(base *Report) (float64, bool) {
+	total, covered := 0, 0
+	for _, coverage := range report.Coverages {
+		files := make(map[string]*File)
+		if baseCoverage, ok := base.Find(coverage.Type); ok {
+			for _, file := range baseCoverage.Files {
+				files[file.Name] = file
+			}
+		}
+		for _, file := range coverage.Files {
+			if baseFile, ok := files[file.Name]; ok && sameStatements(file, baseFile) {
+				continue
+			}
+			for _, hit := range file.StatementHits {
+				total++
+				if hit.Hits > 0 {
+					covered++
+				}
+			}
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(covered) / float64(total), true
+}
+
+func sameStatements(a, b *File) bool {
+	if len(a.StatementHits) != len(b.StatementHits) {
+		return false
+	}
+	for i, hit := range a.StatementHits {
+		if hit.LineNumber != b.StatementHits[i].LineNumber {
+			return false
+		}
+	}
+	return true
+}
+
 // Find coverage report of given type
 func (report *Report) Find(t ReportType) (*CoverageReport, bool) {
 	for _, coverage := range report.Coverages {
@@ -91,6 +246,24 @@ func (report *Report) Find(t ReportType) (*CoverageReport, bool) {
 	return nil, false
 }
 
+// Summary of the coverage with counts of files and statement lines
+func (cov *CoverageReport) Summary() *CoverageSummary {
+	summary := &CoverageSummary{
+		Type:              cov.Type,
+		StatementCoverage: cov.ComputeStatementCoverage(),
+		Files:             len(cov.Files),
+	}
+	for _, file := range cov.Files {
+		for _, hit := range file.StatementHits {
+			summary.Lines++
+			if hit.Hits > 0 {
+				summary.Covered++
+			}
+		}
+	}
+	return summary
+}
+
 // ComputeStatementCoverage of the report
 func (cov *CoverageReport) ComputeStatementCoverage() float64 {
 	if len(cov.Files) == 0 {
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+//go:generate mockgen -package mock -destination ../mock/retention_mock.go . RetentionService
+
+var errRetentionDays = errors.New("retention days should not be negative")
+
+// RetentionPolicy of repository reports. Reports are kept forever with the zero value.
+type RetentionPolicy struct {
+	// DefaultBranchDays keeps default branch reports for the days, zero to keep all.
+	// The latest report of the default branch is always kept.
+	DefaultBranchDays int `json:"defaultBranchDays"`
+	// LatestOnly keeps only the latest report of other branches
+	LatestOnly bool `json:"latestOnly"`
+	// DeletedBranchDays removes reports of a branch the days after it is deleted, zero to keep them
+	DeletedBranchDays int `json:"deletedBranchDays"`
+}
+
+// Reference of reports, such as branch or tag name
+type Reference struct {
+	Name string
+	// BranchDeletedAt is when the branch is found deleted, nil if the branch exists
+	BranchDeletedAt *time.Time
+	// Reports of the reference from the latest, without coverage data
+	Reports []*Report
+}
+
+// PruneResult lists pruned reports of a repository
+type PruneResult struct {
+	Repo    *Repo
+	Reports []*Report
+}
+
+// RetentionService prunes reports in the retention policy of repositories
+type RetentionService interface {
+	// Prune reports of the repository. Nothing is deleted with dryRun.
+	Prune(ctx context.Context, repo *Repo, dryRun bool) (*PruneResult, error)
+	// PruneAll activated repositories
+	PruneAll(ctx context.Context, dryRun bool) ([]*PruneResult, error)
+}
+
+// Enabled if reports may be pruned in the policy
+func (policy *RetentionPolicy) Enabled() bool {
+	return policy.DefaultBranchDays > 0 || policy.LatestOnly || policy.DeletedBranchDays > 0
+}
+
+// Validate the policy
+func (policy *RetentionPolicy) Validate() error {
+	if policy.DefaultBranchDays < 0 || policy.DeletedBranchDays < 0 {
+		return errRetentionDays
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ import (
 	"time"
 )
 
-//go:generate mockgen -package mock -destination ../mock/scm_mock.go . SCMService,Client,GitRepoService,UserService,ContentService,GitService,WebhookService
+//go:generate mockgen -package mock -destination ../mock/scm_mock.go . SCMService,Client,GitRepoService,UserService,ContentService,GitService,PullRequestService,WebhookService
 
 // SCMService to interact with given SCM provider
 type SCMService interface {
@@ -74,7 +74,8 @@ type GitRepoService interface {
 	CloneURL(ctx context.Context, user *User, name string) (string, error)
 	CreateHook(ctx context.Context, user *User, name string) (*Hook, error)
 	RemoveHook(ctx context.Context, user *User, name string, hook *Hook) error
-	IsAdmin(ctx context.Context, user *User, name string) bool
+	// Permission of the user to the repository
+	Permission(ctx context.Context, user *User, name string) (*RepoPerm, error)
 }
 
 // UserService defines operations with SCM
@@ -89,11 +90,15 @@ type UserService interface {
 type GitService interface {
 	GitRepository(ctx context.Context, user *User, repo string) (GitRepository, error)
 	FindCommit(ctx context.Context, user *User, repo *Repo) string
-	// ListCommits in the repository default branch
-	ListCommits(ctx context.Context, user *User, repo string) ([]*Commit, error)
+	// ListCommits in the repository default branch.
+	// The cursor of the next page is returned, which is empty at the last page.
+	ListCommits(ctx context.Context, user *User, repo string, page Page) ([]*Commit, string, error)
 	// ListCommitsByRef in the repository reference. The reference could be branch name.
-	ListCommitsByRef(ctx context.Context, user *User, repo, ref string) ([]*Commit, error)
-	ListBranches(ctx context.Context, user *User, repo string) ([]string, error)
+	ListCommitsByRef(ctx context.Context, user *User, repo, ref string, page Page) ([]*Commit, string, error)
+	ListBranches(ctx context.Context, user *User, repo string, page Page) ([]string, string, error)
+	// ListTags of the repository.
+	// The cursor of the next page is returned, which is empty at the last page.
+	ListTags(ctx context.Context, user *User, repo string, page Page) ([]string, string, error)
 }
 
 // ContentService provides information of source codes
@@ -1,11 +1,30 @@
 package core
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 //go:generate mockgen -package mock -destination ../mock/store_mock.go . DatabaseService
 
 // DatabaseService provides database operations with GORM
 type DatabaseService interface {
 	Session() *gorm.DB
+	// Migrate the schema up to the latest version
 	Migrate() error
+	// Rollback the latest applied migrations by steps
+	Rollback(steps int) error
+	// Migrations known to the server with their status
+	Migrations() ([]*Migration, error)
+	// CheckSchema returns error if the schema is not at the latest version
+	CheckSchema() error
+}
+
+// Migration is a versioned change of the database schema
+type Migration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"appliedAt"`
 }
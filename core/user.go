@@ -41,6 +41,19 @@ type UserStore interface {
 	Update(scm SCMProvider, user *scm.User, token *Token) error
 	// Bind a new user from another SCM to registered user
 	Bind(scm SCMProvider, user *User, scmUser *scm.User, token *Token) (*User, error)
+	// FindOIDC user with the subject of OpenID Connect provider
+	FindOIDC(subject string) (*User, error)
+	// CreateOIDC user from OpenID Connect provider, the login should not be taken
+	CreateOIDC(user *OIDCUser) (*User, error)
+	// UpdateOIDC profile of the user from OpenID Connect provider
+	UpdateOIDC(user *OIDCUser) (*User, error)
+	// BindOIDC identity of OpenID Connect provider to registered user
+	BindOIDC(user *User, oidcUser *OIDCUser) (*User, error)
 	ListRepositories(user *User) ([]*Repo, error)
 	UpdateRepositories(user *User, repositories []*Repo) error
+	// List all users ordered by login
+	List() ([]*User, error)
+	// Delete the user with its tokens and roles.
+	// It fails if the user is the creator of any repository.
+	Delete(user *User) error
 }
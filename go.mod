@@ -6,6 +6,7 @@ require (
 	github.com/ajstarks/svgo v0.0.0-20200725142600-7a3c8b57fecb
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751
 	github.com/blueworrybear/svg-charts v0.0.3
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/drone/go-login v1.0.4-0.20190311170324-2a4df4f242a2
 	github.com/drone/go-scm v1.7.1
 	github.com/dustin/go-humanize v1.0.0
@@ -16,6 +17,7 @@ require (
 	github.com/go-git/go-git/v5 v5.1.0
 	github.com/go-oauth2/oauth2/v4 v4.1.2
 	github.com/go-playground/validator/v10 v10.3.0 // indirect
+	github.com/go-redis/redis/v7 v7.4.0
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/golang/mock v1.4.4
 	github.com/google/go-cmp v0.5.2
@@ -24,7 +26,6 @@ require (
 	github.com/jinzhu/gorm v1.9.16
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible // indirect
-	github.com/narqo/go-badge v0.0.0-20190124110329-d9415e4e1e9f
 	github.com/nbio/st v0.0.0-20140626010706-e9e8d9816f32 // indirect
 	github.com/rs/xid v1.2.1
 	github.com/sabhiram/go-gitignore v0.0.0-20201211210132-54b8a0bf510f
@@ -34,7 +35,7 @@ require (
 	github.com/swaggo/swag v1.6.7
 	github.com/tidwall/gjson v1.6.1 // indirect
 	github.com/urfave/cli/v2 v2.2.0
-	golang.org/x/image v0.0.0-20200924062109-4578eab98f00 // indirect
+	golang.org/x/image v0.0.0-20200924062109-4578eab98f00
 	golang.org/x/net v0.0.0-20200925080053-05aa5d4ee321
 	golang.org/x/oauth2 v0.0.0-20200902213428-5d25da1a8d43
 	golang.org/x/sys v0.0.0-20200923182605-d9f96fdee20d // indirect
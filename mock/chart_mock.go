@@ -34,6 +34,34 @@ func (m *MockChartService) EXPECT() *MockChartServiceMockRecorder {
 	return m.recorder
 }
 
+// Badge mocks base method
+func (m *MockChartService) Badge(arg0 *core.Badge) core.Chart {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Badge", arg0)
+	ret0, _ := ret[0].(core.Chart)
+	return ret0
+}
+
+// Badge indicates an expected call of Badge
+func (mr *MockChartServiceMockRecorder) Badge(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Badge", reflect.TypeOf((*MockChartService)(nil).Badge), arg0)
+}
+
+// CoverageChangeTreeMap mocks base method
+func (m *MockChartService) CoverageChangeTreeMap(arg0, arg1 *core.Report, arg2 []*core.FileChange, arg3 int) core.Chart {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoverageChangeTreeMap", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(core.Chart)
+	return ret0
+}
+
+// CoverageChangeTreeMap indicates an expected call of CoverageChangeTreeMap
+func (mr *MockChartServiceMockRecorder) CoverageChangeTreeMap(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoverageChangeTreeMap", reflect.TypeOf((*MockChartService)(nil).CoverageChangeTreeMap), arg0, arg1, arg2, arg3)
+}
+
 // CoverageDiffTreeMap mocks base method
 func (m *MockChartService) CoverageDiffTreeMap(arg0, arg1 *core.Report) core.Chart {
 	m.ctrl.T.Helper()
@@ -48,18 +76,60 @@ func (mr *MockChartServiceMockRecorder) CoverageDiffTreeMap(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoverageDiffTreeMap", reflect.TypeOf((*MockChartService)(nil).CoverageDiffTreeMap), arg0, arg1)
 }
 
+// CoverageHierarchy mocks base method
+func (m *MockChartService) CoverageHierarchy(arg0 *core.Report, arg1 core.ChartLink) core.Chart {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoverageHierarchy", arg0, arg1)
+	ret0, _ := ret[0].(core.Chart)
+	return ret0
+}
+
+// CoverageHierarchy indicates an expected call of CoverageHierarchy
+func (mr *MockChartServiceMockRecorder) CoverageHierarchy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoverageHierarchy", reflect.TypeOf((*MockChartService)(nil).CoverageHierarchy), arg0, arg1)
+}
+
+// CoverageTrend mocks base method
+func (m *MockChartService) CoverageTrend(arg0 []*core.Report) core.Chart {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CoverageTrend", arg0)
+	ret0, _ := ret[0].(core.Chart)
+	return ret0
+}
+
+// CoverageTrend indicates an expected call of CoverageTrend
+func (mr *MockChartServiceMockRecorder) CoverageTrend(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CoverageTrend", reflect.TypeOf((*MockChartService)(nil).CoverageTrend), arg0)
+}
+
+// PNG mocks base method
+func (m *MockChartService) PNG(arg0 core.Chart, arg1, arg2 int) core.Chart {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PNG", arg0, arg1, arg2)
+	ret0, _ := ret[0].(core.Chart)
+	return ret0
+}
+
+// PNG indicates an expected call of PNG
+func (mr *MockChartServiceMockRecorder) PNG(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PNG", reflect.TypeOf((*MockChartService)(nil).PNG), arg0, arg1, arg2)
+}
+
 // RepoCard mocks base method
-func (m *MockChartService) RepoCard(arg0 *core.Repo, arg1 *core.Report) core.Chart {
+func (m *MockChartService) RepoCard(arg0 *core.Card) core.Chart {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RepoCard", arg0, arg1)
+	ret := m.ctrl.Call(m, "RepoCard", arg0)
 	ret0, _ := ret[0].(core.Chart)
 	return ret0
 }
 
 // RepoCard indicates an expected call of RepoCard
-func (mr *MockChartServiceMockRecorder) RepoCard(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockChartServiceMockRecorder) RepoCard(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepoCard", reflect.TypeOf((*MockChartService)(nil).RepoCard), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepoCard", reflect.TypeOf((*MockChartService)(nil).RepoCard), arg0)
 }
 
 // MockChart is a mock of Chart interface
@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	http "net/http"
 	reflect "reflect"
+	time "time"
 )
 
 // MockOAuthService is a mock of OAuthService interface
@@ -36,18 +37,18 @@ func (m *MockOAuthService) EXPECT() *MockOAuthServiceMockRecorder {
 }
 
 // CreateToken mocks base method
-func (m *MockOAuthService) CreateToken(arg0 context.Context, arg1 string) (*core.OAuthToken, error) {
+func (m *MockOAuthService) CreateToken(arg0 context.Context, arg1 string, arg2 []core.TokenScope, arg3 time.Duration) (*core.OAuthToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateToken", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateToken", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*core.OAuthToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateToken indicates an expected call of CreateToken
-func (mr *MockOAuthServiceMockRecorder) CreateToken(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockOAuthServiceMockRecorder) CreateToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockOAuthService)(nil).CreateToken), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockOAuthService)(nil).CreateToken), arg0, arg1, arg2, arg3)
 }
 
 // DeleteToken mocks base method
@@ -80,10 +81,10 @@ func (mr *MockOAuthServiceMockRecorder) ListTokens(arg0 interface{}) *gomock.Cal
 }
 
 // Validate mocks base method
-func (m *MockOAuthService) Validate(arg0 *http.Request) (*core.User, error) {
+func (m *MockOAuthService) Validate(arg0 *http.Request) (*core.OAuthToken, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Validate", arg0)
-	ret0, _ := ret[0].(*core.User)
+	ret0, _ := ret[0].(*core.OAuthToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -188,3 +189,17 @@ func (mr *MockOAuthStoreMockRecorder) List(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOAuthStore)(nil).List), arg0)
 }
+
+// UpdateLastUsed mocks base method
+func (m *MockOAuthStore) UpdateLastUsed(arg0 *core.OAuthToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsed", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsed indicates an expected call of UpdateLastUsed
+func (mr *MockOAuthStoreMockRecorder) UpdateLastUsed(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsed", reflect.TypeOf((*MockOAuthStore)(nil).UpdateLastUsed), arg0)
+}
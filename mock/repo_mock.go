@@ -62,6 +62,21 @@ func (mr *MockRepoStoreMockRecorder) Create(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepoStore)(nil).Create), arg0)
 }
 
+// CreateToken mocks base method
+func (m *MockRepoStore) CreateToken(arg0 *core.Repo, arg1 string) (*core.RepoToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", arg0, arg1)
+	ret0, _ := ret[0].(*core.RepoToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateToken indicates an expected call of CreateToken
+func (mr *MockRepoStoreMockRecorder) CreateToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockRepoStore)(nil).CreateToken), arg0, arg1)
+}
+
 // Creator mocks base method
 func (m *MockRepoStore) Creator(arg0 *core.Repo) (*core.User, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +92,20 @@ func (mr *MockRepoStoreMockRecorder) Creator(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Creator", reflect.TypeOf((*MockRepoStore)(nil).Creator), arg0)
 }
 
+// DeleteToken mocks base method
+func (m *MockRepoStore) DeleteToken(arg0 *core.Repo, arg1 *core.RepoToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteToken indicates an expected call of DeleteToken
+func (mr *MockRepoStoreMockRecorder) DeleteToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToken", reflect.TypeOf((*MockRepoStore)(nil).DeleteToken), arg0, arg1)
+}
+
 // Find mocks base method
 func (m *MockRepoStore) Find(arg0 *core.Repo) (*core.Repo, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +136,21 @@ func (mr *MockRepoStoreMockRecorder) FindHook(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHook", reflect.TypeOf((*MockRepoStore)(nil).FindHook), arg0)
 }
 
+// FindToken mocks base method
+func (m *MockRepoStore) FindToken(arg0 *core.Repo, arg1 string) (*core.RepoToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindToken", arg0, arg1)
+	ret0, _ := ret[0].(*core.RepoToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindToken indicates an expected call of FindToken
+func (mr *MockRepoStoreMockRecorder) FindToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindToken", reflect.TypeOf((*MockRepoStore)(nil).FindToken), arg0, arg1)
+}
+
 // Finds mocks base method
 func (m *MockRepoStore) Finds(arg0 ...string) ([]*core.Repo, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +170,51 @@ func (mr *MockRepoStoreMockRecorder) Finds(arg0 ...interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finds", reflect.TypeOf((*MockRepoStore)(nil).Finds), arg0...)
 }
 
+// ListActivated mocks base method
+func (m *MockRepoStore) ListActivated() ([]*core.Repo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActivated")
+	ret0, _ := ret[0].([]*core.Repo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActivated indicates an expected call of ListActivated
+func (mr *MockRepoStoreMockRecorder) ListActivated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActivated", reflect.TypeOf((*MockRepoStore)(nil).ListActivated))
+}
+
+// ListTokens mocks base method
+func (m *MockRepoStore) ListTokens(arg0 *core.Repo) ([]*core.RepoToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTokens", arg0)
+	ret0, _ := ret[0].([]*core.RepoToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTokens indicates an expected call of ListTokens
+func (mr *MockRepoStoreMockRecorder) ListTokens(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTokens", reflect.TypeOf((*MockRepoStore)(nil).ListTokens), arg0)
+}
+
+// RenewToken mocks base method
+func (m *MockRepoStore) RenewToken(arg0 *core.Repo, arg1 *core.RepoToken) (*core.RepoToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewToken", arg0, arg1)
+	ret0, _ := ret[0].(*core.RepoToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewToken indicates an expected call of RenewToken
+func (mr *MockRepoStoreMockRecorder) RenewToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewToken", reflect.TypeOf((*MockRepoStore)(nil).RenewToken), arg0, arg1)
+}
+
 // Setting mocks base method
 func (m *MockRepoStore) Setting(arg0 *core.Repo) (*core.RepoSetting, error) {
 	m.ctrl.T.Helper()
@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
+	time "time"
 )
 
 // MockReportStore is a mock of ReportStore interface
@@ -49,6 +50,55 @@ func (mr *MockReportStoreMockRecorder) CreateComment(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockReportStore)(nil).CreateComment), arg0, arg1)
 }
 
+// Delete mocks base method
+func (m *MockReportStore) Delete(arg0 string, arg1 ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReportStoreMockRecorder) Delete(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReportStore)(nil).Delete), varargs...)
+}
+
+// FileHistory mocks base method
+func (m *MockReportStore) FileHistory(arg0, arg1, arg2 string, arg3, arg4 time.Time) ([]*core.FileSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileHistory", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]*core.FileSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileHistory indicates an expected call of FileHistory
+func (mr *MockReportStoreMockRecorder) FileHistory(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileHistory", reflect.TypeOf((*MockReportStore)(nil).FileHistory), arg0, arg1, arg2, arg3, arg4)
+}
+
+// FileSummaries mocks base method
+func (m *MockReportStore) FileSummaries(arg0, arg1 string) ([]*core.FileSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FileSummaries", arg0, arg1)
+	ret0, _ := ret[0].([]*core.FileSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FileSummaries indicates an expected call of FileSummaries
+func (mr *MockReportStoreMockRecorder) FileSummaries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileSummaries", reflect.TypeOf((*MockReportStore)(nil).FileSummaries), arg0, arg1)
+}
+
 // Find mocks base method
 func (m *MockReportStore) Find(arg0 *core.Report) (*core.Report, error) {
 	m.ctrl.T.Helper()
@@ -79,34 +129,152 @@ func (mr *MockReportStoreMockRecorder) FindComment(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindComment", reflect.TypeOf((*MockReportStore)(nil).FindComment), arg0, arg1)
 }
 
+// FindSummary mocks base method
+func (m *MockReportStore) FindSummary(arg0 *core.Report) (*core.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSummary", arg0)
+	ret0, _ := ret[0].(*core.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSummary indicates an expected call of FindSummary
+func (mr *MockReportStoreMockRecorder) FindSummary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSummary", reflect.TypeOf((*MockReportStore)(nil).FindSummary), arg0)
+}
+
 // Finds mocks base method
-func (m *MockReportStore) Finds(arg0 *core.Report) ([]*core.Report, error) {
+func (m *MockReportStore) Finds(arg0 *core.Report, arg1 core.Page) ([]*core.Report, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Finds", arg0)
+	ret := m.ctrl.Call(m, "Finds", arg0, arg1)
 	ret0, _ := ret[0].([]*core.Report)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Finds indicates an expected call of Finds
+func (mr *MockReportStoreMockRecorder) Finds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finds", reflect.TypeOf((*MockReportStore)(nil).Finds), arg0, arg1)
+}
+
+// History mocks base method
+func (m *MockReportStore) History(arg0, arg1 string, arg2, arg3 time.Time) ([]*core.ReportSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*core.ReportSummary)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Finds indicates an expected call of Finds
-func (mr *MockReportStoreMockRecorder) Finds(arg0 interface{}) *gomock.Call {
+// History indicates an expected call of History
+func (mr *MockReportStoreMockRecorder) History(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finds", reflect.TypeOf((*MockReportStore)(nil).Finds), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockReportStore)(nil).History), arg0, arg1, arg2, arg3)
 }
 
 // List mocks base method
-func (m *MockReportStore) List(arg0, arg1 string) ([]*core.Report, error) {
+func (m *MockReportStore) List(arg0, arg1 string, arg2 core.Page) ([]*core.Report, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*core.Report)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List
+func (mr *MockReportStoreMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReportStore)(nil).List), arg0, arg1, arg2)
+}
+
+// ListComments mocks base method
+func (m *MockReportStore) ListComments(arg0 string) ([]*core.ReportComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListComments", arg0)
+	ret0, _ := ret[0].([]*core.ReportComment)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// List indicates an expected call of List
-func (mr *MockReportStoreMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+// ListComments indicates an expected call of ListComments
+func (mr *MockReportStoreMockRecorder) ListComments(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockReportStore)(nil).ListComments), arg0)
+}
+
+// ListReferences mocks base method
+func (m *MockReportStore) ListReferences(arg0 string) ([]*core.Reference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReferences", arg0)
+	ret0, _ := ret[0].([]*core.Reference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReferences indicates an expected call of ListReferences
+func (mr *MockReportStoreMockRecorder) ListReferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReferences", reflect.TypeOf((*MockReportStore)(nil).ListReferences), arg0)
+}
+
+// Restore mocks base method
+func (m *MockReportStore) Restore(arg0 *core.Report) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockReportStoreMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockReportStore)(nil).Restore), arg0)
+}
+
+// RestoreReference mocks base method
+func (m *MockReportStore) RestoreReference(arg0 string, arg1 *core.Reference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreReference", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreReference indicates an expected call of RestoreReference
+func (mr *MockReportStoreMockRecorder) RestoreReference(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreReference", reflect.TypeOf((*MockReportStore)(nil).RestoreReference), arg0, arg1)
+}
+
+// Transaction mocks base method
+func (m *MockReportStore) Transaction(arg0 func(core.ReportStore) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction
+func (mr *MockReportStoreMockRecorder) Transaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockReportStore)(nil).Transaction), arg0)
+}
+
+// UpdateBranchDeleted mocks base method
+func (m *MockReportStore) UpdateBranchDeleted(arg0, arg1 string, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBranchDeleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBranchDeleted indicates an expected call of UpdateBranchDeleted
+func (mr *MockReportStoreMockRecorder) UpdateBranchDeleted(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReportStore)(nil).List), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBranchDeleted", reflect.TypeOf((*MockReportStore)(nil).UpdateBranchDeleted), arg0, arg1, arg2)
 }
 
 // Upload mocks base method
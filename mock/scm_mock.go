@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/covergates/covergates/core (interfaces: SCMService,Client,GitRepoService,UserService,ContentService,GitService,WebhookService)
+// Source: github.com/covergates/covergates/core (interfaces: SCMService,Client,GitRepoService,UserService,ContentService,GitService,PullRequestService,WebhookService)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -239,20 +239,6 @@ func (mr *MockGitRepoServiceMockRecorder) Find(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockGitRepoService)(nil).Find), arg0, arg1, arg2)
 }
 
-// IsAdmin mocks base method
-func (m *MockGitRepoService) IsAdmin(arg0 context.Context, arg1 *core.User, arg2 string) bool {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsAdmin", arg0, arg1, arg2)
-	ret0, _ := ret[0].(bool)
-	return ret0
-}
-
-// IsAdmin indicates an expected call of IsAdmin
-func (mr *MockGitRepoServiceMockRecorder) IsAdmin(arg0, arg1, arg2 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdmin", reflect.TypeOf((*MockGitRepoService)(nil).IsAdmin), arg0, arg1, arg2)
-}
-
 // List mocks base method
 func (m *MockGitRepoService) List(arg0 context.Context, arg1 *core.User) ([]*core.Repo, error) {
 	m.ctrl.T.Helper()
@@ -282,6 +268,21 @@ func (mr *MockGitRepoServiceMockRecorder) NewReportID(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewReportID", reflect.TypeOf((*MockGitRepoService)(nil).NewReportID), arg0)
 }
 
+// Permission mocks base method
+func (m *MockGitRepoService) Permission(arg0 context.Context, arg1 *core.User, arg2 string) (*core.RepoPerm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Permission", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*core.RepoPerm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Permission indicates an expected call of Permission
+func (mr *MockGitRepoServiceMockRecorder) Permission(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permission", reflect.TypeOf((*MockGitRepoService)(nil).Permission), arg0, arg1, arg2)
+}
+
 // RemoveHook mocks base method
 func (m *MockGitRepoService) RemoveHook(arg0 context.Context, arg1 *core.User, arg2 string, arg3 *core.Hook) error {
 	m.ctrl.T.Helper()
@@ -485,48 +486,149 @@ func (mr *MockGitServiceMockRecorder) GitRepository(arg0, arg1, arg2 interface{}
 }
 
 // ListBranches mocks base method
-func (m *MockGitService) ListBranches(arg0 context.Context, arg1 *core.User, arg2 string) ([]string, error) {
+func (m *MockGitService) ListBranches(arg0 context.Context, arg1 *core.User, arg2 string, arg3 core.Page) ([]string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListBranches", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ListBranches", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // ListBranches indicates an expected call of ListBranches
-func (mr *MockGitServiceMockRecorder) ListBranches(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockGitServiceMockRecorder) ListBranches(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranches", reflect.TypeOf((*MockGitService)(nil).ListBranches), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBranches", reflect.TypeOf((*MockGitService)(nil).ListBranches), arg0, arg1, arg2, arg3)
 }
 
 // ListCommits mocks base method
-func (m *MockGitService) ListCommits(arg0 context.Context, arg1 *core.User, arg2 string) ([]*core.Commit, error) {
+func (m *MockGitService) ListCommits(arg0 context.Context, arg1 *core.User, arg2 string, arg3 core.Page) ([]*core.Commit, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCommits", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ListCommits", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]*core.Commit)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // ListCommits indicates an expected call of ListCommits
-func (mr *MockGitServiceMockRecorder) ListCommits(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockGitServiceMockRecorder) ListCommits(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommits", reflect.TypeOf((*MockGitService)(nil).ListCommits), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommits", reflect.TypeOf((*MockGitService)(nil).ListCommits), arg0, arg1, arg2, arg3)
 }
 
 // ListCommitsByRef mocks base method
-func (m *MockGitService) ListCommitsByRef(arg0 context.Context, arg1 *core.User, arg2, arg3 string) ([]*core.Commit, error) {
+func (m *MockGitService) ListCommitsByRef(arg0 context.Context, arg1 *core.User, arg2, arg3 string, arg4 core.Page) ([]*core.Commit, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListCommitsByRef", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "ListCommitsByRef", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]*core.Commit)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListCommitsByRef indicates an expected call of ListCommitsByRef
+func (mr *MockGitServiceMockRecorder) ListCommitsByRef(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommitsByRef", reflect.TypeOf((*MockGitService)(nil).ListCommitsByRef), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListTags mocks base method
+func (m *MockGitService) ListTags(arg0 context.Context, arg1 *core.User, arg2 string, arg3 core.Page) ([]string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTags indicates an expected call of ListTags
+func (mr *MockGitServiceMockRecorder) ListTags(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockGitService)(nil).ListTags), arg0, arg1, arg2, arg3)
+}
+
+// MockPullRequestService is a mock of PullRequestService interface
+type MockPullRequestService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPullRequestServiceMockRecorder
+}
+
+// MockPullRequestServiceMockRecorder is the mock recorder for MockPullRequestService
+type MockPullRequestServiceMockRecorder struct {
+	mock *MockPullRequestService
+}
+
+// NewMockPullRequestService creates a new mock instance
+func NewMockPullRequestService(ctrl *gomock.Controller) *MockPullRequestService {
+	mock := &MockPullRequestService{ctrl: ctrl}
+	mock.recorder = &MockPullRequestServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPullRequestService) EXPECT() *MockPullRequestServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method
+func (m *MockPullRequestService) CreateComment(arg0 context.Context, arg1 *core.User, arg2 string, arg3 int, arg4 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListCommitsByRef indicates an expected call of ListCommitsByRef
-func (mr *MockGitServiceMockRecorder) ListCommitsByRef(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// CreateComment indicates an expected call of CreateComment
+func (mr *MockPullRequestServiceMockRecorder) CreateComment(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockPullRequestService)(nil).CreateComment), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Find mocks base method
+func (m *MockPullRequestService) Find(arg0 context.Context, arg1 *core.User, arg2 string, arg3 int) (*core.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*core.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockPullRequestServiceMockRecorder) Find(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockPullRequestService)(nil).Find), arg0, arg1, arg2, arg3)
+}
+
+// ListChanges mocks base method
+func (m *MockPullRequestService) ListChanges(arg0 context.Context, arg1 *core.User, arg2 string, arg3 int) ([]*core.FileChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChanges", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*core.FileChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChanges indicates an expected call of ListChanges
+func (mr *MockPullRequestServiceMockRecorder) ListChanges(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChanges", reflect.TypeOf((*MockPullRequestService)(nil).ListChanges), arg0, arg1, arg2, arg3)
+}
+
+// RemoveComment mocks base method
+func (m *MockPullRequestService) RemoveComment(arg0 context.Context, arg1 *core.User, arg2 string, arg3, arg4 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveComment", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveComment indicates an expected call of RemoveComment
+func (mr *MockPullRequestServiceMockRecorder) RemoveComment(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommitsByRef", reflect.TypeOf((*MockGitService)(nil).ListCommitsByRef), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveComment", reflect.TypeOf((*MockPullRequestService)(nil).RemoveComment), arg0, arg1, arg2, arg3, arg4)
 }
 
 // MockWebhookService is a mock of WebhookService interface
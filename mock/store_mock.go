@@ -5,6 +5,7 @@
 package mock
 
 import (
+	core "github.com/covergates/covergates/core"
 	gomock "github.com/golang/mock/gomock"
 	gorm "gorm.io/gorm"
 	reflect "reflect"
@@ -33,6 +34,20 @@ func (m *MockDatabaseService) EXPECT() *MockDatabaseServiceMockRecorder {
 	return m.recorder
 }
 
+// CheckSchema mocks base method
+func (m *MockDatabaseService) CheckSchema() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSchema")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSchema indicates an expected call of CheckSchema
+func (mr *MockDatabaseServiceMockRecorder) CheckSchema() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSchema", reflect.TypeOf((*MockDatabaseService)(nil).CheckSchema))
+}
+
 // Migrate mocks base method
 func (m *MockDatabaseService) Migrate() error {
 	m.ctrl.T.Helper()
@@ -47,6 +62,35 @@ func (mr *MockDatabaseServiceMockRecorder) Migrate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockDatabaseService)(nil).Migrate))
 }
 
+// Migrations mocks base method
+func (m *MockDatabaseService) Migrations() ([]*core.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrations")
+	ret0, _ := ret[0].([]*core.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Migrations indicates an expected call of Migrations
+func (mr *MockDatabaseServiceMockRecorder) Migrations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrations", reflect.TypeOf((*MockDatabaseService)(nil).Migrations))
+}
+
+// Rollback mocks base method
+func (m *MockDatabaseService) Rollback(arg0 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback
+func (mr *MockDatabaseServiceMockRecorder) Rollback(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockDatabaseService)(nil).Rollback), arg0)
+}
+
 // Session mocks base method
 func (m *MockDatabaseService) Session() *gorm.DB {
 	m.ctrl.T.Helper()
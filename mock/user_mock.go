@@ -49,6 +49,21 @@ func (mr *MockUserStoreMockRecorder) Bind(arg0, arg1, arg2, arg3 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Bind", reflect.TypeOf((*MockUserStore)(nil).Bind), arg0, arg1, arg2, arg3)
 }
 
+// BindOIDC mocks base method
+func (m *MockUserStore) BindOIDC(arg0 *core.User, arg1 *core.OIDCUser) (*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BindOIDC", arg0, arg1)
+	ret0, _ := ret[0].(*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BindOIDC indicates an expected call of BindOIDC
+func (mr *MockUserStoreMockRecorder) BindOIDC(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BindOIDC", reflect.TypeOf((*MockUserStore)(nil).BindOIDC), arg0, arg1)
+}
+
 // Create mocks base method
 func (m *MockUserStore) Create(arg0 core.SCMProvider, arg1 *scm.User, arg2 *core.Token) error {
 	m.ctrl.T.Helper()
@@ -63,6 +78,35 @@ func (mr *MockUserStoreMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserStore)(nil).Create), arg0, arg1, arg2)
 }
 
+// CreateOIDC mocks base method
+func (m *MockUserStore) CreateOIDC(arg0 *core.OIDCUser) (*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDC", arg0)
+	ret0, _ := ret[0].(*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOIDC indicates an expected call of CreateOIDC
+func (mr *MockUserStoreMockRecorder) CreateOIDC(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDC", reflect.TypeOf((*MockUserStore)(nil).CreateOIDC), arg0)
+}
+
+// Delete mocks base method
+func (m *MockUserStore) Delete(arg0 *core.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockUserStoreMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserStore)(nil).Delete), arg0)
+}
+
 // Find mocks base method
 func (m *MockUserStore) Find(arg0 core.SCMProvider, arg1 *scm.User) (*core.User, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +137,36 @@ func (mr *MockUserStoreMockRecorder) FindByLogin(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLogin", reflect.TypeOf((*MockUserStore)(nil).FindByLogin), arg0)
 }
 
+// FindOIDC mocks base method
+func (m *MockUserStore) FindOIDC(arg0 string) (*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOIDC", arg0)
+	ret0, _ := ret[0].(*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOIDC indicates an expected call of FindOIDC
+func (mr *MockUserStoreMockRecorder) FindOIDC(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOIDC", reflect.TypeOf((*MockUserStore)(nil).FindOIDC), arg0)
+}
+
+// List mocks base method
+func (m *MockUserStore) List() ([]*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List")
+	ret0, _ := ret[0].([]*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockUserStoreMockRecorder) List() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserStore)(nil).List))
+}
+
 // ListRepositories mocks base method
 func (m *MockUserStore) ListRepositories(arg0 *core.User) ([]*core.Repo, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +196,21 @@ func (mr *MockUserStoreMockRecorder) Update(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserStore)(nil).Update), arg0, arg1, arg2)
 }
 
+// UpdateOIDC mocks base method
+func (m *MockUserStore) UpdateOIDC(arg0 *core.OIDCUser) (*core.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOIDC", arg0)
+	ret0, _ := ret[0].(*core.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOIDC indicates an expected call of UpdateOIDC
+func (mr *MockUserStoreMockRecorder) UpdateOIDC(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOIDC", reflect.TypeOf((*MockUserStore)(nil).UpdateOIDC), arg0)
+}
+
 // UpdateRepositories mocks base method
 func (m *MockUserStore) UpdateRepositories(arg0 *core.User, arg1 []*core.Repo) error {
 	m.ctrl.T.Helper()
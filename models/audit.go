@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+)
+
+// AuditLog defines a recorded action to a repository or a user account
+type AuditLog struct {
+	gorm.Model
+	// RepoID is zero for actions to the account of the actor
+	RepoID uint `gorm:"index"`
+	Action string
+	Actor  string
+	IP     string
+	Before []byte
+	After  []byte
+}
+
+// AuditStore audit logs in storage
+type AuditStore struct {
+	DB core.DatabaseService
+}
+
+// Create an audit log
+func (store *AuditStore) Create(log *core.AuditLog) error {
+	var repoID uint
+	switch {
+	case log.Repo != nil && log.Repo.ID > 0:
+		repoID = log.Repo.ID
+	case log.Repo == nil && log.Actor != "":
+	default:
+		return fmt.Errorf("invalid repository")
+	}
+	session := store.DB.Session()
+	l := &AuditLog{
+		RepoID: repoID,
+		Action: string(log.Action),
+		Actor:  log.Actor,
+		IP:     log.IP,
+		Before: log.Before,
+		After:  log.After,
+	}
+	if err := session.Create(l).Error; err != nil {
+		return err
+	}
+	log.ID = l.ID
+	log.CreatedAt = l.CreatedAt
+	return nil
+}
+
+// List audit logs of the repository, the latest first
+func (store *AuditStore) List(repo *core.Repo) ([]*core.AuditLog, error) {
+	session := store.DB.Session()
+	var logs []*AuditLog
+	if err := session.Where(
+		&AuditLog{RepoID: repo.ID},
+	).Order("id desc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.AuditLog, len(logs))
+	for i, log := range logs {
+		result[i] = log.toCoreAuditLog(repo)
+	}
+	return result, nil
+}
+
+// ListUser audit logs of actions to the account of the user, the latest first
+func (store *AuditStore) ListUser(user *core.User) ([]*core.AuditLog, error) {
+	if user.Login == "" {
+		return nil, fmt.Errorf("user login should not be empty")
+	}
+	session := store.DB.Session()
+	var logs []*AuditLog
+	if err := session.Where(
+		"repo_id = ? AND actor = ?", 0, user.Login,
+	).Order("id desc").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.AuditLog, len(logs))
+	for i, log := range logs {
+		result[i] = log.toCoreAuditLog(nil)
+	}
+	return result, nil
+}
+
+func (log *AuditLog) toCoreAuditLog(repo *core.Repo) *core.AuditLog {
+	return &core.AuditLog{
+		ID:        log.ID,
+		Repo:      repo,
+		Action:    core.AuditAction(log.Action),
+		Actor:     log.Actor,
+		IP:        log.IP,
+		Before:    json.RawMessage(log.Before),
+		After:     json.RawMessage(log.After),
+		CreatedAt: log.CreatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/covergates/covergates/core"
+)
+
+func TestAudit(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+
+	store := &AuditStore{DB: db}
+	repoStore := &RepoStore{DB: db}
+	repo := &core.Repo{
+		URL:       "http://gitea/audit/repo",
+		NameSpace: "audit",
+		Name:      "repo",
+		SCM:       core.Gitea,
+	}
+	if err := repoStore.Create(repo); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repoStore.Find(&core.Repo{URL: repo.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Create(&core.AuditLog{Action: core.AuditHookCreate}); err == nil {
+		t.Fatal("should require repository or actor")
+	}
+	logs := []*core.AuditLog{
+		{
+			Repo:   repo,
+			Action: core.AuditSettingUpdate,
+			Actor:  "user",
+			IP:     "127.0.0.1",
+			Before: []byte(`{"protected":false}`),
+			After:  []byte(`{"protected":true}`),
+		},
+		{
+			Repo:   repo,
+			Action: core.AuditUploadRejected,
+			IP:     "127.0.0.2",
+		},
+	}
+	for _, log := range logs {
+		if err := store.Create(log); err != nil {
+			t.Fatal(err)
+		}
+		if log.ID == 0 || log.CreatedAt.IsZero() {
+			t.Fatal("should fill id and time")
+		}
+	}
+
+	result, err := store.List(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expect 2 logs, got %d", len(result))
+	}
+	if result[0].Action != core.AuditUploadRejected || result[0].Actor != "" {
+		t.Fatal("should list the latest first")
+	}
+	if string(result[1].Before) != `{"protected":false}` ||
+		string(result[1].After) != `{"protected":true}` ||
+		result[1].Actor != "user" || result[1].IP != "127.0.0.1" {
+		t.Fatalf("unexpected log %v", result[1])
+	}
+
+	// actions to the account of the actor
+	for _, log := range []*core.AuditLog{
+		{Action: core.AuditUserTokenCreate, Actor: "user", After: []byte(`{"name":"token"}`)},
+		{Action: core.AuditUserTokenDelete, Actor: "user", Before: []byte(`{"name":"token"}`)},
+		{Action: core.AuditUserTokenCreate, Actor: "other"},
+	} {
+		if err := store.Create(log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err = store.ListUser(&core.User{Login: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Action != core.AuditUserTokenDelete || result[1].Repo != nil {
+		t.Fatalf("unexpected user logs %v", result)
+	}
+	if result, _ := store.List(repo); len(result) != 2 {
+		t.Fatal("should not list user logs of repository")
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlobLock serializes transactions using a blob, such as uploads referencing the blob
+// and the removal of the blob once it is no longer referenced by any coverage
+type BlobLock struct {
+	BlobKey  string `gorm:"primaryKey;size:64"`
+	LockedAt time.Time
+}
+
+// lockBlob of the key until the transaction ends, which waits for other transactions locking the key
+func lockBlob(tx *gorm.DB, key string) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "blob_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locked_at"}),
+	}).Create(&BlobLock{BlobKey: key, LockedAt: time.Now()}).Error
+}
+
+// blobKey of the data, which is the SHA-256 hex digest as keys of the blob store
+func blobKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
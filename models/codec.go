@@ -0,0 +1,235 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/covergates/covergates/core"
+)
+
+// coverageMagic tags coverage data in the compact encoding.
+// Data without the tag is the legacy JSON encoding.
+var coverageMagic = []byte{'C', 'G', 'C'}
+
+const coverageVersion byte = 1
+
+// maxCoverageString is the longest string, such as a file name, to decode
+const maxCoverageString = 1 << 16
+
+// errCoverageFormat is returned for malformed compact coverage data
+var errCoverageFormat = errors.New("malformed coverage data")
+
+// encodeCoverage in the compact encoding.
+//
+// The header is the magic and a version byte followed by a gzip stream of:
+//
+//	type, statement coverage, number of files
+//	for each file: name, statement coverage, number of hits
+//	for each hit: line number delta and hits, both zigzag varint
+//
+// Strings are prefixed with uvarint length and floats are 8 bytes little endian.
+func encodeCoverage(cov *core.CoverageReport) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.Write(coverageMagic)
+	buf.WriteByte(coverageVersion)
+	zw, err := gzip.NewWriterLevel(buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	w := &coverageWriter{w: bufio.NewWriter(zw)}
+	w.string(string(cov.Type))
+	w.float(cov.StatementCoverage)
+	w.uvarint(uint64(len(cov.Files)))
+	for _, file := range cov.Files {
+		w.string(file.Name)
+		w.float(file.StatementCoverage)
+		w.uvarint(uint64(len(file.StatementHits)))
+		last := 0
+		for _, hit := range file.StatementHits {
+			w.varint(int64(hit.LineNumber - last))
+			w.varint(int64(hit.Hits))
+			last = hit.LineNumber
+		}
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCoverage of either the compact or the legacy JSON encoding
+func decodeCoverage(data []byte, cov *core.CoverageReport) error {
+	if !bytes.HasPrefix(data, coverageMagic) {
+		return json.Unmarshal(data, cov)
+	}
+	data = data[len(coverageMagic):]
+	if len(data) == 0 {
+		return errCoverageFormat
+	}
+	if version := data[0]; version != coverageVersion {
+		return fmt.Errorf("coverage encoding version %d not support", version)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	r := &coverageReader{r: bufio.NewReader(zr)}
+	cov.Type = core.ReportType(r.string())
+	cov.StatementCoverage = r.float()
+	files := r.length()
+	cov.Files = nil
+	if files > 0 {
+		cov.Files = make([]*core.File, 0, capacity(files))
+	}
+	for i := 0; i < files && r.err == nil; i++ {
+		file := &core.File{
+			Name:              r.string(),
+			StatementCoverage: r.float(),
+		}
+		n := r.length()
+		hits := make([]core.StatementHit, 0, capacity(n))
+		last := 0
+		for j := 0; j < n && r.err == nil; j++ {
+			last += int(r.varint())
+			hits = append(hits, core.StatementHit{LineNumber: last, Hits: int(r.varint())})
+		}
+		if len(hits) > 0 {
+			file.StatementHits = make([]*core.StatementHit, len(hits))
+		}
+		for j := range hits {
+			file.StatementHits[j] = &hits[j]
+		}
+		cov.Files = append(cov.Files, file)
+	}
+	return r.err
+}
+
+// capacity to preallocate for a decoded length, which is not trusted
+func capacity(n int) int {
+	const limit = 1 << 16
+	if n > limit {
+		return limit
+	}
+	return n
+}
+
+type coverageWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (w *coverageWriter) write(p []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(p)
+	}
+}
+
+func (w *coverageWriter) uvarint(v uint64) {
+	n := binary.PutUvarint(w.buf[:], v)
+	w.write(w.buf[:n])
+}
+
+func (w *coverageWriter) varint(v int64) {
+	n := binary.PutVarint(w.buf[:], v)
+	w.write(w.buf[:n])
+}
+
+func (w *coverageWriter) float(v float64) {
+	binary.LittleEndian.PutUint64(w.buf[:8], math.Float64bits(v))
+	w.write(w.buf[:8])
+}
+
+func (w *coverageWriter) string(s string) {
+	w.uvarint(uint64(len(s)))
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+type coverageReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (r *coverageReader) fail(err error) {
+	if r.err != nil {
+		return
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errCoverageFormat
+	}
+	r.err = err
+}
+
+func (r *coverageReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.fail(err)
+	return v
+}
+
+func (r *coverageReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.fail(err)
+	return v
+}
+
+func (r *coverageReader) length() int {
+	n := r.uvarint()
+	if n > math.MaxInt32 {
+		r.fail(errCoverageFormat)
+		return 0
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (r *coverageReader) float() float64 {
+	if r.err != nil {
+		return 0
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r.r, buf[:]); err != nil {
+		r.fail(err)
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf[:]))
+}
+
+func (r *coverageReader) string() string {
+	n := r.length()
+	if n > maxCoverageString {
+		r.fail(errCoverageFormat)
+	}
+	if r.err != nil || n == 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		r.fail(err)
+		return ""
+	}
+	return string(buf)
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+)
+
+func makeCoverage(files, lines int) *core.CoverageReport {
+	cov := &core.CoverageReport{
+		Type:  core.ReportGo,
+		Files: make([]*core.File, files),
+	}
+	for i := range cov.Files {
+		file := &core.File{
+			Name:          fmt.Sprintf("github.com/org/repo/pkg%d/module/file%d.go", i/20, i),
+			StatementHits: make([]*core.StatementHit, lines),
+		}
+		covered := 0
+		for j := range file.StatementHits {
+			hits := (i + j) % 4
+			if hits > 0 {
+				covered++
+			}
+			file.StatementHits[j] = &core.StatementHit{LineNumber: 3 + j*2 + j%3, Hits: hits}
+		}
+		file.StatementCoverage = float64(covered) / float64(lines)
+		cov.Files[i] = file
+	}
+	cov.StatementCoverage = cov.ComputeStatementCoverage()
+	return cov
+}
+
+func TestCoverageCodec(t *testing.T) {
+	cov := makeCoverage(10, 30)
+	cov.Files[0].StatementHits[1].LineNumber = 1 // out of order lines
+	cov.Files[1].StatementHits = nil
+	data, err := encodeCoverage(cov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := json.Marshal(cov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(legacy) {
+		t.Fatalf("compact encoding %d bytes should be smaller than JSON %d bytes", len(data), len(legacy))
+	}
+	for _, data := range [][]byte{data, legacy} {
+		result := &core.CoverageReport{}
+		if err := decodeCoverage(data, result); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(cov, result); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	empty := &core.CoverageReport{Type: core.ReportPerl}
+	data, err = encodeCoverage(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &core.CoverageReport{}
+	if err := decodeCoverage(data, result); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(empty, result); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCoverageCodecMalformed(t *testing.T) {
+	data, err := encodeCoverage(makeCoverage(3, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := append([]byte{}, data...)
+	version[len(coverageMagic)] = coverageVersion + 1
+	for _, data := range [][]byte{
+		data[:len(coverageMagic)],
+		data[:len(data)/2],
+		version,
+	} {
+		if err := decodeCoverage(data, &core.CoverageReport{}); err == nil {
+			t.Fatal("should fail to decode malformed data")
+		}
+	}
+}
+
+const benchmarkFiles = 50000
+
+func BenchmarkCoverageEncoding(b *testing.B) {
+	cov := makeCoverage(benchmarkFiles, 20)
+	encoders := []struct {
+		name   string
+		encode func(*core.CoverageReport) ([]byte, error)
+	}{
+		{"json", func(cov *core.CoverageReport) ([]byte, error) { return json.Marshal(cov) }},
+		{"compact", encodeCoverage},
+	}
+	for _, encoder := range encoders {
+		b.Run(encoder.name, func(b *testing.B) {
+			var data []byte
+			for i := 0; i < b.N; i++ {
+				var err error
+				if data, err = encoder.encode(cov); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(data)), "bytes")
+		})
+	}
+}
+
+func BenchmarkReportStoreFind(b *testing.B) {
+	cov := makeCoverage(benchmarkFiles, 20)
+	legacy, err := json.Marshal(cov)
+	if err != nil {
+		b.Fatal(err)
+	}
+	compact, err := encodeCoverage(cov)
+	if err != nil {
+		b.Fatal(err)
+	}
+	store := &ReportStore{DB: NewDatabaseService(db, nil)}
+	for _, encoding := range []struct {
+		name string
+		data []byte
+	}{
+		{"json", legacy},
+		{"compact", compact},
+	} {
+		report := &Report{
+			ReportID:  "BenchmarkReportStoreFind",
+			Commit:    encoding.name,
+			Coverages: []*Coverage{{Type: string(core.ReportGo), Data: encoding.data}},
+		}
+		if err := db.Create(report).Error; err != nil {
+			b.Fatal(err)
+		}
+		b.Run(encoding.name, func(b *testing.B) {
+			b.ReportMetric(float64(len(encoding.data)), "bytes")
+			for i := 0; i < b.N; i++ {
+				if _, err := store.Find(&core.Report{
+					ReportID: report.ReportID,
+					Commit:   report.Commit,
+				}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		db.Unscoped().Where(&Coverage{ReportID: report.ID}).Delete(&Coverage{})
+		db.Unscoped().Delete(report)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 var errUserExist = errors.New("user already exist")
 
+var errUserIsCreator = errors.New("user is the creator of repositories, transfer them first")
+
 type errNotSupportedSCM struct {
 	scm core.SCMProvider
 }
@@ -22,3 +24,24 @@ func IsErrNotSupportedSCM(err error) bool {
 	_, ok := err.(*errNotSupportedSCM)
 	return ok
 }
+
+// errSchemaMismatch if the database schema is not at the latest version
+type errSchemaMismatch struct {
+	// pending migrations not applied yet
+	pending []int
+	// unknown migrations applied by a newer server
+	unknown []int
+}
+
+func (e *errSchemaMismatch) Error() string {
+	if len(e.unknown) > 0 {
+		return fmt.Sprintf("database schema is newer than the server, unknown migrations %v", e.unknown)
+	}
+	return fmt.Sprintf("database schema is outdated, pending migrations %v", e.pending)
+}
+
+// IsErrSchemaMismatch check
+func IsErrSchemaMismatch(err error) bool {
+	_, ok := err.(*errSchemaMismatch)
+	return ok
+}
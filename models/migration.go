@@ -0,0 +1,395 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/covergates/covergates/core"
+)
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName for GORM
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migration is a versioned schema change, which is applied in a transaction
+type migration struct {
+	version int
+	name    string
+	up      step
+	down    step
+}
+
+// migrator applies migrations with the blob store,
+// which is nil if data should be kept in the database
+type migrator struct {
+	tx   *gorm.DB
+	blob core.BlobStore
+}
+
+// resumable if a failed migration may be partially applied, as MySQL commits
+// DDL statements implicitly in transactions. Schema steps skip changes made by the
+// failed attempt only in that case, and fail on other databases if the schema drifted.
+func (m *migrator) resumable() bool {
+	return m.tx.Dialector.Name() == "mysql"
+}
+
+type step func(m *migrator) error
+
+// migrate the schema up to the latest version
+func migrate(db *gorm.DB, blob core.BlobStore) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	if err := checkUnknown(applied); err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(&migrator{tx: tx, blob: blob}); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   m.version,
+				Name:      m.name,
+				AppliedAt: time.Now(),
+			}).Error
+		}); err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// rollback the latest applied migrations by steps
+func rollback(db *gorm.DB, blob core.BlobStore, steps int) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	if err := checkUnknown(applied); err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.down(&migrator{tx: tx, blob: blob}); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: m.version}).Error
+		}); err != nil {
+			return fmt.Errorf("rollback %d %s: %w", m.version, m.name, err)
+		}
+		steps--
+	}
+	return nil
+}
+
+// migrationStatus lists known migrations in order
+func migrationStatus(db *gorm.DB) ([]*core.Migration, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*core.Migration, len(migrations))
+	for i, m := range migrations {
+		result[i] = &core.Migration{
+			Version: m.version,
+			Name:    m.name,
+		}
+		if record, ok := applied[m.version]; ok {
+			result[i].Applied = true
+			result[i].AppliedAt = record.AppliedAt
+		}
+	}
+	return result, nil
+}
+
+// checkSchema returns errSchemaMismatch if any migration is pending or unknown
+func checkSchema(db *gorm.DB) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	if err := checkUnknown(applied); err != nil {
+		return err
+	}
+	var pending []int
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; !ok {
+			pending = append(pending, m.version)
+		}
+	}
+	if len(pending) > 0 {
+		return &errSchemaMismatch{pending: pending}
+	}
+	return nil
+}
+
+func appliedMigrations(db *gorm.DB) (map[int]*SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var records []*SchemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]*SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// checkUnknown migrations, which are applied by a newer server
+func checkUnknown(applied map[int]*SchemaMigration) error {
+	known := make(map[int]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.version] = true
+	}
+	var unknown []int
+	for version := range applied {
+		if !known[version] {
+			unknown = append(unknown, version)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Ints(unknown)
+		return &errSchemaMismatch{unknown: unknown}
+	}
+	return nil
+}
+
+// autoMigrate the models, which keeps tables created before versioned migrations
+func autoMigrate(models ...interface{}) step {
+	return func(m *migrator) error {
+		return m.tx.AutoMigrate(models...)
+	}
+}
+
+// createTables of the models.
+// Unlike autoMigrate, tables of the associations are never altered.
+func createTables(models ...interface{}) step {
+	return func(m *migrator) error {
+		schema := m.tx.Migrator()
+		for _, model := range models {
+			if m.resumable() && schema.HasTable(model) {
+				continue
+			}
+			if err := schema.CreateTable(model); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func dropTables(tables ...interface{}) step {
+	return func(m *migrator) error {
+		return m.tx.Migrator().DropTable(tables...)
+	}
+}
+
+// addColumns of the model
+func addColumns(model interface{}, fields ...string) step {
+	return func(m *migrator) error {
+		schema := m.tx.Migrator()
+		for _, field := range fields {
+			if m.resumable() && schema.HasColumn(model, field) {
+				continue
+			}
+			if err := schema.AddColumn(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createIndexes of the model
+func createIndexes(model interface{}, fields ...string) step {
+	return func(m *migrator) error {
+		schema := m.tx.Migrator()
+		for _, field := range fields {
+			if m.resumable() && schema.HasIndex(model, field) {
+				continue
+			}
+			if err := schema.CreateIndex(model, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dialects runs the step of the database dialect, or the step of "" for others
+func dialects(steps map[string]step) step {
+	return func(m *migrator) error {
+		step, ok := steps[m.tx.Dialector.Name()]
+		if !ok {
+			step = steps[""]
+		}
+		return step(m)
+	}
+}
+
+// dropColumns of the table with their indexes
+func dropColumns(table string, columns ...string) step {
+	alter := func(m *migrator) error {
+		for _, column := range columns {
+			if m.resumable() && !mysqlHasColumn(m.tx, table, column) {
+				continue
+			}
+			if err := m.tx.Exec(
+				"ALTER TABLE ? DROP COLUMN ?",
+				clause.Table{Name: table},
+				clause.Column{Name: column},
+			).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return dialects(map[string]step{
+		"sqlite": func(m *migrator) error {
+			return sqliteDropColumns(m.tx, table, columns...)
+		},
+		"": alter,
+	})
+}
+
+func mysqlHasColumn(tx *gorm.DB, table, column string) bool {
+	var count int64
+	if err := tx.Raw(
+		"SELECT count(*) FROM INFORMATION_SCHEMA.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?",
+		table, column,
+	).Row().Scan(&count); err != nil {
+		return true
+	}
+	return count > 0
+}
+
+// sqliteDropColumns rebuilds the table without the columns,
+// as SQLite before 3.35 is not able to drop columns.
+// Indexes of the remaining columns are restored.
+func sqliteDropColumns(tx *gorm.DB, table string, columns ...string) error {
+	var createSQL string
+	if err := tx.Raw(
+		"SELECT sql FROM sqlite_master WHERE type = ? AND name = ?", "table", table,
+	).Row().Scan(&createSQL); err != nil {
+		return err
+	}
+	indexes, err := queryStrings(tx.Raw(
+		"SELECT sql FROM sqlite_master WHERE type = ? AND tbl_name = ? AND sql IS NOT NULL",
+		"index", table,
+	))
+	if err != nil {
+		return err
+	}
+	names, err := queryStrings(tx.Raw("SELECT name FROM pragma_table_info(?)", table))
+	if err != nil {
+		return err
+	}
+
+	drop := make(map[string]bool)
+	for _, column := range columns {
+		drop[column] = true
+		re := regexp.MustCompile(",\\s*[`\"']?" + regexp.QuoteMeta(column) + "[`\"']?\\s[^,()]*(\\([^)]*\\))?[^,()]*")
+		createSQL = re.ReplaceAllString(createSQL, "")
+	}
+	var remains []string
+	for _, name := range names {
+		if !drop[name] {
+			remains = append(remains, "`"+name+"`")
+		}
+	}
+	temp := table + "__temp"
+	re := regexp.MustCompile("^CREATE TABLE\\s+[`\"']?" + regexp.QuoteMeta(table) + "[`\"']?")
+	createSQL = re.ReplaceAllString(createSQL, "CREATE TABLE `"+temp+"`")
+	queries := []string{
+		createSQL,
+		fmt.Sprintf(
+			"INSERT INTO `%s`(%s) SELECT %s FROM `%s`",
+			temp, strings.Join(remains, ","), strings.Join(remains, ","), table,
+		),
+		fmt.Sprintf("DROP TABLE `%s`", table),
+		fmt.Sprintf("ALTER TABLE `%s` RENAME TO `%s`", temp, table),
+	}
+	for _, index := range indexes {
+		if !mentions(index, columns) {
+			queries = append(queries, index)
+		}
+	}
+	for _, query := range queries {
+		if err := tx.Exec(query).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// strings of the first column from the query
+func queryStrings(query *gorm.DB) ([]string, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+func mentions(sql string, columns []string) bool {
+	for _, column := range columns {
+		re := regexp.MustCompile("[`\"'(,\\s]" + regexp.QuoteMeta(column) + "[`\"'),\\s]")
+		if re.MatchString(sql) {
+			return true
+		}
+	}
+	return false
+}
+
+func steps(steps ...step) step {
+	return func(m *migrator) error {
+		for _, step := range steps {
+			if err := step(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// markLegacyTokens without scope, which were granted all permissions when they were created
+func markLegacyTokens(m *migrator) error {
+	return m.tx.Table("oauth_token").Where(
+		"scope = ? OR scope IS NULL", "",
+	).Update("legacy", true).Error
+}
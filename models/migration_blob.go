@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/covergates/covergates/core"
+	"gorm.io/gorm"
+)
+
+const coverageBatchSize = 100
+
+// moveCoveragesToBlob keeps only metadata of coverages in the database.
+// Coverages are kept in the database without blob store.
+func moveCoveragesToBlob(m *migrator) error {
+	if m.blob == nil {
+		return nil
+	}
+	return eachCoverage(m.tx, "blob_key = ?", []interface{}{""}, func(c *coverageRowV7) error {
+		cover, err := c.coverage(nil)
+		if err != nil {
+			return fmt.Errorf("coverage %d: %w", c.ID, err)
+		}
+		key, err := putCoverage(m.blob, cover)
+		if err != nil {
+			return fmt.Errorf("coverage %d: %w", c.ID, err)
+		}
+		c.Data = nil
+		c.BlobKey = key
+		c.StatementCoverage = cover.Summary().StatementCoverage
+		return m.tx.Unscoped().Model(c).Select("Data", "BlobKey", "StatementCoverage").Updates(c).Error
+	})
+}
+
+// moveCoveragesFromBlob back to the database
+func moveCoveragesFromBlob(m *migrator) error {
+	return eachCoverage(m.tx, "blob_key <> ?", []interface{}{""}, func(c *coverageRowV7) error {
+		if m.blob == nil {
+			return fmt.Errorf("require blob store for coverage %s", c.BlobKey)
+		}
+		data, err := m.blob.Get(c.BlobKey)
+		if err != nil {
+			return fmt.Errorf("coverage %d: %w", c.ID, err)
+		}
+		c.Data = data
+		c.BlobKey = ""
+		return m.tx.Unscoped().Model(c).Select("Data", "BlobKey").Updates(c).Error
+	})
+}
+
+// eachCoverage matching the condition in batches
+func eachCoverage(tx *gorm.DB, condition string, args []interface{}, fn func(c *coverageRowV7) error) error {
+	var last uint
+	for {
+		var coverages []*coverageRowV7
+		if err := tx.Unscoped().Where(condition, args...).Where(
+			"id > ?", last,
+		).Order("id").Limit(coverageBatchSize).Find(&coverages).Error; err != nil {
+			return err
+		}
+		for _, c := range coverages {
+			if err := fn(c); err != nil {
+				return err
+			}
+			last = c.ID
+		}
+		if len(coverages) < coverageBatchSize {
+			return nil
+		}
+	}
+}
+
+// coverage decoded from the row, which is fetched from the blob store once moved
+func (c *coverageRowV7) coverage(blob core.BlobStore) (*core.CoverageReport, error) {
+	data := c.Data
+	if c.BlobKey != "" {
+		if blob == nil {
+			return nil, fmt.Errorf("require blob store for coverage %s", c.BlobKey)
+		}
+		var err error
+		if data, err = blob.Get(c.BlobKey); err != nil {
+			return nil, err
+		}
+	}
+	cover := &core.CoverageReport{Type: core.ReportType(c.Type)}
+	if err := decodeCoverage(data, cover); err != nil {
+		return nil, err
+	}
+	return cover, nil
+}
+
+// putCoverage encoded to the blob store, returning the key of the blob
+func putCoverage(blob core.BlobStore, cover *core.CoverageReport) (string, error) {
+	data, err := encodeCoverage(cover)
+	if err != nil {
+		return "", err
+	}
+	return blob.Put(data)
+}
+
+// summarizeCoverages computes aggregates of coverages uploaded before they are kept
+func summarizeCoverages(m *migrator) error {
+	return eachCoverage(m.tx, "1 = 1", nil, func(c *coverageRowV7) error {
+		cover, err := c.coverage(m.blob)
+		if err != nil {
+			return fmt.Errorf("coverage %d: %w", c.ID, err)
+		}
+		summary := cover.Summary()
+		return m.tx.Unscoped().Model(c).UpdateColumns(map[string]interface{}{
+			"statement_coverage": summary.StatementCoverage,
+			"file_count":         summary.Files,
+			"line_count":         summary.Lines,
+			"covered_count":      summary.Covered,
+		}).Error
+	})
+}
+
+// summarizeFiles of coverages uploaded before file summaries are kept
+func summarizeFiles(m *migrator) error {
+	return eachCoverage(m.tx, "1 = 1", nil, func(c *coverageRowV7) error {
+		cover, err := c.coverage(m.blob)
+		if err != nil {
+			return fmt.Errorf("coverage %d: %w", c.ID, err)
+		}
+		summaries := make([]*fileSummaryV10, 0, len(cover.Files))
+		for _, file := range cover.Files {
+			summary := &fileSummaryV10{ReportID: c.ReportID, Type: c.Type, Path: file.Name}
+			for _, hit := range file.StatementHits {
+				summary.Lines++
+				if hit.Hits > 0 {
+					summary.Covered++
+				}
+			}
+			summaries = append(summaries, summary)
+		}
+		for len(summaries) > 0 {
+			n := len(summaries)
+			if n > coverageBatchSize {
+				n = coverageBatchSize
+			}
+			if err := m.tx.Create(summaries[:n]).Error; err != nil {
+				return err
+			}
+			summaries = summaries[n:]
+		}
+		return nil
+	})
+}
@@ -0,0 +1,306 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Models of the schema frozen at the version of each migration.
+// They must never be changed, so applied migrations stay the same
+// however models of the stores evolve. Later changes of a table only
+// keep the added fields, and associations are only kept for foreign keys.
+
+// version 1, the schema before versioned migrations
+
+type reportV1 struct {
+	gorm.Model
+	FileData []byte
+	ReportID string `gorm:"size:256;uniqueIndex:report_record"`
+	Commit   string `gorm:"size:256;uniqueIndex:report_record"`
+}
+
+func (reportV1) TableName() string {
+	return "reports"
+}
+
+type coverageV1 struct {
+	gorm.Model
+	Data     []byte
+	Type     string
+	ReportID uint
+}
+
+func (coverageV1) TableName() string {
+	return "coverages"
+}
+
+type referenceV1 struct {
+	gorm.Model
+	ReportID string `gorm:"size:256;uniqueIndex:reference_record"`
+	Name     string `gorm:"size:256;uniqueIndex:reference_record"`
+}
+
+func (referenceV1) TableName() string {
+	return "references"
+}
+
+type reportCommentV1 struct {
+	gorm.Model
+	ReportID string `gorm:"size:256;uniqueIndex:report_comment_number"`
+	Number   int    `gorm:"uniqueIndex:report_comment_number"`
+	Comment  int
+}
+
+func (reportCommentV1) TableName() string {
+	return "report_comments"
+}
+
+type userV1 struct {
+	gorm.Model
+	Login         string `gorm:"size:256;uniqueIndex;not null"`
+	Name          string
+	Email         string `gorm:"index"`
+	Active        bool
+	Avater        string
+	GiteaLogin    string `gorm:"index"`
+	GiteaEmail    string `gorm:"index"`
+	GiteaToken    string
+	GiteaRefresh  string
+	GiteaExpire   int64
+	GitLabLogin   string `gorm:"index"`
+	GitLabEmail   string `gorm:"index"`
+	GitLabToken   string
+	GitLabRefresh string
+	GitLabExpire  int64
+	GithubLogin   string `gorm:"index"`
+	GithubEmail   string `gorm:"index"`
+	GithubToken   string
+	GithubRefresh string
+	GithubExpire  int64
+}
+
+func (userV1) TableName() string {
+	return "users"
+}
+
+type repoV1 struct {
+	gorm.Model
+	URL       string `gorm:"size:256;uniqueIndex;not null"`
+	ReportID  string
+	NameSpace string `gorm:"index;not null"`
+	Name      string `gorm:"index;not null"`
+	Branch    string
+	SCM       string `gorm:"index;not null"`
+	Creator   string
+	Private   bool
+}
+
+func (repoV1) TableName() string {
+	return "repos"
+}
+
+type repoSettingV1 struct {
+	gorm.Model
+	RepoID uint `gorm:"uniqueIndex"`
+	Config []byte
+}
+
+func (repoSettingV1) TableName() string {
+	return "repo_settings"
+}
+
+type repoHookV1 struct {
+	gorm.Model
+	RepoID uint `gorm:"index"`
+	Hook   string
+}
+
+func (repoHookV1) TableName() string {
+	return "repo_hooks"
+}
+
+type oauthTokenV1 struct {
+	gorm.Model
+	Name    string `gorm:"index"`
+	Code    string `gorm:"index"`
+	Access  string `gorm:"index"`
+	Refresh string `gorm:"index"`
+	Expires time.Time
+	OwnerID uint
+	Owner   *userV1 `gorm:"foreignKey:OwnerID"`
+	Data    []byte
+}
+
+func (oauthTokenV1) TableName() string {
+	return "oauth_token"
+}
+
+// reportReferenceV1 is the join table of reports and references
+type reportReferenceV1 struct {
+	ReportID    uint `gorm:"primaryKey"`
+	ReferenceID uint `gorm:"primaryKey"`
+}
+
+func (reportReferenceV1) TableName() string {
+	return "report_reference"
+}
+
+// userRepositoryV1 is the join table of users and repositories
+type userRepositoryV1 struct {
+	UserID uint `gorm:"primaryKey"`
+	RepoID uint `gorm:"primaryKey"`
+}
+
+func (userRepositoryV1) TableName() string {
+	return "user_repositories"
+}
+
+// version 2
+
+type repoTokenV2 struct {
+	gorm.Model
+	RepoID uint `gorm:"index"`
+	Name   string
+	Hash   string `gorm:"size:64;uniqueIndex"`
+}
+
+func (repoTokenV2) TableName() string {
+	return "repo_tokens"
+}
+
+// version 3
+
+type oauthTokenV3 struct {
+	Scope      string
+	LastUsedAt time.Time
+}
+
+func (oauthTokenV3) TableName() string {
+	return "oauth_token"
+}
+
+// version 4
+
+type repoPermissionV4 struct {
+	gorm.Model
+	RepoID   uint `gorm:"uniqueIndex:idx_repo_permission"`
+	UserID   uint `gorm:"uniqueIndex:idx_repo_permission"`
+	User     userV1
+	Role     string
+	Override string
+	SyncedAt time.Time
+}
+
+func (repoPermissionV4) TableName() string {
+	return "repo_permissions"
+}
+
+// version 5
+
+type userV5 struct {
+	OIDCSubject string `gorm:"index"`
+}
+
+func (userV5) TableName() string {
+	return "users"
+}
+
+// version 6
+
+type auditLogV6 struct {
+	gorm.Model
+	RepoID uint `gorm:"index"`
+	Action string
+	Actor  string
+	IP     string
+	Before []byte
+	After  []byte
+}
+
+func (auditLogV6) TableName() string {
+	return "audit_logs"
+}
+
+// version 7
+
+type coverageV7 struct {
+	BlobKey           string `gorm:"size:64;index"`
+	StatementCoverage float64
+}
+
+func (coverageV7) TableName() string {
+	return "coverages"
+}
+
+// coverageRowV7 of coverages with data kept in either the database or the blob store
+type coverageRowV7 struct {
+	gorm.Model
+	Data              []byte
+	BlobKey           string
+	StatementCoverage float64
+	Type              string
+	ReportID          uint
+}
+
+func (coverageRowV7) TableName() string {
+	return "coverages"
+}
+
+// version 8
+
+type referenceV8 struct {
+	BranchDeletedAt *time.Time
+}
+
+func (referenceV8) TableName() string {
+	return "references"
+}
+
+// version 9
+
+type coverageV9 struct {
+	FileCount    int
+	LineCount    int
+	CoveredCount int
+}
+
+func (coverageV9) TableName() string {
+	return "coverages"
+}
+
+// version 10
+
+type fileSummaryV10 struct {
+	ID       uint   `gorm:"primarykey"`
+	ReportID uint   `gorm:"index"`
+	Type     string `gorm:"size:32"`
+	Path     string `gorm:"size:512;index"`
+	Lines    int
+	Covered  int
+}
+
+func (fileSummaryV10) TableName() string {
+	return "file_summaries"
+}
+
+// version 11
+
+type oauthTokenV11 struct {
+	Legacy bool
+}
+
+func (oauthTokenV11) TableName() string {
+	return "oauth_token"
+}
+
+// version 12
+
+type blobLockV12 struct {
+	BlobKey  string `gorm:"primaryKey;size:64"`
+	LockedAt time.Time
+}
+
+func (blobLockV12) TableName() string {
+	return "blob_locks"
+}
@@ -0,0 +1,248 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/modules/blob"
+)
+
+func TestMigration(t *testing.T) {
+	service := NewDatabaseService(db, nil)
+	userStore := &UserStore{DB: service}
+
+	if err := service.CheckSchema(); err != nil {
+		t.Fatal(err)
+	}
+	status, err := service.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != len(migrations) {
+		t.Fatalf("expect %d migrations, got %d", len(migrations), len(status))
+	}
+	for i, m := range status {
+		if !m.Applied || m.AppliedAt.IsZero() {
+			t.Fatalf("migration %d should be applied", m.Version)
+		}
+		if i > 0 && m.Version <= status[i-1].Version {
+			t.Fatal("migrations should be in order")
+		}
+	}
+
+	if err := userStore.Create(core.Github, &scm.User{Login: "migration"}, &core.Token{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// rollback to the initial tables
+	if err := service.Rollback(len(migrations) - 1); err != nil {
+		t.Fatal(err)
+	}
+	m := db.Migrator()
+	if m.HasTable(&RepoToken{}) || m.HasTable(&AuditLog{}) {
+		t.Fatal("should drop tables")
+	}
+	if m.HasColumn(&OAuthToken{}, "Scope") || m.HasColumn(&User{}, "OIDCSubject") {
+		t.Fatal("should drop columns")
+	}
+	if !m.HasIndex(&User{}, "Login") || !m.HasIndex(&User{}, "GithubLogin") {
+		t.Fatal("should keep indexes of remaining columns")
+	}
+	if err := service.CheckSchema(); !IsErrSchemaMismatch(err) {
+		t.Fatalf("expect schema mismatch, got %v", err)
+	}
+	status, _ = service.Migrations()
+	if !status[0].Applied || status[1].Applied {
+		t.Fatal("should only keep the first migration")
+	}
+
+	if err := service.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.CheckSchema(); err != nil {
+		t.Fatal(err)
+	}
+	if !m.HasColumn(&User{}, "OIDCSubject") || !m.HasIndex(&User{}, "OIDCSubject") {
+		t.Fatal("should add columns back")
+	}
+	if _, err := userStore.FindByLogin("migration"); err != nil {
+		t.Fatal("should keep data", err)
+	}
+	if err := userStore.Create(core.Gitea, &scm.User{Login: "migration"}, &core.Token{}); err == nil {
+		t.Fatal("should keep unique index")
+	}
+
+	// schema from a newer server
+	unknown := &SchemaMigration{Version: len(migrations) + 100}
+	if err := db.Create(unknown).Error; err != nil {
+		t.Fatal(err)
+	}
+	defer db.Delete(unknown)
+	if err := service.CheckSchema(); !IsErrSchemaMismatch(err) {
+		t.Fatalf("expect schema mismatch, got %v", err)
+	}
+	if err := service.Migrate(); !IsErrSchemaMismatch(err) {
+		t.Fatal("should refuse to migrate newer schema")
+	}
+}
+
+// TestMigrationSchema checks migrations create every column and index of the models
+func TestMigrationSchema(t *testing.T) {
+	m := db.Migrator()
+	for _, model := range []interface{}{
+		&Report{},
+		&ReportComment{},
+		&Reference{},
+		&Coverage{},
+		&FileSummary{},
+		&User{},
+		&Repo{},
+		&RepoSetting{},
+		&RepoHook{},
+		&RepoToken{},
+		&RepoPermission{},
+		&OAuthToken{},
+		&AuditLog{},
+		&BlobLock{},
+	} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		if !m.HasTable(model) {
+			t.Fatalf("table %s should be created", stmt.Table)
+		}
+		for _, column := range stmt.Schema.DBNames {
+			if !m.HasColumn(model, column) {
+				t.Fatalf("column %s.%s should be created", stmt.Table, column)
+			}
+		}
+		for name := range stmt.Schema.ParseIndexes() {
+			if !m.HasIndex(model, name) {
+				t.Fatalf("index %s of %s should be created", name, stmt.Table)
+			}
+		}
+	}
+	for _, table := range []string{"report_reference", "user_repositories"} {
+		if !m.HasTable(table) {
+			t.Fatalf("join table %s should be created", table)
+		}
+	}
+}
+
+func TestMigrateCoverageBlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := blob.NewFileSystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewDatabaseService(db, nil)
+	inline := &ReportStore{DB: service}
+	report := &core.Report{
+		ReportID: "TestMigrateCoverageBlob",
+		Commit:   "commit",
+		Coverages: []*core.CoverageReport{
+			{
+				Type:  core.ReportGo,
+				Files: []*core.File{{Name: "a.go", StatementCoverage: 0.8}},
+			},
+		},
+	}
+	if err := inline.Upload(report); err != nil {
+		t.Fatal(err)
+	}
+	find := func() *Coverage {
+		r := &Report{}
+		db.Preload("Coverages").Where(query(report)).First(r)
+		if len(r.Coverages) != 1 {
+			t.Fatal("should have coverage")
+		}
+		return r.Coverages[0]
+	}
+	m := &migrator{tx: db, blob: fs}
+
+	if err := moveCoveragesToBlob(m); err != nil {
+		t.Fatal(err)
+	}
+	c := find()
+	if len(c.Data) > 0 || c.BlobKey == "" || c.StatementCoverage != 0.8 {
+		t.Fatal("should move coverage data to blob store")
+	}
+	if _, err := inline.Find(report); err == nil {
+		t.Fatal("should require blob store to read coverage")
+	}
+	result, err := (&ReportStore{DB: service, Blob: fs}).Find(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Coverages[0].Files) != 1 {
+		t.Fatal("should read coverage from blob store")
+	}
+
+	if err := moveCoveragesFromBlob(m); err != nil {
+		t.Fatal(err)
+	}
+	c = find()
+	if len(c.Data) == 0 || c.BlobKey != "" {
+		t.Fatal("should move coverage data back to database")
+	}
+	if _, err := inline.Find(report); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	service := NewDatabaseService(db, nil)
+	store := &ReportStore{DB: service}
+	report := &core.Report{
+		ReportID: "TestSummarizeCoverages",
+		Commit:   "commit",
+		Coverages: []*core.CoverageReport{
+			{
+				Type: core.ReportGo,
+				Files: []*core.File{{Name: "a.go", StatementCoverage: 0.5, StatementHits: []*core.StatementHit{
+					{LineNumber: 1, Hits: 1},
+					{LineNumber: 2, Hits: 0},
+				}}},
+			},
+		},
+	}
+	if err := store.Upload(report); err != nil {
+		t.Fatal(err)
+	}
+	r := &Report{}
+	db.Preload("Coverages").Where(query(report)).First(r)
+	// coverages uploaded before aggregates are kept
+	db.Model(&Coverage{}).Where("report_id = ?", r.ID).Updates(map[string]interface{}{
+		"file_count":    0,
+		"line_count":    0,
+		"covered_count": 0,
+	})
+	if err := summarizeCoverages(&migrator{tx: db}); err != nil {
+		t.Fatal(err)
+	}
+	c := &Coverage{}
+	db.Where("report_id = ?", r.ID).First(c)
+	if c.FileCount != 1 || c.LineCount != 2 || c.CoveredCount != 1 {
+		t.Fatalf("should summarize coverage, got %d %d %d", c.FileCount, c.LineCount, c.CoveredCount)
+	}
+
+	db.Where("report_id = ?", r.ID).Delete(&FileSummary{})
+	if err := summarizeFiles(&migrator{tx: db}); err != nil {
+		t.Fatal(err)
+	}
+	var files []*FileSummary
+	db.Where("report_id = ?", r.ID).Find(&files)
+	if len(files) != 1 || files[0].Path != "a.go" || files[0].Lines != 2 || files[0].Covered != 1 {
+		t.Fatal("should summarize files")
+	}
+}
@@ -0,0 +1,124 @@
+package models
+
+// migrations of the schema in order. Applied migrations must never be changed,
+// add a new migration to change the schema instead.
+// Migrations only use models frozen at their version, see migration_schema.go.
+var migrations = []*migration{
+	{
+		version: 1,
+		name:    "create initial tables",
+		// tables of databases created before versioned migrations are kept
+		up: autoMigrate(
+			&reportV1{},
+			&reportCommentV1{},
+			&referenceV1{},
+			&coverageV1{},
+			&userV1{},
+			&repoV1{},
+			&repoSettingV1{},
+			&repoHookV1{},
+			&oauthTokenV1{},
+			&reportReferenceV1{},
+			&userRepositoryV1{},
+		),
+		// tables with foreign keys are dropped before the referenced tables
+		down: dropTables(
+			"report_reference",
+			"user_repositories",
+			"oauth_token",
+			"reports",
+			"report_comments",
+			"references",
+			"coverages",
+			"users",
+			"repos",
+			"repo_settings",
+			"repo_hooks",
+		),
+	},
+	{
+		version: 2,
+		name:    "create repository upload tokens",
+		up:      createTables(&repoTokenV2{}),
+		down:    dropTables("repo_tokens"),
+	},
+	{
+		version: 3,
+		name:    "add scope and last used time to OAuth tokens",
+		up:      addColumns(&oauthTokenV3{}, "Scope", "LastUsedAt"),
+		down:    dropColumns("oauth_token", "scope", "last_used_at"),
+	},
+	{
+		version: 4,
+		name:    "create repository permissions",
+		up:      createTables(&repoPermissionV4{}),
+		down:    dropTables("repo_permissions"),
+	},
+	{
+		version: 5,
+		name:    "add OpenID Connect subject to users",
+		up: steps(
+			addColumns(&userV5{}, "OIDCSubject"),
+			createIndexes(&userV5{}, "OIDCSubject"),
+		),
+		down: dropColumns("users", "o_id_c_subject"),
+	},
+	{
+		version: 6,
+		name:    "create audit logs",
+		up:      createTables(&auditLogV6{}),
+		down:    dropTables("audit_logs"),
+	},
+	{
+		version: 7,
+		name:    "move coverage data to blob store",
+		up: steps(
+			addColumns(&coverageV7{}, "BlobKey", "StatementCoverage"),
+			createIndexes(&coverageV7{}, "BlobKey"),
+			moveCoveragesToBlob,
+		),
+		down: steps(
+			moveCoveragesFromBlob,
+			dropColumns("coverages", "blob_key", "statement_coverage"),
+		),
+	},
+	{
+		version: 8,
+		name:    "add branch deleted time to references",
+		up:      addColumns(&referenceV8{}, "BranchDeletedAt"),
+		down:    dropColumns("references", "branch_deleted_at"),
+	},
+	{
+		version: 9,
+		name:    "add aggregates to coverages",
+		up: steps(
+			addColumns(&coverageV9{}, "FileCount", "LineCount", "CoveredCount"),
+			summarizeCoverages,
+		),
+		down: dropColumns("coverages", "file_count", "line_count", "covered_count"),
+	},
+	{
+		version: 10,
+		name:    "create file summaries",
+		up: steps(
+			createTables(&fileSummaryV10{}),
+			summarizeFiles,
+		),
+		down: dropTables("file_summaries"),
+	},
+	{
+		version: 11,
+		name:    "mark OAuth tokens without scope as legacy",
+		up: steps(
+			addColumns(&oauthTokenV11{}, "Legacy"),
+			markLegacyTokens,
+		),
+		down: dropColumns("oauth_token", "legacy"),
+	},
+	{
+		version: 12,
+		name:    "create blob locks",
+		up:      createTables(&blobLockV12{}),
+		down:    dropTables("blob_locks"),
+	},
+}
@@ -6,18 +6,17 @@ import (
 	"github.com/covergates/covergates/core"
 )
 
-var (
-	tables []interface{}
-)
-
 type databaseService struct {
-	db *gorm.DB
+	db   *gorm.DB
+	blob core.BlobStore
 }
 
-// NewDatabaseService with GORM
-func NewDatabaseService(db *gorm.DB) core.DatabaseService {
+// NewDatabaseService with GORM. Migrations move coverage data to the blob store,
+// which is kept in the database if blob is nil.
+func NewDatabaseService(db *gorm.DB, blob core.BlobStore) core.DatabaseService {
 	return &databaseService{
-		db: db,
+		db:   db,
+		blob: blob,
 	}
 }
 
@@ -26,23 +25,35 @@ func (store *databaseService) Session() *gorm.DB {
 }
 
 func (store *databaseService) Migrate() error {
-	return migrate(store.db)
+	return migrate(store.db, store.blob)
+}
+
+func (store *databaseService) Rollback(steps int) error {
+	return rollback(store.db, store.blob, steps)
+}
+
+func (store *databaseService) Migrations() ([]*core.Migration, error) {
+	return migrationStatus(store.db)
+}
+
+func (store *databaseService) CheckSchema() error {
+	return checkSchema(store.db)
+}
+
+// transaction is a database service bound to a transaction
+type transaction struct {
+	core.DatabaseService
+	tx *gorm.DB
 }
 
-func init() {
-	tables = append(tables,
-		&Report{},
-		&ReportComment{},
-		&Reference{},
-		&Coverage{},
-		&User{},
-		&Repo{},
-		&RepoSetting{},
-		&RepoHook{},
-		&OAuthToken{},
-	)
+func (t *transaction) Session() *gorm.DB {
+	return t.tx.Session(&gorm.Session{})
 }
 
-func migrate(db *gorm.DB) error {
-	return db.AutoMigrate(tables...)
+// withTransaction runs fn with the database service bound to a transaction,
+// which is nested with a savepoint if db is already in a transaction
+func withTransaction(db core.DatabaseService, fn func(db core.DatabaseService) error) error {
+	return db.Session().Transaction(func(tx *gorm.DB) error {
+		return fn(&transaction{DatabaseService: db, tx: tx})
+	})
 }
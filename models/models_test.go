@@ -26,7 +26,7 @@ func getDatabaseService(t *testing.T) (*gomock.Controller, core.DatabaseService)
 func TestMain(m *testing.M) {
 	log.SetReportCaller(true)
 	db = tests.OpenDatabase()
-	if err := migrate(db); err != nil {
+	if err := migrate(db, nil); err != nil {
 		log.Fatal(err)
 	}
 	exit := m.Run()
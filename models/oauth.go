@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,9 +18,14 @@ type OAuthToken struct {
 	Access  string `gorm:"index"`
 	Refresh string `gorm:"index"`
 	Expires time.Time
-	OwnerID uint
-	Owner   *User `gorm:"foreignKey:OwnerID"`
-	Data    []byte
+	// Scope is space-delimited token scopes
+	Scope string
+	// Legacy tokens are created before scopes
+	Legacy     bool
+	LastUsedAt time.Time
+	OwnerID    uint
+	Owner      *User `gorm:"foreignKey:OwnerID"`
+	Data       []byte
 }
 
 // OAuthStore tokens
@@ -43,6 +49,7 @@ func (store *OAuthStore) Create(token *core.OAuthToken) error {
 		Code:    token.Code,
 		Refresh: token.Refresh,
 		Expires: token.Expires,
+		Scope:   joinScopes(token.Scopes),
 		Owner:   user,
 		Data:    token.Data,
 	}
@@ -105,18 +112,40 @@ func (store *OAuthStore) Delete(token *core.OAuthToken) error {
 	return session.Where(cond).Delete(OAuthToken{}).Error
 }
 
+// UpdateLastUsed time of the token
+func (store *OAuthStore) UpdateLastUsed(token *core.OAuthToken) error {
+	if token.ID <= 0 {
+		return fmt.Errorf("invalid token")
+	}
+	session := store.DB.Session()
+	return session.Model(&OAuthToken{}).Where("id = ?", token.ID).Update(
+		"last_used_at", token.LastUsedAt,
+	).Error
+}
+
 func (token *OAuthToken) toCoreOAuthToken() *core.OAuthToken {
 	return &core.OAuthToken{
-		ID:        token.ID,
-		Name:      token.Name,
-		Code:      token.Code,
-		Access:    token.Access,
-		Refresh:   token.Refresh,
-		Expires:   token.Expires,
-		CreatedAt: token.CreatedAt,
-		Owner:     token.Owner.toCoreUser(),
-		Data:      token.Data,
+		ID:         token.ID,
+		Name:       token.Name,
+		Code:       token.Code,
+		Access:     token.Access,
+		Refresh:    token.Refresh,
+		Expires:    token.Expires,
+		Scopes:     core.ParseTokenScopes(token.Scope),
+		Legacy:     token.Legacy,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+		Owner:      token.Owner.toCoreUser(),
+		Data:       token.Data,
+	}
+}
+
+func joinScopes(scopes []core.TokenScope) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
 	}
+	return strings.Join(s, " ")
 }
 
 // TableName for GORM
@@ -137,3 +137,98 @@ func TestOAuth(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestOAuthScopes(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+
+	store := &OAuthStore{DB: db}
+	userStore := &UserStore{DB: db}
+	_ = userStore.Create(core.Gitea, &scm.User{
+		Login: "oauth_scope_user",
+	}, &core.Token{})
+	user, err := userStore.FindByLogin("oauth_scope_user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scopes := []core.TokenScope{core.ScopeReportWrite, core.ScopeRepoRead}
+	if err := store.Create(&core.OAuthToken{
+		Access: "scoped_access",
+		Owner:  user,
+		Scopes: scopes,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	token, err := store.Find(&core.OAuthToken{Access: "scoped_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(scopes, token.Scopes); diff != "" {
+		t.Fatal(diff)
+	}
+	if !token.LastUsedAt.IsZero() {
+		t.Fatal("token should not be used")
+	}
+
+	token.LastUsedAt = time.Now()
+	if err := store.UpdateLastUsed(token); err != nil {
+		t.Fatal(err)
+	}
+	used, err := store.Find(&core.OAuthToken{Access: "scoped_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !used.LastUsedAt.Equal(token.LastUsedAt) {
+		t.Fatalf("expect %v, got %v", token.LastUsedAt, used.LastUsedAt)
+	}
+}
+
+func TestOAuthLegacyToken(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+
+	store := &OAuthStore{DB: service}
+	userStore := &UserStore{DB: service}
+	_ = userStore.Create(core.Gitea, &scm.User{
+		Login: "oauth_legacy_user",
+	}, &core.Token{})
+	user, err := userStore.FindByLogin("oauth_legacy_user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []*core.OAuthToken{
+		{Access: "legacy_access", Owner: user},
+		{Access: "legacy_scoped_access", Owner: user, Scopes: []core.TokenScope{core.ScopeRepoRead}},
+	} {
+		if err := store.Create(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token, err := store.Find(&core.OAuthToken{Access: "legacy_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Legacy || token.HasScope(core.ScopeUser) {
+		t.Fatal("new token without scope should not have any permission")
+	}
+
+	// tokens are created before scopes
+	if err := markLegacyTokens(&migrator{tx: db}); err != nil {
+		t.Fatal(err)
+	}
+	token, err = store.Find(&core.OAuthToken{Access: "legacy_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !token.Legacy || !token.HasScope(core.ScopeUser) {
+		t.Fatal("legacy token should keep all permissions")
+	}
+	scoped, err := store.Find(&core.OAuthToken{Access: "legacy_scoped_access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scoped.Legacy || scoped.HasScope(core.ScopeUser) {
+		t.Fatal("scoped token should not be legacy")
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+)
+
+// reportCursor of the position of the report in a listing from the latest
+func reportCursor(r *Report) string {
+	cursor := fmt.Sprintf("%d.%d", r.CreatedAt.UnixNano(), r.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+func parseReportCursor(cursor string) (time.Time, uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, core.ErrInvalidCursor
+	}
+	var nano int64
+	var id uint
+	if n, err := fmt.Sscanf(string(data), "%d.%d", &nano, &id); err != nil || n != 2 || id == 0 {
+		return time.Time{}, 0, core.ErrInvalidCursor
+	}
+	return time.Unix(0, nano), id, nil
+}
+
+// reportPage of reports from the latest
+type reportPage struct {
+	size int
+	// position of the cursor, which is zero for the first page
+	createdAt time.Time
+	id        uint
+}
+
+func newReportPage(page core.Page) (*reportPage, error) {
+	p := &reportPage{size: page.Size()}
+	if page.Cursor == "" {
+		return p, nil
+	}
+	var err error
+	p.createdAt, p.id, err = parseReportCursor(page.Cursor)
+	return p, err
+}
+
+// apply the page to the query.
+// One more report than the page size is queried to tell if there is a next page.
+func (p *reportPage) apply(db *gorm.DB) *gorm.DB {
+	db = db.Order("created_at desc").Order("id desc").Limit(p.size + 1)
+	if p.id == 0 {
+		return db
+	}
+	return db.Where(
+		"(created_at < ? OR (created_at = ? AND id < ?))", p.createdAt, p.createdAt, p.id,
+	)
+}
+
+// next trims the reports to the page size and returns the next cursor
+func (p *reportPage) next(reports []*Report) ([]*Report, string) {
+	if len(reports) <= p.size {
+		return reports, ""
+	}
+	reports = reports[:p.size]
+	return reports, reportCursor(reports[len(reports)-1])
+}
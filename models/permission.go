@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+)
+
+// RepoPermission defines the role of a user to a repository
+type RepoPermission struct {
+	gorm.Model
+	RepoID uint `gorm:"uniqueIndex:idx_repo_permission"`
+	UserID uint `gorm:"uniqueIndex:idx_repo_permission"`
+	User   User
+	// Role synchronized from SCM
+	Role string
+	// Override is the role set locally
+	Override string
+	SyncedAt time.Time
+}
+
+// PermissionStore roles of users in storage
+type PermissionStore struct {
+	DB core.DatabaseService
+}
+
+// Find the role of the user to the repository
+func (store *PermissionStore) Find(repo *core.Repo, user *core.User) (*core.RepoPermission, error) {
+	session := store.DB.Session()
+	r, err := findRepo(session, repo)
+	if err != nil {
+		return nil, err
+	}
+	u, err := findUser(session, user)
+	if err != nil {
+		return nil, err
+	}
+	permission := &RepoPermission{}
+	condition := &RepoPermission{RepoID: r.ID, UserID: u.ID}
+	if err := session.Where(condition).First(permission).Error; err != nil {
+		return nil, err
+	}
+	permission.User = *u
+	return permission.toCorePermission(r), nil
+}
+
+// List roles of all users to the repository
+func (store *PermissionStore) List(repo *core.Repo) ([]*core.RepoPermission, error) {
+	session := store.DB.Session()
+	r, err := findRepo(session, repo)
+	if err != nil {
+		return nil, err
+	}
+	var permissions []*RepoPermission
+	if err := session.Preload("User").Where(
+		&RepoPermission{RepoID: r.ID},
+	).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.RepoPermission, len(permissions))
+	for i, permission := range permissions {
+		result[i] = permission.toCorePermission(r)
+	}
+	return result, nil
+}
+
+// Synchronize SCM roles of the user. Local overrides are kept.
+func (store *PermissionStore) Synchronize(user *core.User, permissions []*core.RepoPermission) error {
+	session := store.DB.Session()
+	u, err := findUser(session, user)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return session.Transaction(func(tx *gorm.DB) error {
+		for _, permission := range permissions {
+			r, err := findRepo(tx, permission.Repo)
+			if err != nil {
+				return err
+			}
+			p := &RepoPermission{}
+			if err := tx.FirstOrCreate(p, &RepoPermission{RepoID: r.ID, UserID: u.ID}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(p).Select("Role", "SyncedAt").Updates(&RepoPermission{
+				Role:     string(permission.Role),
+				SyncedAt: now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Override the role of the user locally. RoleNone removes the override.
+func (store *PermissionStore) Override(repo *core.Repo, user *core.User, role core.Role) error {
+	session := store.DB.Session()
+	r, err := findRepo(session, repo)
+	if err != nil {
+		return err
+	}
+	u, err := findUser(session, user)
+	if err != nil {
+		return err
+	}
+	p := &RepoPermission{}
+	if err := session.FirstOrCreate(p, &RepoPermission{RepoID: r.ID, UserID: u.ID}).Error; err != nil {
+		return err
+	}
+	return session.Model(p).Select("Override").Updates(&RepoPermission{
+		Override: string(role),
+	}).Error
+}
+
+func (permission *RepoPermission) toCorePermission(repo *Repo) *core.RepoPermission {
+	return &core.RepoPermission{
+		Repo:     repo.ToCoreRepo(),
+		Login:    permission.User.Login,
+		Role:     core.Role(permission.Role),
+		Override: core.Role(permission.Override),
+		SyncedAt: permission.SyncedAt,
+	}
+}
+
+func findRepo(session *gorm.DB, repo *core.Repo) (*Repo, error) {
+	if repo == nil || (repo.ID <= 0 && repo.URL == "") {
+		return nil, gorm.ErrRecordNotFound
+	}
+	r := &Repo{}
+	condition := &Repo{URL: repo.URL}
+	condition.ID = repo.ID
+	if err := session.Where(condition).First(r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func findUser(session *gorm.DB, user *core.User) (*User, error) {
+	if user == nil || user.Login == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	u := &User{}
+	if err := session.Where(&User{Login: user.Login}).First(u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/drone/go-scm/scm"
+
+	"github.com/covergates/covergates/core"
+)
+
+func TestPermission(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+
+	store := &PermissionStore{DB: db}
+	repoStore := &RepoStore{DB: db}
+	userStore := &UserStore{DB: db}
+
+	_ = userStore.Create(core.Gitea, &scm.User{Login: "permission_user"}, &core.Token{})
+	user, err := userStore.FindByLogin("permission_user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &core.Repo{
+		URL:       "http://gitea/permission/repo",
+		NameSpace: "permission",
+		Name:      "repo",
+		SCM:       core.Gitea,
+	}
+	if err := repoStore.Create(repo); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Find(repo, user); err == nil {
+		t.Fatal("should not find permission before synchronized")
+	}
+
+	if err := store.Synchronize(user, []*core.RepoPermission{
+		{Repo: repo, Role: core.RoleUploader},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	permission, err := store.Find(repo, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if permission.Login != user.Login || permission.Effective() != core.RoleUploader {
+		t.Fatalf("unexpected permission %v", permission)
+	}
+	if permission.SyncedAt.IsZero() {
+		t.Fatal("should update synchronized time")
+	}
+
+	if err := store.Override(repo, user, core.RoleMaintainer); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Synchronize(user, []*core.RepoPermission{
+		{Repo: repo, Role: core.RoleViewer},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	permissions, err := store.List(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(permissions) != 1 {
+		t.Fatalf("expect 1 permission, got %d", len(permissions))
+	}
+	permission = permissions[0]
+	if permission.Role != core.RoleViewer || permission.Effective() != core.RoleMaintainer {
+		t.Fatal("synchronization should keep local override")
+	}
+
+	if err := store.Override(repo, user, core.RoleNone); err != nil {
+		t.Fatal(err)
+	}
+	permission, err = store.Find(repo, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if permission.Effective() != core.RoleViewer {
+		t.Fatal("should remove local override")
+	}
+}
@@ -161,6 +161,9 @@ func (store *RepoStore) Creator(repo *core.Repo) (*core.User, error) {
 	if err := session.Where(repo).First(r).Error; err != nil {
 		return nil, err
 	}
+	if r.Creator == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
 	user := &User{
 		Login: r.Creator,
 	}
@@ -196,6 +199,20 @@ func (store *RepoStore) Finds(urls ...string) ([]*core.Repo, error) {
 	return coreRepositories, nil
 }
 
+// ListActivated repositories, which have report ID
+func (store *RepoStore) ListActivated() ([]*core.Repo, error) {
+	session := store.DB.Session()
+	var repositories []*Repo
+	if err := session.Where("report_id <> ?", "").Order("id").Find(&repositories).Error; err != nil {
+		return nil, err
+	}
+	coreRepositories := make([]*core.Repo, len(repositories))
+	for i, repo := range repositories {
+		coreRepositories[i] = repo.ToCoreRepo()
+	}
+	return coreRepositories, nil
+}
+
 // Setting of the repository
 func (store *RepoStore) Setting(repo *core.Repo) (*core.RepoSetting, error) {
 	session := store.DB.Session()
@@ -207,6 +224,7 @@ func (store *RepoStore) Setting(repo *core.Repo) (*core.RepoSetting, error) {
 	if err := json.Unmarshal(setting.Config, coreSetting); err != nil {
 		return nil, err
 	}
+	coreSetting.UpdatedAt = setting.UpdatedAt
 	return coreSetting, nil
 }
 
@@ -84,6 +84,43 @@ func TestRepoFinds(t *testing.T) {
 	}
 }
 
+func TestRepoListActivated(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+	session := db.Session()
+	repos := []*Repo{
+		{
+			Name:      "activated",
+			NameSpace: "list_activated",
+			SCM:       string(core.Gitea),
+			URL:       "http://gitea/list_activated/activated",
+			ReportID:  "TestRepoListActivated",
+		},
+		{
+			Name:      "inactivated",
+			NameSpace: "list_activated",
+			SCM:       string(core.Gitea),
+			URL:       "http://gitea/list_activated/inactivated",
+		},
+	}
+	session.Create(repos)
+	store := &RepoStore{DB: db}
+	result, err := store.ListActivated()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, repo := range result {
+		if repo.ReportID == "" {
+			t.Fatal("should only list activated repositories")
+		}
+		found[repo.URL] = true
+	}
+	if !found[repos[0].URL] || found[repos[1].URL] {
+		t.Fatal("should list activated repository")
+	}
+}
+
 func TestRepoUpdateOrCreate(t *testing.T) {
 	ctrl, db := getDatabaseService(t)
 	defer ctrl.Finish()
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+)
+
+const repoTokenLength = 20
+
+// RepoToken defines an upload token of a repository
+type RepoToken struct {
+	gorm.Model
+	RepoID uint `gorm:"index"`
+	Name   string
+	// Hash of the token secret, the secret itself is never stored
+	Hash string `gorm:"size:64;uniqueIndex"`
+}
+
+// CreateToken for the repository. The returned token carries the plain secret,
+// which will not be able to retrieve again.
+func (store *RepoStore) CreateToken(repo *core.Repo, name string) (*core.RepoToken, error) {
+	if repo.ID <= 0 {
+		return nil, fmt.Errorf("invalid repository")
+	}
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, err
+	}
+	session := store.DB.Session()
+	token := &RepoToken{
+		RepoID: repo.ID,
+		Name:   name,
+		Hash:   hashTokenSecret(secret),
+	}
+	if err := session.Create(token).Error; err != nil {
+		return nil, err
+	}
+	result := token.toCoreRepoToken()
+	result.Token = secret
+	return result, nil
+}
+
+// RenewToken with a new secret. The returned token carries the new plain secret.
+func (store *RepoStore) RenewToken(repo *core.Repo, token *core.RepoToken) (*core.RepoToken, error) {
+	session := store.DB.Session()
+	t := &RepoToken{}
+	if err := session.Where(&RepoToken{RepoID: repo.ID}).First(t, token.ID).Error; err != nil {
+		return nil, err
+	}
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, err
+	}
+	t.Hash = hashTokenSecret(secret)
+	if err := session.Save(t).Error; err != nil {
+		return nil, err
+	}
+	result := t.toCoreRepoToken()
+	result.Token = secret
+	return result, nil
+}
+
+// FindToken of the repository with the plain secret
+func (store *RepoStore) FindToken(repo *core.Repo, secret string) (*core.RepoToken, error) {
+	if repo.ID <= 0 || secret == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	session := store.DB.Session()
+	t := &RepoToken{}
+	condition := &RepoToken{RepoID: repo.ID, Hash: hashTokenSecret(secret)}
+	if err := session.Where(condition).First(t).Error; err != nil {
+		return nil, err
+	}
+	return t.toCoreRepoToken(), nil
+}
+
+// ListTokens of the repository without secrets
+func (store *RepoStore) ListTokens(repo *core.Repo) ([]*core.RepoToken, error) {
+	session := store.DB.Session()
+	var tokens []*RepoToken
+	if err := session.Where(&RepoToken{RepoID: repo.ID}).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.RepoToken, len(tokens))
+	for i, token := range tokens {
+		result[i] = token.toCoreRepoToken()
+	}
+	return result, nil
+}
+
+// DeleteToken of the repository
+func (store *RepoStore) DeleteToken(repo *core.Repo, token *core.RepoToken) error {
+	if repo.ID <= 0 || token.ID <= 0 {
+		return fmt.Errorf("invalid token")
+	}
+	session := store.DB.Session()
+	// tokens deleted already are not counted
+	result := session.Where(&RepoToken{RepoID: repo.ID}).Where("deleted_at IS NULL").Delete(&RepoToken{}, token.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (token *RepoToken) toCoreRepoToken() *core.RepoToken {
+	return &core.RepoToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		CreatedAt: token.CreatedAt,
+	}
+}
+
+func newTokenSecret() (string, error) {
+	data := make([]byte, repoTokenLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+)
+
+func TestRepoToken(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &RepoStore{DB: db}
+
+	if _, err := store.CreateToken(&core.Repo{}, "ci"); err == nil {
+		t.Fatal("invalid repo should return error")
+	}
+
+	repo := &core.Repo{ID: uint(2345)}
+	other := &core.Repo{ID: uint(2346)}
+
+	token, err := store.CreateToken(repo, "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token == "" || token.Name != "ci" || token.ID <= 0 {
+		t.Fatal("should return token with secret")
+	}
+
+	found, err := store.FindToken(repo, token.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != token.ID || found.Token != "" {
+		t.Fatal("should find token without secret")
+	}
+
+	if _, err := store.FindToken(other, token.Token); err == nil {
+		t.Fatal("token should not be valid for other repository")
+	}
+
+	m := &RepoToken{}
+	if err := db.Session().First(m, token.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if m.Hash == token.Token {
+		t.Fatal("secret should be stored hashed")
+	}
+
+	renewed, err := store.RenewToken(repo, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.ID != token.ID || renewed.Token == token.Token {
+		t.Fatal("should renew token secret")
+	}
+	if _, err := store.FindToken(repo, token.Token); err == nil {
+		t.Fatal("old secret should be invalid")
+	}
+	if _, err := store.RenewToken(other, token); err == nil {
+		t.Fatal("should not renew token of other repository")
+	}
+
+	if _, err := store.CreateToken(repo, "ci2"); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := store.ListTokens(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expect 2 tokens, got %d", len(tokens))
+	}
+	for _, token := range tokens {
+		if token.Token != "" {
+			t.Fatal("list should not expose secret")
+		}
+	}
+
+	if err := store.DeleteToken(other, token); err != gorm.ErrRecordNotFound {
+		t.Fatal("should not find token of other repository")
+	}
+	if _, err := store.FindToken(repo, renewed.Token); err != nil {
+		t.Fatal("should not delete token of other repository")
+	}
+	if err := store.DeleteToken(repo, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FindToken(repo, renewed.Token); err == nil {
+		t.Fatal("token should be deleted")
+	}
+	if err := store.DeleteToken(repo, token); err != gorm.ErrRecordNotFound {
+		t.Fatal("should not find deleted token")
+	}
+}
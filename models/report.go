@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -28,9 +29,29 @@ type Report struct {
 // Coverage defines test coverage report
 type Coverage struct {
 	gorm.Model
-	Data     []byte
-	Type     string
-	ReportID uint
+	// Data of the coverage report, which is only kept in the database without blob store
+	Data []byte
+	// BlobKey of the coverage report data in the blob store
+	BlobKey           string `gorm:"size:64;index"`
+	StatementCoverage float64
+	Type              string
+	ReportID          uint
+	// FileCount, LineCount and CoveredCount are aggregates of the data,
+	// which are computed at upload to summarize reports without loading data
+	FileCount    int
+	LineCount    int
+	CoveredCount int
+}
+
+// FileSummary of a file coverage in a report, which is kept at upload to track files over reports
+type FileSummary struct {
+	ID uint `gorm:"primarykey"`
+	// ReportID is the ID of the report row
+	ReportID uint   `gorm:"index"`
+	Type     string `gorm:"size:32"`
+	Path     string `gorm:"size:512;index"`
+	Lines    int
+	Covered  int
 }
 
 // Reference of Report, such as branch or tag name
@@ -39,6 +60,8 @@ type Reference struct {
 	ReportID string    `gorm:"size:256;uniqueIndex:reference_record"`
 	Name     string    `gorm:"size:256;uniqueIndex:reference_record"`
 	Reports  []*Report `gorm:"many2many:report_reference"`
+	// BranchDeletedAt is when the branch is found deleted
+	BranchDeletedAt *time.Time
 }
 
 // ReportComment defines summary report comment in the pull request
@@ -53,139 +76,508 @@ type ReportComment struct {
 // ReportStore reports in storage
 type ReportStore struct {
 	DB core.DatabaseService
+	// Blob keeps coverage data, which is kept in the database if nil
+	Blob core.BlobStore
+	// replaced keys of blobs no longer referenced by coverages updated in the transaction
+	replaced []string
 }
 
 // Upload create a report to database
 // If the report id and commit is already existed in the table,
-// the report will be updated instead.
+// the report will be updated instead. Nothing is changed if any step fails.
 func (store *ReportStore) Upload(r *core.Report) error {
 	if r.ReportID == "" || r.Commit == "" {
 		return errReportFields
 	}
+	return store.transaction(func(tx *ReportStore) error {
+		return tx.upload(r)
+	})
+}
+
+// Transaction runs fn with the store bound to a database transaction
+func (store *ReportStore) Transaction(fn func(store core.ReportStore) error) error {
+	return store.transaction(func(tx *ReportStore) error {
+		return fn(tx)
+	})
+}
+
+// transaction runs fn with the store bound to a database transaction.
+// Blobs put in the transaction are locked until it ends, and are removed
+// after it is rolled back, unless they are still used by other coverages.
+// Blobs replaced in the transaction are removed after the outermost transaction is committed.
+func (store *ReportStore) transaction(fn func(tx *ReportStore) error) error {
+	var recorder *blobRecorder
+	tx := &ReportStore{}
+	err := withTransaction(store.DB, func(db core.DatabaseService) error {
+		tx.DB = db
+		if store.Blob != nil {
+			recorder = &blobRecorder{BlobStore: store.Blob, tx: db.Session()}
+			tx.Blob = recorder
+		}
+		return fn(tx)
+	})
+	if recorder == nil {
+		return err
+	}
+	if err != nil {
+		if err := store.removeBlobs(recorder.keys); err != nil {
+			log.Warningf("fail to remove blobs of rolled back transaction: %v", err)
+		}
+		return err
+	}
+	if _, nested := store.DB.(*transaction); nested {
+		store.replaced = append(store.replaced, tx.replaced...)
+		return nil
+	}
+	if err := store.removeBlobs(tx.replaced); err != nil {
+		log.Warningf("fail to remove replaced blobs: %v", err)
+	}
+	return nil
+}
+
+// blobRecorder records keys of the blobs put through it.
+// Blobs are locked in the transaction before they are put,
+// so they are not removed before coverages referencing them are committed.
+type blobRecorder struct {
+	core.BlobStore
+	tx   *gorm.DB
+	keys []string
+}
+
+func (b *blobRecorder) Put(data []byte) (string, error) {
+	if err := lockBlob(b.tx, blobKey(data)); err != nil {
+		return "", err
+	}
+	key, err := b.BlobStore.Put(data)
+	if err == nil {
+		b.keys = append(b.keys, key)
+	}
+	return key, err
+}
+
+// removeBlobs of the keys which are not used by any coverage
+func (store *ReportStore) removeBlobs(keys []string) error {
+	for _, key := range keys {
+		if err := store.removeBlob(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeBlob of the key if it is not used by any coverage. The blob is locked
+// while counting its coverages, to wait for uncommitted uploads using it.
+func (store *ReportStore) removeBlob(key string) error {
+	return withTransaction(store.DB, func(db core.DatabaseService) error {
+		tx := db.Session()
+		if err := lockBlob(tx, key); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&Coverage{}).Unscoped().Where(
+			&Coverage{BlobKey: key},
+		).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Delete(&BlobLock{BlobKey: key}).Error; err != nil {
+			return err
+		}
+		if err := store.Blob.Delete(key); err != nil && !errors.Is(err, core.ErrBlobNotFound) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (store *ReportStore) upload(r *core.Report) error {
 	session := store.DB.Session()
 	if r.Reference != "" {
 		session = session.Preload("References", "name=?", r.Reference)
 	}
 	report := &Report{}
-	session.Preload("Coverages").FirstOrCreate(report, &Report{
+	if err := session.Preload("Coverages").FirstOrCreate(report, &Report{
 		ReportID: r.ReportID,
 		Commit:   r.Commit,
-	})
+	}).Error; err != nil {
+		return err
+	}
 	if len(report.References) == 0 && r.Reference != "" {
 		if err := store.appendReference(report, r.Reference); err != nil {
 			return err
 		}
 	}
+	return store.save(session, report, r)
+}
+
+// save coverages and files of the report
+func (store *ReportStore) save(session *gorm.DB, report *Report, r *core.Report) error {
 	for _, coverage := range r.Coverages {
 		if err := store.updateCoverage(report, coverage); err != nil {
 			return err
 		}
 	}
 	copyReport(report, r)
-	return session.Save(report).Error
+	if err := session.Save(report).Error; err != nil {
+		return err
+	}
+	return saveFileSummaries(store.DB.Session(), report.ID, r.Coverages...)
+}
+
+// saveFileSummaries of the coverages, replacing summaries of the same report types
+func saveFileSummaries(session *gorm.DB, reportID uint, coverages ...*core.CoverageReport) error {
+	summaries := make([]*FileSummary, 0)
+	types := make([]string, len(coverages))
+	for i, coverage := range coverages {
+		types[i] = string(coverage.Type)
+		for _, file := range coverage.Files {
+			summary := &FileSummary{ReportID: reportID, Type: string(coverage.Type), Path: file.Name}
+			for _, hit := range file.StatementHits {
+				summary.Lines++
+				if hit.Hits > 0 {
+					summary.Covered++
+				}
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	if err := session.Where("report_id = ? AND type IN ?", reportID, types).Delete(&FileSummary{}).Error; err != nil {
+		return err
+	}
+	for len(summaries) > 0 {
+		n := len(summaries)
+		if n > coverageBatchSize {
+			n = coverageBatchSize
+		}
+		if err := session.Create(summaries[:n]).Error; err != nil {
+			return err
+		}
+		summaries = summaries[n:]
+	}
+	return nil
+}
+
+// Restore the report with its created time, the report of the same commit is replaced.
+// References of the report are not changed.
+func (store *ReportStore) Restore(r *core.Report) error {
+	if r.ReportID == "" || r.Commit == "" {
+		return errReportFields
+	}
+	return store.transaction(func(tx *ReportStore) error {
+		session := tx.DB.Session()
+		report := &Report{}
+		if err := session.Preload("Coverages").FirstOrCreate(report, &Report{
+			ReportID: r.ReportID,
+			Commit:   r.Commit,
+		}).Error; err != nil {
+			return err
+		}
+		if !r.CreatedAt.IsZero() {
+			report.CreatedAt = r.CreatedAt
+		}
+		return tx.save(session, report, r)
+	})
+}
+
+// RestoreReference links the reference to its reports of the commits,
+// which are ignored if not found. The reference is created if not exists.
+func (store *ReportStore) RestoreReference(reportID string, ref *core.Reference) error {
+	if reportID == "" || ref.Name == "" {
+		return errReportFields
+	}
+	commits := make([]string, len(ref.Reports))
+	for i, report := range ref.Reports {
+		commits[i] = report.Commit
+	}
+	return withTransaction(store.DB, func(db core.DatabaseService) error {
+		session := db.Session()
+		reference := &Reference{ReportID: reportID, Name: ref.Name}
+		if err := session.FirstOrCreate(reference, reference).Error; err != nil {
+			return err
+		}
+		if err := session.Model(reference).Update("branch_deleted_at", ref.BranchDeletedAt).Error; err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			return nil
+		}
+		var linked []uint
+		if err := session.Table("report_reference").Where(
+			"reference_id = ?", reference.ID,
+		).Pluck("report_id", &linked).Error; err != nil {
+			return err
+		}
+		query := session.Model(&Report{}).Where(map[string]interface{}{
+			"report_id": reportID,
+			"commit":    commits,
+		})
+		if len(linked) > 0 {
+			query = query.Where("id NOT IN ?", linked)
+		}
+		var ids []uint
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := session.Exec(
+				"INSERT INTO report_reference (report_id, reference_id) VALUES (?, ?)", id, reference.ID,
+			).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // Find report with the input seed. No-empty filed will use as where condition
 func (store *ReportStore) Find(r *core.Report) (*core.Report, error) {
+	target, err := store.find(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	report, err := target.ToCoreReport(store.Blob)
+	if err != nil {
+		return nil, err
+	}
+	report.Reference = r.Reference
+	return report, nil
+}
+
+// FindSummary of the report found as Find, of which coverages have no files.
+// Coverage data is neither loaded from the database nor fetched from the blob store.
+func (store *ReportStore) FindSummary(r *core.Report) (*core.Report, error) {
+	target, err := store.find(r, func(db *gorm.DB) *gorm.DB {
+		return db.Select(
+			"id", "report_id", "type", "blob_key", "statement_coverage",
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	report := target.toCoreSummary()
+	report.Reference = r.Reference
+	return report, nil
+}
+
+// find the latest report of the seed with its coverages, which are narrowed by coverages if not nil
+func (store *ReportStore) find(r *core.Report, coverages func(db *gorm.DB) *gorm.DB) (*Report, error) {
+	preload := []interface{}{}
+	if coverages != nil {
+		preload = append(preload, coverages)
+	}
 	session := store.DB.Session()
 	target := &Report{}
 	if r.Reference == "" {
-		session = session.Preload("Coverages").Order("created_at desc").First(target, query(r))
+		session = session.Preload("Coverages", preload...).Order("created_at desc").First(target, query(r))
 		if err := session.Error; err != nil {
 			return nil, err
 		}
-	} else {
-		if r.ReportID == "" {
-			log.Warning("report id should not be empty when search with reference")
-			return nil, gorm.ErrRecordNotFound
-		}
-		ref := &Reference{ReportID: r.ReportID, Name: r.Reference}
-		session = session.Preload("Reports", func(db *gorm.DB) *gorm.DB {
-			return db.Where(query(r)).Order("created_at desc").Limit(50)
-		}).Preload("Reports.Coverages").First(ref, ref)
-		if err := session.Error; err != nil {
-			return nil, err
-		}
-		if len(ref.Reports) == 0 {
-			return nil, gorm.ErrRecordNotFound
-		}
-		target = ref.Reports[0]
+		return target, nil
 	}
-	report := target.ToCoreReport()
-	report.Reference = r.Reference
-	return report, nil
+	if r.ReportID == "" {
+		log.Warning("report id should not be empty when search with reference")
+		return nil, gorm.ErrRecordNotFound
+	}
+	ref := &Reference{ReportID: r.ReportID, Name: r.Reference}
+	session = session.Preload("Reports", func(db *gorm.DB) *gorm.DB {
+		return db.Where(query(r)).Order("created_at desc").Limit(1)
+	}).Preload("Reports.Coverages", preload...).First(ref, ref)
+	if err := session.Error; err != nil {
+		return nil, err
+	}
+	if len(ref.Reports) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return ref.Reports[0], nil
 }
 
-// Finds all report with given seed
-func (store *ReportStore) Finds(r *core.Report) ([]*core.Report, error) {
+// Finds reports with given seed from the latest
+func (store *ReportStore) Finds(r *core.Report, page core.Page) ([]*core.Report, string, error) {
+	p, err := newReportPage(page)
+	if err != nil {
+		return nil, "", err
+	}
 	session := store.DB.Session()
 	var rst []*Report
 
 	if r.Reference == "" {
-		if err := session.Preload(
-			"Coverages",
-		).Where(query(r)).Order(
-			"created_at desc",
-		).Limit(100).Find(&rst).Error; err != nil {
-			return nil, err
+		if err := p.apply(session.Preload("Coverages").Where(query(r))).Find(&rst).Error; err != nil {
+			return nil, "", err
 		}
 	} else {
 		if r.ReportID == "" {
 			log.Warning("report id should not be empty when search with reference")
-			return nil, gorm.ErrRecordNotFound
+			return nil, "", gorm.ErrRecordNotFound
 		}
 		ref := &Reference{ReportID: r.ReportID, Name: r.Reference}
 		session = session.Preload("Reports", func(db *gorm.DB) *gorm.DB {
-			return db.Where(query(r)).Order("created_at desc").Limit(100)
+			return p.apply(db.Where(query(r)))
 		}).Preload("Reports.Coverages").First(ref, ref)
 		if err := session.Error; err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		if len(ref.Reports) == 0 {
-			return nil, gorm.ErrRecordNotFound
+			return nil, "", gorm.ErrRecordNotFound
 		}
 		rst = ref.Reports
 	}
+	rst, next := p.next(rst)
 	reports := make([]*core.Report, len(rst))
 	for i, report := range rst {
-		reports[i] = report.ToCoreReport()
+		result, err := report.ToCoreReport(store.Blob)
+		if err != nil {
+			return nil, "", err
+		}
+		reports[i] = result
 		reports[i].Reference = r.Reference
 	}
-	return reports, nil
+	return reports, next, nil
 }
 
-// List reports with reference
+// List reports with reference from the latest
 //
 // reference (ref) could be commit SHA, branch or tag name.
 // The files and data field will be remove from result to reduce memory usage.
-func (store *ReportStore) List(reportID, ref string) ([]*core.Report, error) {
+func (store *ReportStore) List(reportID, ref string, page core.Page) ([]*core.Report, string, error) {
+	p, err := newReportPage(page)
+	if err != nil {
+		return nil, "", err
+	}
 	session := store.DB.Session()
 	var reports reportList
 	condition := &Report{ReportID: reportID, Commit: ref}
-	err := session.Preload("Coverages").Where(condition).Order(
-		"created_at desc",
-	).Limit(100).Find(&reports).Error
+	err = p.apply(session.Preload("Coverages").Where(condition)).Find(&reports).Error
 	if err == nil && len(reports) > 0 {
-		return reports.ToCoreReports(""), nil
+		reports, next := p.next(reports)
+		return reportList(reports).ToCoreReports(""), next, nil
 	}
 	reference := &Reference{ReportID: reportID, Name: ref}
 	session = store.DB.Session().Preload("Reports", func(db *gorm.DB) *gorm.DB {
-		return db.Order(
-			"created_at desc",
-		).Limit(200)
+		return p.apply(db)
 	}).Preload("Reports.Coverages").First(reference, reference)
 	if err := session.Error; err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if len(reference.Reports) == 0 {
-		return nil, gorm.ErrRecordNotFound
+		return nil, "", gorm.ErrRecordNotFound
+	}
+	reports, next := p.next(reference.Reports)
+	return reportList(reports).ToCoreReports(ref), next, nil
+}
+
+// History of the reference from the oldest, created in [from, to).
+// Only aggregates of coverages are loaded.
+func (store *ReportStore) History(reportID, ref string, from, to time.Time) ([]*core.ReportSummary, error) {
+	query, err := store.referenceQuery(reportID, ref, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var reports []*Report
+	if err := query.Preload("Coverages", func(db *gorm.DB) *gorm.DB {
+		return db.Select(
+			"id", "report_id", "type", "statement_coverage", "file_count", "line_count", "covered_count",
+		).Order("type")
+	}).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.ReportSummary, len(reports))
+	for i, report := range reports {
+		result[i] = report.toSummary()
+	}
+	return result, nil
+}
+
+// FileHistory of the reference from the oldest, created in [from, to).
+// Files of all paths are listed if path is empty.
+func (store *ReportStore) FileHistory(reportID, ref, path string, from, to time.Time) ([]*core.FileSummary, error) {
+	query, err := store.referenceQuery(reportID, ref, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var reports []*Report
+	if err := query.Find(&reports).Error; err != nil {
+		return nil, err
 	}
-	return reportList(reference.Reports).ToCoreReports(ref), nil
+	return store.fileSummaries(reports, path)
 }
 
-// CreateComment of the report summary
+// FileSummaries of files in the report of the commit
+func (store *ReportStore) FileSummaries(reportID, commit string) ([]*core.FileSummary, error) {
+	report := &Report{}
+	if err := store.DB.Session().Select("id", "commit", "created_at").Where(
+		&Report{ReportID: reportID, Commit: commit},
+	).First(report).Error; err != nil {
+		return nil, err
+	}
+	return store.fileSummaries([]*Report{report}, "")
+}
+
+// fileSummaries of the reports in order, files of all paths are listed if path is empty
+func (store *ReportStore) fileSummaries(reports []*Report, path string) ([]*core.FileSummary, error) {
+	ids := make([]uint, len(reports))
+	for i, report := range reports {
+		ids[i] = report.ID
+	}
+	result := make([]*core.FileSummary, 0)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	files := store.DB.Session().Where("report_id IN ?", ids)
+	if path != "" {
+		files = files.Where(&FileSummary{Path: path})
+	}
+	var summaries []*FileSummary
+	if err := files.Order("type").Order("path").Find(&summaries).Error; err != nil {
+		return nil, err
+	}
+	summariesOf := make(map[uint][]*FileSummary)
+	for _, summary := range summaries {
+		summariesOf[summary.ReportID] = append(summariesOf[summary.ReportID], summary)
+	}
+	for _, report := range reports {
+		for _, summary := range summariesOf[report.ID] {
+			result = append(result, &core.FileSummary{
+				Commit:    report.Commit,
+				CreatedAt: report.CreatedAt,
+				Type:      core.ReportType(summary.Type),
+				Path:      summary.Path,
+				Lines:     summary.Lines,
+				Covered:   summary.Covered,
+			})
+		}
+	}
+	return result, nil
+}
+
+// referenceQuery of reports of the reference from the oldest, created in [from, to),
+// which selects neither files nor coverage data
+func (store *ReportStore) referenceQuery(reportID, ref string, from, to time.Time) (*gorm.DB, error) {
+	session := store.DB.Session()
+	reference := &Reference{}
+	if err := session.First(reference, &Reference{ReportID: reportID, Name: ref}).Error; err != nil {
+		return nil, err
+	}
+	query := session.Select("id", "commit", "created_at").Where(
+		"id IN (?)",
+		session.Table("report_reference").Select("report_id").Where("reference_id = ?", reference.ID),
+	)
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	return query.Order("created_at").Order("id"), nil
+}
+
+// CreateComment of the report summary, which may be created before the comment is posted
 func (store *ReportStore) CreateComment(r *core.Report, comment *core.ReportComment) error {
-	if comment.Comment <= 0 || comment.Number <= 0 {
+	if comment.Comment < 0 || comment.Number <= 0 {
 		return fmt.Errorf("invalid comment")
 	}
 	session := store.DB.Session()
@@ -212,15 +604,149 @@ func (store *ReportStore) FindComment(r *core.Report, number int) (*core.ReportC
 	}, nil
 }
 
+// ListComments of the report ID
+func (store *ReportStore) ListComments(reportID string) ([]*core.ReportComment, error) {
+	session := store.DB.Session()
+	var comments []*ReportComment
+	if err := session.Where(&ReportComment{ReportID: reportID}).Order("number").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.ReportComment, len(comments))
+	for i, comment := range comments {
+		result[i] = &core.ReportComment{
+			Number:  comment.Number,
+			Comment: comment.Comment,
+		}
+	}
+	return result, nil
+}
+
+// ListReferences of the report ID with their reports
+func (store *ReportStore) ListReferences(reportID string) ([]*core.Reference, error) {
+	session := store.DB.Session()
+	var references []*Reference
+	err := session.Preload("Reports", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "report_id", "commit", "created_at").Order("created_at desc")
+	}).Where(&Reference{ReportID: reportID}).Order("id").Find(&references).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*core.Reference, len(references))
+	for i, ref := range references {
+		reports := make([]*core.Report, len(ref.Reports))
+		for j, report := range ref.Reports {
+			reports[j] = &core.Report{
+				ReportID:  report.ReportID,
+				Commit:    report.Commit,
+				Reference: ref.Name,
+				CreatedAt: report.CreatedAt,
+			}
+		}
+		result[i] = &core.Reference{
+			Name:            ref.Name,
+			BranchDeletedAt: ref.BranchDeletedAt,
+			Reports:         reports,
+		}
+	}
+	return result, nil
+}
+
+// UpdateBranchDeleted sets when the branch of the reference is found deleted, nil if it exists
+func (store *ReportStore) UpdateBranchDeleted(reportID, name string, deletedAt *time.Time) error {
+	session := store.DB.Session()
+	return session.Model(&Reference{}).Where(
+		&Reference{ReportID: reportID, Name: name},
+	).Update("branch_deleted_at", deletedAt).Error
+}
+
+// Delete reports of the commits with their coverages.
+// References and comments left without reports are deleted as well.
+// Blobs no longer used by any coverage are removed after the deletion is committed.
+func (store *ReportStore) Delete(reportID string, commits ...string) error {
+	var keys []string
+	err := withTransaction(store.DB, func(db core.DatabaseService) error {
+		var err error
+		keys, err = deleteReports(db.Session(), reportID, commits)
+		return err
+	})
+	if err != nil || store.Blob == nil {
+		return err
+	}
+	return store.removeBlobs(keys)
+}
+
+// deleteReports returns blob keys of the deleted coverages
+func deleteReports(tx *gorm.DB, reportID string, commits []string) ([]string, error) {
+	var ids []uint
+	if err := tx.Model(&Report{}).Unscoped().Where(map[string]interface{}{
+		"report_id": reportID,
+		"commit":    commits,
+	}).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var keys []string
+	if err := tx.Model(&Coverage{}).Unscoped().Distinct("blob_key").Where(
+		"report_id IN ? AND blob_key <> ?", ids, "",
+	).Pluck("blob_key", &keys).Error; err != nil {
+		return nil, err
+	}
+	deletes := []func() *gorm.DB{
+		func() *gorm.DB {
+			return tx.Unscoped().Where("report_id IN ?", ids).Delete(&Coverage{})
+		},
+		func() *gorm.DB {
+			return tx.Where("report_id IN ?", ids).Delete(&FileSummary{})
+		},
+		func() *gorm.DB {
+			return tx.Exec("DELETE FROM report_reference WHERE report_id IN ?", ids)
+		},
+		func() *gorm.DB {
+			return tx.Unscoped().Where("id IN ?", ids).Delete(&Report{})
+		},
+		func() *gorm.DB {
+			return tx.Unscoped().Where(
+				"report_id = ? AND id NOT IN (?)",
+				reportID, tx.Table("report_reference").Select("reference_id"),
+			).Delete(&Reference{})
+		},
+	}
+	for _, fn := range deletes {
+		if err := fn().Error; err != nil {
+			return nil, err
+		}
+	}
+	var remains int64
+	if err := tx.Model(&Report{}).Unscoped().Where(
+		&Report{ReportID: reportID},
+	).Count(&remains).Error; err != nil {
+		return nil, err
+	}
+	if remains > 0 {
+		return keys, nil
+	}
+	comment := &ReportComment{ReportID: reportID}
+	if err := tx.Unscoped().Where(comment).Delete(&ReportComment{}).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
 func (store *ReportStore) updateCoverage(r *Report, cov *core.CoverageReport) error {
 	c, ok := r.find(cov.Type)
-	if err := copyCoverage(c, cov); err != nil {
+	old := c.BlobKey
+	if err := copyCoverage(c, cov, store.Blob); err != nil {
 		return err
 	}
+	if old != "" && old != c.BlobKey {
+		store.replaced = append(store.replaced, old)
+	}
 	if !ok {
 		r.Coverages = append(r.Coverages, c)
 	} else if c.ID > 0 {
-		store.DB.Session().Save(c)
+		return store.DB.Session().Save(c).Error
 	}
 	return nil
 }
@@ -244,25 +770,60 @@ func (r *Report) Files() ([]string, error) {
 	return files, err
 }
 
-// ToCoreReport object
-func (r *Report) ToCoreReport() *core.Report {
+// ToCoreReport object with coverage data fetched from the blob store
+func (r *Report) ToCoreReport(blob core.BlobStore) (*core.Report, error) {
 	coverages := make([]*core.CoverageReport, len(r.Coverages))
 	for i, coverage := range r.Coverages {
-		c, _ := coverage.ToCoreCoverage()
+		c, err := coverage.ToCoreCoverage(blob)
+		if err != nil && c == nil {
+			return nil, err
+		}
 		coverages[i] = c
 	}
+	report := r.toCoreSummary()
+	report.Coverages = coverages
+	return report, nil
+}
+
+// toCoreSummary of the report without files and coverage data
+func (r *Report) toCoreSummary() *core.Report {
 	files, err := r.Files()
 	if err != nil {
 		files = []string{}
 	}
-	report := &core.Report{
+	coverages := make([]*core.CoverageReport, len(r.Coverages))
+	for i, coverage := range r.Coverages {
+		coverages[i] = coverage.toCoreSummary()
+	}
+	return &core.Report{
 		Commit:    r.Commit,
 		ReportID:  r.ReportID,
 		Files:     files,
 		Coverages: coverages,
 		CreatedAt: r.CreatedAt,
 	}
-	return report
+}
+
+// toSummary of the report from aggregates of its coverages
+func (r *Report) toSummary() *core.ReportSummary {
+	summary := &core.ReportSummary{
+		Commit:    r.Commit,
+		CreatedAt: r.CreatedAt,
+		Coverages: make([]*core.CoverageSummary, len(r.Coverages)),
+	}
+	for i, coverage := range r.Coverages {
+		summary.Coverages[i] = &core.CoverageSummary{
+			Type:              core.ReportType(coverage.Type),
+			StatementCoverage: coverage.StatementCoverage,
+			Files:             coverage.FileCount,
+			Lines:             coverage.LineCount,
+			Covered:           coverage.CoveredCount,
+		}
+		summary.Files += coverage.FileCount
+		summary.Lines += coverage.LineCount
+		summary.Covered += coverage.CoveredCount
+	}
+	return summary
 }
 
 func (r *Report) find(t core.ReportType) (*Coverage, bool) {
@@ -274,25 +835,53 @@ func (r *Report) find(t core.ReportType) (*Coverage, bool) {
 	return &Coverage{}, false
 }
 
-// ToCoreCoverage unmarshal Coverage Data
-func (c *Coverage) ToCoreCoverage() (*core.CoverageReport, error) {
+// ToCoreCoverage unmarshal Coverage Data, which is fetched from the blob store lazily.
+// It returns nil only if the data is not able to fetch.
+func (c *Coverage) ToCoreCoverage(blob core.BlobStore) (*core.CoverageReport, error) {
+	data, err := c.data(blob)
+	if err != nil {
+		return nil, err
+	}
 	cover := &core.CoverageReport{}
 	cover.Type = core.ReportType(c.Type)
-	if err := json.Unmarshal(c.Data, cover); err != nil {
+	if err := decodeCoverage(data, cover); err != nil {
 		return cover, err
 	}
 	cover.StatementCoverage = cover.ComputeStatementCoverage()
 	return cover, nil
 }
 
+func (c *Coverage) data(blob core.BlobStore) ([]byte, error) {
+	if c.BlobKey == "" {
+		return c.Data, nil
+	}
+	if blob == nil {
+		return nil, fmt.Errorf("require blob store for coverage %s", c.BlobKey)
+	}
+	return blob.Get(c.BlobKey)
+}
+
+// toCoreSummary of the coverage without files, which does not fetch data from blob store
+func (c *Coverage) toCoreSummary() *core.CoverageReport {
+	if c.BlobKey == "" && len(c.Data) > 0 {
+		// coverage kept in the database, which may not have statement coverage
+		if cover, err := c.ToCoreCoverage(nil); err == nil {
+			cover.Files = nil
+			return cover
+		}
+	}
+	return &core.CoverageReport{
+		Type:              core.ReportType(c.Type),
+		StatementCoverage: c.StatementCoverage,
+	}
+}
+
+// ToCoreReports summaries without files and coverage data
 func (r reportList) ToCoreReports(ref string) []*core.Report {
 	result := make([]*core.Report, len(r))
 	for i, report := range r {
 		report.FileData = nil
-		coreReport := report.ToCoreReport()
-		for _, coverage := range coreReport.Coverages {
-			coverage.Files = nil
-		}
+		coreReport := report.toCoreSummary()
 		coreReport.Reference = ref
 		result[i] = coreReport
 	}
@@ -316,12 +905,27 @@ func copyReport(dst *Report, src *core.Report) {
 	dst.FileData = files
 }
 
-func copyCoverage(dst *Coverage, src *core.CoverageReport) error {
-	cov, err := json.Marshal(src)
+func copyCoverage(dst *Coverage, src *core.CoverageReport, blob core.BlobStore) error {
+	cov, err := encodeCoverage(src)
 	if err != nil {
 		return err
 	}
-	dst.Data = cov
+	summary := src.Summary()
 	dst.Type = string(src.Type)
+	dst.StatementCoverage = summary.StatementCoverage
+	dst.FileCount = summary.Files
+	dst.LineCount = summary.Lines
+	dst.CoveredCount = summary.Covered
+	if blob == nil {
+		dst.Data = cov
+		dst.BlobKey = ""
+		return nil
+	}
+	key, err := blob.Put(cov)
+	if err != nil {
+		return err
+	}
+	dst.Data = nil
+	dst.BlobKey = key
 	return nil
 }
@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"reflect"
 	"sort"
 	"strings"
@@ -13,6 +15,8 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/blob"
 )
 
 // nolint:unused
@@ -184,7 +188,10 @@ func TestReportStoreUpload(t *testing.T) {
 		if len(results) != 1 {
 			t.Fatal()
 		}
-		result := results[0].ToCoreReport()
+		result, err := results[0].ToCoreReport(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
 		sort.Sort(coverageSlice(result.Coverages))
 		expect := expects[i]
 		sort.Sort(coverageSlice(expect.Coverages))
@@ -572,7 +579,7 @@ func TestReportFinds(t *testing.T) {
 	for i, query := range queries[base:] {
 		t.Run(queryString(query), func(t *testing.T) {
 			expect := expects[i+base]
-			results, err := store.Finds(query)
+			results, _, err := store.Finds(query, core.Page{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -695,7 +702,7 @@ func TestReportList(t *testing.T) {
 	base := 0
 	for i, query := range queries[base:] {
 		t.Run(fmt.Sprintf("%s,%s", query[0], query[1]), func(t *testing.T) {
-			result, err := store.List(query[0], query[1])
+			result, _, err := store.List(query[0], query[1], core.Page{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -746,6 +753,13 @@ func TestReportComment(t *testing.T) {
 	if err := store.CreateComment(report, &core.ReportComment{}); err == nil {
 		t.Fail()
 	}
+	// comments are created before they are posted
+	if err := store.CreateComment(report, &core.ReportComment{Number: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if comment, err := store.FindComment(report, 1); err != nil || comment.Comment != 0 {
+		t.Fatal("should create the comment not posted yet")
+	}
 
 	if err := store.CreateComment(report, &core.ReportComment{Comment: 1, Number: 1}); err != nil {
 		t.Fatal(err)
@@ -771,3 +785,796 @@ func TestReportComment(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// injectFailure to creates and updates of the table until restored
+func injectFailure(t *testing.T, table string) (restore func()) {
+	name := "test:inject_failure"
+	fail := func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			_ = tx.AddError(errors.New("injected failure"))
+		}
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register(name, fail); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(name, fail); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		_ = db.Callback().Create().Remove(name)
+		_ = db.Callback().Update().Remove(name)
+	}
+}
+
+func TestReportUploadAtomic(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &ReportStore{DB: service}
+
+	id := "TestReportUploadAtomic"
+	report := &core.Report{
+		ReportID:  id,
+		Commit:    "commit",
+		Reference: "master",
+		Coverages: []*core.CoverageReport{
+			{Type: core.ReportGo},
+		},
+	}
+
+	// fail between creating the report and its steps
+	for _, table := range []string{"report_reference", "coverages"} {
+		restore := injectFailure(t, table)
+		err := store.Upload(report)
+		restore()
+		if err == nil {
+			t.Fatalf("should fail when %s fails", table)
+		}
+		if _, err := store.Find(&core.Report{ReportID: id, Commit: "commit"}); err == nil {
+			t.Fatalf("should not leave report when %s fails", table)
+		}
+		if _, err := store.Find(&core.Report{ReportID: id, Reference: "master"}); err == nil {
+			t.Fatalf("should not leave reference when %s fails", table)
+		}
+	}
+
+	if err := store.Upload(report); err != nil {
+		t.Fatal(err)
+	}
+
+	// fail after coverages are updated
+	update := &core.Report{
+		ReportID:  id,
+		Commit:    "commit",
+		Reference: "dev",
+		Coverages: []*core.CoverageReport{
+			{Type: core.ReportGo, StatementCoverage: 0.5},
+		},
+	}
+	restore := injectFailure(t, "reports")
+	if err := store.Upload(update); err == nil {
+		t.Fatal("should fail when report fails")
+	}
+	restore()
+	result, err := store.Find(&core.Report{ReportID: id, Commit: "commit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Coverages[0].StatementCoverage != 0 {
+		t.Fatal("should rollback coverages")
+	}
+	if _, err := store.Find(&core.Report{ReportID: id, Reference: "dev"}); err == nil {
+		t.Fatal("should rollback reference")
+	}
+
+	// rollback changes in transaction
+	errAbort := errors.New("abort")
+	if err := store.Transaction(func(tx core.ReportStore) error {
+		if err := tx.Upload(update); err != nil {
+			return err
+		}
+		if _, err := tx.Find(&core.Report{ReportID: id, Reference: "dev"}); err != nil {
+			t.Fatal("should find changes in the same transaction")
+		}
+		return errAbort
+	}); err != errAbort {
+		t.Fatal("should return error of the transaction")
+	}
+	if _, err := store.Find(&core.Report{ReportID: id, Reference: "dev"}); err == nil {
+		t.Fatal("should rollback transaction")
+	}
+}
+
+func TestReportUploadBlob(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := blob.NewFileSystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ReportStore{DB: service, Blob: fs}
+
+	id := "TestReportUploadBlob"
+	coverage := &core.CoverageReport{
+		Type: core.ReportGo,
+		Files: []*core.File{
+			{
+				Name:              "a.go",
+				StatementCoverage: 0.5,
+				StatementHits: []*core.StatementHit{
+					{LineNumber: 1, Hits: 1},
+					{LineNumber: 2, Hits: 0},
+				},
+			},
+		},
+	}
+	for _, commit := range []string{"commit1", "commit2"} {
+		if err := store.Upload(&core.Report{
+			ReportID:  id,
+			Commit:    commit,
+			Reference: "master",
+			Coverages: []*core.CoverageReport{coverage},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var coverages []*Coverage
+	service.Session().Joins(
+		"JOIN reports ON reports.id = coverages.report_id",
+	).Where("reports.report_id = ?", id).Find(&coverages)
+	if len(coverages) != 2 {
+		t.Fatalf("expect 2 coverages, got %d", len(coverages))
+	}
+	for _, c := range coverages {
+		if len(c.Data) > 0 || c.BlobKey == "" {
+			t.Fatal("should keep coverage data in blob store")
+		}
+		if c.BlobKey != coverages[0].BlobKey {
+			t.Fatal("should deduplicate same coverage data")
+		}
+	}
+
+	report, err := store.Find(&core.Report{ReportID: id, Commit: "commit1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Coverages) != 1 || len(report.Coverages[0].Files) != 1 {
+		t.Fatal("should fetch coverage data from blob store")
+	}
+	if report.Coverages[0].StatementCoverage != 0.5 {
+		t.Fatalf("expect coverage 0.5, got %f", report.Coverages[0].StatementCoverage)
+	}
+
+	// list summaries without fetching any blob
+	strict := &ReportStore{DB: service, Blob: mock.NewMockBlobStore(ctrl)}
+	reports, _, err := strict.List(id, "master", core.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expect 2 reports, got %d", len(reports))
+	}
+	for _, r := range reports {
+		if len(r.Coverages) != 1 || r.Coverages[0].StatementCoverage != 0.5 {
+			t.Fatal("should keep statement coverage in summary")
+		}
+	}
+	summary, err := strict.FindSummary(&core.Report{ReportID: id, Reference: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Coverages) != 1 || summary.Coverages[0].StatementCoverage != 0.5 {
+		t.Fatal("should find summary without fetching blob")
+	}
+	if len(summary.Coverages[0].Files) > 0 {
+		t.Fatal("should not load files in summary")
+	}
+
+	// remove blobs put in rolled back transactions, but not those still in use
+	errAbort := errors.New("abort")
+	other := &core.CoverageReport{
+		Type:  core.ReportPerl,
+		Files: []*core.File{{Name: "a.pl", StatementCoverage: 1}},
+	}
+	if err := store.Transaction(func(tx core.ReportStore) error {
+		if err := tx.Upload(&core.Report{
+			ReportID:  id,
+			Commit:    "commit3",
+			Coverages: []*core.CoverageReport{coverage, other},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return errAbort
+	}); err != errAbort {
+		t.Fatal("should return error of the transaction")
+	}
+	if _, err := fs.Get(coverages[0].BlobKey); err != nil {
+		t.Fatal("should keep blob used by other coverages")
+	}
+	data, err := encodeCoverage(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Get(blob.Key(data)); err != core.ErrBlobNotFound {
+		t.Fatal("should remove blob of rolled back transaction")
+	}
+}
+
+func TestReportRemoveBlobWithUpload(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := blob.NewFileSystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ReportStore{DB: service, Blob: fs}
+
+	id := "TestReportRemoveBlobWithUpload"
+	coverage := &core.CoverageReport{
+		Type:  core.ReportGo,
+		Files: []*core.File{{Name: "gc.go", StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: 1}}}},
+	}
+	data, err := encodeCoverage(coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// blob put by an upload rolled back later, which is not used by any coverage
+	key, err := fs.Put(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the upload deduplicates onto the blob and is not committed yet
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	uploaded := make(chan error)
+	go func() {
+		uploaded <- store.Transaction(func(tx core.ReportStore) error {
+			if err := tx.Upload(&core.Report{
+				ReportID:  id,
+				Commit:    "commit",
+				Coverages: []*core.CoverageReport{coverage},
+			}); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+	removed := make(chan error)
+	go func() {
+		removed <- store.removeBlobs([]string{key})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	if err := <-uploaded; err != nil {
+		t.Fatal(err)
+	}
+	// the removal either waits for the upload or fails to lock the blob
+	<-removed
+
+	if _, err := fs.Get(key); err != nil {
+		t.Fatal("should keep blob used by the committed upload")
+	}
+	report, err := store.Find(&core.Report{ReportID: id, Commit: "commit"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Coverages) != 1 || len(report.Coverages[0].Files) != 1 {
+		t.Fatal("should fetch coverage data of the upload")
+	}
+
+	if err := store.Delete(id, "commit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Get(key); err != core.ErrBlobNotFound {
+		t.Fatal("should remove blob no longer used")
+	}
+	var count int64
+	service.Session().Model(&BlobLock{}).Where(&BlobLock{BlobKey: key}).Count(&count)
+	if count != 0 {
+		t.Fatal("should remove lock of the removed blob")
+	}
+}
+
+func TestReportUploadReplaceBlob(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := blob.NewFileSystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ReportStore{DB: service, Blob: fs}
+
+	id := "TestReportUploadReplaceBlob"
+	upload := func(store core.ReportStore, lines int) string {
+		coverage := &core.CoverageReport{
+			Type:  core.ReportGo,
+			Files: []*core.File{{Name: "replace.go"}},
+		}
+		for i := 1; i <= lines; i++ {
+			coverage.Files[0].StatementHits = append(coverage.Files[0].StatementHits, &core.StatementHit{LineNumber: i})
+		}
+		if err := store.Upload(&core.Report{
+			ReportID:  id,
+			Commit:    "commit",
+			Coverages: []*core.CoverageReport{coverage},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := encodeCoverage(coverage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return blob.Key(data)
+	}
+
+	first := upload(store, 1)
+	second := upload(store, 2)
+	if _, err := fs.Get(first); err != core.ErrBlobNotFound {
+		t.Fatal("should remove blob replaced by upload of the same commit")
+	}
+	if _, err := fs.Get(second); err != nil {
+		t.Fatal("should keep blob of the latest upload")
+	}
+
+	// blobs replaced in nested transactions are removed after the outermost commit
+	var third string
+	if err := store.Transaction(func(tx core.ReportStore) error {
+		third = upload(tx, 3)
+		if _, err := fs.Get(second); err != nil {
+			t.Fatal("should keep replaced blob before commit")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Get(second); err != core.ErrBlobNotFound {
+		t.Fatal("should remove replaced blob after commit")
+	}
+	if _, err := fs.Get(third); err != nil {
+		t.Fatal("should keep blob of the latest upload")
+	}
+}
+
+func TestReportDelete(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fs, err := blob.NewFileSystemStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ReportStore{DB: service, Blob: fs}
+
+	id := "TestReportDelete"
+	shared := &core.CoverageReport{
+		Type:  core.ReportGo,
+		Files: []*core.File{{Name: "shared.go"}},
+	}
+	unique := &core.CoverageReport{
+		Type:  core.ReportGo,
+		Files: []*core.File{{Name: "unique.go"}},
+	}
+	uploads := []struct {
+		commit   string
+		ref      string
+		coverage *core.CoverageReport
+	}{
+		{"commit1", "master", shared},
+		{"commit2", "master", shared},
+		{"commit2", "feature", shared},
+		{"commit3", "feature", unique},
+	}
+	for _, upload := range uploads {
+		if err := store.Upload(&core.Report{
+			ReportID:  id,
+			Commit:    upload.commit,
+			Reference: upload.ref,
+			Coverages: []*core.CoverageReport{upload.coverage},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.CreateComment(&core.Report{ReportID: id}, &core.ReportComment{Number: 1, Comment: 1}); err != nil {
+		t.Fatal(err)
+	}
+	sharedData, _ := encodeCoverage(shared)
+	uniqueData, _ := encodeCoverage(unique)
+	exists := func(data []byte) bool {
+		_, err := fs.Get(blob.Key(data))
+		return err == nil
+	}
+
+	references, err := store.ListReferences(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(references) != 2 || references[0].Name != "master" || references[1].Name != "feature" {
+		t.Fatal("should list references")
+	}
+	if len(references[1].Reports) != 2 || references[1].Reports[0].Commit != "commit3" {
+		t.Fatal("should list reports from the latest")
+	}
+	now := time.Now()
+	if err := store.UpdateBranchDeleted(id, "feature", &now); err != nil {
+		t.Fatal(err)
+	}
+	references, _ = store.ListReferences(id)
+	if references[1].BranchDeletedAt == nil || references[0].BranchDeletedAt != nil {
+		t.Fatal("should mark deleted branch")
+	}
+
+	if err := store.Delete(id, "commit3"); err != nil {
+		t.Fatal(err)
+	}
+	if exists(uniqueData) || !exists(sharedData) {
+		t.Fatal("should delete unused blob only")
+	}
+	if err := store.Delete(id, "commit2"); err != nil {
+		t.Fatal(err)
+	}
+	references, _ = store.ListReferences(id)
+	if len(references) != 1 || references[0].Name != "master" || len(references[0].Reports) != 1 {
+		t.Fatal("should delete reference without reports")
+	}
+	if !exists(sharedData) {
+		t.Fatal("should keep blob in use")
+	}
+	if _, err := store.FindComment(&core.Report{ReportID: id}, 1); err != nil {
+		t.Fatal("should keep comment of remaining reports")
+	}
+
+	if err := store.Delete(id, "commit1"); err != nil {
+		t.Fatal(err)
+	}
+	if exists(sharedData) {
+		t.Fatal("should delete blob")
+	}
+	if _, err := store.FindComment(&core.Report{ReportID: id}, 1); err == nil {
+		t.Fatal("should delete comment without reports")
+	}
+	var count int64
+	service.Session().Unscoped().Model(&Report{}).Where(&Report{ReportID: id}).Count(&count)
+	if count > 0 {
+		t.Fatal("should delete reports")
+	}
+	service.Session().Unscoped().Model(&Coverage{}).Where(
+		"blob_key IN ?", []string{blob.Key(sharedData), blob.Key(uniqueData)},
+	).Count(&count)
+	if count > 0 {
+		t.Fatal("should delete coverages")
+	}
+	service.Session().Table("report_reference").Where(
+		"report_id NOT IN (?)", service.Session().Unscoped().Model(&Report{}).Select("id"),
+	).Count(&count)
+	if count > 0 {
+		t.Fatal("should delete report references")
+	}
+}
+
+func TestReportPagination(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &ReportStore{DB: service}
+
+	id := "TestReportPagination"
+	commits := []string{"c5", "c4", "c3", "c2", "c1"}
+	createdAt := time.Now().Add(-time.Hour)
+	for i, commit := range commits {
+		if err := store.Upload(&core.Report{
+			ReportID:  id,
+			Commit:    commit,
+			Reference: "master",
+			Coverages: []*core.CoverageReport{{Type: core.ReportGo}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		// reports c4 and c3 are created at the same time
+		if i != 2 {
+			createdAt = createdAt.Add(-time.Minute)
+		}
+		service.Session().Model(&Report{}).Where(
+			&Report{ReportID: id, Commit: commit},
+		).Update("created_at", createdAt)
+	}
+
+	listers := map[string]func(page core.Page) ([]*core.Report, string, error){
+		"finds": func(page core.Page) ([]*core.Report, string, error) {
+			return store.Finds(&core.Report{ReportID: id}, page)
+		},
+		"finds reference": func(page core.Page) ([]*core.Report, string, error) {
+			return store.Finds(&core.Report{ReportID: id, Reference: "master"}, page)
+		},
+		"list": func(page core.Page) ([]*core.Report, string, error) {
+			return store.List(id, "master", page)
+		},
+	}
+	for name, list := range listers {
+		t.Run(name, func(t *testing.T) {
+			var result []string
+			page := core.Page{Limit: 2}
+			pages := 0
+			for {
+				reports, next, err := list(page)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, report := range reports {
+					result = append(result, report.Commit)
+				}
+				pages++
+				if next == "" {
+					break
+				}
+				page.Cursor = next
+			}
+			if pages != 3 {
+				t.Fatalf("expect 3 pages, got %d", pages)
+			}
+			// reports created at the same time are listed from the last uploaded
+			expect := []string{"c5", "c3", "c4", "c2", "c1"}
+			if diff := cmp.Diff(expect, result); diff != "" {
+				t.Fatal(diff)
+			}
+			if _, _, err := list(core.Page{Cursor: "invalid"}); err != core.ErrInvalidCursor {
+				t.Fatal("should reject invalid cursor")
+			}
+		})
+	}
+}
+
+func TestReportRestore(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &ReportStore{DB: service}
+
+	id := "TestReportRestore"
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	deletedAt := time.Date(2020, 2, 3, 4, 5, 6, 0, time.UTC)
+	report := &core.Report{
+		ReportID:  id,
+		Commit:    "commit1",
+		Files:     []string{"a.go"},
+		CreatedAt: createdAt,
+		Coverages: []*core.CoverageReport{
+			{Type: core.ReportGo, Files: []*core.File{{Name: "a.go", StatementCoverage: 0.5}}},
+		},
+	}
+	ref := &core.Reference{
+		Name:            "feature",
+		BranchDeletedAt: &deletedAt,
+		Reports:         []*core.Report{{Commit: "commit1"}, {Commit: "missing"}},
+	}
+	comment := &core.ReportComment{Number: 1, Comment: 2}
+	// restore twice should not duplicate anything
+	for i := 0; i < 2; i++ {
+		if err := store.Restore(report); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.RestoreReference(id, ref); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateComment(report, comment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := store.Find(&core.Report{ReportID: id, Reference: "feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.CreatedAt.Equal(createdAt) {
+		t.Fatalf("should restore created time, got %v", result.CreatedAt)
+	}
+	if diff := cmp.Diff(report.Files, result.Files); diff != "" {
+		t.Fatal(diff)
+	}
+	if len(result.Coverages) != 1 || result.Coverages[0].StatementCoverage != 0.5 {
+		t.Fatal("should restore coverages")
+	}
+	references, err := store.ListReferences(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(references) != 1 || len(references[0].Reports) != 1 {
+		t.Fatal("should link reference once")
+	}
+	if references[0].BranchDeletedAt == nil || !references[0].BranchDeletedAt.Equal(deletedAt) {
+		t.Fatal("should restore branch deleted time")
+	}
+	comments, err := store.ListComments(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*core.ReportComment{comment}, comments); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestReportHistory(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &ReportStore{DB: service}
+
+	id := "TestReportHistory"
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ref := &core.Reference{Name: "master"}
+	for i := 0; i < 3; i++ {
+		report := &core.Report{
+			ReportID:  id,
+			Commit:    fmt.Sprintf("commit%d", i),
+			CreatedAt: day.AddDate(0, 0, i),
+			Coverages: []*core.CoverageReport{
+				{Type: core.ReportGo, Files: []*core.File{
+					{Name: "a.go", StatementCoverage: 0.5, StatementHits: []*core.StatementHit{
+						{LineNumber: 1, Hits: 1},
+						{LineNumber: 2, Hits: 0},
+					}},
+				}},
+				{Type: core.ReportPerl, Files: []*core.File{
+					{Name: "a.pl", StatementCoverage: 1, StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: i}}},
+				}},
+			},
+		}
+		if err := store.Restore(report); err != nil {
+			t.Fatal(err)
+		}
+		ref.Reports = append(ref.Reports, report)
+	}
+	if err := store.RestoreReference(id, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := store.History(id, "master", day.AddDate(0, 0, 1), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, summary := range history {
+		summary.CreatedAt = summary.CreatedAt.UTC()
+	}
+	expect := []*core.ReportSummary{
+		{
+			Commit:    "commit1",
+			CreatedAt: day.AddDate(0, 0, 1),
+			Coverages: []*core.CoverageSummary{
+				{Type: core.ReportGo, StatementCoverage: 0.5, Files: 1, Lines: 2, Covered: 1},
+				{Type: core.ReportPerl, StatementCoverage: 1, Files: 1, Lines: 1, Covered: 1},
+			},
+			Files:   2,
+			Lines:   3,
+			Covered: 2,
+		},
+		{
+			Commit:    "commit2",
+			CreatedAt: day.AddDate(0, 0, 2),
+			Coverages: []*core.CoverageSummary{
+				{Type: core.ReportGo, StatementCoverage: 0.5, Files: 1, Lines: 2, Covered: 1},
+				{Type: core.ReportPerl, StatementCoverage: 1, Files: 1, Lines: 1, Covered: 1},
+			},
+			Files:   2,
+			Lines:   3,
+			Covered: 2,
+		},
+	}
+	if diff := cmp.Diff(expect, history); diff != "" {
+		t.Fatal(diff)
+	}
+
+	history, err = store.History(id, "master", time.Time{}, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Commit != "commit0" || history[0].Coverages[1].Covered != 0 {
+		t.Fatal("should be bounded by the end time")
+	}
+	if _, err := store.History(id, "feature", time.Time{}, time.Time{}); err == nil {
+		t.Fatal("should fail on unknown reference")
+	}
+}
+
+func TestReportFileHistory(t *testing.T) {
+	ctrl, service := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &ReportStore{DB: service}
+
+	id := "TestReportFileHistory"
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ref := &core.Reference{Name: "master"}
+	for i := 0; i < 3; i++ {
+		report := &core.Report{
+			ReportID:  id,
+			Commit:    fmt.Sprintf("commit%d", i),
+			CreatedAt: day.AddDate(0, 0, i),
+			Coverages: []*core.CoverageReport{
+				{Type: core.ReportGo, Files: []*core.File{
+					{Name: "a.go", StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: i}, {LineNumber: 2}}},
+					{Name: "b.go", StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: 1}}},
+				}},
+			},
+		}
+		if err := store.Restore(report); err != nil {
+			t.Fatal(err)
+		}
+		ref.Reports = append(ref.Reports, report)
+	}
+	if err := store.RestoreReference(id, ref); err != nil {
+		t.Fatal(err)
+	}
+	// upload again should replace summaries of the commit
+	if err := store.Upload(&core.Report{
+		ReportID: id,
+		Commit:   "commit2",
+		Coverages: []*core.CoverageReport{
+			{Type: core.ReportGo, Files: []*core.File{
+				{Name: "a.go", StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: 1}, {LineNumber: 2, Hits: 1}}},
+			}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := store.FileHistory(id, "master", "a.go", day.AddDate(0, 0, 1), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, summary := range history {
+		summary.CreatedAt = summary.CreatedAt.UTC()
+	}
+	expect := []*core.FileSummary{
+		{Commit: "commit1", CreatedAt: day.AddDate(0, 0, 1), Type: core.ReportGo, Path: "a.go", Lines: 2, Covered: 1},
+		{Commit: "commit2", CreatedAt: day.AddDate(0, 0, 2), Type: core.ReportGo, Path: "a.go", Lines: 2, Covered: 2},
+	}
+	if diff := cmp.Diff(expect, history); diff != "" {
+		t.Fatal(diff)
+	}
+
+	history, err = store.FileHistory(id, "master", "", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("should list all files, got %d", len(history))
+	}
+
+	files, err := store.FileSummaries(id, "commit1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Commit != "commit1" || files[0].Path != "a.go" || files[1].Path != "b.go" {
+		t.Fatal("should list files of the commit")
+	}
+	if _, err := store.FileSummaries(id, "unknown"); err == nil {
+		t.Fatal("should fail on unknown commit")
+	}
+
+	if err := store.Delete(id, "commit0", "commit1", "commit2"); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	service.Session().Model(&FileSummary{}).Where("path = ?", "b.go").Count(&count)
+	if count != 0 {
+		t.Fatal("should delete file summaries with reports")
+	}
+}
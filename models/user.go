@@ -36,7 +36,9 @@ type User struct {
 	GithubToken   string
 	GithubRefresh string
 	GithubExpire  int64
-	Repositories  []*Repo `gorm:"many2many:user_repositories"`
+	// OIDCSubject identifies the user in OpenID Connect provider
+	OIDCSubject  string  `gorm:"index"`
+	Repositories []*Repo `gorm:"many2many:user_repositories"`
 }
 
 // UserStore user in storage
@@ -147,6 +149,87 @@ func (store *UserStore) Bind(
 	return u.toCoreUser(), nil
 }
 
+// FindOIDC user with the subject of OpenID Connect provider
+func (store *UserStore) FindOIDC(subject string) (*core.User, error) {
+	u, err := store.findWithOIDC(subject)
+	if err != nil {
+		return nil, err
+	}
+	return u.toCoreUser(), nil
+}
+
+// CreateOIDC user from OpenID Connect provider
+func (store *UserStore) CreateOIDC(user *core.OIDCUser) (*core.User, error) {
+	if user.Subject == "" || user.Login == "" {
+		return nil, fmt.Errorf("user subject and login should not be empty")
+	}
+	if _, err := store.FindByLogin(user.Login); err == nil {
+		return nil, errUserExist
+	}
+	session := store.DB.Session()
+	u := &User{
+		Login:       user.Login,
+		Email:       user.Email,
+		Avater:      user.Avatar,
+		Active:      true,
+		OIDCSubject: user.Subject,
+	}
+	if err := session.Create(u).Error; err != nil {
+		return nil, err
+	}
+	return u.toCoreUser(), nil
+}
+
+// UpdateOIDC profile of the user from OpenID Connect provider
+func (store *UserStore) UpdateOIDC(user *core.OIDCUser) (*core.User, error) {
+	session := store.DB.Session()
+	u, err := store.findWithOIDC(user.Subject)
+	if err != nil {
+		return nil, err
+	}
+	u.Email = user.Email
+	u.Avater = user.Avatar
+	if err := session.Save(u).Error; err != nil {
+		return nil, err
+	}
+	return u.toCoreUser(), nil
+}
+
+// BindOIDC identity of OpenID Connect provider to registered user
+func (store *UserStore) BindOIDC(user *core.User, oidcUser *core.OIDCUser) (*core.User, error) {
+	if user.Login == "" || oidcUser.Subject == "" {
+		return user, fmt.Errorf("user login and subject should not be empty")
+	}
+	if _, err := store.findWithOIDC(oidcUser.Subject); err == nil {
+		return user, errUserExist
+	}
+	session := store.DB.Session()
+	u := &User{}
+	if err := session.Where(&User{Login: user.Login}).First(u).Error; err != nil {
+		return user, err
+	}
+	if u.OIDCSubject != "" {
+		return user, fmt.Errorf("user %s is bound to another OpenID Connect identity", user.Login)
+	}
+	u.OIDCSubject = oidcUser.Subject
+	if err := session.Save(u).Error; err != nil {
+		return user, err
+	}
+	return u.toCoreUser(), nil
+}
+
+func (store *UserStore) findWithOIDC(subject string) (*User, error) {
+	if subject == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	session := store.DB.Session()
+	u := &User{}
+	if err := session.Where(&User{OIDCSubject: subject}).First(u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
 // ListRepositories for the user
 func (store *UserStore) ListRepositories(user *core.User) ([]*core.Repo, error) {
 	session := store.DB.Session()
@@ -180,6 +263,59 @@ func (store *UserStore) UpdateRepositories(user *core.User, repositories []*core
 	return session.Model(u).Association("Repositories").Replace(userRepos)
 }
 
+// List all users ordered by login
+func (store *UserStore) List() ([]*core.User, error) {
+	session := store.DB.Session()
+	var users []*User
+	if err := session.Order("login").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*core.User, len(users))
+	for i, user := range users {
+		result[i] = user.toCoreUser()
+	}
+	return result, nil
+}
+
+// Delete the user with its tokens, roles and repositories links.
+// It fails if the user is the creator of any repository, which should be transferred first.
+func (store *UserStore) Delete(user *core.User) error {
+	return withTransaction(store.DB, func(db core.DatabaseService) error {
+		session := db.Session()
+		u := &User{}
+		if err := session.Where(&User{Login: user.Login}).First(u).Error; err != nil {
+			return err
+		}
+		var created int64
+		if err := session.Model(&Repo{}).Where(&Repo{Creator: u.Login}).Count(&created).Error; err != nil {
+			return err
+		}
+		if created > 0 {
+			return errUserIsCreator
+		}
+		deletes := []func() *gorm.DB{
+			func() *gorm.DB {
+				return session.Unscoped().Where(&OAuthToken{OwnerID: u.ID}).Delete(&OAuthToken{})
+			},
+			func() *gorm.DB {
+				return session.Unscoped().Where(&RepoPermission{UserID: u.ID}).Delete(&RepoPermission{})
+			},
+			func() *gorm.DB {
+				return session.Exec("DELETE FROM user_repositories WHERE user_id = ?", u.ID)
+			},
+			func() *gorm.DB {
+				return session.Unscoped().Delete(u)
+			},
+		}
+		for _, fn := range deletes {
+			if err := fn().Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (u *User) toCoreUser() *core.User {
 	return &core.User{
 		Login:  u.Login,
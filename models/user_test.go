@@ -276,3 +276,151 @@ func TestUserRepositories(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestUserOIDC(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &UserStore{DB: db}
+
+	oidcUser := &core.OIDCUser{
+		Subject: "oidc_subject",
+		Login:   "oidc_user",
+		Email:   "oidc@example.com",
+	}
+	if _, err := store.FindOIDC(oidcUser.Subject); err == nil {
+		t.Fatal("should not find user before created")
+	}
+	user, err := store.CreateOIDC(oidcUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != oidcUser.Login || user.Email != oidcUser.Email {
+		t.Fatalf("unexpected user %v", user)
+	}
+	if _, err := store.CreateOIDC(&core.OIDCUser{
+		Subject: "other_subject",
+		Login:   oidcUser.Login,
+	}); err != errUserExist {
+		t.Fatal("should not take login of other user")
+	}
+
+	oidcUser.Avatar = "http://avatar"
+	if _, err := store.UpdateOIDC(oidcUser); err != nil {
+		t.Fatal(err)
+	}
+	user, err = store.FindOIDC(oidcUser.Subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Avatar != oidcUser.Avatar {
+		t.Fatal("should update profile")
+	}
+
+	// bind SCM account to the OIDC user
+	giteaUser := &scm.User{Login: "oidc_gitea", Email: "oidc@gitea"}
+	if _, err := store.Bind(core.Gitea, user, giteaUser, &core.Token{}); err != nil {
+		t.Fatal(err)
+	}
+	bound, err := store.Find(core.Gitea, giteaUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bound.Login != oidcUser.Login {
+		t.Fatal("should bind SCM account to OIDC user")
+	}
+
+	// bind OIDC identity to the SCM user
+	scmUser := &User{Login: "oidc_scm", GithubLogin: "oidc_scm"}
+	if err := db.Session().Create(scmUser).Error; err != nil {
+		t.Fatal(err)
+	}
+	other := &core.OIDCUser{Subject: "scm_subject", Login: "ignored"}
+	if _, err := store.BindOIDC(&core.User{Login: scmUser.Login}, oidcUser); err != errUserExist {
+		t.Fatal("should not bind identity of other user")
+	}
+	if _, err := store.BindOIDC(&core.User{Login: scmUser.Login}, other); err != nil {
+		t.Fatal(err)
+	}
+	user, err = store.FindOIDC(other.Subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != scmUser.Login {
+		t.Fatal("should bind OIDC identity to SCM user")
+	}
+	if _, err := store.BindOIDC(user, &core.OIDCUser{Subject: "another_subject"}); err == nil {
+		t.Fatal("should not replace bound identity")
+	}
+}
+
+func TestUserDelete(t *testing.T) {
+	ctrl, db := getDatabaseService(t)
+	defer ctrl.Finish()
+	store := &UserStore{DB: db}
+	session := db.Session()
+
+	users := []*User{
+		{Login: "delete2", GithubLogin: "delete2"},
+		{Login: "delete1", GithubLogin: "delete1"},
+	}
+	for _, user := range users {
+		if err := session.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := &Repo{URL: "http://github.com/delete/repo", SCM: "github", Name: "repo", NameSpace: "delete"}
+	created := &Repo{URL: "http://github.com/delete/created", SCM: "github", Name: "created", NameSpace: "delete", Creator: "delete2"}
+	for _, r := range []*Repo{repo, created} {
+		if err := session.Create(r).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	user := users[1]
+	if err := session.Create(&OAuthToken{Name: "token", Access: "delete1", OwnerID: user.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Create(&RepoPermission{RepoID: repo.ID, UserID: user.ID, Role: "admin"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateRepositories(user.toCoreUser(), []*core.Repo{repo.ToCoreRepo()}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logins []string
+	for _, u := range list {
+		if u.Login == "delete1" || u.Login == "delete2" {
+			logins = append(logins, u.Login)
+		}
+	}
+	if diff := cmp.Diff([]string{"delete1", "delete2"}, logins); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err := store.Delete(&core.User{Login: "delete2"}); !errors.Is(err, errUserIsCreator) {
+		t.Fatal("should not delete creator of repositories")
+	}
+	if err := store.Delete(&core.User{Login: "delete1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.FindByLogin("delete1"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatal("should delete user")
+	}
+	counts := map[string]*gorm.DB{
+		"tokens":       session.Unscoped().Model(&OAuthToken{}).Where("owner_id = ?", user.ID),
+		"permissions":  session.Unscoped().Model(&RepoPermission{}).Where("user_id = ?", user.ID),
+		"repositories": session.Table("user_repositories").Where("user_id = ?", user.ID),
+	}
+	for name, query := range counts {
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Fatalf("should delete %s of the user", name)
+		}
+	}
+}
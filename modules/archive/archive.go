@@ -0,0 +1,288 @@
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	manifestName = "manifest.json"
+	reportsDir   = "reports/"
+	// pageLimit of reports to export at a time, which are fetched with coverage data
+	pageLimit = 10
+)
+
+var errReportIDUsed = errors.New("report ID of the archive is used by another repository")
+
+// manifest of a repository archive. Reports are archived in separated files under reportsDir.
+type manifest struct {
+	Version    int                   `json:"version"`
+	Repo       *core.Repo            `json:"repo"`
+	Setting    *core.RepoSetting     `json:"setting"`
+	References []*reference          `json:"references"`
+	Comments   []*core.ReportComment `json:"comments"`
+}
+
+type reference struct {
+	Name            string     `json:"name"`
+	BranchDeletedAt *time.Time `json:"branchDeletedAt,omitempty"`
+	Commits         []string   `json:"commits"`
+}
+
+// Service exports and imports repositories as zip archives
+type Service struct {
+	repoStore   core.RepoStore
+	reportStore core.ReportStore
+}
+
+// NewService of archive
+func NewService(repoStore core.RepoStore, reportStore core.ReportStore) *Service {
+	return &Service{
+		repoStore:   repoStore,
+		reportStore: reportStore,
+	}
+}
+
+// Export the repository as a zip archive, which is written to w while reports are read
+func (s *Service) Export(ctx context.Context, repo *core.Repo, w io.Writer) error {
+	m, err := s.manifest(repo)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(w)
+	if err := writeJSON(zw, manifestName, m); err != nil {
+		return err
+	}
+	if repo.ReportID != "" {
+		if err := s.exportReports(ctx, zw, repo.ReportID); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (s *Service) manifest(repo *core.Repo) (*manifest, error) {
+	setting, err := s.repoStore.Setting(repo)
+	if err != nil {
+		return nil, err
+	}
+	archived := *repo
+	archived.ID = 0
+	m := &manifest{
+		Version:    core.ArchiveVersion,
+		Repo:       &archived,
+		Setting:    setting,
+		References: []*reference{},
+		Comments:   []*core.ReportComment{},
+	}
+	if repo.ReportID == "" {
+		return m, nil
+	}
+	references, err := s.reportStore.ListReferences(repo.ReportID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range references {
+		commits := make([]string, len(ref.Reports))
+		for i, report := range ref.Reports {
+			commits[i] = report.Commit
+		}
+		m.References = append(m.References, &reference{
+			Name:            ref.Name,
+			BranchDeletedAt: ref.BranchDeletedAt,
+			Commits:         commits,
+		})
+	}
+	if m.Comments, err = s.reportStore.ListComments(repo.ReportID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) exportReports(ctx context.Context, zw *zip.Writer, reportID string) error {
+	page := core.Page{Limit: pageLimit}
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reports, next, err := s.reportStore.Finds(&core.Report{ReportID: reportID}, page)
+		if err != nil {
+			return err
+		}
+		for _, report := range reports {
+			count++
+			if err := writeJSON(zw, fmt.Sprintf("%s%08d.json", reportsDir, count), report); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		page.Cursor = next
+	}
+}
+
+// Import the archive to the repository, or the archived repository if repo is nil.
+// Reports are restored before their references and comments.
+// The import is not atomic, but importing again completes a failed import.
+func (s *Service) Import(ctx context.Context, r io.Reader, repo *core.Repo) (*core.Repo, error) {
+	z, err := openZip(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", core.ErrInvalidArchive, err)
+	}
+	defer z.Close()
+	zr := z.Reader
+	m := &manifest{}
+	if err := readJSON(zr, manifestName, m); err != nil {
+		return nil, err
+	}
+	if m.Version != core.ArchiveVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", core.ErrInvalidArchive, m.Version)
+	}
+	if m.Repo == nil || m.Repo.URL == "" || m.Repo.SCM == "" {
+		return nil, fmt.Errorf("%w: missing repository", core.ErrInvalidArchive)
+	}
+	target, err := s.target(m.Repo, repo)
+	if err != nil {
+		return nil, err
+	}
+	if m.Setting != nil {
+		if err := s.repoStore.UpdateSetting(target, m.Setting); err != nil {
+			return nil, err
+		}
+	}
+	if target.ReportID == "" {
+		return target, nil
+	}
+	if err := s.importReports(ctx, zr, target.ReportID); err != nil {
+		return nil, err
+	}
+	for _, ref := range m.References {
+		reports := make([]*core.Report, len(ref.Commits))
+		for i, commit := range ref.Commits {
+			reports[i] = &core.Report{Commit: commit}
+		}
+		if err := s.reportStore.RestoreReference(target.ReportID, &core.Reference{
+			Name:            ref.Name,
+			BranchDeletedAt: ref.BranchDeletedAt,
+			Reports:         reports,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, comment := range m.Comments {
+		if err := s.reportStore.CreateComment(&core.Report{ReportID: target.ReportID}, comment); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+// target repository to import. The repository keeps its report ID if it is activated,
+// otherwise it is activated with the report ID of the archive.
+func (s *Service) target(archived, repo *core.Repo) (*core.Repo, error) {
+	if repo != nil && (repo.SCM != archived.SCM || repo.FullName() != archived.FullName()) {
+		return nil, fmt.Errorf(
+			"%w: archive of %s/%s is not of the repository",
+			core.ErrInvalidArchive, archived.SCM, archived.FullName(),
+		)
+	}
+	if repo == nil {
+		var err error
+		if repo, err = s.repoStore.Find(&core.Repo{URL: archived.URL}); err != nil {
+			if err := s.repoStore.UpdateOrCreate(&core.Repo{
+				URL:       archived.URL,
+				NameSpace: archived.NameSpace,
+				Name:      archived.Name,
+				SCM:       archived.SCM,
+				Branch:    archived.Branch,
+				Private:   archived.Private,
+			}); err != nil {
+				return nil, err
+			}
+			if repo, err = s.repoStore.Find(&core.Repo{URL: archived.URL}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if repo.ReportID != "" || archived.ReportID == "" {
+		return repo, nil
+	}
+	if used, err := s.repoStore.Find(&core.Repo{ReportID: archived.ReportID}); err == nil && used.URL != repo.URL {
+		return nil, errReportIDUsed
+	}
+	repo.ReportID = archived.ReportID
+	if err := s.repoStore.Update(repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (s *Service) importReports(ctx context.Context, zr *zip.Reader, reportID string) error {
+	var files []*zip.File
+	for _, file := range zr.File {
+		if strings.HasPrefix(file.Name, reportsDir) && !file.FileInfo().IsDir() {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		report := &core.Report{}
+		if err := decodeJSON(file, report); err != nil {
+			return err
+		}
+		if report.Commit == "" {
+			return fmt.Errorf("%w: missing commit of %s", core.ErrInvalidArchive, file.Name)
+		}
+		report.ReportID = reportID
+		report.Reference = ""
+		if err := s.reportStore.Restore(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+func readJSON(zr *zip.Reader, name string, v interface{}) error {
+	for _, file := range zr.File {
+		if file.Name == name {
+			return decodeJSON(file, v)
+		}
+	}
+	return fmt.Errorf("%w: missing %s", core.ErrInvalidArchive, name)
+}
+
+func decodeJSON(file *zip.File, v interface{}) error {
+	r, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", core.ErrInvalidArchive, err)
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", core.ErrInvalidArchive, file.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/archive"
+)
+
+func TestExportImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := &core.Repo{
+		ID:        10,
+		URL:       "https://github.com/org/repo",
+		ReportID:  "report",
+		NameSpace: "org",
+		Name:      "repo",
+		Branch:    "master",
+		SCM:       core.Github,
+	}
+	setting := &core.RepoSetting{Filters: core.FileNameFilters{"^src/"}, Protected: true}
+	deletedAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	report := func(commit string) *core.Report {
+		return &core.Report{
+			ReportID:  repo.ReportID,
+			Commit:    commit,
+			Files:     []string{"a.go"},
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Coverages: []*core.CoverageReport{
+				{Type: core.ReportGo, Files: []*core.File{{Name: "a.go", StatementCoverage: 0.5}}},
+			},
+		}
+	}
+	reports := []*core.Report{report("c3"), report("c2"), report("c1")}
+	references := []*core.Reference{
+		{Name: "master", Reports: []*core.Report{{Commit: "c3"}, {Commit: "c1"}}},
+		{Name: "old", BranchDeletedAt: &deletedAt, Reports: []*core.Report{{Commit: "c2"}}},
+	}
+	comments := []*core.ReportComment{{Number: 1, Comment: 100}}
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).Return(setting, nil)
+	reportStore.EXPECT().ListReferences(gomock.Eq(repo.ReportID)).Return(references, nil)
+	reportStore.EXPECT().ListComments(gomock.Eq(repo.ReportID)).Return(comments, nil)
+	query := &core.Report{ReportID: repo.ReportID}
+	reportStore.EXPECT().Finds(gomock.Eq(query), gomock.Eq(core.Page{Limit: 10})).Return(reports[:2], "next", nil)
+	reportStore.EXPECT().Finds(gomock.Eq(query), gomock.Eq(core.Page{Cursor: "next", Limit: 10})).Return(reports[2:], "", nil)
+
+	service := archive.NewService(repoStore, reportStore)
+	ctx := context.Background()
+	buffer := &bytes.Buffer{}
+	if err := service.Export(ctx, repo, buffer); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	t.Run("import to activated repository", func(t *testing.T) {
+		target := &core.Repo{
+			ID:        1,
+			URL:       repo.URL,
+			ReportID:  "local",
+			NameSpace: repo.NameSpace,
+			Name:      repo.Name,
+			SCM:       repo.SCM,
+		}
+		repoStore.EXPECT().UpdateSetting(gomock.Eq(target), gomock.Eq(setting)).Return(nil)
+		var restored []*core.Report
+		reportStore.EXPECT().Restore(gomock.Any()).Times(3).DoAndReturn(func(r *core.Report) error {
+			restored = append(restored, r)
+			return nil
+		})
+		reportStore.EXPECT().RestoreReference(gomock.Eq("local"), gomock.Eq(&core.Reference{
+			Name:    "master",
+			Reports: []*core.Report{{Commit: "c3"}, {Commit: "c1"}},
+		})).Return(nil)
+		reportStore.EXPECT().RestoreReference(gomock.Eq("local"), gomock.Eq(&core.Reference{
+			Name:            "old",
+			BranchDeletedAt: &deletedAt,
+			Reports:         []*core.Report{{Commit: "c2"}},
+		})).Return(nil)
+		reportStore.EXPECT().CreateComment(
+			gomock.Eq(&core.Report{ReportID: "local"}), gomock.Eq(comments[0]),
+		).Return(nil)
+
+		result, err := service.Import(ctx, bytes.NewReader(data), target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ReportID != "local" {
+			t.Fatal("should keep report ID of the activated repository")
+		}
+		for _, r := range reports {
+			r.ReportID = "local"
+		}
+		if diff := cmp.Diff(reports, restored); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("import archived repository", func(t *testing.T) {
+		created := &core.Repo{ID: 2, URL: repo.URL, NameSpace: repo.NameSpace, Name: repo.Name, SCM: repo.SCM}
+		gomock.InOrder(
+			repoStore.EXPECT().Find(gomock.Eq(&core.Repo{URL: repo.URL})).Return(nil, errors.New("not found")),
+			repoStore.EXPECT().UpdateOrCreate(gomock.Eq(&core.Repo{
+				URL:       repo.URL,
+				NameSpace: repo.NameSpace,
+				Name:      repo.Name,
+				Branch:    repo.Branch,
+				SCM:       repo.SCM,
+			})).Return(nil),
+			repoStore.EXPECT().Find(gomock.Eq(&core.Repo{URL: repo.URL})).Return(created, nil),
+		)
+		repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: repo.ReportID})).Return(nil, errors.New("not found"))
+		repoStore.EXPECT().Update(gomock.Any()).DoAndReturn(func(r *core.Repo) error {
+			if r.ReportID != repo.ReportID {
+				t.Fatal("should activate with report ID of the archive")
+			}
+			return nil
+		})
+		repoStore.EXPECT().UpdateSetting(gomock.Any(), gomock.Eq(setting)).Return(nil)
+		reportStore.EXPECT().Restore(gomock.Any()).Times(3).Return(nil)
+		reportStore.EXPECT().RestoreReference(gomock.Eq(repo.ReportID), gomock.Any()).Times(2).Return(nil)
+		reportStore.EXPECT().CreateComment(gomock.Any(), gomock.Any()).Return(nil)
+
+		result, err := service.Import(ctx, bytes.NewReader(data), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ID != created.ID || result.ReportID != repo.ReportID {
+			t.Fatal("should import to the created repository")
+		}
+	})
+
+	t.Run("import to other repository", func(t *testing.T) {
+		other := &core.Repo{URL: "https://github.com/org/other", NameSpace: "org", Name: "other", SCM: core.Github}
+		if _, err := service.Import(ctx, bytes.NewReader(data), other); !errors.Is(err, core.ErrInvalidArchive) {
+			t.Fatal("should not import to other repository")
+		}
+	})
+}
+
+func TestImportInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	service := archive.NewService(mock.NewMockRepoStore(ctrl), mock.NewMockReportStore(ctrl))
+
+	archiveOf := func(files map[string]string) []byte {
+		buffer := &bytes.Buffer{}
+		w := zip.NewWriter(buffer)
+		for name, content := range files {
+			f, _ := w.Create(name)
+			_, _ = f.Write([]byte(content))
+		}
+		_ = w.Close()
+		return buffer.Bytes()
+	}
+	tests := map[string][]byte{
+		"not zip":          []byte("not zip"),
+		"missing manifest": archiveOf(map[string]string{}),
+		"bad manifest":     archiveOf(map[string]string{"manifest.json": "{"}),
+		"unknown version":  archiveOf(map[string]string{"manifest.json": `{"version":99}`}),
+		"missing repo":     archiveOf(map[string]string{"manifest.json": `{"version":1}`}),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.Import(context.Background(), bytes.NewReader(data), nil)
+			if !errors.Is(err, core.ErrInvalidArchive) {
+				t.Fatalf("should be invalid archive, got %v", err)
+			}
+		})
+	}
+}
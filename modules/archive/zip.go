@@ -4,6 +4,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"io"
+	"io/ioutil"
+	"os"
 )
 
 // NewZipReader from io.Reader
@@ -16,3 +18,53 @@ func NewZipReader(r io.Reader) (*zip.Reader, error) {
 	reader := bytes.NewReader(buffer.Bytes())
 	return zip.NewReader(reader, size)
 }
+
+// zipFile is a zip archive read without keeping it in memory
+type zipFile struct {
+	*zip.Reader
+	// spool is the temporary file of the archive, nil if it is read in place
+	spool *os.File
+}
+
+// openZip reads readers with random access, such as files, in place,
+// and spools others to a temporary file, which is removed on Close
+func openZip(r io.Reader) (*zipFile, error) {
+	if file, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		size, err := file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		zr, err := zip.NewReader(file, size)
+		if err != nil {
+			return nil, err
+		}
+		return &zipFile{Reader: zr}, nil
+	}
+	spool, err := ioutil.TempFile("", "archive-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	z := &zipFile{spool: spool}
+	size, err := io.Copy(spool, r)
+	if err != nil {
+		z.Close()
+		return nil, err
+	}
+	if z.Reader, err = zip.NewReader(spool, size); err != nil {
+		z.Close()
+		return nil, err
+	}
+	return z, nil
+}
+
+// Close and remove the temporary file of the archive
+func (z *zipFile) Close() error {
+	if z.spool == nil {
+		return nil
+	}
+	z.spool.Close()
+	return os.Remove(z.spool.Name())
+}
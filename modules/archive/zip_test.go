@@ -0,0 +1,46 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestOpenZip(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	zw := zip.NewWriter(buffer)
+	w, _ := zw.Create("manifest.json")
+	_, _ = w.Write([]byte("{}"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	for name, r := range map[string]io.Reader{
+		"in place": bytes.NewReader(data),
+		"spooled":  struct{ io.Reader }{bytes.NewReader(data)},
+	} {
+		z, err := openZip(r)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if len(z.File) != 1 || z.File[0].Name != "manifest.json" {
+			t.Fatal(name, "should read files of the archive")
+		}
+		spool := z.spool
+		if err := z.Close(); err != nil {
+			t.Fatal(name, err)
+		}
+		if spool != nil {
+			if _, err := os.Stat(spool.Name()); !os.IsNotExist(err) {
+				t.Fatal("should remove the spooled archive")
+			}
+		}
+	}
+
+	if _, err := openZip(struct{ io.Reader }{bytes.NewReader([]byte("archive"))}); err == nil {
+		t.Fatal("should fail on invalid archive")
+	}
+}
@@ -0,0 +1,41 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+)
+
+var keyPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// NewStore of the configured driver.
+// It returns nil for the database driver, which keeps data in the database.
+func NewStore(config *config.Config) (core.BlobStore, error) {
+	switch config.Blob.Driver {
+	case "database":
+		return nil, nil
+	case "filesystem":
+		return NewFileSystemStore(config.Blob.Path)
+	case "s3":
+		return NewS3Store(config)
+	default:
+		return nil, fmt.Errorf("blob driver %q not support", config.Blob.Driver)
+	}
+}
+
+// Key of the data, which is the SHA-256 hex digest
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func checkKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	return nil
+}
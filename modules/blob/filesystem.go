@@ -0,0 +1,79 @@
+package blob
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/covergates/covergates/core"
+)
+
+// FileSystemStore keeps blobs in the local directory,
+// which are sharded by the first two characters of their keys
+type FileSystemStore struct {
+	root string
+}
+
+// NewFileSystemStore in the root directory, which is created if not exist
+func NewFileSystemStore(root string) (*FileSystemStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &FileSystemStore{root: root}, nil
+}
+
+// Put data if not exist
+func (s *FileSystemStore) Put(data []byte) (string, error) {
+	key := Key(data)
+	path := s.path(key)
+	if _, err := os.Stat(path); err == nil {
+		return key, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	// write to a temporary file first, so that a partial blob is never visible
+	file, err := ioutil.TempFile(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Get data of the key
+func (s *FileSystemStore) Get(key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, core.ErrBlobNotFound
+	}
+	return data, err
+}
+
+// Delete data of the key
+func (s *FileSystemStore) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileSystemStore) path(key string) string {
+	return filepath.Join(s.root, key[:2], key)
+}
@@ -0,0 +1,213 @@
+package blob
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+)
+
+// S3Store keeps blobs in an S3 compatible bucket with path-style requests,
+// which are signed with AWS Signature Version 4
+type S3Store struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Store of the configured bucket
+func NewS3Store(config *config.Config) (*S3Store, error) {
+	conf := config.Blob
+	if conf.S3Bucket == "" {
+		return nil, fmt.Errorf("require S3 bucket")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(conf.S3Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{
+		endpoint:  endpoint,
+		region:    conf.S3Region,
+		bucket:    conf.S3Bucket,
+		prefix:    conf.S3Prefix,
+		accessKey: conf.S3AccessKey,
+		secretKey: conf.S3SecretKey,
+		client:    &http.Client{Timeout: time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+// Put data if not exist
+func (s *S3Store) Put(data []byte) (string, error) {
+	key := Key(data)
+	res, err := s.do("HEAD", key, nil)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	if res.StatusCode == 200 {
+		return key, nil
+	}
+	res, err = s.do("PUT", key, data)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return "", s.error(res)
+	}
+	return key, nil
+}
+
+// Get data of the key
+func (s *S3Store) Get(key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	res, err := s.do("GET", key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil, core.ErrBlobNotFound
+	}
+	if res.StatusCode != 200 {
+		return nil, s.error(res)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// Delete data of the key
+func (s *S3Store) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	res, err := s.do("DELETE", key, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 204 && res.StatusCode != 200 && res.StatusCode != 404 {
+		return s.error(res)
+	}
+	return nil
+}
+
+func (s *S3Store) do(method, key string, data []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.Join([]string{u.Path, s.bucket, s.prefix + key}, "/")
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	sign(req, data, s.accessKey, s.secretKey, s.region, s.now())
+	return s.client.Do(req)
+}
+
+func (s *S3Store) error(res *http.Response) error {
+	body, _ := ioutil.ReadAll(res.Body)
+	return fmt.Errorf("%s %s: %s %s", res.Request.Method, res.Request.URL, res.Status, body)
+}
+
+// sign the request with AWS Signature Version 4, all headers of the request are signed
+func sign(req *http.Request, payload []byte, accessKey, secretKey, region string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{date, region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature,
+	))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+)
+
+// NewCache of the configured driver. It returns nil for the none driver, which disables caching.
+func NewCache(config *config.Config) (core.Cache, error) {
+	switch config.Cache.Driver {
+	case "none":
+		return nil, nil
+	case "memory":
+		return NewMemory(config.Cache.Size), nil
+	case "redis":
+		return NewRedis(config.Cache.RedisAddr, config.Cache.RedisPassword, config.Cache.RedisDB), nil
+	default:
+		return nil, fmt.Errorf("cache driver %q not support", config.Cache.Driver)
+	}
+}
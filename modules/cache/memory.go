@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/covergates/covergates/core"
+)
+
+// Memory is a least recently used cache of the server instance
+type Memory struct {
+	mu      sync.Mutex
+	size    int
+	entries *list.List
+	keys    map[string]*list.Element
+	now     func() time.Time
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemory cache keeping size entries at most
+func NewMemory(size int) *Memory {
+	if size <= 0 {
+		size = 1
+	}
+	return &Memory{
+		size:    size,
+		entries: list.New(),
+		keys:    make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Get value of the key
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.keys[key]
+	if !ok {
+		return nil, core.ErrCacheMiss
+	}
+	e := element.Value.(*entry)
+	if !m.now().Before(e.expires) {
+		m.remove(element)
+		return nil, core.ErrCacheMiss
+	}
+	m.entries.MoveToFront(element)
+	return e.value, nil
+}
+
+// Set value of the key, the least recently used entry is evicted if the cache is full
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expires := m.now().Add(ttl)
+	if element, ok := m.keys[key]; ok {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		m.entries.MoveToFront(element)
+		return nil
+	}
+	m.keys[key] = m.entries.PushFront(&entry{key: key, value: value, expires: expires})
+	for m.entries.Len() > m.size {
+		m.remove(m.entries.Back())
+	}
+	return nil
+}
+
+// Delete the key
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.keys[key]; ok {
+		m.remove(element)
+	}
+	return nil
+}
+
+func (m *Memory) remove(element *list.Element) {
+	m.entries.Remove(element)
+	delete(m.keys, element.Value.(*entry).key)
+}
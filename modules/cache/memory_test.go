@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/covergates/covergates/core"
+)
+
+func TestMemory(t *testing.T) {
+	now := time.Now()
+	m := NewMemory(2)
+	m.now = func() time.Time { return now }
+
+	if _, err := m.Get("a"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal(err)
+	}
+	_ = m.Set("a", []byte("1"), time.Minute)
+	_ = m.Set("b", []byte("2"), time.Minute)
+	if v, err := m.Get("a"); err != nil || string(v) != "1" {
+		t.Fatal(v, err)
+	}
+	// b is the least recently used
+	_ = m.Set("c", []byte("3"), time.Minute)
+	if _, err := m.Get("b"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal("b should be evicted")
+	}
+	if v, err := m.Get("c"); err != nil || string(v) != "3" {
+		t.Fatal(v, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := m.Get("a"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal("a should be expired")
+	}
+	_ = m.Set("c", []byte("4"), time.Minute)
+	if v, err := m.Get("c"); err != nil || string(v) != "4" {
+		t.Fatal(v, err)
+	}
+	_ = m.Delete("c")
+	if _, err := m.Get("c"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal("c should be deleted")
+	}
+	if m.entries.Len() != 0 || len(m.keys) != 0 {
+		t.Fatal("entries should be removed")
+	}
+}
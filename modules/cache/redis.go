@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	redisTimeout = 3 * time.Second
+	// redisRetries of a command on network errors, with backoff between retries
+	redisRetries = 2
+	// redisPoolSize is the most connections to keep
+	redisPoolSize = 8
+)
+
+// Redis cache shared by server instances
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis cache of the server address. Connections are pooled,
+// and broken ones are dialed again by the following commands.
+func NewRedis(addr, password string, db int) *Redis {
+	return &Redis{
+		client: redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     password,
+			DB:           db,
+			MaxRetries:   redisRetries,
+			DialTimeout:  redisTimeout,
+			ReadTimeout:  redisTimeout,
+			WriteTimeout: redisTimeout,
+			PoolSize:     redisPoolSize,
+		}),
+	}
+}
+
+// Get value of the key
+func (r *Redis) Get(key string) ([]byte, error) {
+	value, err := r.client.Get(key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, core.ErrCacheMiss
+	}
+	return value, err
+}
+
+// Set value of the key with expiration, which is at least a millisecond
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl < time.Millisecond {
+		// zero expiration keeps the key forever
+		ttl = time.Millisecond
+	}
+	return r.client.Set(key, value, ttl).Err()
+}
+
+// Delete the key
+func (r *Redis) Delete(key string) error {
+	return r.client.Del(key).Err()
+}
+
+// Close connections to the server
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/covergates/covergates/core"
+)
+
+// fakeRedis serves GET, SET, DEL, AUTH and SELECT commands
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string]string
+	commands []string
+	conns    []net.Conn
+	// fail commands with an error reply
+	fail bool
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	f.mu.Lock()
+	f.conns = append(f.conns, conn)
+	f.mu.Unlock()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		if f.fail {
+			fmt.Fprint(conn, "-ERR failure\r\n")
+			f.mu.Unlock()
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if v, ok := f.values[args[1]]; ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			f.values[args[1]] = args[2]
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			_, ok := f.values[args[1]]
+			delete(f.values, args[1])
+			if ok {
+				fmt.Fprint(conn, ":1\r\n")
+			} else {
+				fmt.Fprint(conn, ":0\r\n")
+			}
+		case "AUTH":
+			if args[1] == "secret" {
+				fmt.Fprint(conn, "+OK\r\n")
+			} else {
+				fmt.Fprint(conn, "-ERR invalid password\r\n")
+			}
+		default:
+			fmt.Fprint(conn, "+OK\r\n")
+		}
+		f.mu.Unlock()
+	}
+}
+
+// drop connections of the clients
+func (f *fakeRedis) drop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeRedis) history() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return strings.Join(f.commands, "\n")
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	server := &fakeRedis{values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	r := NewRedis(listener.Addr().String(), "secret", 2)
+	defer r.Close()
+	if _, err := r.Get("key"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal(err)
+	}
+	if err := r.Set("key", []byte("value\r\n"), 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := r.Get("key"); err != nil || string(v) != "value\r\n" {
+		t.Fatal(v, err)
+	}
+	if err := r.Delete("key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("key"); !errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"auth secret",
+		"select 2",
+		"get key",
+		"set key value\r\n px 1500",
+		"get key",
+		"del key",
+		"get key",
+	}, "\n")
+	if commands := server.history(); commands != expected {
+		t.Fatalf("connection should be reused, got commands:\n%s", commands)
+	}
+
+	// dial again after connections are dropped
+	server.drop()
+	if err := r.Set("key", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := r.Get("key"); err != nil || string(v) != "value" {
+		t.Fatal(v, err)
+	}
+
+	server.mu.Lock()
+	server.fail = true
+	server.mu.Unlock()
+	if _, err := r.Get("key"); err == nil || errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal("should return error reply")
+	}
+	server.mu.Lock()
+	server.fail = false
+	server.mu.Unlock()
+
+	wrong := NewRedis(listener.Addr().String(), "wrong", 0)
+	defer wrong.Close()
+	if _, err := wrong.Get("key"); err == nil || errors.Is(err, core.ErrCacheMiss) {
+		t.Fatal("should fail to authenticate")
+	}
+}
@@ -0,0 +1,307 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/xid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/covergates/covergates/core"
+)
+
+// Metrics counts hits and misses of cached stores
+type Metrics struct {
+	mu       sync.Mutex
+	counters []*counter
+}
+
+type counter struct {
+	name   string
+	hits   uint64
+	misses uint64
+}
+
+// NewMetrics of cached stores
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) counter(name string) *counter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.counters {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &counter{name: name}
+	m.counters = append(m.counters, c)
+	return c
+}
+
+// Stats of cached stores
+func (m *Metrics) Stats() []*core.CacheStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]*core.CacheStats, len(m.counters))
+	for i, c := range m.counters {
+		stats[i] = &core.CacheStats{
+			Name:   c.name,
+			Hits:   atomic.LoadUint64(&c.hits),
+			Misses: atomic.LoadUint64(&c.misses),
+		}
+	}
+	return stats
+}
+
+// lookup values in the cache with generations.
+// Values are cached under the current generation of their group,
+// so deleting the generation invalidates all values of the group.
+type lookup struct {
+	cache   core.Cache
+	ttl     time.Duration
+	counter *counter
+}
+
+func (l *lookup) generation(group string) string {
+	key := "gen:" + group
+	if gen, err := l.cache.Get(key); err == nil {
+		return string(gen)
+	}
+	gen := xid.New().String()
+	if err := l.cache.Set(key, []byte(gen), l.ttl); err != nil {
+		log.Warningf("fail to set cache generation: %v", err)
+	}
+	return gen
+}
+
+// get the value of the group into v, or load and cache it if not found
+func (l *lookup) get(group, key string, v interface{}, load func() (interface{}, error)) error {
+	key = fmt.Sprintf("%s:%s:%s", group, l.generation(group), key)
+	data, err := l.cache.Get(key)
+	if err == nil && json.Unmarshal(data, v) == nil {
+		atomic.AddUint64(&l.counter.hits, 1)
+		return nil
+	}
+	if err != nil && !errors.Is(err, core.ErrCacheMiss) {
+		log.Warningf("fail to get cache: %v", err)
+	}
+	atomic.AddUint64(&l.counter.misses, 1)
+	value, err := load()
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(value); err != nil {
+		return err
+	}
+	if err := l.cache.Set(key, data, l.ttl); err != nil {
+		log.Warningf("fail to set cache: %v", err)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (l *lookup) invalidate(group string) {
+	if err := l.cache.Delete("gen:" + group); err != nil {
+		log.Warningf("fail to invalidate cache %s: %v", group, err)
+	}
+}
+
+// ReportStore caches reports found by report ID.
+// Cached reports of a report ID are invalidated after it is changed.
+type ReportStore struct {
+	core.ReportStore
+	lookup *lookup
+	// invalidate the report ID, which is deferred to the end of transactions
+	invalidate func(reportID string)
+}
+
+// NewReportStore decorates the store with the cache
+func NewReportStore(store core.ReportStore, cache core.Cache, ttl time.Duration, metrics *Metrics) *ReportStore {
+	l := &lookup{cache: cache, ttl: ttl, counter: metrics.counter("report")}
+	return &ReportStore{
+		ReportStore: store,
+		lookup:      l,
+		invalidate: func(reportID string) {
+			l.invalidate(reportGroup(reportID))
+		},
+	}
+}
+
+func reportGroup(reportID string) string {
+	return "report:" + reportID
+}
+
+// Find the report from the cache, which is loaded from the store if not cached
+func (s *ReportStore) Find(r *core.Report) (*core.Report, error) {
+	if s.lookup == nil || r.ReportID == "" {
+		return s.ReportStore.Find(r)
+	}
+	report := &core.Report{}
+	key := fmt.Sprintf("%q:%q", r.Commit, r.Reference)
+	err := s.lookup.get(reportGroup(r.ReportID), key, report, func() (interface{}, error) {
+		return s.ReportStore.Find(r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FindSummary of the report from the cache, which is loaded from the store if not cached
+func (s *ReportStore) FindSummary(r *core.Report) (*core.Report, error) {
+	if s.lookup == nil || r.ReportID == "" {
+		return s.ReportStore.FindSummary(r)
+	}
+	report := &core.Report{}
+	key := fmt.Sprintf("summary:%q:%q", r.Commit, r.Reference)
+	err := s.lookup.get(reportGroup(r.ReportID), key, report, func() (interface{}, error) {
+		return s.ReportStore.FindSummary(r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Upload the report and invalidate its report ID
+func (s *ReportStore) Upload(r *core.Report) error {
+	defer s.invalidate(r.ReportID)
+	return s.ReportStore.Upload(r)
+}
+
+// Transaction runs fn with the store bound to a database transaction.
+// Changed report IDs are invalidated after the transaction, and reads in it are not cached.
+func (s *ReportStore) Transaction(fn func(store core.ReportStore) error) error {
+	var changed []string
+	defer func() {
+		for _, reportID := range changed {
+			s.invalidate(reportID)
+		}
+	}()
+	return s.ReportStore.Transaction(func(store core.ReportStore) error {
+		return fn(&ReportStore{
+			ReportStore: store,
+			invalidate: func(reportID string) {
+				changed = append(changed, reportID)
+			},
+		})
+	})
+}
+
+// UpdateBranchDeleted and invalidate the report ID
+func (s *ReportStore) UpdateBranchDeleted(reportID, name string, deletedAt *time.Time) error {
+	defer s.invalidate(reportID)
+	return s.ReportStore.UpdateBranchDeleted(reportID, name, deletedAt)
+}
+
+// Delete reports and invalidate the report ID
+func (s *ReportStore) Delete(reportID string, commits ...string) error {
+	defer s.invalidate(reportID)
+	return s.ReportStore.Delete(reportID, commits...)
+}
+
+// Restore the report and invalidate its report ID
+func (s *ReportStore) Restore(r *core.Report) error {
+	defer s.invalidate(r.ReportID)
+	return s.ReportStore.Restore(r)
+}
+
+// RestoreReference and invalidate the report ID
+func (s *ReportStore) RestoreReference(reportID string, ref *core.Reference) error {
+	defer s.invalidate(reportID)
+	return s.ReportStore.RestoreReference(reportID, ref)
+}
+
+const repoGroup = "repo"
+
+// RepoStore caches repositories and their settings.
+// Cached repositories are invalidated after any repository is changed,
+// and the setting is invalidated after it is updated.
+type RepoStore struct {
+	core.RepoStore
+	repos    *lookup
+	settings *lookup
+}
+
+// NewRepoStore decorates the store with the cache
+func NewRepoStore(store core.RepoStore, cache core.Cache, ttl time.Duration, metrics *Metrics) *RepoStore {
+	return &RepoStore{
+		RepoStore: store,
+		repos:     &lookup{cache: cache, ttl: ttl, counter: metrics.counter("repo")},
+		settings:  &lookup{cache: cache, ttl: ttl, counter: metrics.counter("setting")},
+	}
+}
+
+func settingGroup(repo *core.Repo) string {
+	return fmt.Sprintf("setting:%d", repo.ID)
+}
+
+// Find the repository from the cache, which is loaded from the store if not cached
+func (s *RepoStore) Find(repo *core.Repo) (*core.Repo, error) {
+	key, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+	result := &core.Repo{}
+	if err := s.repos.get(repoGroup, string(key), result, func() (interface{}, error) {
+		return s.RepoStore.Find(repo)
+	}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Setting of the repository from the cache, which is loaded from the store if not cached
+func (s *RepoStore) Setting(repo *core.Repo) (*core.RepoSetting, error) {
+	if repo.ID == 0 {
+		return s.RepoStore.Setting(repo)
+	}
+	setting := &core.RepoSetting{}
+	if err := s.settings.get(settingGroup(repo), "", setting, func() (interface{}, error) {
+		return s.RepoStore.Setting(repo)
+	}); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// Create the repository and invalidate cached repositories
+func (s *RepoStore) Create(repo *core.Repo) error {
+	defer s.repos.invalidate(repoGroup)
+	return s.RepoStore.Create(repo)
+}
+
+// Update the repository and invalidate cached repositories
+func (s *RepoStore) Update(repo *core.Repo) error {
+	defer s.repos.invalidate(repoGroup)
+	return s.RepoStore.Update(repo)
+}
+
+// UpdateOrCreate the repository and invalidate cached repositories
+func (s *RepoStore) UpdateOrCreate(repo *core.Repo) error {
+	defer s.repos.invalidate(repoGroup)
+	return s.RepoStore.UpdateOrCreate(repo)
+}
+
+// BatchUpdateOrCreate repositories and invalidate cached repositories
+func (s *RepoStore) BatchUpdateOrCreate(repos []*core.Repo) error {
+	defer s.repos.invalidate(repoGroup)
+	return s.RepoStore.BatchUpdateOrCreate(repos)
+}
+
+// UpdateCreator of the repository and invalidate cached repositories
+func (s *RepoStore) UpdateCreator(repo *core.Repo, user *core.User) error {
+	defer s.repos.invalidate(repoGroup)
+	return s.RepoStore.UpdateCreator(repo, user)
+}
+
+// UpdateSetting of the repository and invalidate the cached setting
+func (s *RepoStore) UpdateSetting(repo *core.Repo, setting *core.RepoSetting) error {
+	defer s.settings.invalidate(settingGroup(repo))
+	return s.RepoStore.UpdateSetting(repo, setting)
+}
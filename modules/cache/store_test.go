@@ -0,0 +1,152 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/cache"
+)
+
+func findStats(metrics *cache.Metrics, name string) *core.CacheStats {
+	for _, stats := range metrics.Stats() {
+		if stats.Name == name {
+			return stats
+		}
+	}
+	return &core.CacheStats{}
+}
+
+func TestReportStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock.NewMockReportStore(ctrl)
+	metrics := cache.NewMetrics()
+	store := cache.NewReportStore(mockStore, cache.NewMemory(100), time.Minute, metrics)
+
+	seed := &core.Report{ReportID: "report", Commit: "sha"}
+	report := &core.Report{ReportID: "report", Commit: "sha", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, StatementCoverage: 0.5},
+	}}
+	mockStore.EXPECT().Find(gomock.Eq(seed)).Return(report, nil).Times(2)
+	for i := 0; i < 3; i++ {
+		result, err := store.Find(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Commit != "sha" || len(result.Coverages) != 1 || result.Coverages[0].StatementCoverage != 0.5 {
+			t.Fatalf("unexpected report %v", result)
+		}
+	}
+
+	mockStore.EXPECT().Upload(gomock.Any()).Return(nil)
+	if err := store.Upload(&core.Report{ReportID: "report", Commit: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Find(seed); err != nil {
+		t.Fatal(err)
+	}
+	if stats := findStats(metrics, "report"); stats.Hits != 2 || stats.Misses != 2 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+
+	// summaries are cached apart from reports with files
+	mockStore.EXPECT().FindSummary(gomock.Eq(seed)).Return(report, nil)
+	for i := 0; i < 2; i++ {
+		if _, err := store.FindSummary(seed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mockStore.EXPECT().Find(gomock.Eq(seed)).Return(nil, errors.New("not found")).Times(2)
+	other := &core.Report{ReportID: "report", Commit: "sha", Reference: "master"}
+	mockStore.EXPECT().Find(gomock.Eq(other)).Return(report, nil)
+	mockStore.EXPECT().Transaction(gomock.Any()).DoAndReturn(
+		func(fn func(core.ReportStore) error) error {
+			return fn(mockStore)
+		},
+	)
+	mockStore.EXPECT().Delete("report", "sha").Return(nil)
+	if _, err := store.Find(other); err != nil {
+		t.Fatal(err)
+	}
+	err := store.Transaction(func(tx core.ReportStore) error {
+		return tx.Delete("report", "sha")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// errors are not cached
+	for i := 0; i < 2; i++ {
+		if _, err := store.Find(seed); err == nil {
+			t.Fatal("should return error")
+		}
+	}
+}
+
+func TestRepoStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock.NewMockRepoStore(ctrl)
+	metrics := cache.NewMetrics()
+	store := cache.NewRepoStore(mockStore, cache.NewMemory(100), time.Minute, metrics)
+
+	seed := &core.Repo{ReportID: "report"}
+	repo := &core.Repo{ID: 1, Name: "repo", ReportID: "report"}
+	setting := &core.RepoSetting{Filters: core.FileNameFilters{"vendor"}}
+	mockStore.EXPECT().Find(gomock.Eq(seed)).Return(repo, nil).Times(3)
+	mockStore.EXPECT().Setting(gomock.Eq(repo)).Return(setting, nil).Times(2)
+	find := func() {
+		result, err := store.Find(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ID != 1 || result.Name != "repo" {
+			t.Fatalf("unexpected repo %v", result)
+		}
+		s, err := store.Setting(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Filters) != 1 || s.Filters[0] != "vendor" {
+			t.Fatalf("unexpected setting %v", s)
+		}
+	}
+	find()
+	find()
+
+	mockStore.EXPECT().Update(gomock.Eq(repo)).Return(nil)
+	if err := store.Update(repo); err != nil {
+		t.Fatal(err)
+	}
+	find()
+
+	mockStore.EXPECT().UpdateSetting(gomock.Eq(repo), gomock.Eq(setting)).Return(nil)
+	if err := store.UpdateSetting(repo, setting); err != nil {
+		t.Fatal(err)
+	}
+	find()
+
+	// the creator is a field of the repository
+	user := &core.User{Login: "creator"}
+	mockStore.EXPECT().UpdateCreator(gomock.Eq(repo), gomock.Eq(user)).Return(nil)
+	if err := store.UpdateCreator(repo, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Find(seed); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := findStats(metrics, "repo"); stats.Hits != 2 || stats.Misses != 3 {
+		t.Fatalf("unexpected repo stats %v", stats)
+	}
+	if stats := findStats(metrics, "setting"); stats.Hits != 2 || stats.Misses != 2 {
+		t.Fatalf("unexpected setting stats %v", stats)
+	}
+}
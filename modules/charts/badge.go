@@ -0,0 +1,158 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+
+	svg "github.com/ajstarks/svgo"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	badgeFont       = "Verdana,Geneva,DejaVu Sans,sans-serif"
+	badgeLabelColor = "#555"
+)
+
+// verdanaWidths of characters in 11px Verdana, which differ from their class widths
+var verdanaWidths = map[rune]float64{
+	' ': 3.9, '%': 11.9, '.': 3.9, ',': 3.9, ':': 4.6, '-': 4.9, '_': 7.0, '/': 4.9,
+	'f': 3.9, 'i': 3.0, 'j': 3.8, 'l': 3.0, 'm': 10.7, 'r': 4.7, 's': 5.7, 't': 4.3, 'w': 9.0,
+	'I': 4.6, 'J': 5.0, 'M': 8.6, 'W': 10.8,
+}
+
+// textWidth estimates width of the text in Verdana of the font size
+func textWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		w, ok := verdanaWidths[r]
+		switch {
+		case ok:
+		case unicode.IsDigit(r):
+			w = 7.0
+		case unicode.IsUpper(r):
+			w = 7.5
+		case unicode.IsLower(r):
+			w = 6.6
+		default:
+			w = 8.0
+		}
+		width += w
+	}
+	return width * size / 11
+}
+
+// Badge renders svg badges natively in flat, flat-square and for-the-badge styles
+type Badge struct {
+	badge *core.Badge
+}
+
+// NewBadge render
+func NewBadge(badge *core.Badge) *Badge {
+	return &Badge{badge: badge}
+}
+
+// badgeLayout of a style
+type badgeLayout struct {
+	height   int
+	fontSize float64
+	padding  float64
+	// spacing between letters
+	spacing float64
+	radius  int
+	shadow  bool
+	upper   bool
+}
+
+func layoutOf(style core.BadgeStyle) *badgeLayout {
+	switch style {
+	case core.BadgeFlatSquare:
+		return &badgeLayout{height: 20, fontSize: 11, padding: 6}
+	case core.BadgeForTheBadge:
+		return &badgeLayout{height: 28, fontSize: 10, padding: 12, spacing: 1.2, upper: true}
+	default:
+		return &badgeLayout{height: 20, fontSize: 11, padding: 6, radius: 3, shadow: true}
+	}
+}
+
+func (l *badgeLayout) width(text string) int {
+	size := float64(len([]rune(text)))
+	return int(math.Ceil(textWidth(text, l.fontSize) + size*l.spacing + 2*l.padding))
+}
+
+// Render the badge
+func (b *Badge) Render(w io.Writer) error {
+	layout := layoutOf(b.badge.Style)
+	label, message := b.badge.Label, b.badge.Message
+	if layout.upper {
+		label, message = strings.ToUpper(label), strings.ToUpper(message)
+	}
+	labelWidth := 0
+	if label != "" {
+		labelWidth = layout.width(label)
+	}
+	messageWidth := layout.width(message)
+	width, height := labelWidth+messageWidth, layout.height
+	title := message
+	if label != "" {
+		title = label + ": " + message
+	}
+
+	canvas := svg.New(w)
+	canvas.Start(width, height, `role="img"`, fmt.Sprintf(`aria-label="%s"`, escapeAttr(title)))
+	canvas.Title(title)
+	if layout.shadow {
+		canvas.LinearGradient("s", 0, 0, 0, 100, []svg.Offcolor{
+			{Offset: 0, Color: "#bbb", Opacity: 0.1},
+			{Offset: 100, Color: "#000", Opacity: 0.1},
+		})
+	}
+	canvas.ClipPath(`id="r"`)
+	canvas.Roundrect(0, 0, width, height, layout.radius, layout.radius, `fill="#fff"`)
+	canvas.ClipEnd()
+	canvas.Group(`clip-path="url(#r)"`)
+	if labelWidth > 0 {
+		canvas.Rect(0, 0, labelWidth, height, fmt.Sprintf(`fill="%s"`, badgeLabelColor))
+	}
+	canvas.Rect(labelWidth, 0, messageWidth, height, fmt.Sprintf(`fill="%s"`, escapeAttr(b.badge.Color)))
+	if layout.shadow {
+		canvas.Rect(0, 0, width, height, `fill="url(#s)"`)
+	}
+	canvas.Gend()
+
+	style := []string{
+		`fill="#fff"`,
+		`text-anchor="middle"`,
+		fmt.Sprintf(`font-family="%s"`, badgeFont),
+		fmt.Sprintf(`font-size="%g"`, layout.fontSize),
+	}
+	if layout.spacing > 0 {
+		style = append(style, fmt.Sprintf(`letter-spacing="%g"`, layout.spacing))
+	}
+	canvas.Group(style...)
+	baseline := height/2 + int(layout.fontSize*0.35)
+	text := func(x int, s string, bold bool) {
+		var weight []string
+		if bold {
+			weight = append(weight, `font-weight="bold"`)
+		}
+		if layout.shadow {
+			canvas.Text(x, baseline+1, s, append([]string{`fill="#010101"`, `fill-opacity=".3"`}, weight...)...)
+		}
+		canvas.Text(x, baseline, s, weight...)
+	}
+	if labelWidth > 0 {
+		text(labelWidth/2, label, false)
+	}
+	text(labelWidth+messageWidth/2, message, layout.upper)
+	canvas.Gend()
+	canvas.End()
+	return nil
+}
+
+func escapeAttr(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;").Replace(s)
+}
@@ -0,0 +1,93 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/covergates/covergates/core"
+)
+
+func validXML(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func TestBadge(t *testing.T) {
+	tests := []struct {
+		style    core.BadgeStyle
+		height   string
+		contains []string
+	}{
+		{
+			style:    core.BadgeFlat,
+			height:   `height="20"`,
+			contains: []string{`rx="3"`, `url(#s)`, ">coverage</text>", ">85%</text>"},
+		},
+		{
+			style:    core.BadgeFlatSquare,
+			height:   `height="20"`,
+			contains: []string{`rx="0"`, ">coverage</text>", ">85%</text>"},
+		},
+		{
+			style:    core.BadgeForTheBadge,
+			height:   `height="28"`,
+			contains: []string{`letter-spacing="1.2"`, ">COVERAGE</text>", ">85%</text>"},
+		},
+	}
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		badge := NewBadge(&core.Badge{
+			Label:   "coverage",
+			Message: "85%",
+			Color:   core.BadgeGreen,
+			Style:   test.style,
+		})
+		if err := badge.Render(buf); err != nil {
+			t.Fatal(err)
+		}
+		svg := buf.String()
+		for _, s := range append(test.contains, test.height, core.BadgeGreen) {
+			if !strings.Contains(svg, s) {
+				t.Fatalf("%s badge should contain %s:\n%s", test.style, s, svg)
+			}
+		}
+		if test.style == core.BadgeFlatSquare && strings.Contains(svg, `url(#s)`) {
+			t.Fatal("flat-square badge should not have shadow")
+		}
+		if err := validXML(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBadgeEscape(t *testing.T) {
+	buf := &bytes.Buffer{}
+	badge := NewBadge(&core.Badge{Label: `<a&"b">`, Message: "unknown", Color: core.BadgeGrey})
+	if err := badge.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `<a&`) {
+		t.Fatal("label should be escaped")
+	}
+	if err := validXML(buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if w := textWidth("iii", 11); w >= textWidth("mmm", 11) {
+		t.Fatalf("narrow text should be shorter, got %f", w)
+	}
+	if w := textWidth("100%", 22); w != 2*textWidth("100%", 11) {
+		t.Fatal("width should scale with the font size")
+	}
+}
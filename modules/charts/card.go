@@ -1,13 +1,12 @@
 package charts
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"math"
 
 	svg "github.com/ajstarks/svgo"
-	"github.com/dustin/go-humanize"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 
 	"github.com/covergates/covergates/core"
 	"github.com/covergates/covergates/modules/charts/icons"
@@ -18,16 +17,19 @@ const (
 	listIcon = "list.svg"
 	gitIcon  = "git.svg"
 	logoIcon = "logo.svg"
-	cssStyle = `
+	// iconColor is the fill of icon assets, which is replaced by the theme
+	iconColor = "#b9ac38"
+	cardFont  = `'Segoe UI', Ubuntu, 'Helvetica Neue', sans-serif`
+	cardStyle = `
 	<style>
 	.score-circle-ring {
-		stroke: #3f8167;
+		stroke: %[1]s;
 		stroke-width: 6;
 		fill: none;
 		opacity: 0.2;
 	}
 	.score-circle {
-		stroke: #3f8167;
+		stroke: %[1]s;
 		stroke-width: 6;
 		fill: none;
 		stroke-linecap: round;
@@ -35,33 +37,81 @@ const (
 	}
 	</style>
 	`
+	cardWidth   = 495
+	cardTop     = 75
+	cardRow     = 40
+	cardFooter  = 25
+	cardPadding = 15
+	// cardScoreLeft is where the score circle starts, which texts should not cross
+	cardScoreLeft = 370
+	cardValueLeft = 200
 )
 
+type cardIcon struct {
+	asset     string
+	transform string
+}
+
+var cardIcons = map[core.CardMetric]cardIcon{
+	core.CardFiles:     {fileIcon, "scale(0.7) translate(0, -22)"},
+	core.CardHits:      {listIcon, "translate(-1, -16) scale(0.18)"},
+	core.CardUncovered: {listIcon, "translate(-1, -16) scale(0.18)"},
+	core.CardTrend:     {listIcon, "translate(-1, -16) scale(0.18)"},
+	core.CardBuild:     {gitIcon, "scale(0.11) translate(-140, -160)"},
+	core.CardCommit:    {gitIcon, "scale(0.11) translate(-140, -160)"},
+}
+
 // RepoCard render svg repo status card
 type RepoCard struct {
 	core.Chart
-	repo   *core.Repo
-	report *core.Report
+	card   *core.Card
 	canvas *svg.SVG
 }
 
 // NewRepoCard render
-func NewRepoCard(repo *core.Repo, report *core.Report) *RepoCard {
+func NewRepoCard(card *core.Card) *RepoCard {
 	return &RepoCard{
-		repo:   repo,
-		report: report,
+		card: card,
+	}
+}
+
+// fitText in the width, shrinking the font size to the minimum and then truncating with an ellipsis
+func fitText(text string, size, min, width float64) (string, float64) {
+	for ; size > min && textWidth(text, size) > width; size-- {
 	}
+	if textWidth(text, size) <= width {
+		return text, size
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…", size
+}
+
+func (c *RepoCard) font(weight int, size float64) string {
+	return fmt.Sprintf(`style="font: %d %gpx %s"`, weight, size, cardFont)
+}
+
+func (c *RepoCard) fill(color string) string {
+	return fmt.Sprintf(`fill="%s"`, escapeAttr(color))
+}
+
+func (c *RepoCard) height() int {
+	rows := cardTop + cardRow*len(c.card.Items) - cardFooter
+	return int(math.Max(float64(rows), cardTop+55)) + cardFooter
 }
 
 func (c *RepoCard) start(w io.Writer) {
+	height := c.height()
 	c.canvas = svg.New(w)
-	c.canvas.Start(495, 195)
-	_, _ = c.canvas.Writer.Write([]byte(cssStyle))
+	c.canvas.Start(cardWidth, height)
+	_, _ = fmt.Fprintf(c.canvas.Writer, cardStyle, escapeAttr(c.card.Theme.Accent))
 	c.canvas.ClipPath(`id="a1"`)
-	c.canvas.Rect(0, 0, 500, 170)
+	c.canvas.Rect(0, 0, cardWidth+5, height-cardFooter)
 	c.canvas.ClipEnd()
-	c.canvas.Roundrect(1, 1, 493, 193, 5, 5, `fill="#3b726b"`)
-	c.canvas.Roundrect(1, 1, 493, 193, 5, 5, `fill="#333333"`, `clip-path="url(#a1)"`)
+	c.canvas.Roundrect(1, 1, cardWidth-2, height-2, 5, 5, c.fill(c.card.Theme.Footer))
+	c.canvas.Roundrect(1, 1, cardWidth-2, height-2, 5, 5, c.fill(c.card.Theme.Background), `clip-path="url(#a1)"`)
 }
 
 func (c *RepoCard) end() {
@@ -69,82 +119,56 @@ func (c *RepoCard) end() {
 }
 
 func (c *RepoCard) title() {
-	c.canvas.Text(15, 32, c.repo.FullName(), `font: 600 24px 'Segoe UI', sans-serif`, `fill="#80b9a2"`)
+	title, size := fitText(c.card.Title, 24, 16, cardScoreLeft-cardPadding*2)
+	c.canvas.Text(cardPadding, 32, title, c.font(600, size), c.fill(c.card.Theme.Title))
 }
 
-func (c *RepoCard) files() {
-	c.canvas.Translate(15, 75)
-	c.canvas.Group(`transform="scale(0.7) translate(0, -22)"`)
-	_, _ = c.canvas.Writer.Write(icons.MustAsset(fileIcon))
-	c.canvas.Gend()
-	c.canvas.Text(32, 0, "Total Files", `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
-	p := message.NewPrinter(language.English)
-	c.canvas.Text(200, 0, p.Sprintf("%d", len(c.report.Files)), `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
-	c.canvas.Gend()
-}
-
-func (c *RepoCard) hits() {
-	c.canvas.Translate(15, 115)
-	c.canvas.Translate(-1, -16)
-	c.canvas.Gtransform("scale(0.18)")
-	_, _ = c.canvas.Writer.Write(icons.MustAsset(listIcon))
-	c.canvas.Gend()
-	c.canvas.Gend()
-	c.canvas.Text(32, 0, "Hit Lines", `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
-	p := message.NewPrinter(language.English)
-	c.canvas.Text(200, 0, p.Sprintf("%d", c.hitLines()), `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
-	c.canvas.Gend()
-}
-
-func (c *RepoCard) hitLines() int {
-	hits := 0
-	for _, coverage := range c.report.Coverages {
-		for _, file := range coverage.Files {
-			for _, line := range file.StatementHits {
-				if line.Hits > 0 {
-					hits++
-				}
-			}
-		}
+func (c *RepoCard) icon(metric core.CardMetric) {
+	icon, ok := cardIcons[metric]
+	if !ok {
+		return
 	}
-	return hits
+	asset := bytes.ReplaceAll(icons.MustAsset(icon.asset), []byte(iconColor), []byte(escapeAttr(c.card.Theme.Icon)))
+	c.canvas.Gtransform(icon.transform)
+	_, _ = c.canvas.Writer.Write(asset)
+	c.canvas.Gend()
 }
 
-func (c *RepoCard) build() {
-	c.canvas.Translate(15, 155)
-	c.canvas.Gtransform("scale(0.11) translate(-140, -160)")
-	_, _ = c.canvas.Writer.Write(icons.MustAsset(gitIcon))
-	c.canvas.Gend()
-	c.canvas.Text(32, 0, "Recent Build", `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
-	c.canvas.Text(200, 0, fmt.Sprintf("%s, %s", c.repo.Branch, humanize.Time(c.report.CreatedAt)), `font: 600 18px 'Segoe UI', sans-serif`, `fill="#e6e6e6"`)
+func (c *RepoCard) item(i int, item *core.CardItem) {
+	c.canvas.Translate(cardPadding, cardTop+cardRow*i)
+	c.icon(item.Metric)
+	label, size := fitText(item.Label, 18, 14, cardValueLeft-cardPadding-32-8)
+	c.canvas.Text(32, 0, label, c.font(600, size), c.fill(c.card.Theme.Text))
+	value, size := fitText(item.Value, 18, 14, cardScoreLeft-cardValueLeft-8)
+	c.canvas.Text(cardValueLeft, 0, value, c.font(600, size), c.fill(c.card.Theme.Text))
 	c.canvas.Gend()
 }
 
 func (c *RepoCard) brand() {
-	c.canvas.Translate(180, 185)
+	c.canvas.Translate(200, c.height()-10)
 	c.canvas.Gtransform("scale(0.08) translate(-5, -180)")
 	_, _ = c.canvas.Writer.Write(icons.MustAsset(logoIcon))
 	c.canvas.Gend()
-	c.canvas.Text(30, 0, "covergates©2020", "font-size:12px")
+	c.canvas.Text(30, 0, "covergates", c.font(400, 12), c.fill(c.card.Theme.Title))
 	c.canvas.Gend()
 }
 
 func (c *RepoCard) score() {
-	c.canvas.Translate(420, 75)
+	c.canvas.Translate(cardScoreLeft+50, cardTop)
 	c.canvas.Circle(0, 0, 45, `class="score-circle-ring"`)
 	c.canvas.Circle(
 		0, 0, 45,
 		`class="score-circle"`,
-		fmt.Sprintf("stroke-dashoffset: %d", int(45*2*3.14*(1.0-c.report.StatementCoverage()))),
+		fmt.Sprintf("stroke-dashoffset: %d", int(45*2*3.14*(1.0-c.card.Coverage))),
 	)
 	c.canvas.Translate(-35, 12)
-	score := int(c.report.StatementCoverage() * 100)
+	score := int(c.card.Coverage * 100)
 	pad := 0
 	if score < 100 {
 		pad = 10
 	}
-	c.canvas.Text(pad, 0, fmt.Sprintf("%d", score), `font: 600 36px 'Segoe UI', sans-serif`, `fill="#3f8167"`)
-	c.canvas.Text(55, 0, "%", `font: 800 14px 'Segoe UI', sans-serif`, `fill="#3f8167"`)
+	c.canvas.Text(pad, 0, fmt.Sprintf("%d", score), c.font(600, 36), c.fill(c.card.Theme.Accent))
+	c.canvas.Text(55, 0, "%", c.font(800, 14), c.fill(c.card.Theme.Accent))
 	c.canvas.Gend()
 	c.canvas.Gend()
 }
@@ -153,9 +177,9 @@ func (c *RepoCard) score() {
 func (c *RepoCard) Render(w io.Writer) error {
 	c.start(w)
 	c.title()
-	c.files()
-	c.hits()
-	c.build()
+	for i, item := range c.card.Items {
+		c.item(i, item)
+	}
 	c.brand()
 	c.score()
 	c.end()
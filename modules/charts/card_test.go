@@ -1,33 +1,29 @@
 package charts
 
 import (
+	"bytes"
 	"os"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/covergates/covergates/core"
 )
 
+func newTestCard(t *testing.T, title string, items ...*core.CardItem) *core.Card {
+	theme, err := core.ParseCardTheme(core.CardDark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &core.Card{Title: title, Coverage: 0.8, Theme: theme, Items: items}
+}
+
 func TestCard(t *testing.T) {
-	card := NewRepoCard(
-		&core.Repo{
-			NameSpace: "covergates",
-			Name:      "covergates",
-			Branch:    "master",
-		}, &core.Report{
-			Files:     []string{"main.go", "core.go"},
-			CreatedAt: time.Date(2020, time.July, 20, 12, 12, 40, 0, time.Local),
-			Coverages: []*core.CoverageReport{
-				{
-					Files: []*core.File{
-						{
-							StatementCoverage: 0.8,
-						},
-					},
-				},
-			},
-		},
-	)
+	card := NewRepoCard(newTestCard(
+		t, "covergates/covergates",
+		&core.CardItem{Metric: core.CardFiles, Label: "Total Files", Value: "2"},
+		&core.CardItem{Metric: core.CardHits, Label: "Hit Lines", Value: "1,024"},
+		&core.CardItem{Metric: core.CardBuild, Label: "Recent Build", Value: "master, 1 hour ago"},
+	))
 	file, err := os.Create("card.svg")
 	if err != nil {
 		t.Fatal(err)
@@ -38,3 +34,69 @@ func TestCard(t *testing.T) {
 	}()
 	_ = card.Render(file)
 }
+
+func TestCardTheme(t *testing.T) {
+	theme, err := core.ParseCardTheme(core.CardLight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := &core.Card{
+		Title: "org/repo",
+		Theme: theme.Merge(core.CardTheme{Icon: "#123456"}),
+		Items: []*core.CardItem{{Metric: core.CardFiles, Label: "Total Files", Value: "1"}},
+	}
+	buf := &bytes.Buffer{}
+	if err := NewRepoCard(card).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if err := validXML(strings.NewReader(svg)); err != nil {
+		t.Fatal(err)
+	}
+	for _, color := range []string{theme.Background, theme.Footer, theme.Title, theme.Text, theme.Accent, "#123456"} {
+		if !strings.Contains(svg, color) {
+			t.Fatalf("card should be in color %s", color)
+		}
+	}
+	if strings.Contains(svg, iconColor) {
+		t.Fatal("icons should be recolored")
+	}
+}
+
+func TestCardLayout(t *testing.T) {
+	name := strings.Repeat("organization/", 10) + "repository"
+	message := strings.Repeat("fix ", 50)
+	items := make([]*core.CardItem, 0)
+	for i := 0; i < core.MaxCardMetrics; i++ {
+		items = append(items, &core.CardItem{Metric: core.CardCommit, Label: "Last Commit", Value: message})
+	}
+	buf := &bytes.Buffer{}
+	if err := NewRepoCard(newTestCard(t, name, items...)).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	if strings.Contains(svg, name) || strings.Contains(svg, message) {
+		t.Fatal("long texts should be truncated")
+	}
+	if !strings.Contains(svg, "…") {
+		t.Fatal("truncated texts should end with ellipsis")
+	}
+	if width, height := svgSize(t, buf.Bytes()); width != cardWidth || height != 275 {
+		t.Fatalf("card should grow with metrics, got %dx%d", width, height)
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if text, size := fitText("short", 24, 16, 200); text != "short" || size != 24 {
+		t.Fatalf("short text should be kept, got %s %f", text, size)
+	}
+	long := "a long repository name"
+	text, size := fitText(long, 24, 16, textWidth(long, 20))
+	if text != long || size > 20 || size < 16 {
+		t.Fatalf("text should shrink, got %s %f", text, size)
+	}
+	text, size = fitText(strings.Repeat(long, 5), 24, 16, 200)
+	if size != 16 || !strings.HasSuffix(text, "…") || textWidth(text, size) > 200 {
+		t.Fatalf("text should be truncated, got %s %f", text, size)
+	}
+}
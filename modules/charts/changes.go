@@ -0,0 +1,269 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	svg "github.com/ajstarks/svgo"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	changesWidth  = 600
+	changesHeight = 400
+	changesFont   = `font-family="'Segoe UI', sans-serif"`
+)
+
+// changeCell is a changed file, or an aggregate of files in a directory
+type changeCell struct {
+	path string
+	dir  bool
+	// changed if the cell has any file changed in the pull request
+	changed       bool
+	files         int
+	oldStatements int
+	oldCovered    int
+	newStatements int
+	newCovered    int
+}
+
+// parent directory of the cell, which is the directory itself for aggregates
+func (cell *changeCell) parent() string {
+	if cell.dir {
+		return cell.path
+	}
+	return path.Dir(cell.path)
+}
+
+func (cell *changeCell) size() float64 {
+	size := cell.newStatements
+	if cell.oldStatements > size {
+		size = cell.oldStatements
+	}
+	if size == 0 {
+		return 1
+	}
+	return float64(size)
+}
+
+func (cell *changeCell) delta() float64 {
+	return ratio(cell.newCovered, cell.newStatements) - ratio(cell.oldCovered, cell.oldStatements)
+}
+
+func (cell *changeCell) removed() bool {
+	return cell.files > 0 && cell.newStatements == 0 && cell.oldStatements > 0 && !cell.dir
+}
+
+func (cell *changeCell) merge(other *changeCell) {
+	cell.changed = cell.changed || other.changed
+	cell.files += other.files
+	cell.oldStatements += other.oldStatements
+	cell.oldCovered += other.oldCovered
+	cell.newStatements += other.newStatements
+	cell.newCovered += other.newCovered
+}
+
+func ratio(covered, statements int) float64 {
+	if statements == 0 {
+		return 0
+	}
+	return float64(covered) / float64(statements)
+}
+
+func countHits(file *core.File) (int, int) {
+	covered := 0
+	for _, hit := range file.StatementHits {
+		if hit.Hits > 0 {
+			covered++
+		}
+	}
+	return len(file.StatementHits), covered
+}
+
+func underDir(p, dir string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// CoverageChangeTreeMap shows coverage differences of files changed in a pull request.
+// Unchanged files next to changed ones are aggregated by directory, and cells beyond
+// the budget are collapsed into their directories.
+type CoverageChangeTreeMap struct {
+	cells []*changeCell
+}
+
+// NewCoverageChangeTreeMap of the changed files between reports with at most cells cells
+func NewCoverageChangeTreeMap(old, new *core.Report, changes []*core.FileChange, cells int) *CoverageChangeTreeMap {
+	oldFiles := make(map[string]*core.File)
+	newFiles := make(map[string]*core.File)
+	for _, file := range reportFileSlice(old) {
+		oldFiles[file.Name] = file
+	}
+	for _, file := range reportFileSlice(new) {
+		newFiles[file.Name] = file
+	}
+	changed := make(map[string]bool)
+	dirs := make(map[string]bool)
+	result := make([]*changeCell, 0)
+	for _, change := range changes {
+		oldFile, inOld := oldFiles[change.Path]
+		newFile, inNew := newFiles[change.Path]
+		if (!inOld && !inNew) || changed[change.Path] {
+			continue
+		}
+		changed[change.Path] = true
+		dirs[path.Dir(change.Path)] = true
+		cell := &changeCell{path: change.Path, changed: true, files: 1}
+		if inOld {
+			cell.oldStatements, cell.oldCovered = countHits(oldFile)
+		}
+		if inNew {
+			cell.newStatements, cell.newCovered = countHits(newFile)
+		}
+		result = append(result, cell)
+	}
+	siblings := make(map[string]*changeCell)
+	for name, file := range newFiles {
+		dir := path.Dir(name)
+		if changed[name] || !dirs[dir] {
+			continue
+		}
+		cell, ok := siblings[dir]
+		if !ok {
+			cell = &changeCell{path: dir, dir: true}
+			siblings[dir] = cell
+			result = append(result, cell)
+		}
+		cell.files++
+		statements, covered := countHits(file)
+		cell.newStatements += statements
+		cell.newCovered += covered
+		if oldFile, ok := oldFiles[name]; ok {
+			statements, covered := countHits(oldFile)
+			cell.oldStatements += statements
+			cell.oldCovered += covered
+		}
+	}
+	return &CoverageChangeTreeMap{cells: collapse(result, cells)}
+}
+
+// collapse cells of the deepest directory until the number of cells is in the budget
+func collapse(cells []*changeCell, budget int) []*changeCell {
+	if budget < 1 {
+		budget = 1
+	}
+	for len(cells) > budget {
+		counts := make(map[string]int)
+		for _, cell := range cells {
+			for dir := cell.parent(); ; dir = path.Dir(dir) {
+				counts[dir]++
+				if dir == "." || dir == "/" {
+					break
+				}
+			}
+		}
+		target, depth := ".", -1
+		for dir, count := range counts {
+			if count < 2 {
+				continue
+			}
+			d := strings.Count(dir, "/")
+			if dir == "." {
+				d = -1
+			}
+			if d > depth || (d == depth && (count > counts[target] || (count == counts[target] && dir < target))) {
+				target, depth = dir, d
+			}
+		}
+		merged := &changeCell{path: target, dir: true}
+		kept := make([]*changeCell, 0, len(cells))
+		for _, cell := range cells {
+			if underDir(cell.path, target) || (cell.dir && cell.path == target) {
+				merged.merge(cell)
+			} else {
+				kept = append(kept, cell)
+			}
+		}
+		cells = append(kept, merged)
+	}
+	return cells
+}
+
+func (c *CoverageChangeTreeMap) label(cell *changeCell) (string, string) {
+	name := path.Base(cell.path)
+	if cell.dir {
+		name = cell.path + "/"
+		if cell.path == "." {
+			name = "/"
+		}
+	}
+	switch {
+	case cell.removed():
+		return name, "removed"
+	case !cell.changed:
+		return name, fmt.Sprintf("%d files", cell.files)
+	default:
+		return name, fmt.Sprintf("%+.1f%%", cell.delta()*100)
+	}
+}
+
+// Render chart to writer
+func (c *CoverageChangeTreeMap) Render(w io.Writer) error {
+	canvas := svg.New(w)
+	canvas.Start(changesWidth, changesHeight)
+	canvas.Rect(0, 0, changesWidth, changesHeight, `fill="#ffffff"`)
+	if len(c.cells) == 0 {
+		canvas.Text(changesWidth/2, changesHeight/2, "No covered changes",
+			`text-anchor="middle"`, `font-size="16"`, `fill="#757575"`, changesFont)
+		canvas.End()
+		return nil
+	}
+	cells := make([]*changeCell, len(c.cells))
+	copy(cells, c.cells)
+	sort.SliceStable(cells, func(i, j int) bool {
+		if cells[i].size() != cells[j].size() {
+			return cells[i].size() > cells[j].size()
+		}
+		return cells[i].path < cells[j].path
+	})
+	values := make([]float64, len(cells))
+	for i, cell := range cells {
+		values[i] = cell.size()
+	}
+	for i, b := range squarify(values, box{w: changesWidth, h: changesHeight}) {
+		cell := cells[i]
+		x, y := int(math.Round(b.x)), int(math.Round(b.y))
+		w, h := int(math.Round(b.x+b.w))-x, int(math.Round(b.y+b.h))-y
+		if w <= 0 || h <= 0 {
+			continue
+		}
+		color := colorNoChange
+		if cell.changed {
+			switch delta := cell.delta(); {
+			case delta > 0:
+				color = colorIncrease
+			case delta < 0:
+				color = colorDecrease
+			}
+		}
+		name, detail := c.label(cell)
+		canvas.Group()
+		canvas.Title(fmt.Sprintf("%s: %s, %.1f%% to %.1f%%", cell.path, detail,
+			ratio(cell.oldCovered, cell.oldStatements)*100, ratio(cell.newCovered, cell.newStatements)*100))
+		canvas.Rect(x, y, w, h, fmt.Sprintf(`fill="%s"`, color), `stroke="#ffffff"`)
+		if textWidth(name, 12)+8 <= float64(w) && textWidth(detail, 12)+8 <= float64(w) && h >= 36 {
+			canvas.Text(x+w/2, y+h/2-2, name, `text-anchor="middle"`, `font-size="12"`, `fill="#ffffff"`, changesFont)
+			canvas.Text(x+w/2, y+h/2+13, detail,
+				`text-anchor="middle"`, `font-size="12"`, `font-weight="bold"`, `fill="#ffffff"`, changesFont)
+		} else if textWidth(detail, 12)+8 <= float64(w) && h >= 18 {
+			canvas.Text(x+w/2, y+h/2+4, detail, `text-anchor="middle"`, `font-size="12"`, `fill="#ffffff"`, changesFont)
+		}
+		canvas.Gend()
+	}
+	canvas.End()
+	return nil
+}
@@ -0,0 +1,127 @@
+package charts
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+)
+
+func cellPaths(cells []*changeCell) []string {
+	paths := make([]string, len(cells))
+	for i, cell := range cells {
+		paths[i] = cell.path
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestCoverageChangeTreeMap(t *testing.T) {
+	old := &core.Report{Coverages: []*core.CoverageReport{{Files: []*core.File{
+		{Name: "core/a.go", StatementHits: hitsOf(1, 0)},
+		{Name: "core/b.go", StatementHits: hitsOf(1, 1)},
+		{Name: "core/c.go", StatementHits: hitsOf(0, 1)},
+		{Name: "models/removed.go", StatementHits: hitsOf(1)},
+		{Name: "web/other.go", StatementHits: hitsOf(1)},
+	}}}}
+	new := &core.Report{Coverages: []*core.CoverageReport{{Files: []*core.File{
+		{Name: "core/a.go", StatementHits: hitsOf(1, 1)},
+		{Name: "core/b.go", StatementHits: hitsOf(1, 1)},
+		{Name: "core/c.go", StatementHits: hitsOf(0, 1)},
+		{Name: "models/added.go", StatementHits: hitsOf(0, 0, 1, 1)},
+		{Name: "web/other.go", StatementHits: hitsOf(1)},
+	}}}}
+	changes := []*core.FileChange{
+		{Path: "core/a.go"},
+		{Path: "models/added.go", Added: true},
+		{Path: "models/removed.go", Deleted: true},
+		{Path: "README.md"},
+	}
+	chart := NewCoverageChangeTreeMap(old, new, changes, 10)
+	expect := []string{"core", "core/a.go", "models/added.go", "models/removed.go"}
+	if diff := cmp.Diff(expect, cellPaths(chart.cells)); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, cell := range chart.cells {
+		switch cell.path {
+		case "core":
+			if cell.changed || cell.files != 2 || cell.newStatements != 4 || cell.newCovered != 3 {
+				t.Fatalf("unchanged siblings should be aggregated, got %v", cell)
+			}
+		case "core/a.go":
+			if cell.delta() != 0.5 {
+				t.Fatalf("delta should be 0.5, got %f", cell.delta())
+			}
+		case "models/removed.go":
+			if !cell.removed() {
+				t.Fatal("deleted file should be removed")
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := chart.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := validXML(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"a.go", "+50.0%", colorIncrease, colorNoChange} {
+		if !strings.Contains(buf.String(), text) {
+			t.Fatalf("svg should contain %q", text)
+		}
+	}
+	if strings.Contains(buf.String(), "other.go") {
+		t.Fatal("unchanged directories should not be shown")
+	}
+}
+
+func TestCoverageChangeTreeMapCollapse(t *testing.T) {
+	cells := []*changeCell{
+		{path: "a/b/c/x.go", changed: true, files: 1, newStatements: 2, newCovered: 1},
+		{path: "a/b/c/y.go", changed: true, files: 1, newStatements: 2, newCovered: 2},
+		{path: "a/b/c", dir: true, files: 3, newStatements: 4},
+		{path: "a/d/z.go", changed: true, files: 1, newStatements: 1, oldStatements: 1, oldCovered: 1},
+		{path: "e.go", changed: true, files: 1, newStatements: 1},
+	}
+	collapsed := collapse(cells, 3)
+	expect := []string{"a/b/c", "a/d/z.go", "e.go"}
+	if diff := cmp.Diff(expect, cellPaths(collapsed)); diff != "" {
+		t.Fatal(diff)
+	}
+	for _, cell := range collapsed {
+		if cell.path == "a/b/c" && (!cell.dir || !cell.changed || cell.files != 5 || cell.newCovered != 3) {
+			t.Fatalf("deepest directory should be merged, got %v", cell)
+		}
+	}
+	if collapsed = collapse(cells, 1); len(collapsed) != 1 || collapsed[0].path != "." {
+		t.Fatalf("everything should be merged into the root, got %v", cellPaths(collapsed))
+	}
+
+	buf := &bytes.Buffer{}
+	chart := &CoverageChangeTreeMap{cells: collapse(cells, 2)}
+	if err := chart.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := validXML(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "a/") {
+		t.Fatal("should render labelled directory cells")
+	}
+}
+
+func TestCoverageChangeTreeMapEmpty(t *testing.T) {
+	buf := &bytes.Buffer{}
+	chart := NewCoverageChangeTreeMap(&core.Report{}, &core.Report{}, nil, 10)
+	if err := chart.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No covered changes") {
+		t.Fatal("should show empty message")
+	}
+}
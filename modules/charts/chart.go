@@ -10,7 +10,36 @@ func (service *ChartService) CoverageDiffTreeMap(old, new *core.Report) core.Cha
 	return NewCoverageDiffTreeMap(old, new)
 }
 
+// CoverageChangeTreeMap of files changed in a pull request with at most cells cells
+func (service *ChartService) CoverageChangeTreeMap(
+	old, new *core.Report,
+	changes []*core.FileChange,
+	cells int,
+) core.Chart {
+	return NewCoverageChangeTreeMap(old, new, changes, cells)
+}
+
 // RepoCard of repository status
-func (service *ChartService) RepoCard(repo *core.Repo, report *core.Report) core.Chart {
-	return NewRepoCard(repo, report)
+func (service *ChartService) RepoCard(card *core.Card) core.Chart {
+	return NewRepoCard(card)
+}
+
+// Badge of the label and message
+func (service *ChartService) Badge(badge *core.Badge) core.Chart {
+	return NewBadge(badge)
+}
+
+// CoverageTrend of the reports over time
+func (service *ChartService) CoverageTrend(reports []*core.Report) core.Chart {
+	return NewCoverageTrend(reports)
+}
+
+// CoverageHierarchy of the report grouped by directory
+func (service *ChartService) CoverageHierarchy(report *core.Report, link core.ChartLink) core.Chart {
+	return NewCoverageHierarchy(report, link)
+}
+
+// PNG rasterizes the chart to fit in width and height, zero to keep its size or ratio
+func (service *ChartService) PNG(chart core.Chart, width, height int) core.Chart {
+	return NewPNG(chart, width, height)
 }
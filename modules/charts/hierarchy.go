@@ -0,0 +1,258 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	svg "github.com/ajstarks/svgo"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	hierarchyWidth  = 800
+	hierarchyHeight = 500
+	// hierarchyHeader is the height of directory labels
+	hierarchyHeader = 16
+	hierarchyPad    = 2
+	// hierarchyMinSize of a directory to show its children
+	hierarchyMinSize = 24
+	hierarchyFont    = `font-family="'Segoe UI', sans-serif"`
+)
+
+// coverageNode is a directory or a file with its statement counts
+type coverageNode struct {
+	name       string
+	path       string
+	dir        bool
+	statements int
+	covered    int
+	children   []*coverageNode
+}
+
+func (n *coverageNode) coverage() float64 {
+	if n.statements == 0 {
+		return 0
+	}
+	return float64(n.covered) / float64(n.statements)
+}
+
+func (n *coverageNode) child(name string, dir bool) *coverageNode {
+	for _, child := range n.children {
+		if child.name == name && child.dir == dir {
+			return child
+		}
+	}
+	path := name
+	if n.path != "" {
+		path = n.path + "/" + name
+	}
+	child := &coverageNode{name: name, path: path, dir: dir}
+	n.children = append(n.children, child)
+	return child
+}
+
+// newCoverageTree groups files of the report by directory
+func newCoverageTree(report *core.Report) *coverageNode {
+	root := &coverageNode{dir: true}
+	for _, coverage := range report.Coverages {
+		for _, file := range coverage.Files {
+			parts := strings.Split(strings.Trim(file.Name, "/"), "/")
+			nodes := []*coverageNode{root}
+			node := root
+			for i, part := range parts {
+				node = node.child(part, i < len(parts)-1)
+				nodes = append(nodes, node)
+			}
+			covered := 0
+			for _, hit := range file.StatementHits {
+				if hit.Hits > 0 {
+					covered++
+				}
+			}
+			for _, n := range nodes {
+				n.statements += len(file.StatementHits)
+				n.covered += covered
+			}
+		}
+	}
+	root.compact()
+	return root
+}
+
+// compact directories having only one directory, and sort children from the largest
+func (n *coverageNode) compact() {
+	for n.dir && n.path != "" && len(n.children) == 1 && n.children[0].dir {
+		child := n.children[0]
+		n.name = n.name + "/" + child.name
+		n.path = child.path
+		n.children = child.children
+	}
+	kept := n.children[:0]
+	for _, child := range n.children {
+		if child.statements > 0 {
+			child.compact()
+			kept = append(kept, child)
+		}
+	}
+	n.children = kept
+	sort.SliceStable(n.children, func(i, j int) bool {
+		return n.children[i].statements > n.children[j].statements
+	})
+}
+
+type box struct {
+	x, y, w, h float64
+}
+
+// squarify lays out areas of the values, sorted from the largest, in the box
+func squarify(values []float64, b box) []box {
+	result := make([]box, len(values))
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	if total <= 0 || b.w <= 0 || b.h <= 0 {
+		return result
+	}
+	areas := make([]float64, len(values))
+	for i, v := range values {
+		areas[i] = v * b.w * b.h / total
+	}
+	for i := 0; i < len(areas); {
+		side := math.Min(b.w, b.h)
+		j := i + 1
+		for j < len(areas) && worstRatio(areas[i:j+1], side) <= worstRatio(areas[i:j], side) {
+			j++
+		}
+		sum := 0.0
+		for _, a := range areas[i:j] {
+			sum += a
+		}
+		if b.w >= b.h {
+			width := sum / b.h
+			y := b.y
+			for k, a := range areas[i:j] {
+				result[i+k] = box{x: b.x, y: y, w: width, h: a / width}
+				y += a / width
+			}
+			b.x += width
+			b.w -= width
+		} else {
+			height := sum / b.w
+			x := b.x
+			for k, a := range areas[i:j] {
+				result[i+k] = box{x: x, y: b.y, w: a / height, h: height}
+				x += a / height
+			}
+			b.y += height
+			b.h -= height
+		}
+		i = j
+	}
+	return result
+}
+
+func worstRatio(row []float64, side float64) float64 {
+	sum, max, min := 0.0, 0.0, math.Inf(1)
+	for _, a := range row {
+		sum += a
+		max = math.Max(max, a)
+		min = math.Min(min, a)
+	}
+	if sum == 0 || min == 0 {
+		return math.Inf(1)
+	}
+	return math.Max(side*side*max/(sum*sum), sum*sum/(side*side*min))
+}
+
+// coverageColor from red to green
+func coverageColor(coverage float64) string {
+	return fmt.Sprintf("hsl(%d, 65%%, 45%%)", int(coverage*120))
+}
+
+// CoverageHierarchy renders a nested treemap of the report grouped by directory.
+// Sizes are statement counts and colors are coverage, and nodes link to their paths.
+type CoverageHierarchy struct {
+	root *coverageNode
+	link core.ChartLink
+}
+
+// NewCoverageHierarchy of the report, nodes are not linked if link is nil
+func NewCoverageHierarchy(report *core.Report, link core.ChartLink) *CoverageHierarchy {
+	return &CoverageHierarchy{
+		root: newCoverageTree(report),
+		link: link,
+	}
+}
+
+// Render chart to writer
+func (c *CoverageHierarchy) Render(w io.Writer) error {
+	canvas := svg.New(w)
+	canvas.Start(hierarchyWidth, hierarchyHeight)
+	canvas.Rect(0, 0, hierarchyWidth, hierarchyHeight, `fill="#263238"`)
+	if c.root.statements == 0 {
+		canvas.Text(hierarchyWidth/2, hierarchyHeight/2, "No statements",
+			`text-anchor="middle"`, `font-size="16"`, `fill="#eceff1"`, hierarchyFont)
+		canvas.End()
+		return nil
+	}
+	c.children(canvas, c.root, box{w: hierarchyWidth, h: hierarchyHeight})
+	canvas.End()
+	return nil
+}
+
+func (c *CoverageHierarchy) children(canvas *svg.SVG, node *coverageNode, b box) {
+	values := make([]float64, len(node.children))
+	for i, child := range node.children {
+		values[i] = float64(child.statements)
+	}
+	for i, childBox := range squarify(values, b) {
+		c.node(canvas, node.children[i], childBox)
+	}
+}
+
+func (c *CoverageHierarchy) node(canvas *svg.SVG, node *coverageNode, b box) {
+	x, y := int(math.Round(b.x)), int(math.Round(b.y))
+	w, h := int(math.Round(b.x+b.w))-x, int(math.Round(b.y+b.h))-y
+	if w <= 0 || h <= 0 {
+		return
+	}
+	label := fmt.Sprintf("%s %.1f%%", node.name, node.coverage()*100)
+	linked := false
+	if c.link != nil {
+		if href := c.link(node.path, node.dir); href != "" {
+			canvas.Link(escapeAttr(href), node.path)
+			linked = true
+		}
+	}
+	canvas.Group()
+	canvas.Title(fmt.Sprintf("%s: %.1f%% (%d/%d)", node.path, node.coverage()*100, node.covered, node.statements))
+	canvas.Rect(x, y, w, h, fmt.Sprintf(`fill="%s"`, coverageColor(node.coverage())), `stroke="#263238"`)
+	expand := node.dir && w >= hierarchyMinSize && h >= hierarchyMinSize+hierarchyHeader
+	if expand || (w > 6 && h > 14) {
+		fits := textWidth(label, 11)+6 <= float64(w)
+		if !fits {
+			label = node.name
+			fits = textWidth(label, 11)+6 <= float64(w)
+		}
+		if fits {
+			canvas.Text(x+3, y+12, label, `font-size="11"`, `fill="#ffffff"`, hierarchyFont)
+		}
+	}
+	canvas.Gend()
+	if linked {
+		canvas.LinkEnd()
+	}
+	if expand {
+		c.children(canvas, node, box{
+			x: b.x + hierarchyPad,
+			y: b.y + hierarchyHeader,
+			w: b.w - 2*hierarchyPad,
+			h: b.h - hierarchyHeader - hierarchyPad,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package charts
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/covergates/covergates/core"
+)
+
+func hitsOf(hits ...int) []*core.StatementHit {
+	result := make([]*core.StatementHit, len(hits))
+	for i, hit := range hits {
+		result[i] = &core.StatementHit{LineNumber: i + 1, Hits: hit}
+	}
+	return result
+}
+
+func TestCoverageTree(t *testing.T) {
+	report := &core.Report{Coverages: []*core.CoverageReport{{Files: []*core.File{
+		{Name: "github.com/org/repo/main.go", StatementHits: hitsOf(1, 0)},
+		{Name: "github.com/org/repo/core/a.go", StatementHits: hitsOf(1, 1, 1, 0)},
+		{Name: "github.com/org/repo/core/b.go", StatementHits: hitsOf(0, 0)},
+		{Name: "github.com/org/repo/empty.go"},
+	}}}}
+	root := newCoverageTree(report)
+	if root.statements != 8 || root.covered != 4 {
+		t.Fatalf("unexpected root %d/%d", root.covered, root.statements)
+	}
+	if len(root.children) != 1 || root.children[0].name != "github.com/org/repo" {
+		t.Fatal("single directories should be compacted")
+	}
+	repo := root.children[0]
+	if repo.path != "github.com/org/repo" || len(repo.children) != 2 {
+		t.Fatal("files without statements should be removed")
+	}
+	dir := repo.children[0]
+	if dir.name != "core" || dir.path != "github.com/org/repo/core" || !dir.dir || dir.coverage() != 0.5 {
+		t.Fatalf("children should be sorted from the largest, got %v", dir)
+	}
+	if file := dir.children[0]; file.path != "github.com/org/repo/core/a.go" || file.dir || file.covered != 3 {
+		t.Fatalf("unexpected file %v", file)
+	}
+}
+
+func TestSquarify(t *testing.T) {
+	values := []float64{6, 6, 4, 3, 2, 2, 1}
+	boxes := squarify(values, box{x: 10, y: 20, w: 600, h: 400})
+	for i, b := range boxes {
+		area := values[i] / 24 * 600 * 400
+		if math.Abs(b.w*b.h-area) > 1e-6 {
+			t.Fatalf("area of %d should be %f, got %f", i, area, b.w*b.h)
+		}
+		if b.x < 10-1e-6 || b.y < 20-1e-6 || b.x+b.w > 610+1e-6 || b.y+b.h > 420+1e-6 {
+			t.Fatalf("box %v should be in the bounds", b)
+		}
+	}
+}
+
+func TestCoverageHierarchy(t *testing.T) {
+	report := &core.Report{Coverages: []*core.CoverageReport{{Files: []*core.File{
+		{Name: "main.go", StatementHits: hitsOf(1, 0)},
+		{Name: "core/a.go", StatementHits: hitsOf(1, 1, 1, 0)},
+	}}}}
+	link := func(path string, dir bool) string {
+		if dir {
+			return "/code?path=" + path + "&a=b"
+		}
+		return "/code/" + path
+	}
+	buf := &bytes.Buffer{}
+	if err := NewCoverageHierarchy(report, link).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, s := range []string{
+		`xlink:href="/code?path=core&amp;a=b"`,
+		`xlink:href="/code/core/a.go"`,
+		`xlink:href="/code/main.go"`,
+		"<title>core/a.go: 75.0% (3/4)</title>",
+		coverageColor(0.5),
+	} {
+		if !strings.Contains(svg, s) {
+			t.Fatalf("chart should contain %s:\n%s", s, svg)
+		}
+	}
+	if err := validXML(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	buf = &bytes.Buffer{}
+	if err := NewCoverageHierarchy(&core.Report{}, nil).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No statements") {
+		t.Fatal("should render empty chart")
+	}
+}
@@ -0,0 +1,40 @@
+package charts
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/modules/charts/raster"
+)
+
+// PNG renders a SVG chart as a PNG bitmap, for platforms not showing SVG previews
+type PNG struct {
+	chart  core.Chart
+	width  int
+	height int
+}
+
+// NewPNG of the chart fitting in width and height, zero to keep its size or ratio
+func NewPNG(chart core.Chart, width, height int) *PNG {
+	return &PNG{
+		chart:  chart,
+		width:  width,
+		height: height,
+	}
+}
+
+// Render chart to writer
+func (c *PNG) Render(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	if err := c.chart.Render(buf); err != nil {
+		return err
+	}
+	img, err := raster.Rasterize(buf, c.width, c.height)
+	if err != nil {
+		return err
+	}
+	encoder := &png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, img)
+}
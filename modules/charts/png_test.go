@@ -0,0 +1,68 @@
+package charts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strconv"
+	"testing"
+
+	"github.com/covergates/covergates/core"
+)
+
+// svgSize of the root element
+func svgSize(t *testing.T, data []byte) (int, int) {
+	root := &struct {
+		Width  string `xml:"width,attr"`
+		Height string `xml:"height,attr"`
+	}{}
+	if err := xml.Unmarshal(data, root); err != nil {
+		t.Fatal(err)
+	}
+	width, err := strconv.Atoi(root.Width)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height, err := strconv.Atoi(root.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return width, height
+}
+
+func TestPNG(t *testing.T) {
+	badge := NewBadge(&core.Badge{Label: "coverage", Message: "80%", Color: core.BadgeGreen})
+	buf := &bytes.Buffer{}
+	if err := badge.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	width, height := svgSize(t, buf.Bytes())
+
+	buf.Reset()
+	if err := NewPNG(badge, width*2, 0).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != width*2 || size.Y != height*2 {
+		t.Fatalf("should scale to %dx%d, got %v", width*2, height*2, size)
+	}
+
+	items := make([]*core.CardItem, 0)
+	for _, metric := range core.DefaultCardMetrics {
+		items = append(items, &core.CardItem{Metric: metric, Label: string(metric), Value: "1"})
+	}
+	card := NewRepoCard(newTestCard(t, "org/repo", items...))
+	buf.Reset()
+	if err := NewPNG(card, 0, 0).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if img, err = png.Decode(buf); err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 495 || size.Y != 195 {
+		t.Fatalf("should keep the size of the card, got %v", size)
+	}
+}
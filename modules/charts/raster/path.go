@@ -0,0 +1,571 @@
+package raster
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type point struct {
+	x, y float64
+}
+
+// matrix is an affine transform, x' = a*x + c*y + e and y' = b*x + d*y + f
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns the transform applying n and then m
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// scale of lengths, such as stroke widths, in the transform
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+func translate(x, y float64) matrix {
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+func scale(x, y float64) matrix {
+	return matrix{x, 0, 0, y, 0, 0}
+}
+
+// parseTransform of the transform attribute
+func parseTransform(s string) (matrix, error) {
+	m := identity
+	s = strings.TrimSpace(s)
+	for s != "" {
+		open := strings.Index(s, "(")
+		end := strings.Index(s, ")")
+		if open < 0 || end < open {
+			return m, fmt.Errorf("invalid transform %q", s)
+		}
+		name := strings.TrimSpace(strings.Trim(s[:open], ", "))
+		args, err := parseNumbers(s[open+1 : end])
+		if err != nil {
+			return m, err
+		}
+		var t matrix
+		switch {
+		case name == "translate" && len(args) == 1:
+			t = translate(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			t = translate(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			t = scale(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			t = scale(args[0], args[1])
+		case name == "rotate" && (len(args) == 1 || len(args) == 3):
+			a := args[0] * math.Pi / 180
+			t = matrix{math.Cos(a), math.Sin(a), -math.Sin(a), math.Cos(a), 0, 0}
+			if len(args) == 3 {
+				t = translate(args[1], args[2]).mul(t).mul(translate(-args[1], -args[2]))
+			}
+		case name == "matrix" && len(args) == 6:
+			t = matrix{args[0], args[1], args[2], args[3], args[4], args[5]}
+		default:
+			return m, fmt.Errorf("transform %s not support", name)
+		}
+		m = m.mul(t)
+		s = strings.TrimSpace(s[end+1:])
+	}
+	return m, nil
+}
+
+// parseNumbers separated by spaces or commas
+func parseNumbers(s string) ([]float64, error) {
+	scanner := &numberScanner{s: s}
+	result := make([]float64, 0)
+	for scanner.more() {
+		n, err := scanner.number()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+type numberScanner struct {
+	s string
+	i int
+}
+
+func (scanner *numberScanner) skip() {
+	for scanner.i < len(scanner.s) {
+		switch scanner.s[scanner.i] {
+		case ' ', ',', '\t', '\n', '\r':
+			scanner.i++
+		default:
+			return
+		}
+	}
+}
+
+func (scanner *numberScanner) more() bool {
+	scanner.skip()
+	return scanner.i < len(scanner.s)
+}
+
+// number scans a number, where "1.5.5" is two numbers and "1-2" is two numbers
+func (scanner *numberScanner) number() (float64, error) {
+	scanner.skip()
+	start, i := scanner.i, scanner.i
+	s := scanner.s
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		i++
+	}
+	dot := false
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' && !dot) {
+		dot = dot || s[i] == '.'
+		i++
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		j := i + 1
+		if j < len(s) && (s[j] == '-' || s[j] == '+') {
+			j++
+		}
+		if j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			for i = j; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			}
+		}
+	}
+	n, err := strconv.ParseFloat(s[start:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number at %d of %q", start, s)
+	}
+	scanner.i = i
+	return n, nil
+}
+
+// flag of arcs, which may be written without separators
+func (scanner *numberScanner) flag() (bool, error) {
+	scanner.skip()
+	if scanner.i < len(scanner.s) {
+		switch scanner.s[scanner.i] {
+		case '0':
+			scanner.i++
+			return false, nil
+		case '1':
+			scanner.i++
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("invalid flag at %d of %q", scanner.i, scanner.s)
+}
+
+type segmentOp int
+
+const (
+	opMove segmentOp = iota
+	opLine
+	opQuad
+	opCube
+	opClose
+)
+
+// segment of a path, the last point is the end point
+type segment struct {
+	op  segmentOp
+	pts []point
+}
+
+type path []segment
+
+func (p *path) moveTo(a point) {
+	*p = append(*p, segment{opMove, []point{a}})
+}
+
+func (p *path) lineTo(a point) {
+	*p = append(*p, segment{opLine, []point{a}})
+}
+
+func (p *path) quadTo(a, b point) {
+	*p = append(*p, segment{opQuad, []point{a, b}})
+}
+
+func (p *path) cubeTo(a, b, c point) {
+	*p = append(*p, segment{opCube, []point{a, b, c}})
+}
+
+func (p *path) close() {
+	*p = append(*p, segment{op: opClose})
+}
+
+func (p path) transform(m matrix) path {
+	result := make(path, len(p))
+	for i, s := range p {
+		pts := make([]point, len(s.pts))
+		for j, pt := range s.pts {
+			pts[j] = m.apply(pt)
+		}
+		result[i] = segment{s.op, pts}
+	}
+	return result
+}
+
+// bounds of the control points
+func (p path) bounds() (point, point, bool) {
+	min := point{math.Inf(1), math.Inf(1)}
+	max := point{math.Inf(-1), math.Inf(-1)}
+	ok := false
+	for _, s := range p {
+		for _, pt := range s.pts {
+			min.x, min.y = math.Min(min.x, pt.x), math.Min(min.y, pt.y)
+			max.x, max.y = math.Max(max.x, pt.x), math.Max(max.y, pt.y)
+			ok = true
+		}
+	}
+	return min, max, ok
+}
+
+// polyline is a flattened sub path
+type polyline struct {
+	pts    []point
+	closed bool
+}
+
+// flatten curves of the path into polylines, where tolerance is in the path's unit
+func (p path) flatten(tolerance float64) []*polyline {
+	result := make([]*polyline, 0)
+	var current *polyline
+	pen := point{}
+	start := func() {
+		if current == nil {
+			current = &polyline{pts: []point{pen}}
+			result = append(result, current)
+		}
+	}
+	for _, s := range p {
+		switch s.op {
+		case opMove:
+			pen = s.pts[0]
+			current = &polyline{pts: []point{pen}}
+			result = append(result, current)
+		case opLine:
+			start()
+			pen = s.pts[0]
+			current.pts = append(current.pts, pen)
+		case opQuad, opCube:
+			start()
+			ctrl := append([]point{pen}, s.pts...)
+			n := curveSteps(ctrl, tolerance)
+			for i := 1; i <= n; i++ {
+				current.pts = append(current.pts, bezier(ctrl, float64(i)/float64(n)))
+			}
+			pen = s.pts[len(s.pts)-1]
+		case opClose:
+			if current != nil {
+				current.closed = true
+				pen = current.pts[0]
+				current = nil
+			}
+		}
+	}
+	return result
+}
+
+func curveSteps(ctrl []point, tolerance float64) int {
+	length := 0.0
+	for i := 1; i < len(ctrl); i++ {
+		length += math.Hypot(ctrl[i].x-ctrl[i-1].x, ctrl[i].y-ctrl[i-1].y)
+	}
+	n := int(math.Ceil(math.Sqrt(length / tolerance)))
+	if n < 1 {
+		return 1
+	}
+	if n > 100 {
+		return 100
+	}
+	return n
+}
+
+// bezier point of the control points at t with de Casteljau's algorithm
+func bezier(ctrl []point, t float64) point {
+	pts := make([]point, len(ctrl))
+	copy(pts, ctrl)
+	for n := len(pts) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			pts[i] = point{pts[i].x + (pts[i+1].x-pts[i].x)*t, pts[i].y + (pts[i+1].y-pts[i].y)*t}
+		}
+	}
+	return pts[0]
+}
+
+// parsePath data of the d attribute
+func parsePath(d string) (path, error) {
+	p := make(path, 0)
+	scanner := &numberScanner{s: d}
+	var pen, start, ctrl point
+	var command, last byte
+	for {
+		scanner.skip()
+		if scanner.i >= len(d) {
+			break
+		}
+		if c := d[scanner.i]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			command = c
+			scanner.i++
+		} else if command == 0 {
+			return nil, fmt.Errorf("invalid path data %q", d)
+		}
+		relative := command >= 'a'
+		abs := func(x, y float64) point {
+			if relative {
+				return point{pen.x + x, pen.y + y}
+			}
+			return point{x, y}
+		}
+		read := func(n int) ([]float64, error) {
+			args := make([]float64, n)
+			for i := range args {
+				v, err := scanner.number()
+				if err != nil {
+					return nil, err
+				}
+				args[i] = v
+			}
+			return args, nil
+		}
+		var args []float64
+		var err error
+		switch command {
+		case 'Z', 'z':
+			p.close()
+			pen = start
+		case 'M', 'm':
+			if args, err = read(2); err != nil {
+				return nil, err
+			}
+			pen = abs(args[0], args[1])
+			start = pen
+			p.moveTo(pen)
+			// following pairs are line-to commands
+			if relative {
+				command = 'l'
+			} else {
+				command = 'L'
+			}
+		case 'L', 'l':
+			if args, err = read(2); err != nil {
+				return nil, err
+			}
+			pen = abs(args[0], args[1])
+			p.lineTo(pen)
+		case 'H', 'h':
+			if args, err = read(1); err != nil {
+				return nil, err
+			}
+			if relative {
+				pen.x += args[0]
+			} else {
+				pen.x = args[0]
+			}
+			p.lineTo(pen)
+		case 'V', 'v':
+			if args, err = read(1); err != nil {
+				return nil, err
+			}
+			if relative {
+				pen.y += args[0]
+			} else {
+				pen.y = args[0]
+			}
+			p.lineTo(pen)
+		case 'C', 'c':
+			if args, err = read(6); err != nil {
+				return nil, err
+			}
+			a, b, c := abs(args[0], args[1]), abs(args[2], args[3]), abs(args[4], args[5])
+			p.cubeTo(a, b, c)
+			ctrl, pen = b, c
+		case 'S', 's':
+			if args, err = read(4); err != nil {
+				return nil, err
+			}
+			a := pen
+			if last == 'C' || last == 'S' {
+				a = point{2*pen.x - ctrl.x, 2*pen.y - ctrl.y}
+			}
+			b, c := abs(args[0], args[1]), abs(args[2], args[3])
+			p.cubeTo(a, b, c)
+			ctrl, pen = b, c
+		case 'Q', 'q':
+			if args, err = read(4); err != nil {
+				return nil, err
+			}
+			a, b := abs(args[0], args[1]), abs(args[2], args[3])
+			p.quadTo(a, b)
+			ctrl, pen = a, b
+		case 'T', 't':
+			if args, err = read(2); err != nil {
+				return nil, err
+			}
+			a := pen
+			if last == 'Q' || last == 'T' {
+				a = point{2*pen.x - ctrl.x, 2*pen.y - ctrl.y}
+			}
+			b := abs(args[0], args[1])
+			p.quadTo(a, b)
+			ctrl, pen = a, b
+		case 'A', 'a':
+			if args, err = read(3); err != nil {
+				return nil, err
+			}
+			large, err := scanner.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := scanner.flag()
+			if err != nil {
+				return nil, err
+			}
+			end, err := read(2)
+			if err != nil {
+				return nil, err
+			}
+			to := abs(end[0], end[1])
+			p.arcTo(pen, to, args[0], args[1], args[2], large, sweep)
+			pen = to
+		}
+		last = command &^ 0x20
+	}
+	return p, nil
+}
+
+// arcTo appends an elliptical arc as cubic curves,
+// see https://www.w3.org/TR/SVG/implnote.html#ArcImplementationNotes
+func (p *path) arcTo(from, to point, rx, ry, rotation float64, large, sweep bool) {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || from == to {
+		p.lineTo(to)
+		return
+	}
+	phi := rotation * math.Pi / 180
+	cos, sin := math.Cos(phi), math.Sin(phi)
+	dx, dy := (from.x-to.x)/2, (from.y-to.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1, cy1 := coef*rx*y1/ry, -coef*ry*x1/rx
+	center := point{
+		cos*cx1 - sin*cy1 + (from.x+to.x)/2,
+		sin*cx1 + cos*cy1 + (from.y+to.y)/2,
+	}
+	angle := func(ux, uy, vx, vy float64) float64 {
+		a := math.Atan2(uy, ux)
+		b := math.Atan2(vy, vx)
+		return b - a
+	}
+	theta := angle(1, 0, (x1-cx1)/rx, (y1-cy1)/ry)
+	delta := angle((x1-cx1)/rx, (y1-cy1)/ry, (-x1-cx1)/rx, (-y1-cy1)/ry)
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	n := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(n)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	ellipse := func(t float64) (point, point) {
+		x, y := rx*math.Cos(t), ry*math.Sin(t)
+		tx, ty := -rx*math.Sin(t), ry*math.Cos(t)
+		return point{center.x + cos*x - sin*y, center.y + sin*x + cos*y},
+			point{cos*tx - sin*ty, sin*tx + cos*ty}
+	}
+	for i := 0; i < n; i++ {
+		t0, t1 := theta+float64(i)*step, theta+float64(i+1)*step
+		a, da := ellipse(t0)
+		b, db := ellipse(t1)
+		if i == n-1 {
+			b = to
+		}
+		p.cubeTo(
+			point{a.x + k*da.x, a.y + k*da.y},
+			point{b.x - k*db.x, b.y - k*db.y},
+			b,
+		)
+	}
+}
+
+// ellipsePath of the center and radii
+func ellipsePath(cx, cy, rx, ry float64) path {
+	p := make(path, 0)
+	start := point{cx + rx, cy}
+	p.moveTo(start)
+	p.arcTo(start, point{cx - rx, cy}, rx, ry, 0, false, true)
+	p.arcTo(point{cx - rx, cy}, start, rx, ry, 0, false, true)
+	p.close()
+	return p
+}
+
+// rectPath with optional rounded corners
+func rectPath(x, y, w, h, rx, ry float64) path {
+	p := make(path, 0)
+	if rx <= 0 && ry <= 0 {
+		p.moveTo(point{x, y})
+		p.lineTo(point{x + w, y})
+		p.lineTo(point{x + w, y + h})
+		p.lineTo(point{x, y + h})
+		p.close()
+		return p
+	}
+	if rx <= 0 {
+		rx = ry
+	}
+	if ry <= 0 {
+		ry = rx
+	}
+	rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+	p.moveTo(point{x + rx, y})
+	p.lineTo(point{x + w - rx, y})
+	p.arcTo(point{x + w - rx, y}, point{x + w, y + ry}, rx, ry, 0, false, true)
+	p.lineTo(point{x + w, y + h - ry})
+	p.arcTo(point{x + w, y + h - ry}, point{x + w - rx, y + h}, rx, ry, 0, false, true)
+	p.lineTo(point{x + rx, y + h})
+	p.arcTo(point{x + rx, y + h}, point{x, y + h - ry}, rx, ry, 0, false, true)
+	p.lineTo(point{x, y + ry})
+	p.arcTo(point{x, y + ry}, point{x + rx, y}, rx, ry, 0, false, true)
+	p.close()
+	return p
+}
+
+// polyPath of points in polyline and polygon elements
+func polyPath(points []float64, closed bool) path {
+	p := make(path, 0)
+	for i := 0; i+1 < len(points); i += 2 {
+		pt := point{points[i], points[i+1]}
+		if i == 0 {
+			p.moveTo(pt)
+		} else {
+			p.lineTo(pt)
+		}
+	}
+	if closed && len(p) > 0 {
+		p.close()
+	}
+	return p
+}
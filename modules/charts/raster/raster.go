@@ -0,0 +1,671 @@
+// Package raster renders SVG charts to bitmaps in pure Go.
+//
+// It supports the subset of SVG drawn by the charts: shapes, paths, transforms,
+// nested viewports, class rules of style elements, clip paths, dashed strokes,
+// embedded images and texts, which are drawn with the embedded Go fonts.
+package raster
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	// decoders of embedded images
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/vector"
+)
+
+// MaxSize of each side of rasterized images
+const MaxSize = 4096
+
+// ErrTooLarge is returned if the image is larger than MaxSize
+var ErrTooLarge = errors.New("image is too large")
+
+// tolerance of flattening curves in pixels
+const tolerance = 0.2
+
+// presentation attributes which are styles of elements
+var presentations = []string{
+	"fill", "fill-opacity", "stroke", "stroke-opacity", "stroke-width", "stroke-linecap",
+	"stroke-dasharray", "stroke-dashoffset", "opacity", "font-size", "font-weight",
+	"text-anchor", "clip-path",
+}
+
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+func (n *node) attr(name string) string {
+	return n.attrs[name]
+}
+
+func (n *node) number(name string, reference float64) (float64, error) {
+	value, ok := n.attrs[name]
+	if !ok {
+		return 0, nil
+	}
+	return parseLength(value, reference)
+}
+
+func (n *node) numbers(reference float64, names ...string) ([]float64, error) {
+	result := make([]float64, len(names))
+	for i, name := range names {
+		v, err := n.number(name, reference)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+	return result, nil
+}
+
+// content of texts with collapsed white spaces
+func (n *node) content() string {
+	text := n.text
+	for _, child := range n.children {
+		text += " " + child.content()
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func parse(r io.Reader) (*node, error) {
+	decoder := xml.NewDecoder(r)
+	var root *node
+	stack := make([]*node, 0)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range t.Attr {
+				n.attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil || root.name != "svg" {
+		return nil, errors.New("not a svg document")
+	}
+	return root, nil
+}
+
+// Rasterize the SVG to fit in width and height, keeping its aspect ratio.
+// A zero side is scaled with the other side, and the size of the SVG is kept if both are zero.
+func Rasterize(r io.Reader, width, height int) (*image.RGBA, error) {
+	root, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	box, hasBox, err := viewBox(root)
+	if err != nil {
+		return nil, err
+	}
+	w, err := root.number("width", 0)
+	if err != nil {
+		return nil, err
+	}
+	h, err := root.number("height", 0)
+	if err != nil {
+		return nil, err
+	}
+	if w <= 0 || h <= 0 {
+		if !hasBox {
+			w, h = 300, 150
+		} else {
+			w, h = box[2], box[3]
+		}
+	}
+	s := 1.0
+	switch {
+	case width > 0 && height > 0:
+		s = math.Min(float64(width)/w, float64(height)/h)
+	case width > 0:
+		s = float64(width) / w
+	case height > 0:
+		s = float64(height) / h
+	}
+	size := image.Pt(int(math.Round(w*s)), int(math.Round(h*s)))
+	if size.X > MaxSize || size.Y > MaxSize {
+		return nil, ErrTooLarge
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, errors.New("image is empty")
+	}
+	rd := &renderer{
+		dst:   image.NewRGBA(image.Rectangle{Max: size}),
+		ids:   make(map[string]*node),
+		sheet: make(stylesheet),
+	}
+	rd.index(root)
+	m := scale(s, s)
+	if hasBox {
+		m = m.mul(fitViewBox(box, w, h, root.attr("preserveAspectRatio")))
+	}
+	if err := rd.children(root, m, defaultStyle(), point{w, h}, nil); err != nil {
+		return nil, err
+	}
+	return rd.dst, nil
+}
+
+func viewBox(n *node) ([]float64, bool, error) {
+	value, ok := n.attrs["viewBox"]
+	if !ok {
+		return nil, false, nil
+	}
+	box, err := parseNumbers(value)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(box) != 4 || box[2] <= 0 || box[3] <= 0 {
+		return nil, false, fmt.Errorf("invalid viewBox %q", value)
+	}
+	return box, true, nil
+}
+
+// fitViewBox transform of the view box in a viewport of width and height
+func fitViewBox(box []float64, width, height float64, preserve string) matrix {
+	sx, sy := width/box[2], height/box[3]
+	fields := strings.Fields(preserve)
+	align, slice := "xMidYMid", false
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if len(fields) > 1 {
+		slice = fields[1] == "slice"
+	}
+	if align == "none" {
+		return scale(sx, sy).mul(translate(-box[0], -box[1]))
+	}
+	s := math.Min(sx, sy)
+	if slice {
+		s = math.Max(sx, sy)
+	}
+	tx, ty := 0.0, 0.0
+	if strings.Contains(align, "xMid") {
+		tx = (width - box[2]*s) / 2
+	} else if strings.Contains(align, "xMax") {
+		tx = width - box[2]*s
+	}
+	if strings.Contains(align, "YMid") {
+		ty = (height - box[3]*s) / 2
+	} else if strings.Contains(align, "YMax") {
+		ty = height - box[3]*s
+	}
+	return translate(tx, ty).mul(scale(s, s)).mul(translate(-box[0], -box[1]))
+}
+
+type renderer struct {
+	dst   *image.RGBA
+	ids   map[string]*node
+	sheet stylesheet
+}
+
+// gradient paint of url(#id) references, approximated by the average of its stops
+func (rd *renderer) gradient(ref string) (paint, bool) {
+	id := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(ref), "url(#"), ")")
+	n, ok := rd.ids[id]
+	if !ok || (n.name != "linearGradient" && n.name != "radialGradient") {
+		return nil, false
+	}
+	var r, g, b, a float64
+	count := 0.0
+	for _, stop := range n.children {
+		if stop.name != "stop" {
+			continue
+		}
+		values := map[string]string{"stop-color": stop.attr("stop-color"), "stop-opacity": stop.attr("stop-opacity")}
+		for _, decl := range parseDeclarations(stop.attr("style")) {
+			values[decl[0]] = decl[1]
+		}
+		c, ok := parseColor(values["stop-color"])
+		if !ok || c == nil {
+			c = &color.NRGBA{A: 255}
+		}
+		opacity := parseOpacity(values["stop-opacity"], 1)
+		r, g, b, a = r+float64(c.R), g+float64(c.G), b+float64(c.B), a+float64(c.A)*opacity
+		count++
+	}
+	if count == 0 {
+		return nil, true
+	}
+	return &color.NRGBA{
+		uint8(math.Round(r / count)),
+		uint8(math.Round(g / count)),
+		uint8(math.Round(b / count)),
+		uint8(math.Round(a / count)),
+	}, true
+}
+
+// apply the property to the style, resolving gradient references of paints
+func (rd *renderer) apply(s *style, name, value string) {
+	if (name == "fill" || name == "stroke") && strings.HasPrefix(strings.TrimSpace(value), "url(") {
+		if c, ok := rd.gradient(value); ok {
+			if name == "fill" {
+				s.fill = c
+			} else {
+				s.stroke = c
+			}
+		}
+		return
+	}
+	s.apply(name, value)
+}
+
+// index elements by id and collect style sheets
+func (rd *renderer) index(n *node) {
+	if id := n.attr("id"); id != "" {
+		rd.ids[id] = n
+	}
+	if n.name == "style" {
+		rd.sheet.parse(n.text)
+	}
+	for _, child := range n.children {
+		rd.index(child)
+	}
+}
+
+// styleOf the element from presentation attributes, style sheets and then the style attribute
+func (rd *renderer) styleOf(n *node, parent *style) *style {
+	s := parent.inherit()
+	for _, name := range presentations {
+		if value, ok := n.attrs[name]; ok {
+			rd.apply(s, name, value)
+		}
+	}
+	for _, decl := range rd.sheet.rules(n.name, n.attr("class")) {
+		rd.apply(s, decl[0], decl[1])
+	}
+	for _, decl := range parseDeclarations(n.attr("style")) {
+		rd.apply(s, decl[0], decl[1])
+	}
+	return s
+}
+
+func (rd *renderer) children(n *node, m matrix, s *style, viewport point, clip *image.Alpha) error {
+	for _, child := range n.children {
+		if err := rd.draw(child, m, s, viewport, clip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rd *renderer) draw(n *node, m matrix, parent *style, viewport point, clip *image.Alpha) error {
+	switch n.name {
+	case "defs", "clipPath", "title", "desc", "style", "metadata", "symbol", "mask",
+		"linearGradient", "radialGradient":
+		return nil
+	}
+	s := rd.styleOf(n, parent)
+	if value := n.attr("transform"); value != "" {
+		t, err := parseTransform(value)
+		if err != nil {
+			return err
+		}
+		m = m.mul(t)
+	}
+	if s.clipPath != "" && s.clipPath != "none" {
+		mask, err := rd.clipMask(s.clipPath, m)
+		if err != nil {
+			return err
+		}
+		clip = intersect(clip, mask)
+	}
+	switch n.name {
+	case "svg":
+		return rd.viewport(n, m, s, viewport, clip)
+	case "g", "a", "switch":
+		return rd.children(n, m, s, viewport, clip)
+	case "text":
+		return rd.text(n, m, s, viewport, clip)
+	case "image":
+		return rd.image(n, m, viewport, clip)
+	}
+	p, ok, err := shapePath(n, viewport)
+	if err != nil || !ok {
+		return err
+	}
+	rd.paint(p, m, s, clip)
+	return nil
+}
+
+// viewport of nested svg elements, sized in percentages of the parent viewport
+func (rd *renderer) viewport(n *node, m matrix, s *style, parent point, clip *image.Alpha) error {
+	x, err := n.number("x", parent.x)
+	if err != nil {
+		return err
+	}
+	y, err := n.number("y", parent.y)
+	if err != nil {
+		return err
+	}
+	size := parent
+	if _, ok := n.attrs["width"]; ok {
+		if size.x, err = n.number("width", parent.x); err != nil {
+			return err
+		}
+	}
+	if _, ok := n.attrs["height"]; ok {
+		if size.y, err = n.number("height", parent.y); err != nil {
+			return err
+		}
+	}
+	m = m.mul(translate(x, y))
+	box, hasBox, err := viewBox(n)
+	if err != nil {
+		return err
+	}
+	if hasBox {
+		m = m.mul(fitViewBox(box, size.x, size.y, n.attr("preserveAspectRatio")))
+		size = point{box[2], box[3]}
+	}
+	return rd.children(n, m, s, size, clip)
+}
+
+// shapePath of basic shapes and paths, false if the element is not a shape
+func shapePath(n *node, viewport point) (path, bool, error) {
+	diagonal := math.Hypot(viewport.x, viewport.y) / math.Sqrt2
+	switch n.name {
+	case "rect":
+		xy, err := n.numbers(viewport.x, "x", "width", "rx")
+		if err != nil {
+			return nil, false, err
+		}
+		yh, err := n.numbers(viewport.y, "y", "height", "ry")
+		if err != nil {
+			return nil, false, err
+		}
+		if xy[1] <= 0 || yh[1] <= 0 {
+			return nil, false, nil
+		}
+		return rectPath(xy[0], yh[0], xy[1], yh[1], xy[2], yh[2]), true, nil
+	case "circle", "ellipse":
+		cx, err := n.number("cx", viewport.x)
+		if err != nil {
+			return nil, false, err
+		}
+		cy, err := n.number("cy", viewport.y)
+		if err != nil {
+			return nil, false, err
+		}
+		var rx, ry float64
+		if n.name == "circle" {
+			if rx, err = n.number("r", diagonal); err != nil {
+				return nil, false, err
+			}
+			ry = rx
+		} else {
+			if rx, err = n.number("rx", viewport.x); err != nil {
+				return nil, false, err
+			}
+			if ry, err = n.number("ry", viewport.y); err != nil {
+				return nil, false, err
+			}
+		}
+		if rx <= 0 || ry <= 0 {
+			return nil, false, nil
+		}
+		return ellipsePath(cx, cy, rx, ry), true, nil
+	case "line":
+		xs, err := n.numbers(viewport.x, "x1", "x2")
+		if err != nil {
+			return nil, false, err
+		}
+		ys, err := n.numbers(viewport.y, "y1", "y2")
+		if err != nil {
+			return nil, false, err
+		}
+		return polyPath([]float64{xs[0], ys[0], xs[1], ys[1]}, false), true, nil
+	case "polyline", "polygon":
+		points, err := parseNumbers(n.attr("points"))
+		if err != nil {
+			return nil, false, err
+		}
+		return polyPath(points, n.name == "polygon"), true, nil
+	case "path":
+		p, err := parsePath(n.attr("d"))
+		return p, err == nil, err
+	}
+	return nil, false, nil
+}
+
+// paint the fill and the stroke of the path in user space
+func (rd *renderer) paint(p path, m matrix, s *style, clip *image.Alpha) {
+	device := p.transform(m)
+	if s.fill != nil {
+		rd.fill(device, colorOf(s.fill, s.fillOpacity*s.opacity), clip)
+	}
+	if s.stroke != nil && s.strokeWidth > 0 {
+		k := m.scale()
+		lines := device.flatten(tolerance)
+		if len(s.dashArray) > 0 {
+			pattern := make([]float64, len(s.dashArray))
+			for i, d := range s.dashArray {
+				pattern[i] = d * k
+			}
+			lines = dash(lines, pattern, s.dashOffset*k)
+		}
+		rd.fill(outline(lines, s.strokeWidth*k, s.lineCap), colorOf(s.stroke, s.strokeOpacity*s.opacity), clip)
+	}
+}
+
+func colorOf(c paint, opacity float64) color.NRGBA {
+	result := *c
+	result.A = uint8(math.Round(float64(result.A) * opacity))
+	return result
+}
+
+// mask of the path in device space within the bounds of the image
+func (rd *renderer) mask(p path) (*image.Alpha, image.Rectangle) {
+	min, max, ok := p.bounds()
+	if !ok {
+		return nil, image.Rectangle{}
+	}
+	rect := image.Rect(
+		int(math.Floor(min.x))-1, int(math.Floor(min.y))-1,
+		int(math.Ceil(max.x))+1, int(math.Ceil(max.y))+1,
+	).Intersect(rd.dst.Bounds())
+	if rect.Empty() {
+		return nil, rect
+	}
+	z := vector.NewRasterizer(rect.Dx(), rect.Dy())
+	ox, oy := float64(rect.Min.X), float64(rect.Min.Y)
+	pt := func(p point) (float32, float32) {
+		return float32(p.x - ox), float32(p.y - oy)
+	}
+	open := false
+	for _, s := range p {
+		switch s.op {
+		case opMove:
+			if open {
+				z.ClosePath()
+			}
+			z.MoveTo(pt(s.pts[0]))
+			open = true
+		case opLine:
+			z.LineTo(pt(s.pts[0]))
+		case opQuad:
+			bx, by := pt(s.pts[0])
+			cx, cy := pt(s.pts[1])
+			z.QuadTo(bx, by, cx, cy)
+		case opCube:
+			bx, by := pt(s.pts[0])
+			cx, cy := pt(s.pts[1])
+			dx, dy := pt(s.pts[2])
+			z.CubeTo(bx, by, cx, cy, dx, dy)
+		case opClose:
+			z.ClosePath()
+			open = false
+		}
+	}
+	if open {
+		z.ClosePath()
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+	return mask, rect
+}
+
+// fill the path in device space with the color
+func (rd *renderer) fill(p path, c color.NRGBA, clip *image.Alpha) {
+	if c.A == 0 {
+		return
+	}
+	mask, rect := rd.mask(p)
+	if mask == nil {
+		return
+	}
+	if clip != nil {
+		for y := 0; y < rect.Dy(); y++ {
+			for x := 0; x < rect.Dx(); x++ {
+				i := mask.PixOffset(x, y)
+				a := clip.AlphaAt(x+rect.Min.X, y+rect.Min.Y).A
+				mask.Pix[i] = uint8(uint16(mask.Pix[i]) * uint16(a) / 255)
+			}
+		}
+	}
+	draw.DrawMask(rd.dst, rect, image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// clipMask of the clip path reference, such as url(#id), in the user space of the referencing element
+func (rd *renderer) clipMask(ref string, m matrix) (*image.Alpha, error) {
+	id := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(ref), "url(#"), ")")
+	n, ok := rd.ids[id]
+	if !ok || n.name != "clipPath" {
+		return nil, fmt.Errorf("clip path %q not found", ref)
+	}
+	result := image.NewAlpha(rd.dst.Bounds())
+	viewport := point{float64(rd.dst.Bounds().Dx()), float64(rd.dst.Bounds().Dy())}
+	for _, child := range n.children {
+		t := m
+		if value := child.attr("transform"); value != "" {
+			transform, err := parseTransform(value)
+			if err != nil {
+				return nil, err
+			}
+			t = t.mul(transform)
+		}
+		p, ok, err := shapePath(child, viewport)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		mask, rect := rd.mask(p.transform(t))
+		if mask != nil {
+			draw.DrawMask(result, rect, image.Opaque, image.Point{}, mask, image.Point{}, draw.Over)
+		}
+	}
+	return result, nil
+}
+
+func intersect(a, b *image.Alpha) *image.Alpha {
+	if a == nil {
+		return b
+	}
+	result := image.NewAlpha(a.Bounds())
+	for i := range result.Pix {
+		result.Pix[i] = uint8(uint16(a.Pix[i]) * uint16(b.Pix[i]) / 255)
+	}
+	return result
+}
+
+// text of the element with the embedded fonts
+func (rd *renderer) text(n *node, m matrix, s *style, viewport point, clip *image.Alpha) error {
+	content := n.content()
+	if content == "" || s.fill == nil {
+		return nil
+	}
+	x, err := n.number("x", viewport.x)
+	if err != nil {
+		return err
+	}
+	y, err := n.number("y", viewport.y)
+	if err != nil {
+		return err
+	}
+	p, advance, err := textPath(content, s.fontSize, s.fontWeight)
+	if err != nil {
+		return err
+	}
+	switch s.textAnchor {
+	case "middle":
+		x -= advance / 2
+	case "end":
+		x -= advance
+	}
+	rd.fill(p.transform(m.mul(translate(x, y))), colorOf(s.fill, s.fillOpacity*s.opacity), clip)
+	return nil
+}
+
+// image of data URLs, such as the embedded logo of cards
+func (rd *renderer) image(n *node, m matrix, viewport point, clip *image.Alpha) error {
+	href := n.attr("href")
+	comma := strings.Index(href, ",")
+	if !strings.HasPrefix(href, "data:") || comma < 0 || !strings.Contains(href[:comma], ";base64") {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(href[comma+1:]), ""))
+	if err != nil {
+		return err
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	xw, err := n.numbers(viewport.x, "x", "width")
+	if err != nil {
+		return err
+	}
+	yh, err := n.numbers(viewport.y, "y", "height")
+	if err != nil {
+		return err
+	}
+	bounds := src.Bounds()
+	if xw[1] <= 0 {
+		xw[1] = float64(bounds.Dx())
+	}
+	if yh[1] <= 0 {
+		yh[1] = float64(bounds.Dy())
+	}
+	t := m.mul(translate(xw[0], yh[0])).mul(scale(xw[1]/float64(bounds.Dx()), yh[1]/float64(bounds.Dy())))
+	t = t.mul(translate(-float64(bounds.Min.X), -float64(bounds.Min.Y)))
+	options := &xdraw.Options{}
+	if clip != nil {
+		options.DstMask = clip
+	}
+	xdraw.CatmullRom.Transform(rd.dst, f64.Aff3{t[0], t[2], t[4], t[1], t[3], t[5]}, src, bounds, xdraw.Over, options)
+	return nil
+}
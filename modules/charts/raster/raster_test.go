@@ -0,0 +1,210 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func rasterize(t *testing.T, svg string, width, height int) *image.RGBA {
+	img, err := Rasterize(strings.NewReader(svg), width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func near(a, b color.RGBA) bool {
+	diff := func(x, y uint8) bool {
+		return math.Abs(float64(x)-float64(y)) <= 2
+	}
+	return diff(a.R, b.R) && diff(a.G, b.G) && diff(a.B, b.B) && diff(a.A, b.A)
+}
+
+func TestParseNumbers(t *testing.T) {
+	numbers, err := parseNumbers("13.06.43-1e2,.5 -0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]float64{13.06, 0.43, -100, 0.5, -0.5}, numbers); diff != "" {
+		t.Fatal(diff)
+	}
+	if _, err := parseNumbers("1,x"); err == nil {
+		t.Fatal("should fail on invalid numbers")
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	m, err := parseTransform("translate(10, 20) scale(2) rotate(90)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := m.apply(point{1, 0})
+	if math.Abs(p.x-10) > 1e-9 || math.Abs(p.y-22) > 1e-9 {
+		t.Fatalf("unexpected point %v", p)
+	}
+	if math.Abs(m.scale()-2) > 1e-9 {
+		t.Fatalf("scale should be 2, got %f", m.scale())
+	}
+	if _, err := parseTransform("skewX(10)"); err == nil {
+		t.Fatal("should fail on unsupported transforms")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	p, err := parsePath("M1,2h3v4l-1-1Q0,0,1,1T2,2C0,0,1,1,2,2s1,1,2,2a.5.5,0,0,0,1,0z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := make([]segmentOp, 0)
+	for _, s := range p {
+		ops = append(ops, s.op)
+	}
+	expect := []segmentOp{opMove, opLine, opLine, opLine, opQuad, opQuad, opCube, opCube, opCube, opCube, opClose}
+	if diff := cmp.Diff(expect, ops); diff != "" {
+		t.Fatal(diff)
+	}
+	if end := p[3].pts[0]; end != (point{3, 5}) {
+		t.Fatalf("relative line should end at (3, 5), got %v", end)
+	}
+	if end := p[9].pts[2]; math.Abs(end.x-5) > 1e-9 || math.Abs(end.y-4) > 1e-9 {
+		t.Fatalf("arc should end at (5, 4), got %v", end)
+	}
+	if _, err := parsePath("1,2"); err == nil {
+		t.Fatal("should fail without command")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]color.NRGBA{
+		"#fff":                {255, 255, 255, 255},
+		"#16C36D":             {22, 195, 109, 255},
+		"rgb(22, 195, 109)":   {22, 195, 109, 255},
+		"hsl(120, 100%, 50%)": {0, 255, 0, 255},
+		"white":               {255, 255, 255, 255},
+	}
+	for value, expect := range tests {
+		c, ok := parseColor(value)
+		if !ok || c == nil || *c != expect {
+			t.Fatalf("%s should be %v, got %v", value, expect, c)
+		}
+	}
+	if c, ok := parseColor("none"); !ok || c != nil {
+		t.Fatal("none should be no paint")
+	}
+	if _, ok := parseColor("url(#a)"); ok {
+		t.Fatal("references are not colors")
+	}
+}
+
+func TestRasterize(t *testing.T) {
+	svg := `<?xml version="1.0"?>
+<svg width="100" height="50" xmlns="http://www.w3.org/2000/svg">
+	<style>.half { opacity: 0.5 }</style>
+	<clipPath id="c"><rect x="0" y="0" width="50" height="50" /></clipPath>
+	<linearGradient id="g"><stop offset="0" stop-color="#000" /><stop offset="1" stop-color="#fff" /></linearGradient>
+	<rect x="0" y="0" width="100" height="50" fill="#ff0000" clip-path="url(#c)" />
+	<g transform="translate(50, 0)">
+		<rect width="50" height="25" style="fill:rgb(0,0,255)" class="half" />
+		<rect y="25" width="50" height="25" fill="url(#g)" />
+	</g>
+</svg>`
+	img := rasterize(t, svg, 0, 0)
+	if img.Bounds() != image.Rect(0, 0, 100, 50) {
+		t.Fatalf("unexpected bounds %v", img.Bounds())
+	}
+	tests := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{10, 10, color.RGBA{255, 0, 0, 255}},
+		{75, 10, color.RGBA{0, 0, 128, 128}},
+		{75, 40, color.RGBA{128, 128, 128, 255}},
+	}
+	for _, test := range tests {
+		if c := img.RGBAAt(test.x, test.y); !near(c, test.color) {
+			t.Fatalf("(%d, %d) should be %v, got %v", test.x, test.y, test.color, c)
+		}
+	}
+
+	img = rasterize(t, svg, 200, 0)
+	if img.Bounds() != image.Rect(0, 0, 200, 100) {
+		t.Fatalf("should keep the ratio, got %v", img.Bounds())
+	}
+	if c := img.RGBAAt(150, 20); !near(c, color.RGBA{0, 0, 128, 128}) {
+		t.Fatalf("should be scaled, got %v", c)
+	}
+	img = rasterize(t, svg, 200, 20)
+	if img.Bounds() != image.Rect(0, 0, 40, 20) {
+		t.Fatalf("should fit in the size, got %v", img.Bounds())
+	}
+
+	if _, err := Rasterize(strings.NewReader(svg), MaxSize+1, 0); err != ErrTooLarge {
+		t.Fatal("should fail on too large images")
+	}
+	if _, err := Rasterize(strings.NewReader("<html></html>"), 0, 0); err == nil {
+		t.Fatal("should fail on other documents")
+	}
+}
+
+func TestRasterizeViewport(t *testing.T) {
+	svg := `<svg width="100" height="100">
+	<svg viewBox="0 0 10 10" width="50" height="50" x="50"><circle cx="5" cy="5" r="5" fill="#00ff00" /></svg>
+</svg>`
+	img := rasterize(t, svg, 0, 0)
+	if c := img.RGBAAt(75, 25); !near(c, color.RGBA{0, 255, 0, 255}) {
+		t.Fatalf("circle should be in the viewport, got %v", c)
+	}
+	if c := img.RGBAAt(25, 25); c.A != 0 {
+		t.Fatalf("should be transparent outside, got %v", c)
+	}
+	if c := img.RGBAAt(52, 2); c.A != 0 {
+		t.Fatalf("corners should not be filled, got %v", c)
+	}
+}
+
+func TestRasterizeStroke(t *testing.T) {
+	svg := `<svg width="100" height="20">
+	<line x1="0" y1="10" x2="100" y2="10" stroke="#000" stroke-width="4" stroke-dasharray="10 10" />
+</svg>`
+	img := rasterize(t, svg, 0, 0)
+	if c := img.RGBAAt(5, 10); c.A != 255 {
+		t.Fatalf("dash should be drawn, got %v", c)
+	}
+	if c := img.RGBAAt(15, 10); c.A != 0 {
+		t.Fatalf("gap should not be drawn, got %v", c)
+	}
+	if c := img.RGBAAt(5, 14); c.A != 0 {
+		t.Fatalf("stroke should be 4px wide, got %v", c)
+	}
+}
+
+func TestRasterizeText(t *testing.T) {
+	svg := `<svg width="100" height="30">
+	<text x="50" y="20" text-anchor="middle" style="font: 600 16px 'Segoe UI', sans-serif" fill="#000">Go</text>
+</svg>`
+	img := rasterize(t, svg, 0, 0)
+	left, right := 100, 0
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 100; x++ {
+			if img.RGBAAt(x, y).A > 128 {
+				if x < left {
+					left = x
+				}
+				if x > right {
+					right = x
+				}
+			}
+		}
+	}
+	if left >= right {
+		t.Fatal("text should be drawn")
+	}
+	if center := (left + right) / 2; center < 46 || center > 54 {
+		t.Fatalf("text should be centered, got %d", center)
+	}
+}
@@ -0,0 +1,160 @@
+package raster
+
+import (
+	"math"
+)
+
+// dash polylines with the pattern starting at offset, all in device units
+func dash(lines []*polyline, pattern []float64, offset float64) []*polyline {
+	total := 0.0
+	for _, d := range pattern {
+		if d < 0 {
+			return lines
+		}
+		total += d
+	}
+	if total <= 0 {
+		return lines
+	}
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern, pattern...)
+	}
+	result := make([]*polyline, 0)
+	for _, line := range lines {
+		pts := line.pts
+		if line.closed && len(pts) > 0 {
+			pts = append(append([]point{}, pts...), pts[0])
+		}
+		// find the dash at the start of the line
+		index, remain := 0, pattern[0]
+		pos := math.Mod(offset, total)
+		if pos < 0 {
+			pos += total
+		}
+		for pos > 0 {
+			if pos < remain {
+				remain -= pos
+				break
+			}
+			pos -= remain
+			index = (index + 1) % len(pattern)
+			remain = pattern[index]
+		}
+		var current *polyline
+		if index%2 == 0 && len(pts) > 0 {
+			current = &polyline{pts: []point{pts[0]}}
+			result = append(result, current)
+		}
+		for i := 1; i < len(pts); i++ {
+			a, b := pts[i-1], pts[i]
+			length := math.Hypot(b.x-a.x, b.y-a.y)
+			done := 0.0
+			for length-done > remain {
+				done += remain
+				t := done / length
+				p := point{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+				if index%2 == 0 {
+					current.pts = append(current.pts, p)
+					current = nil
+				} else {
+					current = &polyline{pts: []point{p}}
+					result = append(result, current)
+				}
+				index = (index + 1) % len(pattern)
+				remain = pattern[index]
+			}
+			remain -= length - done
+			if current != nil {
+				current.pts = append(current.pts, b)
+			}
+		}
+	}
+	return result
+}
+
+// outline of strokes as polygons with round joins, all in device units
+func outline(lines []*polyline, width float64, lineCap string) path {
+	p := make(path, 0)
+	r := width / 2
+	for _, line := range lines {
+		pts := line.pts
+		if line.closed && len(pts) > 1 {
+			pts = append(append([]point{}, pts...), pts[0])
+		}
+		pts = dedupe(pts)
+		if len(pts) == 1 {
+			if lineCap == "round" {
+				p = append(p, ellipsePath(pts[0].x, pts[0].y, r, r)...)
+			} else if lineCap == "square" {
+				p = append(p, rectPath(pts[0].x-r, pts[0].y-r, width, width, 0, 0)...)
+			}
+			continue
+		}
+		n := len(pts)
+		for i := 1; i < n; i++ {
+			a, b := pts[i-1], pts[i]
+			dx, dy := b.x-a.x, b.y-a.y
+			length := math.Hypot(dx, dy)
+			ux, uy := dx/length, dy/length
+			if lineCap == "square" && !line.closed {
+				if i == 1 {
+					a = point{a.x - ux*r, a.y - uy*r}
+				}
+				if i == n-1 {
+					b = point{b.x + ux*r, b.y + uy*r}
+				}
+			}
+			nx, ny := -uy*r, ux*r
+			p = append(p, polygon(
+				point{a.x + nx, a.y + ny},
+				point{b.x + nx, b.y + ny},
+				point{b.x - nx, b.y - ny},
+				point{a.x - nx, a.y - ny},
+			)...)
+		}
+		for i, pt := range pts {
+			end := i == 0 || i == n-1
+			if !end || line.closed || lineCap == "round" {
+				p = append(p, ellipsePath(pt.x, pt.y, r, r)...)
+			}
+		}
+	}
+	return p
+}
+
+// polygon of the points, oriented clockwise so overlapping parts add up
+func polygon(pts ...point) path {
+	area := 0.0
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].x*pts[j].y - pts[j].x*pts[i].y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	return polyPath(flat(pts), true)
+}
+
+func flat(pts []point) []float64 {
+	result := make([]float64, 0, 2*len(pts))
+	for _, pt := range pts {
+		result = append(result, pt.x, pt.y)
+	}
+	return result
+}
+
+func dedupe(pts []point) []point {
+	result := make([]point, 0, len(pts))
+	for _, pt := range pts {
+		if len(result) > 0 {
+			last := result[len(result)-1]
+			if math.Abs(last.x-pt.x) < 1e-9 && math.Abs(last.y-pt.y) < 1e-9 {
+				continue
+			}
+		}
+		result = append(result, pt)
+	}
+	return result
+}
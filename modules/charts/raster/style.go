@@ -0,0 +1,296 @@
+package raster
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// paint of fills and strokes, none if nil
+type paint *color.NRGBA
+
+// style is the inherited presentation of an element
+type style struct {
+	fill          paint
+	fillOpacity   float64
+	stroke        paint
+	strokeOpacity float64
+	strokeWidth   float64
+	lineCap       string
+	dashArray     []float64
+	dashOffset    float64
+	opacity       float64
+	fontSize      float64
+	fontWeight    int
+	textAnchor    string
+	clipPath      string
+}
+
+func defaultStyle() *style {
+	return &style{
+		fill:          &color.NRGBA{A: 255},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		strokeWidth:   1,
+		lineCap:       "butt",
+		opacity:       1,
+		fontSize:      16,
+		fontWeight:    400,
+		textAnchor:    "start",
+	}
+}
+
+// inherit the style for a child element, opacity and clip paths are applied to groups as a whole
+// and are approximated by multiplying opacities of children
+func (s *style) inherit() *style {
+	child := *s
+	child.clipPath = ""
+	return &child
+}
+
+// apply a property of the element, unknown properties and invalid values are ignored
+func (s *style) apply(name, value string) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+	switch name {
+	case "fill":
+		if c, ok := parseColor(value); ok {
+			s.fill = c
+		}
+	case "stroke":
+		if c, ok := parseColor(value); ok {
+			s.stroke = c
+		}
+	case "fill-opacity":
+		s.fillOpacity = parseOpacity(value, s.fillOpacity)
+	case "stroke-opacity":
+		s.strokeOpacity = parseOpacity(value, s.strokeOpacity)
+	case "opacity":
+		s.opacity *= parseOpacity(value, 1)
+	case "stroke-width":
+		if v, err := parseLength(value, 0); err == nil {
+			s.strokeWidth = v
+		}
+	case "stroke-linecap":
+		s.lineCap = value
+	case "stroke-dasharray":
+		s.dashArray = nil
+		if value != "none" {
+			if dashes, err := parseNumbers(strings.ReplaceAll(value, "px", "")); err == nil {
+				s.dashArray = dashes
+			}
+		}
+	case "stroke-dashoffset":
+		if v, err := parseLength(value, 0); err == nil {
+			s.dashOffset = v
+		}
+	case "font-size":
+		if v, err := parseLength(value, s.fontSize); err == nil {
+			s.fontSize = v
+		}
+	case "font-weight":
+		s.fontWeight = parseWeight(value, s.fontWeight)
+	case "font":
+		s.applyFont(value)
+	case "text-anchor":
+		s.textAnchor = value
+	case "clip-path":
+		s.clipPath = value
+	}
+}
+
+// applyFont shorthand, such as "600 24px 'Segoe UI', sans-serif"
+func (s *style) applyFont(value string) {
+	for _, field := range strings.Fields(value) {
+		if strings.HasSuffix(field, "px") || strings.HasSuffix(field, "pt") {
+			if v, err := parseLength(strings.SplitN(field, "/", 2)[0], s.fontSize); err == nil {
+				s.fontSize = v
+			}
+			return
+		}
+		s.fontWeight = parseWeight(field, s.fontWeight)
+	}
+}
+
+func parseWeight(value string, fallback int) int {
+	switch value {
+	case "normal":
+		return 400
+	case "bold", "bolder":
+		return 700
+	}
+	if v, err := strconv.Atoi(value); err == nil {
+		return v
+	}
+	return fallback
+}
+
+func parseOpacity(value string, fallback float64) float64 {
+	percent := strings.HasSuffix(value, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return fallback
+	}
+	if percent {
+		v /= 100
+	}
+	return math.Max(0, math.Min(1, v))
+}
+
+// parseLength in user units, percentages are of the reference
+func parseLength(value string, reference float64) (float64, error) {
+	value = strings.TrimSpace(value)
+	unit := 1.0
+	switch {
+	case strings.HasSuffix(value, "%"):
+		unit = reference / 100
+		value = strings.TrimSuffix(value, "%")
+	case strings.HasSuffix(value, "px"):
+		value = strings.TrimSuffix(value, "px")
+	case strings.HasSuffix(value, "pt"):
+		unit = 4.0 / 3.0
+		value = strings.TrimSuffix(value, "pt")
+	case strings.HasSuffix(value, "em"):
+		unit = 16
+		value = strings.TrimSuffix(value, "em")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid length %q", value)
+	}
+	return v * unit, nil
+}
+
+var namedColors = map[string]color.NRGBA{
+	"black":  {0, 0, 0, 255},
+	"white":  {255, 255, 255, 255},
+	"red":    {255, 0, 0, 255},
+	"green":  {0, 128, 0, 255},
+	"blue":   {0, 0, 255, 255},
+	"yellow": {255, 255, 0, 255},
+	"orange": {255, 165, 0, 255},
+	"gray":   {128, 128, 128, 255},
+	"grey":   {128, 128, 128, 255},
+	"silver": {192, 192, 192, 255},
+}
+
+// parseColor returns a nil paint for none, and false if the color is not supported
+func parseColor(value string) (paint, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "none" || value == "transparent":
+		return nil, true
+	case strings.HasPrefix(value, "#"):
+		hex := value[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 6 || err != nil {
+			return nil, false
+		}
+		return &color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+	case strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")"):
+		args := strings.Split(value[4:len(value)-1], ",")
+		if len(args) != 3 {
+			return nil, false
+		}
+		rgb := make([]uint8, 3)
+		for i, arg := range args {
+			arg = strings.TrimSpace(arg)
+			scale := 1.0
+			if strings.HasSuffix(arg, "%") {
+				scale, arg = 2.55, strings.TrimSuffix(arg, "%")
+			}
+			v, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return nil, false
+			}
+			rgb[i] = uint8(math.Max(0, math.Min(255, math.Round(v*scale))))
+		}
+		return &color.NRGBA{rgb[0], rgb[1], rgb[2], 255}, true
+	case strings.HasPrefix(value, "hsl(") && strings.HasSuffix(value, ")"):
+		args := strings.Split(value[4:len(value)-1], ",")
+		if len(args) != 3 {
+			return nil, false
+		}
+		h, err1 := strconv.ParseFloat(strings.TrimSpace(args[0]), 64)
+		s, err2 := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(args[1]), "%"), 64)
+		l, err3 := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(args[2]), "%"), 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, false
+		}
+		return hsl(h, s/100, l/100), true
+	}
+	if c, ok := namedColors[value]; ok {
+		return &c, true
+	}
+	return nil, false
+}
+
+func hsl(h, s, l float64) *color.NRGBA {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	q := l + s - l*s
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 1.0/2:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return &color.NRGBA{channel(h + 1.0/3), channel(h), channel(h - 1.0/3), 255}
+}
+
+// parseDeclarations of a style attribute or a CSS rule body
+func parseDeclarations(s string) [][2]string {
+	result := make([][2]string, 0)
+	for _, decl := range strings.Split(s, ";") {
+		kv := strings.SplitN(decl, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		result = append(result, [2]string{strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])})
+	}
+	return result
+}
+
+// stylesheet of class and element selectors in style elements
+type stylesheet map[string][][2]string
+
+func (sheet stylesheet) parse(css string) {
+	for {
+		open := strings.Index(css, "{")
+		end := strings.Index(css, "}")
+		if open < 0 || end < open {
+			return
+		}
+		decls := parseDeclarations(css[open+1 : end])
+		for _, selector := range strings.Split(css[:open], ",") {
+			selector = strings.TrimSpace(selector)
+			sheet[selector] = append(sheet[selector], decls...)
+		}
+		css = css[end+1:]
+	}
+}
+
+// rules matching the element name and classes
+func (sheet stylesheet) rules(name, class string) [][2]string {
+	result := append([][2]string{}, sheet[name]...)
+	for _, c := range strings.Fields(class) {
+		result = append(result, sheet["."+c]...)
+	}
+	return result
+}
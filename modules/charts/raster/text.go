@@ -0,0 +1,99 @@
+package raster
+
+import (
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// fonts are the embedded Go fonts, which replace font families of charts
+var fonts struct {
+	once    sync.Once
+	regular *sfnt.Font
+	bold    *sfnt.Font
+	err     error
+}
+
+func loadFonts() error {
+	fonts.once.Do(func() {
+		if fonts.regular, fonts.err = sfnt.Parse(goregular.TTF); fonts.err != nil {
+			return
+		}
+		fonts.bold, fonts.err = sfnt.Parse(gobold.TTF)
+	})
+	return fonts.err
+}
+
+// fontOf the weight, bold from semibold
+func fontOf(weight int) *sfnt.Font {
+	if weight >= 600 {
+		return fonts.bold
+	}
+	return fonts.regular
+}
+
+// textPath of the text with its baseline starting at the origin, and the advance width
+func textPath(text string, size float64, weight int) (path, float64, error) {
+	if err := loadFonts(); err != nil {
+		return nil, 0, err
+	}
+	f := fontOf(weight)
+	buf := &sfnt.Buffer{}
+	// glyphs are loaded at a fixed size and scaled, which keeps outlines unhinted
+	ppem := fixed.I(64)
+	unit := size / 64
+	p := make(path, 0)
+	x := 0.0
+	var prev sfnt.GlyphIndex
+	for i, r := range text {
+		index, err := f.GlyphIndex(buf, r)
+		if err != nil || index == 0 {
+			index, _ = f.GlyphIndex(buf, '?')
+		}
+		if i > 0 {
+			if kern, err := f.Kern(buf, prev, index, ppem, font.HintingNone); err == nil {
+				x += fixedFloat(kern) * unit
+			}
+		}
+		segments, err := f.LoadGlyph(buf, index, ppem, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		pt := func(v fixed.Point26_6) point {
+			return point{x + fixedFloat(v.X)*unit, fixedFloat(v.Y) * unit}
+		}
+		for _, s := range segments {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				if len(p) > 0 {
+					p.close()
+				}
+				p.moveTo(pt(s.Args[0]))
+			case sfnt.SegmentOpLineTo:
+				p.lineTo(pt(s.Args[0]))
+			case sfnt.SegmentOpQuadTo:
+				p.quadTo(pt(s.Args[0]), pt(s.Args[1]))
+			case sfnt.SegmentOpCubeTo:
+				p.cubeTo(pt(s.Args[0]), pt(s.Args[1]), pt(s.Args[2]))
+			}
+		}
+		if len(segments) > 0 {
+			p.close()
+		}
+		advance, err := f.GlyphAdvance(buf, index, ppem, font.HintingNone)
+		if err != nil {
+			return nil, 0, err
+		}
+		x += fixedFloat(advance) * unit
+		prev = index
+	}
+	return p, x, nil
+}
+
+func fixedFloat(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
@@ -0,0 +1,182 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	svg "github.com/ajstarks/svgo"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	trendWidth  = 600
+	trendHeight = 300
+	trendLeft   = 50
+	trendRight  = 20
+	trendTop    = 40
+	trendBottom = 40
+	// trendDrop of the overall coverage between consecutive reports is annotated
+	trendDrop = 0.05
+	trendFont = `font-family="'Segoe UI', sans-serif"`
+	// trendOverall is the series of all report types
+	trendOverall = "overall"
+)
+
+var trendColors = []string{"#00838F", "#EF6C00", "#6A1B9A", "#2E7D32", "#AD1457", "#1565C0"}
+
+// CoverageTrend renders coverage of reports over time as a line chart,
+// with a series for each report type and dots on large drops
+type CoverageTrend struct {
+	reports []*core.Report
+}
+
+type trendSeries struct {
+	name   string
+	values []float64
+	// valid if the report has coverage of the series
+	valid []bool
+}
+
+// NewCoverageTrend of the reports, which are sorted by their created time
+func NewCoverageTrend(reports []*core.Report) *CoverageTrend {
+	sorted := make([]*core.Report, len(reports))
+	copy(sorted, reports)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	return &CoverageTrend{reports: sorted}
+}
+
+// reportCoverage uses summaries of coverages, which are available without files
+func reportCoverage(report *core.Report) float64 {
+	if len(report.Coverages) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, coverage := range report.Coverages {
+		sum += coverage.StatementCoverage
+	}
+	return sum / float64(len(report.Coverages))
+}
+
+func (c *CoverageTrend) series() []*trendSeries {
+	types := make([]core.ReportType, 0)
+	seen := make(map[core.ReportType]bool)
+	for _, report := range c.reports {
+		for _, coverage := range report.Coverages {
+			if !seen[coverage.Type] {
+				seen[coverage.Type] = true
+				types = append(types, coverage.Type)
+			}
+		}
+	}
+	n := len(c.reports)
+	overall := &trendSeries{name: trendOverall, values: make([]float64, n), valid: make([]bool, n)}
+	for i, report := range c.reports {
+		overall.values[i] = reportCoverage(report)
+		overall.valid[i] = true
+	}
+	if len(types) <= 1 {
+		if len(types) == 1 {
+			overall.name = string(types[0])
+		}
+		return []*trendSeries{overall}
+	}
+	result := []*trendSeries{overall}
+	for _, t := range types {
+		s := &trendSeries{name: string(t), values: make([]float64, n), valid: make([]bool, n)}
+		for i, report := range c.reports {
+			if coverage, ok := report.Find(t); ok {
+				s.values[i] = coverage.StatementCoverage
+				s.valid[i] = true
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func (c *CoverageTrend) x(i int) int {
+	width := trendWidth - trendLeft - trendRight
+	n := len(c.reports)
+	if n <= 1 {
+		return trendLeft + width/2
+	}
+	first := c.reports[0].CreatedAt
+	span := c.reports[n-1].CreatedAt.Sub(first)
+	if span <= 0 {
+		return trendLeft + width*i/(n-1)
+	}
+	return trendLeft + int(float64(width)*float64(c.reports[i].CreatedAt.Sub(first))/float64(span))
+}
+
+func trendY(coverage float64) int {
+	height := trendHeight - trendTop - trendBottom
+	return trendTop + int(float64(height)*(1-coverage))
+}
+
+// Render chart to writer
+func (c *CoverageTrend) Render(w io.Writer) error {
+	canvas := svg.New(w)
+	canvas.Start(trendWidth, trendHeight)
+	canvas.Rect(0, 0, trendWidth, trendHeight, `fill="#ffffff"`)
+	for _, percentage := range []int{0, 25, 50, 75, 100} {
+		y := trendY(float64(percentage) / 100)
+		canvas.Line(trendLeft, y, trendWidth-trendRight, y, `stroke="#e0e0e0"`)
+		canvas.Text(trendLeft-8, y+4, fmt.Sprintf("%d%%", percentage),
+			`text-anchor="end"`, `font-size="11"`, `fill="#757575"`, trendFont)
+	}
+	if len(c.reports) == 0 {
+		canvas.Text(trendWidth/2, trendHeight/2, "No reports",
+			`text-anchor="middle"`, `font-size="16"`, `fill="#757575"`, trendFont)
+		canvas.End()
+		return nil
+	}
+
+	series := c.series()
+	legendX := trendLeft
+	for i, s := range series {
+		color := trendColors[i%len(trendColors)]
+		canvas.Rect(legendX, 14, 12, 12, fmt.Sprintf(`fill="%s"`, color))
+		canvas.Text(legendX+16, 24, s.name, `font-size="12"`, `fill="#424242"`, trendFont)
+		legendX += 16 + int(textWidth(s.name, 12)) + 16
+
+		xs, ys := make([]int, 0), make([]int, 0)
+		for j, value := range s.values {
+			if s.valid[j] {
+				xs = append(xs, c.x(j))
+				ys = append(ys, trendY(value))
+			}
+		}
+		style := fmt.Sprintf(`fill="none" stroke="%s" stroke-width="2"`, color)
+		if len(xs) == 1 {
+			canvas.Circle(xs[0], ys[0], 3, fmt.Sprintf(`fill="%s"`, color))
+		} else {
+			canvas.Polyline(xs, ys, style)
+		}
+	}
+
+	overall := series[0]
+	for i := 1; i < len(c.reports); i++ {
+		drop := overall.values[i-1] - overall.values[i]
+		if drop <= trendDrop {
+			continue
+		}
+		canvas.Group()
+		canvas.Title(fmt.Sprintf("%s dropped %.1f%%", c.reports[i].Commit, drop*100))
+		canvas.Circle(c.x(i), trendY(overall.values[i]), 5, `fill="#C3161B"`, `stroke="#ffffff"`, `stroke-width="1.5"`)
+		canvas.Gend()
+	}
+
+	first, last := c.reports[0].CreatedAt, c.reports[len(c.reports)-1].CreatedAt
+	bottom := trendHeight - trendBottom + 20
+	canvas.Text(trendLeft, bottom, first.Format("2006-01-02"), `font-size="11"`, `fill="#757575"`, trendFont)
+	if len(c.reports) > 1 {
+		canvas.Text(trendWidth-trendRight, bottom, last.Format("2006-01-02"),
+			`text-anchor="end"`, `font-size="11"`, `fill="#757575"`, trendFont)
+	}
+	canvas.End()
+	return nil
+}
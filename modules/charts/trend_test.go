@@ -0,0 +1,66 @@
+package charts
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/covergates/covergates/core"
+)
+
+func TestCoverageTrend(t *testing.T) {
+	now := time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)
+	report := func(commit string, days int, coverages ...*core.CoverageReport) *core.Report {
+		return &core.Report{Commit: commit, CreatedAt: now.AddDate(0, 0, days), Coverages: coverages}
+	}
+	goCoverage := func(coverage float64) *core.CoverageReport {
+		return &core.CoverageReport{Type: core.ReportGo, StatementCoverage: coverage}
+	}
+	perlCoverage := func(coverage float64) *core.CoverageReport {
+		return &core.CoverageReport{Type: core.ReportPerl, StatementCoverage: coverage}
+	}
+
+	buf := &bytes.Buffer{}
+	// reports from the latest as listed
+	trend := NewCoverageTrend([]*core.Report{
+		report("c4", 3, goCoverage(0.6), perlCoverage(0.6)),
+		report("c3", 2, goCoverage(0.6)),
+		report("c2", 1, goCoverage(0.9), perlCoverage(0.7)),
+		report("c1", 0, goCoverage(0.8), perlCoverage(0.7)),
+	})
+	if trend.reports[0].Commit != "c1" {
+		t.Fatal("reports should be sorted by time")
+	}
+	if err := trend.Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	for _, s := range []string{">overall</text>", ">go</text>", ">perl</text>", "c3 dropped 20.0%", "2020-09-01", "2020-09-04"} {
+		if !strings.Contains(svg, s) {
+			t.Fatalf("trend should contain %s:\n%s", s, svg)
+		}
+	}
+	if strings.Contains(svg, "c4 dropped") {
+		t.Fatal("small changes should not be annotated")
+	}
+	if err := validXML(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	buf = &bytes.Buffer{}
+	if err := NewCoverageTrend([]*core.Report{report("c1", 0, goCoverage(0.8))}).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), ">overall</text>") || !strings.Contains(buf.String(), ">go</text>") {
+		t.Fatal("single type should be the only series")
+	}
+
+	buf = &bytes.Buffer{}
+	if err := NewCoverageTrend(nil).Render(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "No reports") {
+		t.Fatal("should render empty trend")
+	}
+}
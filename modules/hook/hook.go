@@ -91,32 +91,31 @@ func (s *Service) resolvePullRequest(ctx context.Context, repo *core.Repo, hook
 		changes = []*core.FileChange{}
 	}
 
-	report, err := s.ReportStore.Find(&core.Report{
-		ReportID: repo.ReportID,
-		Commit:   hook.Commit,
-	})
-	if err != nil {
-		report, err = s.ReportStore.Find(&core.Report{
-			ReportID:  repo.ReportID,
-			Reference: hook.Source,
+	// merge the source report to the target atomically
+	return s.ReportStore.Transaction(func(store core.ReportStore) error {
+		report, err := store.Find(&core.Report{
+			ReportID: repo.ReportID,
+			Commit:   hook.Commit,
 		})
 		if err != nil {
-			return err
+			report, err = store.Find(&core.Report{
+				ReportID:  repo.ReportID,
+				Reference: hook.Source,
+			})
+			if err != nil {
+				return err
+			}
 		}
-	}
-	if previous, err := s.ReportStore.Find(&core.Report{
-		ReportID:  repo.ReportID,
-		Reference: hook.Target,
-	}); err == nil {
-		if report, err = s.ReportService.MergeReport(previous, report, changes); err != nil {
-			return err
+		if previous, err := store.Find(&core.Report{
+			ReportID:  repo.ReportID,
+			Reference: hook.Target,
+		}); err == nil {
+			if report, err = s.ReportService.MergeReport(previous, report, changes); err != nil {
+				return err
+			}
 		}
-	}
-	report.Reference = hook.Target
-	report.Commit = hook.Commit
-	// TODO: need to use transition to prevent error occur in the middle
-	if err := s.ReportStore.Upload(report); err != nil {
-		return err
-	}
-	return nil
+		report.Reference = hook.Target
+		report.Commit = hook.Commit
+		return store.Upload(report)
+	})
 }
@@ -0,0 +1,161 @@
+package hook_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/models"
+	"github.com/covergates/covergates/modules/hook"
+	"github.com/covergates/covergates/modules/report"
+)
+
+var db *gorm.DB
+
+func TestMain(m *testing.M) {
+	cwd, _ := os.Getwd()
+	tempFile, err := ioutil.TempFile(cwd, "*.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_ = tempFile.Close()
+	db, err = gorm.Open(sqlite.Open(tempFile.Name()), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := models.NewDatabaseService(db, nil).Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	exit := m.Run()
+	_ = os.Remove(tempFile.Name())
+	os.Exit(exit)
+}
+
+// injectFailure to updates of the table until restored
+func injectFailure(t *testing.T, table string) (restore func()) {
+	name := "test:inject_failure"
+	if err := db.Callback().Update().Before("gorm:update").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table == table {
+			_ = tx.AddError(errors.New("injected failure"))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		_ = db.Callback().Update().Remove(name)
+	}
+}
+
+func coverage(files ...string) *core.CoverageReport {
+	c := &core.CoverageReport{Type: core.ReportGo}
+	for _, file := range files {
+		c.Files = append(c.Files, &core.File{Name: file, StatementCoverage: 1})
+	}
+	return c
+}
+
+func TestResolvePullRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := &core.Repo{
+		Name:      "repo",
+		NameSpace: "org",
+		SCM:       core.Github,
+		ReportID:  "TestResolvePullRequest",
+	}
+	user := &core.User{Login: "user"}
+	event := &core.PullRequestHook{
+		Number: 1,
+		Commit: "merged",
+		Source: "feature",
+		Target: "master",
+	}
+
+	reportStore := &models.ReportStore{DB: models.NewDatabaseService(db, nil)}
+	for _, r := range []*core.Report{
+		{
+			ReportID:  repo.ReportID,
+			Commit:    "base",
+			Reference: "master",
+			Coverages: []*core.CoverageReport{coverage("a.go")},
+		},
+		{
+			ReportID:  repo.ReportID,
+			Commit:    "merged",
+			Reference: "feature",
+			Coverages: []*core.CoverageReport{coverage("b.go")},
+		},
+	} {
+		if err := reportStore.Upload(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(
+		&core.RepoSetting{MergePullRequest: true}, nil,
+	)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).AnyTimes().Return(user, nil)
+	scmService := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	pullRequests := mock.NewMockPullRequestService(ctrl)
+	scmService.EXPECT().Client(gomock.Eq(repo.SCM)).AnyTimes().Return(client, nil)
+	client.EXPECT().PullRequests().AnyTimes().Return(pullRequests)
+	pullRequests.EXPECT().ListChanges(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(1),
+	).AnyTimes().Return([]*core.FileChange{}, nil)
+
+	service := &hook.Service{
+		SCM:           scmService,
+		RepoStore:     repoStore,
+		ReportStore:   reportStore,
+		ReportService: &report.Service{},
+	}
+	ctx := context.Background()
+	target := &core.Report{ReportID: repo.ReportID, Reference: "master"}
+
+	// fail after the target reference is appended to the merged report
+	restore := injectFailure(t, "coverages")
+	if err := service.Resolve(ctx, repo, event); err == nil {
+		t.Fatal("should fail with injected failure")
+	}
+	restore()
+	master, err := reportStore.Find(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Commit != "base" {
+		t.Fatal("should rollback reference of the target branch")
+	}
+	merged, err := reportStore.Find(&core.Report{ReportID: repo.ReportID, Commit: "merged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Coverages[0].Files) != 1 {
+		t.Fatal("should rollback merged coverage")
+	}
+
+	if err := service.Resolve(ctx, repo, event); err != nil {
+		t.Fatal(err)
+	}
+	master, err = reportStore.Find(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if master.Commit != "merged" || len(master.Coverages[0].Files) != 2 {
+		t.Fatalf("should merge to target, got %s with %d files", master.Commit, len(master.Coverages[0].Files))
+	}
+}
@@ -3,6 +3,8 @@ package oauth
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-oauth2/oauth2/v4"
 	"github.com/go-oauth2/oauth2/v4/errors"
@@ -23,36 +25,41 @@ const (
 	nameKey key = "TokenName"
 )
 
+// lastUsedPrecision limits how often the token last used time is written to storage
+const lastUsedPrecision = time.Minute
+
 // ErrTokenOwnerNotFound in context
 var ErrTokenOwnerNotFound = errors.New("requires token owner")
 
 // ErrPermissionDeny of an operation
 var ErrPermissionDeny = errors.New("no permission")
 
+// ErrInvalidScope of a token
+var ErrInvalidScope = errors.New("invalid token scope")
+
 // Service of OAuth
 type Service struct {
 	config     *config.Config
 	server     *server.Server
 	oauthStore core.OAuthStore
-	userStore  core.UserStore
 }
 
 // NewService for OAuth
 func NewService(
 	config *config.Config,
 	oauthStore core.OAuthStore,
-	userStore core.UserStore,
 ) *Service {
 	return &Service{
 		config:     config,
 		server:     newOAuthServer(config, oauthStore),
 		oauthStore: oauthStore,
-		userStore:  userStore,
 	}
 }
 
 func newOAuthServer(config *config.Config, oauthStore core.OAuthStore) *server.Server {
 	manager := manage.NewDefaultManager()
+	// client tokens never expire unless the expiration is given on creation
+	manager.SetClientTokenCfg(&manage.Config{})
 	manager.MustTokenStorage(
 		&tokenStore{
 			store: oauthStore,
@@ -88,15 +95,35 @@ func newOAuthServer(config *config.Config, oauthStore core.OAuthStore) *server.S
 	return srv
 }
 
-// CreateToken with name. Required context with User.
-func (s *Service) CreateToken(ctx context.Context, name string) (*core.OAuthToken, error) {
+// CreateToken with name and scopes. Required context with User.
+// The token never expires if expiresIn is zero.
+func (s *Service) CreateToken(
+	ctx context.Context,
+	name string,
+	scopes []core.TokenScope,
+	expiresIn time.Duration,
+) (*core.OAuthToken, error) {
+	user, ok := getUser(ctx)
+	if !ok {
+		return nil, ErrTokenOwnerNotFound
+	}
+	scope := make([]string, len(scopes))
+	for i, s := range scopes {
+		if !core.IsValidTokenScope(s) {
+			return nil, ErrInvalidScope
+		}
+		scope[i] = string(s)
+	}
 	ctx = withTokenName(ctx, name)
 	token, err := s.server.Manager.GenerateAccessToken(
 		ctx,
 		oauth2.ClientCredentials,
 		&oauth2.TokenGenerateRequest{
-			ClientID:     s.config.Server.OAuthClient,
-			ClientSecret: s.config.Server.Secret,
+			ClientID:       s.config.Server.OAuthClient,
+			ClientSecret:   s.config.Server.Secret,
+			UserID:         user.Login,
+			Scope:          strings.Join(scope, " "),
+			AccessTokenExp: expiresIn,
 		},
 	)
 	if err != nil {
@@ -126,14 +153,23 @@ func (s *Service) ListTokens(ctx context.Context) ([]*core.OAuthToken, error) {
 	return s.oauthStore.List(user)
 }
 
-// Validate Bearer token and return token's User if exist
-func (s *Service) Validate(r *http.Request) (*core.User, error) {
-	token, err := s.server.ValidationBearerToken(r)
+// Validate Bearer token and return the token with its owner if exist
+func (s *Service) Validate(r *http.Request) (*core.OAuthToken, error) {
+	info, err := s.server.ValidationBearerToken(r)
 	if err != nil {
 		return nil, err
 	}
-	userID := token.GetUserID()
-	return s.userStore.FindByLogin(userID)
+	token, err := s.oauthStore.Find(&core.OAuthToken{Access: info.GetAccess()})
+	if err != nil {
+		return nil, err
+	}
+	if now := time.Now(); now.Sub(token.LastUsedAt) > lastUsedPrecision {
+		token.LastUsedAt = now
+		if err := s.oauthStore.UpdateLastUsed(token); err != nil {
+			log.Warningln(err)
+		}
+	}
+	return token, nil
 }
 
 // WithUser context
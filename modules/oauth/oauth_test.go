@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/drone/go-scm/scm"
 	"github.com/google/go-cmp/cmp"
@@ -41,14 +42,14 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	dbService := models.NewDatabaseService(x)
+	dbService := models.NewDatabaseService(x, nil)
 	userStore = &models.UserStore{DB: dbService}
 	oauthStore := &models.OAuthStore{DB: dbService}
 	_ = dbService.Migrate()
 	mockUsers(userStore)
 
 	conf = &config.Config{}
-	service = oauth.NewService(conf, oauthStore, userStore)
+	service = oauth.NewService(conf, oauthStore)
 	exit := m.Run()
 	_ = os.Remove(tempFile.Name())
 	os.Exit(exit)
@@ -56,7 +57,7 @@ func TestMain(m *testing.M) {
 
 func TestCreate(t *testing.T) {
 	ctx := context.Background()
-	if _, err := service.CreateToken(ctx, ""); err == nil || err != oauth.ErrTokenOwnerNotFound {
+	if _, err := service.CreateToken(ctx, "", nil, 0); err == nil || err != oauth.ErrTokenOwnerNotFound {
 		t.Fatal("should check token owner in contex")
 	}
 
@@ -67,7 +68,7 @@ func TestCreate(t *testing.T) {
 
 	ctx = service.WithUser(ctx, user)
 
-	token, err := service.CreateToken(ctx, "test_token")
+	token, err := service.CreateToken(ctx, "test_token", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -89,7 +90,7 @@ func TestValidate(t *testing.T) {
 
 	ctx := service.WithUser(context.Background(), user)
 
-	token, err := service.CreateToken(ctx, "validate_token")
+	token, err := service.CreateToken(ctx, "validate_token", nil, 0)
 
 	if err != nil {
 		t.Fatal(err)
@@ -101,13 +102,16 @@ func TestValidate(t *testing.T) {
 		nil,
 	)
 
-	tokenOwner, err := service.Validate(request)
+	validToken, err := service.Validate(request)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if diff := cmp.Diff(user, tokenOwner); diff != "" {
+	if diff := cmp.Diff(user, validToken.Owner); diff != "" {
 		t.Fatal(diff)
 	}
+	if validToken.LastUsedAt.IsZero() {
+		t.Fatal("should update last used time")
+	}
 
 	request, _ = http.NewRequest(
 		"GET",
@@ -127,7 +131,7 @@ func TestDelete(t *testing.T) {
 
 	ctx := service.WithUser(context.Background(), user)
 
-	token, err := service.CreateToken(ctx, "delete_token")
+	token, err := service.CreateToken(ctx, "delete_token", nil, 0)
 
 	if err != nil {
 		t.Fatal(err)
@@ -146,7 +150,7 @@ func TestDelete(t *testing.T) {
 		t.Fatal("should return err for deleted token")
 	}
 
-	token, err = service.CreateToken(ctx, "user1_token")
+	token, err = service.CreateToken(ctx, "user1_token", nil, 0)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -171,7 +175,7 @@ func TestList(t *testing.T) {
 
 	ctx := service.WithUser(context.Background(), user)
 	for _, name := range names {
-		_, _ = service.CreateToken(ctx, name)
+		_, _ = service.CreateToken(ctx, name, nil, 0)
 	}
 
 	tokens, err := service.ListTokens(ctx)
@@ -187,3 +191,80 @@ func TestList(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestValidateOwner(t *testing.T) {
+	user, err := userStore.FindByLogin("user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := service.WithUser(context.Background(), user)
+	token, err := service.CreateToken(ctx, "owner_token", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, _ := http.NewRequest("GET", conf.Server.Addr, nil)
+	request.Header.Set("Authorization", "Bearer "+token.Access)
+	validToken, err := service.Validate(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(user, validToken.Owner); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestScopedToken(t *testing.T) {
+	user, err := userStore.FindByLogin("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := service.WithUser(context.Background(), user)
+
+	if _, err := service.CreateToken(
+		ctx, "invalid", []core.TokenScope{"invalid"}, 0,
+	); err != oauth.ErrInvalidScope {
+		t.Fatal("should check scopes")
+	}
+
+	scopes := []core.TokenScope{core.ScopeReportWrite, core.ScopeRepoAdmin}
+	token, err := service.CreateToken(ctx, "scoped_token", scopes, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(scopes, token.Scopes); diff != "" {
+		t.Fatal(diff)
+	}
+	if !token.Expires.IsZero() {
+		t.Fatal("token should never expire")
+	}
+	if !token.HasScope(core.ScopeRepoRead) || token.HasScope(core.ScopeUser) {
+		t.Fatal("unexpected token scopes")
+	}
+}
+
+func TestExpiredToken(t *testing.T) {
+	user, err := userStore.FindByLogin("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := service.WithUser(context.Background(), user)
+
+	token, err := service.CreateToken(ctx, "expiring_token", nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Expires.IsZero() || token.Expires.Before(time.Now()) {
+		t.Fatal("token should expire in an hour")
+	}
+
+	token, err = service.CreateToken(ctx, "expired_token", nil, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	request, _ := http.NewRequest("GET", conf.Server.Addr, nil)
+	request.Header.Set("Authorization", "Bearer "+token.Access)
+	if _, err := service.Validate(request); err == nil {
+		t.Fatal("should not validate expired token")
+	}
+}
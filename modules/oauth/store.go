@@ -3,6 +3,7 @@ package oauth
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/go-oauth2/oauth2/v4"
 	"github.com/go-oauth2/oauth2/v4/models"
@@ -29,24 +30,33 @@ func (s *tokenStore) Create(ctx context.Context, info oauth2.TokenInfo) error {
 		name = user.Login
 	}
 	token := &core.OAuthToken{
-		Name:  name,
-		Owner: user,
-		Data:  data,
+		Name:   name,
+		Owner:  user,
+		Data:   data,
+		Scopes: core.ParseTokenScopes(info.GetScope()),
 	}
 	if code := info.GetCode(); code != "" {
 		token.Code = code
-		token.Expires = info.GetCodeCreateAt().Add(info.GetCodeExpiresIn())
+		token.Expires = expires(info.GetCodeCreateAt(), info.GetCodeExpiresIn())
 	} else {
 		token.Access = info.GetAccess()
-		token.Expires = info.GetAccessCreateAt().Add(info.GetAccessExpiresIn())
+		token.Expires = expires(info.GetAccessCreateAt(), info.GetAccessExpiresIn())
 		if refresh := info.GetRefresh(); refresh != "" {
 			token.Refresh = refresh
-			token.Expires = info.GetRefreshCreateAt().Add(info.GetRefreshExpiresIn())
+			token.Expires = expires(info.GetRefreshCreateAt(), info.GetRefreshExpiresIn())
 		}
 	}
 	return s.store.Create(token)
 }
 
+// expires returns zero time if the token never expires
+func expires(createAt time.Time, expiresIn time.Duration) time.Time {
+	if expiresIn == 0 {
+		return time.Time{}
+	}
+	return createAt.Add(expiresIn)
+}
+
 func (s *tokenStore) RemoveByCode(ctx context.Context, code string) error {
 	return s.store.Delete(&core.OAuthToken{Code: code})
 }
@@ -0,0 +1,112 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/drone/go-login/login"
+	"golang.org/x/oauth2"
+)
+
+const (
+	stateCookie = "_oidc_state"
+	nonceCookie = "_oidc_nonce"
+)
+
+var errInvalidState = errors.New("invalid or missing state")
+
+type middleware struct {
+	service *Service
+}
+
+// Handler runs h at the completion of the authorization code flow
+func (m *middleware) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if erro := r.FormValue("error"); erro != "" {
+			ctx = login.WithError(ctx, errors.New(erro))
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		conf, err := m.service.oauth2Config(ctx)
+		if err != nil {
+			ctx = login.WithError(ctx, err)
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		code := r.FormValue("code")
+		if code == "" {
+			state, err := newState()
+			if err != nil {
+				ctx = login.WithError(ctx, err)
+				h.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			nonce, err := newState()
+			if err != nil {
+				ctx = login.WithError(ctx, err)
+				h.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			for _, cookie := range [][2]string{{stateCookie, state}, {nonceCookie, nonce}} {
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookie[0],
+					Value:    cookie[1],
+					MaxAge:   600,
+					HttpOnly: true,
+				})
+			}
+			http.Redirect(w, r, conf.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)), 303)
+			return
+		}
+
+		cookie, err := r.Cookie(stateCookie)
+		http.SetCookie(w, &http.Cookie{Name: stateCookie, MaxAge: -1})
+		if err != nil || cookie.Value == "" || cookie.Value != r.FormValue("state") {
+			ctx = login.WithError(ctx, errInvalidState)
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		nonce := ""
+		if cookie, err := r.Cookie(nonceCookie); err == nil {
+			nonce = cookie.Value
+		}
+		http.SetCookie(w, &http.Cookie{Name: nonceCookie, MaxAge: -1})
+
+		token, err := conf.Exchange(
+			context.WithValue(ctx, oauth2.HTTPClient, m.service.client),
+			code,
+		)
+		if err != nil {
+			ctx = login.WithError(ctx, err)
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		rawIDToken, _ := token.Extra("id_token").(string)
+		subject, err := m.service.verify(ctx, rawIDToken, nonce)
+		if err != nil {
+			ctx = login.WithError(ctx, err)
+			h.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		ctx = withSubject(ctx, subject)
+		ctx = login.WithToken(ctx, &login.Token{
+			Access:  token.AccessToken,
+			Refresh: token.RefreshToken,
+			Expires: token.Expiry,
+		})
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newState() (string, error) {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
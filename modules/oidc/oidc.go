@@ -0,0 +1,173 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/drone/go-login/login"
+	"golang.org/x/oauth2"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	modlogin "github.com/covergates/covergates/modules/login"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var (
+	// ErrSubjectNotFound if the provider does not claim the subject
+	ErrSubjectNotFound = errors.New("subject claim not found")
+	// ErrLoginNotFound if the provider does not claim the login
+	ErrLoginNotFound = errors.New("login claim not found")
+)
+
+// metadata of the provider from discovery document
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Service of generic OpenID Connect provider
+type Service struct {
+	config *config.Config
+	client *http.Client
+
+	mutex    sync.Mutex
+	metadata *metadata
+	jwks     []*jwk
+}
+
+// NewService of OpenID Connect
+func NewService(config *config.Config) *Service {
+	return &Service{
+		config: config,
+		client: modlogin.BasicClient(config.OIDC.SkipVerity),
+	}
+}
+
+// Enabled if discovery URL and client ID are configured
+func (s *Service) Enabled() bool {
+	return s.config.OIDC.DiscoveryURL != "" && s.config.OIDC.ClientID != ""
+}
+
+// Handler of the authorization code flow
+func (s *Service) Handler() login.Middleware {
+	return &middleware{service: s}
+}
+
+// User claimed by the access token from userinfo endpoint.
+// The context should come from the login handler, which verified the ID token of the same subject.
+func (s *Service) User(ctx context.Context, token *core.Token) (*core.OIDCUser, error) {
+	subject := subjectFrom(ctx)
+	if subject == "" {
+		return nil, ErrIDTokenNotVerified
+	}
+	meta, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", meta.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Accept", "application/json")
+	claims := make(map[string]interface{})
+	if err := s.getJSON(req, &claims); err != nil {
+		return nil, err
+	}
+	conf := s.config.OIDC
+	user := &core.OIDCUser{
+		Subject: claim(claims, "sub"),
+		Login:   claim(claims, conf.LoginClaim),
+		Email:   claim(claims, conf.EmailClaim),
+		Avatar:  claim(claims, conf.AvatarClaim),
+	}
+	if user.Subject == "" {
+		return nil, ErrSubjectNotFound
+	}
+	if user.Subject != subject {
+		return nil, ErrSubjectMismatch
+	}
+	if user.Login == "" {
+		return nil, ErrLoginNotFound
+	}
+	return user, nil
+}
+
+func (s *Service) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	meta, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     s.config.OIDC.ClientID,
+		ClientSecret: s.config.OIDC.ClientSecret,
+		Scopes:       s.config.OIDC.Scope,
+		RedirectURL:  s.config.Server.URL() + "/login/oidc",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  meta.AuthorizationEndpoint,
+			TokenURL: meta.TokenEndpoint,
+		},
+	}, nil
+}
+
+// discover provider metadata, which is cached once it is found
+func (s *Service) discover(ctx context.Context) (*metadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.metadata != nil {
+		return s.metadata, nil
+	}
+	url := s.config.OIDC.DiscoveryURL
+	if !strings.HasSuffix(url, discoveryPath) {
+		url = strings.TrimRight(url, "/") + discoveryPath
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	meta := &metadata{}
+	if err := s.getJSON(req, meta); err != nil {
+		return nil, err
+	}
+	if meta.Issuer == "" || meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" ||
+		meta.UserinfoEndpoint == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("incomplete OpenID Connect discovery document %s", url)
+	}
+	s.metadata = meta
+	return meta, nil
+}
+
+func (s *Service) getJSON(req *http.Request, v interface{}) error {
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		return fmt.Errorf("%s %s: %s", req.Method, req.URL, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+func claim(claims map[string]interface{}, name string) string {
+	if name == "" {
+		return ""
+	}
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
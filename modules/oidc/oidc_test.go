@@ -0,0 +1,308 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/drone/go-login/login"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/modules/oidc"
+)
+
+// provider is an in-process OpenID Connect stand-in,
+// which issues access token "access" and a signed ID token for code "code".
+type provider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+	// nonce of the next ID token
+	nonce string
+	// idClaims override claims of the next ID token
+	idClaims map[string]interface{}
+}
+
+func newProvider(t *testing.T, claims map[string]interface{}) *provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	p := &provider{Server: httptest.NewServer(mux), key: key}
+	writeJSON := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"userinfo_endpoint":      p.URL + "/userinfo",
+			"jwks_uri":               p.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		encode := func(n *big.Int) string {
+			return base64.RawURLEncoding.EncodeToString(n.Bytes())
+		}
+		writeJSON(w, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key",
+				"use": "sig",
+				"n":   encode(key.N),
+				"e":   encode(big.NewInt(int64(key.E))),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" {
+			w.WriteHeader(400)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"id_token":      p.idToken(t),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(401)
+			return
+		}
+		writeJSON(w, claims)
+	})
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *provider) idToken(t *testing.T) string {
+	claims := jwt.MapClaims{
+		"iss":   p.URL,
+		"aud":   "client",
+		"sub":   "1234",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": p.nonce,
+	}
+	for k, v := range p.idClaims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key"
+	raw, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// login through the authorization code flow,
+// it returns the context of the completed flow
+func (p *provider) login(t *testing.T, service *oidc.Service) context.Context {
+	var ctx context.Context
+	h := service.Handler().Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/login/oidc", nil))
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	p.nonce = query.Get("nonce")
+	req := httptest.NewRequest("GET", "/login/oidc?code=code&state="+query.Get("state"), nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	return ctx
+}
+
+func newConfig(provider string) *config.Config {
+	return &config.Config{
+		Server: config.Server{Addr: "http://localhost:8080"},
+		OIDC: config.OIDC{
+			DiscoveryURL: provider,
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Scope:        []string{"openid", "profile", "email"},
+			LoginClaim:   "preferred_username",
+			EmailClaim:   "email",
+			AvatarClaim:  "picture",
+		},
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	if oidc.NewService(&config.Config{}).Enabled() {
+		t.Fatal("should be disabled without configuration")
+	}
+	if !oidc.NewService(newConfig("http://sso")).Enabled() {
+		t.Fatal("should be enabled")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	provider := newProvider(t, nil)
+	service := oidc.NewService(newConfig(provider.URL))
+	var token *login.Token
+	var loginErr error
+	h := service.Handler().Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = login.TokenFrom(r.Context())
+		loginErr = login.ErrorFrom(r.Context())
+	}))
+
+	// redirect to authorization endpoint
+	req := httptest.NewRequest("GET", "/login/oidc", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != 303 {
+		t.Fatalf("expect redirect, got %d", w.Code)
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	if location.Path != "/authorize" || query.Get("client_id") != "client" {
+		t.Fatalf("unexpected redirect %s", location)
+	}
+	if query.Get("redirect_uri") != "http://localhost:8080/login/oidc" {
+		t.Fatalf("unexpected redirect URI %s", query.Get("redirect_uri"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 2 || cookies[0].Value != query.Get("state") || cookies[1].Value != query.Get("nonce") {
+		t.Fatal("should keep state and nonce in cookie")
+	}
+	provider.nonce = query.Get("nonce")
+
+	// callback with invalid state
+	req = httptest.NewRequest("GET", "/login/oidc?code=code&state=invalid", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if loginErr == nil || token != nil {
+		t.Fatal("should reject invalid state")
+	}
+
+	// callback with authorization code
+	req = httptest.NewRequest("GET", "/login/oidc?code=code&state="+query.Get("state"), nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if loginErr != nil {
+		t.Fatal(loginErr)
+	}
+	if token == nil || token.Access != "access" || token.Refresh != "refresh" {
+		t.Fatalf("unexpected token %v", token)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider := newProvider(t, nil)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]map[string]interface{}{
+		"issuer":   {"iss": "http://other"},
+		"audience": {"aud": []string{"other"}},
+		"expired":  {"exp": time.Now().Add(-time.Hour).Unix()},
+		"nonce":    {"nonce": "replayed"},
+	}
+	for name, claims := range tests {
+		provider.idClaims = claims
+		ctx := provider.login(t, oidc.NewService(newConfig(provider.URL)))
+		if login.ErrorFrom(ctx) == nil {
+			t.Fatalf("should reject ID token with invalid %s", name)
+		}
+	}
+
+	provider.idClaims = map[string]interface{}{"aud": []string{"other", "client"}}
+	ctx := provider.login(t, oidc.NewService(newConfig(provider.URL)))
+	if err := login.ErrorFrom(ctx); err != nil {
+		t.Fatalf("should accept audience list containing the client: %s", err)
+	}
+
+	provider.idClaims = nil
+	key := provider.key
+	provider.key = other
+	ctx = provider.login(t, oidc.NewService(newConfig(provider.URL)))
+	provider.key = key
+	if login.ErrorFrom(ctx) == nil {
+		t.Fatal("should reject ID token signed by other key")
+	}
+}
+
+func TestUser(t *testing.T) {
+	provider := newProvider(t, map[string]interface{}{
+		"sub":                "1234",
+		"preferred_username": "octocat",
+		"email":              "octocat@example.com",
+		"picture":            "http://avatar",
+		"nickname":           "cat",
+	})
+
+	service := oidc.NewService(newConfig(provider.URL + "/.well-known/openid-configuration"))
+	if _, err := service.User(context.Background(), &core.Token{Token: "access"}); err != oidc.ErrIDTokenNotVerified {
+		t.Fatal("should require verified ID token")
+	}
+	ctx := provider.login(t, service)
+	user, err := service.User(ctx, &core.Token{Token: "access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &core.OIDCUser{
+		Subject: "1234",
+		Login:   "octocat",
+		Email:   "octocat@example.com",
+		Avatar:  "http://avatar",
+	}
+	if diff := cmp.Diff(expect, user); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err := service.User(ctx, &core.Token{Token: "invalid"}); err == nil {
+		t.Fatal("should fail with invalid token")
+	}
+
+	conf := newConfig(provider.URL)
+	conf.OIDC.LoginClaim = "nickname"
+	user, err = oidc.NewService(conf).User(ctx, &core.Token{Token: "access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != "cat" {
+		t.Fatal("should map login claim")
+	}
+
+	conf.OIDC.LoginClaim = "login"
+	if _, err := oidc.NewService(conf).User(ctx, &core.Token{Token: "access"}); err != oidc.ErrLoginNotFound {
+		t.Fatal("should require login claim")
+	}
+
+	provider.idClaims = map[string]interface{}{"sub": "5678"}
+	ctx = provider.login(t, service)
+	if _, err := service.User(ctx, &core.Token{Token: "access"}); err != oidc.ErrSubjectMismatch {
+		t.Fatal("should reject userinfo of other subject")
+	}
+}
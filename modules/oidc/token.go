@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// ErrIDTokenNotVerified if the user is requested without a verified ID token
+	ErrIDTokenNotVerified = errors.New("ID token is not verified")
+	// ErrSubjectMismatch if the userinfo is not about the subject of the ID token
+	ErrSubjectMismatch = errors.New("subject of userinfo does not match ID token")
+
+	errIDTokenNotFound = errors.New("ID token not found in token response")
+	errKeyNotFound     = errors.New("signing key of ID token not found")
+)
+
+// signingMethods allowed for ID tokens, which are asymmetric only
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type subjectKey struct{}
+
+// withSubject of the verified ID token
+func withSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+func subjectFrom(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey{}).(string)
+	return subject
+}
+
+// jwk is a public key of the provider
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %q not support", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("key type %q not support", k.Kty)
+}
+
+// keys of the provider, which are fetched again if refresh or not cached
+func (s *Service) keys(ctx context.Context, refresh bool) ([]*jwk, error) {
+	meta, err := s.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.jwks != nil && !refresh {
+		return s.jwks, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	set := &struct {
+		Keys []*jwk `json:"keys"`
+	}{}
+	if err := s.getJSON(req, set); err != nil {
+		return nil, err
+	}
+	s.jwks = set.Keys
+	return s.jwks, nil
+}
+
+// findKey to verify the token, keys are fetched again once if not found for rotation
+func (s *Service) findKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, refresh := range []bool{false, true} {
+		keys, err := s.keys(ctx, refresh)
+		if err != nil {
+			return nil, err
+		}
+		found := make([]*jwk, 0, 1)
+		for _, key := range keys {
+			if key.Use != "" && key.Use != "sig" {
+				continue
+			}
+			if kid != "" && key.Kid != kid {
+				continue
+			}
+			found = append(found, key)
+		}
+		// a token without key ID is only verified if the provider has a single key
+		if len(found) == 1 {
+			return found[0].publicKey()
+		}
+	}
+	return nil, errKeyNotFound
+}
+
+// verify the ID token signature with keys of the provider,
+// and its issuer, audience, expiry and nonce. It returns the subject of the token.
+func (s *Service) verify(ctx context.Context, raw, nonce string) (string, error) {
+	if raw == "" {
+		return "", errIDTokenNotFound
+	}
+	meta, err := s.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	parser := &jwt.Parser{ValidMethods: signingMethods}
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		return s.findKey(ctx, token)
+	}); err != nil {
+		return "", err
+	}
+	if claim(claims, "iss") != meta.Issuer {
+		return "", fmt.Errorf("unexpected issuer %q of ID token", claim(claims, "iss"))
+	}
+	if !hasAudience(claims, s.config.OIDC.ClientID) {
+		return "", errors.New("ID token is not issued to the client")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return "", errors.New("ID token without expiry")
+	}
+	if nonce == "" || claim(claims, "nonce") != nonce {
+		return "", errors.New("invalid nonce of ID token")
+	}
+	subject := claim(claims, "sub")
+	if subject == "" {
+		return "", ErrSubjectNotFound
+	}
+	return subject, nil
+}
+
+// hasAudience if aud is the client, or a list containing the client
+func hasAudience(claims jwt.MapClaims, client string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == client
+	case []interface{}:
+		for _, v := range aud {
+			if v == client {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package permission
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+)
+
+// Service decides roles of users to repositories
+type Service struct {
+	scmService      core.SCMService
+	repoStore       core.RepoStore
+	permissionStore core.PermissionStore
+	ttl             time.Duration
+}
+
+// NewService of permission
+func NewService(
+	config *config.Config,
+	scmService core.SCMService,
+	repoStore core.RepoStore,
+	permissionStore core.PermissionStore,
+) *Service {
+	return &Service{
+		scmService:      scmService,
+		repoStore:       repoStore,
+		permissionStore: permissionStore,
+		ttl:             config.Server.PermissionTTL,
+	}
+}
+
+// Role of the user to the repository. Everyone is a viewer of public repositories
+// and the user who activated the repository is always an admin.
+// Otherwise, the local override takes precedence over the role synchronized from SCM,
+// which is refreshed once it is older than the TTL. The previous role is kept if SCM fails.
+func (s *Service) Role(ctx context.Context, repo *core.Repo, user *core.User) (core.Role, error) {
+	base := core.RoleNone
+	if !repo.Private {
+		base = core.RoleViewer
+	}
+	if user == nil || user.Login == "" {
+		return base, nil
+	}
+	if creator, err := s.repoStore.Creator(repo); err == nil && creator.Login == user.Login {
+		return core.RoleAdmin, nil
+	}
+	permission, err := s.permissionStore.Find(repo, user)
+	if err != nil {
+		permission = &core.RepoPermission{Repo: repo, Login: user.Login}
+	}
+	if permission.Override == core.RoleNone && time.Since(permission.SyncedAt) > s.ttl {
+		role, err := s.synchronize(ctx, repo, user)
+		if err != nil && permission.SyncedAt.IsZero() {
+			return core.RoleNone, err
+		}
+		// keep the previous role until SCM answers again
+		if err == nil {
+			permission.Role = role
+		}
+	}
+	return higher(base, permission.Effective()), nil
+}
+
+func (s *Service) synchronize(ctx context.Context, repo *core.Repo, user *core.User) (core.Role, error) {
+	client, err := s.scmService.Client(repo.SCM)
+	if err != nil {
+		return core.RoleNone, err
+	}
+	role := core.RoleNone
+	perm, err := client.Repositories().Permission(ctx, user, repo.FullName())
+	if err == nil {
+		role = perm.Role()
+	} else if err != core.ErrNoRepoAccess {
+		log.Warningf("fail to synchronize permission of %s to %s: %v", user.Login, repo.FullName(), err)
+		return core.RoleNone, err
+	}
+	if err := s.permissionStore.Synchronize(user, []*core.RepoPermission{
+		{Repo: repo, Role: role},
+	}); err != nil {
+		return core.RoleNone, err
+	}
+	return role, nil
+}
+
+func higher(role, other core.Role) core.Role {
+	if role.Includes(other) {
+		return role
+	}
+	return other
+}
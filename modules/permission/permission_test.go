@@ -0,0 +1,142 @@
+package permission_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/permission"
+)
+
+func TestRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := &config.Config{
+		Server: config.Server{PermissionTTL: time.Minute},
+	}
+	user := &core.User{Login: "user"}
+	repo := &core.Repo{
+		NameSpace: "org",
+		Name:      "repo",
+		SCM:       core.Github,
+		Private:   true,
+	}
+
+	mockSCM := mock.NewMockSCMService(ctrl)
+	mockClient := mock.NewMockClient(ctrl)
+	mockGitRepoService := mock.NewMockGitRepoService(ctrl)
+	mockRepoStore := mock.NewMockRepoStore(ctrl)
+	mockPermissionStore := mock.NewMockPermissionStore(ctrl)
+	mockSCM.EXPECT().Client(gomock.Eq(repo.SCM)).AnyTimes().Return(mockClient, nil)
+	mockClient.EXPECT().Repositories().AnyTimes().Return(mockGitRepoService)
+
+	service := permission.NewService(config, mockSCM, mockRepoStore, mockPermissionStore)
+	ctx := context.Background()
+
+	t.Run("anonymous", func(t *testing.T) {
+		role, err := service.Role(ctx, repo, nil)
+		if err != nil || role != core.RoleNone {
+			t.Fatal("anonymous should not access private repository")
+		}
+		public := &core.Repo{Name: "public"}
+		role, err = service.Role(ctx, public, nil)
+		if err != nil || role != core.RoleViewer {
+			t.Fatal("anonymous should view public repository")
+		}
+	})
+
+	t.Run("creator", func(t *testing.T) {
+		mockRepoStore.EXPECT().Creator(gomock.Eq(repo)).Return(user, nil)
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleAdmin {
+			t.Fatal("creator should be admin")
+		}
+	})
+
+	mockRepoStore.EXPECT().Creator(gomock.Eq(repo)).AnyTimes().Return(nil, gorm.ErrRecordNotFound)
+
+	t.Run("cached", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(&core.RepoPermission{
+			Role:     core.RoleUploader,
+			SyncedAt: time.Now(),
+		}, nil)
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleUploader {
+			t.Fatal("should use cached role")
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(&core.RepoPermission{
+			Role:     core.RoleAdmin,
+			Override: core.RoleViewer,
+		}, nil)
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleViewer {
+			t.Fatal("local override should take precedence")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(&core.RepoPermission{
+			Role:     core.RoleViewer,
+			SyncedAt: time.Now().Add(-time.Hour),
+		}, nil)
+		mockGitRepoService.EXPECT().Permission(
+			gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()),
+		).Return(&core.RepoPerm{Pull: true, Push: true, Admin: true}, nil)
+		mockPermissionStore.EXPECT().Synchronize(gomock.Eq(user), gomock.Eq([]*core.RepoPermission{
+			{Repo: repo, Role: core.RoleAdmin},
+		})).Return(nil)
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleAdmin {
+			t.Fatal("should refresh role from SCM")
+		}
+	})
+
+	t.Run("no access", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(nil, gorm.ErrRecordNotFound)
+		mockGitRepoService.EXPECT().Permission(
+			gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()),
+		).Return(nil, core.ErrNoRepoAccess)
+		mockPermissionStore.EXPECT().Synchronize(gomock.Eq(user), gomock.Eq([]*core.RepoPermission{
+			{Repo: repo, Role: core.RoleNone},
+		})).Return(nil)
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleNone {
+			t.Fatal("user without SCM access should have no role")
+		}
+	})
+
+	t.Run("SCM failure", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(&core.RepoPermission{
+			Role:     core.RoleUploader,
+			SyncedAt: time.Now().Add(-time.Hour),
+		}, nil)
+		mockGitRepoService.EXPECT().Permission(
+			gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()),
+		).Return(nil, errors.New("rate limit exceeded"))
+		role, err := service.Role(ctx, repo, user)
+		if err != nil || role != core.RoleUploader {
+			t.Fatal("should keep the previous role if SCM fails")
+		}
+	})
+
+	t.Run("SCM failure without previous role", func(t *testing.T) {
+		mockPermissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(nil, gorm.ErrRecordNotFound)
+		mockGitRepoService.EXPECT().Permission(
+			gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()),
+		).Return(nil, errors.New("timeout"))
+		if _, err := service.Role(ctx, repo, user); err == nil {
+			t.Fatal("should return error if SCM fails")
+		}
+	})
+}
@@ -11,10 +11,11 @@ import (
 
 // Service of repository
 type Service struct {
-	config     *config.Config
-	scmService core.SCMService
-	userStore  core.UserStore
-	repoStore  core.RepoStore
+	config          *config.Config
+	scmService      core.SCMService
+	userStore       core.UserStore
+	repoStore       core.RepoStore
+	permissionStore core.PermissionStore
 }
 
 // NewService of repository
@@ -23,16 +24,19 @@ func NewService(
 	scmService core.SCMService,
 	userStore core.UserStore,
 	repoStore core.RepoStore,
+	permissionStore core.PermissionStore,
 ) *Service {
 	return &Service{
-		config:     config,
-		scmService: scmService,
-		userStore:  userStore,
-		repoStore:  repoStore,
+		config:          config,
+		scmService:      scmService,
+		userStore:       userStore,
+		repoStore:       repoStore,
+		permissionStore: permissionStore,
 	}
 }
 
-// Synchronize repository with remote and store to database
+// Synchronize repository with remote and store to database.
+// Roles of the user to the repositories are synchronized as well.
 func (s *Service) Synchronize(ctx context.Context, user *core.User) error {
 	userRepos := make([]*core.Repo, 0)
 	for _, provider := range s.config.Providers() {
@@ -50,5 +54,18 @@ func (s *Service) Synchronize(ctx context.Context, user *core.User) error {
 		}
 		userRepos = append(userRepos, repos...)
 	}
-	return s.userStore.UpdateRepositories(user, userRepos)
+	if err := s.userStore.UpdateRepositories(user, userRepos); err != nil {
+		return err
+	}
+	permissions := make([]*core.RepoPermission, 0, len(userRepos))
+	for _, repo := range userRepos {
+		if repo.Perm == nil {
+			continue
+		}
+		permissions = append(permissions, &core.RepoPermission{
+			Repo: repo,
+			Role: repo.Perm.Role(),
+		})
+	}
+	return s.permissionStore.Synchronize(user, permissions)
 }
@@ -23,7 +23,8 @@ func TestSynchronize(t *testing.T) {
 	user := &core.User{Login: "user"}
 	repos := []*core.Repo{
 		{
-			URL: "http://github/repo1",
+			URL:  "http://github/repo1",
+			Perm: &core.RepoPerm{Pull: true, Push: true},
 		},
 		{
 			URL: "http://github/repo2",
@@ -36,14 +37,18 @@ func TestSynchronize(t *testing.T) {
 	mockGitRepoService := mock.NewMockGitRepoService(ctrl)
 	mockUserStore := mock.NewMockUserStore(ctrl)
 	mockRepoStore := mock.NewMockRepoStore(ctrl)
+	mockPermissionStore := mock.NewMockPermissionStore(ctrl)
 
 	mockSCM.EXPECT().Client(gomock.Eq(core.Github)).Return(mockClient, nil)
 	mockClient.EXPECT().Repositories().Return(mockGitRepoService)
 	mockGitRepoService.EXPECT().List(gomock.Any(), gomock.Eq(user)).Return(repos, nil)
 	mockRepoStore.EXPECT().BatchUpdateOrCreate(gomock.Eq(repos)).Return(nil)
 	mockUserStore.EXPECT().UpdateRepositories(gomock.Eq(user), gomock.Eq(repos)).Return(nil)
+	mockPermissionStore.EXPECT().Synchronize(gomock.Eq(user), gomock.Eq([]*core.RepoPermission{
+		{Repo: repos[0], Role: core.RoleUploader},
+	})).Return(nil)
 	// testing
-	service := repo.NewService(config, mockSCM, mockUserStore, mockRepoStore)
+	service := repo.NewService(config, mockSCM, mockUserStore, mockRepoStore, mockPermissionStore)
 	if err := service.Synchronize(context.Background(), user); err != nil {
 		t.Fatal(err)
 	}
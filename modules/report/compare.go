@@ -0,0 +1,211 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/covergates/covergates/core"
+)
+
+// CompareReports of the head report against the base report
+func (service *Service) CompareReports(base, head *core.Report) (*core.ReportComparison, error) {
+	diff, err := service.DiffReports(head, base)
+	if err != nil {
+		return nil, err
+	}
+	baseFiles := toFilesMap(base)
+	comparison := &core.ReportComparison{
+		Base:                  base.Commit,
+		Head:                  head.Commit,
+		BaseCoverage:          base.StatementCoverage(),
+		HeadCoverage:          head.StatementCoverage(),
+		StatementCoverageDiff: diff.StatementCoverageDiff,
+		Types:                 compareTypes(base, head),
+		Files:                 make([]*core.FileComparison, 0),
+		NewlyUncoveredFiles:   make([]string, 0),
+		NewlyCoveredLines:     make([]*core.FileLines, 0),
+		RemovedFiles:          make([]string, 0),
+	}
+	for _, fileDiff := range diff.Files {
+		file := fileDiff.File
+		if fileDiff.Removed {
+			comparison.RemovedFiles = append(comparison.RemovedFiles, file.Name)
+			continue
+		}
+		baseFile, ok := baseFiles[file.Name]
+		if !ok || fileDiff.StatementCoverageDiff != 0 {
+			comparison.Files = append(comparison.Files, &core.FileComparison{
+				Name:                  file.Name,
+				BaseCoverage:          file.StatementCoverage - fileDiff.StatementCoverageDiff,
+				HeadCoverage:          file.StatementCoverage,
+				StatementCoverageDiff: fileDiff.StatementCoverageDiff,
+				Added:                 !ok,
+			})
+		}
+		if len(file.StatementHits) > 0 && !covered(file) && (!ok || covered(baseFile)) {
+			comparison.NewlyUncoveredFiles = append(comparison.NewlyUncoveredFiles, file.Name)
+		}
+		if ok {
+			if lines := newlyCoveredLines(baseFile, file); len(lines) > 0 {
+				comparison.NewlyCoveredLines = append(comparison.NewlyCoveredLines, &core.FileLines{
+					Name:  file.Name,
+					Lines: lines,
+				})
+			}
+		}
+	}
+	sort.Slice(comparison.Files, func(i, j int) bool {
+		return comparison.Files[i].Name < comparison.Files[j].Name
+	})
+	sort.Strings(comparison.NewlyUncoveredFiles)
+	sort.Slice(comparison.NewlyCoveredLines, func(i, j int) bool {
+		return comparison.NewlyCoveredLines[i].Name < comparison.NewlyCoveredLines[j].Name
+	})
+	sort.Strings(comparison.RemovedFiles)
+	return comparison, nil
+}
+
+// MarkdownComparison summarizes the comparison in markdown format
+func (service *Service) MarkdownComparison(comparison *core.ReportComparison) (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(fmt.Sprintf(
+		"### Coverage: %.1f%% (%s) from `%s` to `%s`\n\n",
+		comparison.HeadCoverage*100,
+		percentDiff(comparison.StatementCoverageDiff),
+		comparison.Base,
+		comparison.Head,
+	))
+	buf.WriteString("|Type|Base|Head|Diff|\n")
+	buf.WriteString("|----|----|----|----|\n")
+	for _, t := range comparison.Types {
+		buf.WriteString(fmt.Sprintf(
+			"|%s|%.1f%%|%.1f%%|%s|\n",
+			t.Type,
+			t.BaseCoverage*100,
+			t.HeadCoverage*100,
+			percentDiff(t.StatementCoverageDiff),
+		))
+	}
+	if len(comparison.Files) > 0 {
+		buf.WriteString("\n#### Files\n\n")
+		buf.WriteString("||File|Base|Head|Diff|\n")
+		buf.WriteString("|--|----|----|----|----|\n")
+		for _, file := range comparison.Files {
+			mark := ""
+			if file.StatementCoverageDiff > 0 {
+				mark = upArrow
+			} else if file.StatementCoverageDiff < 0 {
+				mark = downArrow
+			}
+			base := fmt.Sprintf("%.1f%%", file.BaseCoverage*100)
+			if file.Added {
+				base = "-"
+			}
+			buf.WriteString(fmt.Sprintf(
+				"|%s|%s|%s|%.1f%%|%s|\n",
+				mark,
+				file.Name,
+				base,
+				file.HeadCoverage*100,
+				percentDiff(file.StatementCoverageDiff),
+			))
+		}
+	}
+	writeList(buf, "Newly Uncovered Files", comparison.NewlyUncoveredFiles)
+	if len(comparison.NewlyCoveredLines) > 0 {
+		buf.WriteString("\n#### Newly Covered Lines\n\n")
+		buf.WriteString("|File|Lines|\n")
+		buf.WriteString("|----|-----|\n")
+		for _, file := range comparison.NewlyCoveredLines {
+			buf.WriteString(fmt.Sprintf("|%s|%s|\n", file.Name, lineRanges(file.Lines)))
+		}
+	}
+	writeList(buf, "Removed Files", comparison.RemovedFiles)
+	return buf, nil
+}
+
+func compareTypes(base, head *core.Report) []*core.TypeComparison {
+	types := make([]*core.TypeComparison, 0)
+	index := make(map[core.ReportType]*core.TypeComparison)
+	find := func(t core.ReportType) *core.TypeComparison {
+		if c, ok := index[t]; ok {
+			return c
+		}
+		c := &core.TypeComparison{Type: t}
+		index[t] = c
+		types = append(types, c)
+		return c
+	}
+	for _, coverage := range head.Coverages {
+		find(coverage.Type).HeadCoverage = coverage.ComputeStatementCoverage()
+	}
+	for _, coverage := range base.Coverages {
+		find(coverage.Type).BaseCoverage = coverage.ComputeStatementCoverage()
+	}
+	for _, c := range types {
+		c.StatementCoverageDiff = c.HeadCoverage - c.BaseCoverage
+	}
+	return types
+}
+
+func covered(file *core.File) bool {
+	for _, hit := range file.StatementHits {
+		if hit.Hits > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// newlyCoveredLines of the head file, which are statements not covered in the base file
+func newlyCoveredLines(base, head *core.File) []int {
+	missed := make(map[int]bool)
+	for _, hit := range base.StatementHits {
+		if hit.Hits <= 0 {
+			missed[hit.LineNumber] = true
+		}
+	}
+	lines := make([]int, 0)
+	for _, hit := range head.StatementHits {
+		if hit.Hits > 0 && missed[hit.LineNumber] {
+			lines = append(lines, hit.LineNumber)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+func percentDiff(diff float64) string {
+	return fmt.Sprintf("%+.1f%%", diff*100)
+}
+
+// lineRanges joins consecutive lines, such as 1-3, 5
+func lineRanges(lines []int) string {
+	ranges := make([]string, 0)
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("%d", lines[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[i], lines[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ", ")
+}
+
+func writeList(buf *bytes.Buffer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	buf.WriteString(fmt.Sprintf("\n#### %s\n\n", title))
+	for _, item := range items {
+		buf.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+}
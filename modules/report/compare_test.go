@@ -0,0 +1,104 @@
+package report
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+)
+
+func hits(lines ...int) []*core.StatementHit {
+	result := make([]*core.StatementHit, 0)
+	for i := 0; i < len(lines); i += 2 {
+		result = append(result, &core.StatementHit{LineNumber: lines[i], Hits: lines[i+1]})
+	}
+	return result
+}
+
+func TestCompareReports(t *testing.T) {
+	base := &core.Report{Commit: "v1", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{
+			{Name: "a.go", StatementCoverage: 0.5, StatementHits: hits(1, 1, 2, 0)},
+			{Name: "b.go", StatementCoverage: 1, StatementHits: hits(1, 1)},
+			{Name: "c.go", StatementCoverage: 1, StatementHits: hits(1, 1)},
+			{Name: "d.go", StatementCoverage: 1, StatementHits: hits(1, 1)},
+		}},
+	}}
+	head := &core.Report{Commit: "v2", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{
+			{Name: "a.go", StatementCoverage: 1, StatementHits: hits(1, 1, 2, 3)},
+			{Name: "b.go", StatementCoverage: 0, StatementHits: hits(1, 0)},
+			{Name: "c.go", StatementCoverage: 1, StatementHits: hits(1, 1)},
+			{Name: "e.go", StatementCoverage: 0, StatementHits: hits(1, 0)},
+		}},
+		{Type: core.ReportPerl, Files: []*core.File{
+			{Name: "f.pl", StatementCoverage: 1, StatementHits: hits(1, 1)},
+		}},
+	}}
+	service := &Service{}
+	comparison, err := service.CompareReports(base, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &core.ReportComparison{
+		Base:                  "v1",
+		Head:                  "v2",
+		BaseCoverage:          0.875,
+		HeadCoverage:          0.75,
+		StatementCoverageDiff: -0.125,
+		Types: []*core.TypeComparison{
+			{Type: core.ReportGo, BaseCoverage: 0.875, HeadCoverage: 0.5, StatementCoverageDiff: -0.375},
+			{Type: core.ReportPerl, HeadCoverage: 1, StatementCoverageDiff: 1},
+		},
+		Files: []*core.FileComparison{
+			{Name: "a.go", BaseCoverage: 0.5, HeadCoverage: 1, StatementCoverageDiff: 0.5},
+			{Name: "b.go", BaseCoverage: 1, StatementCoverageDiff: -1},
+			{Name: "e.go", Added: true},
+			{Name: "f.pl", HeadCoverage: 1, StatementCoverageDiff: 1, Added: true},
+		},
+		NewlyUncoveredFiles: []string{"b.go", "e.go"},
+		NewlyCoveredLines:   []*core.FileLines{{Name: "a.go", Lines: []int{2}}},
+		RemovedFiles:        []string{"d.go"},
+	}
+	if diff := cmp.Diff(expect, comparison); diff != "" {
+		t.Fatal(diff)
+	}
+
+	reader, err := service.MarkdownComparison(comparison)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(reader)
+	expectMarkdown := "### Coverage: 75.0% (-12.5%) from `v1` to `v2`\n\n" +
+		"|Type|Base|Head|Diff|\n" +
+		"|----|----|----|----|\n" +
+		"|go|87.5%|50.0%|-37.5%|\n" +
+		"|perl|0.0%|100.0%|+100.0%|\n" +
+		"\n#### Files\n\n" +
+		"||File|Base|Head|Diff|\n" +
+		"|--|----|----|----|----|\n" +
+		"|:arrow_up_small:|a.go|50.0%|100.0%|+50.0%|\n" +
+		"|:arrow_down_small:|b.go|100.0%|0.0%|-100.0%|\n" +
+		"||e.go|-|0.0%|+0.0%|\n" +
+		"|:arrow_up_small:|f.pl|-|100.0%|+100.0%|\n" +
+		"\n#### Newly Uncovered Files\n\n" +
+		"- b.go\n" +
+		"- e.go\n" +
+		"\n#### Newly Covered Lines\n\n" +
+		"|File|Lines|\n" +
+		"|----|-----|\n" +
+		"|a.go|2|\n" +
+		"\n#### Removed Files\n\n" +
+		"- d.go\n"
+	if diff := cmp.Diff(expectMarkdown, string(data)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLineRanges(t *testing.T) {
+	if s := lineRanges([]int{1, 2, 3, 5, 7, 8}); s != "1-3, 5, 7-8" {
+		t.Fatalf("unexpected ranges %s", s)
+	}
+}
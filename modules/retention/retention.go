@@ -0,0 +1,204 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/covergates/covergates/core"
+)
+
+var errNoBranch = errors.New("no branch found in the repository")
+
+// Service prunes reports in the retention policy of repositories
+type Service struct {
+	scmService  core.SCMService
+	repoStore   core.RepoStore
+	reportStore core.ReportStore
+}
+
+// NewService of retention
+func NewService(
+	scmService core.SCMService,
+	repoStore core.RepoStore,
+	reportStore core.ReportStore,
+) *Service {
+	return &Service{
+		scmService:  scmService,
+		repoStore:   repoStore,
+		reportStore: reportStore,
+	}
+}
+
+// PruneAll activated repositories. It keeps pruning other repositories
+// when one fails and returns the first error.
+func (s *Service) PruneAll(ctx context.Context, dryRun bool) ([]*core.PruneResult, error) {
+	repos, err := s.repoStore.ListActivated()
+	if err != nil {
+		return nil, err
+	}
+	var first error
+	results := make([]*core.PruneResult, 0)
+	for _, repo := range repos {
+		result, err := s.Prune(ctx, repo, dryRun)
+		if err != nil {
+			log.Errorf("fail to prune reports of %s: %v", repo.FullName(), err)
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		if len(result.Reports) > 0 {
+			results = append(results, result)
+		}
+	}
+	return results, first
+}
+
+// Prune reports of the repository. A report is kept if any of its references keeps it:
+// reports of the default branch are kept for DefaultBranchDays, except the latest one,
+// only the latest report of other branches is kept with LatestOnly,
+// and reports of branches deleted for DeletedBranchDays are removed.
+func (s *Service) Prune(ctx context.Context, repo *core.Repo, dryRun bool) (*core.PruneResult, error) {
+	result := &core.PruneResult{Repo: repo, Reports: []*core.Report{}}
+	if repo.ReportID == "" {
+		return result, nil
+	}
+	setting, err := s.repoStore.Setting(repo)
+	if err != nil {
+		return nil, err
+	}
+	policy := &setting.Retention
+	if !policy.Enabled() {
+		return result, nil
+	}
+	references, err := s.reportStore.ListReferences(repo.ReportID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if policy.DeletedBranchDays > 0 {
+		if err := s.updateDeletedBranches(ctx, repo, references, now, dryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	keep := make(map[string]bool)
+	var candidates []*core.Report
+	for _, ref := range references {
+		for i, report := range ref.Reports {
+			if _, ok := keep[report.Commit]; !ok {
+				candidates = append(candidates, report)
+			}
+			keep[report.Commit] = keep[report.Commit] || retain(policy, repo, ref, i, now)
+		}
+	}
+	commits := make([]string, 0)
+	for _, report := range candidates {
+		if !keep[report.Commit] {
+			result.Reports = append(result.Reports, report)
+			commits = append(commits, report.Commit)
+		}
+	}
+	if dryRun || len(commits) == 0 {
+		return result, nil
+	}
+	if err := s.reportStore.Delete(repo.ReportID, commits...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// updateDeletedBranches marks references whose branches no longer exist in SCM.
+// References of tags are uploaded the same as branches, so they are kept unmarked while the tags exist.
+func (s *Service) updateDeletedBranches(
+	ctx context.Context,
+	repo *core.Repo,
+	references []*core.Reference,
+	now time.Time,
+	dryRun bool,
+) error {
+	user, err := s.repoStore.Creator(repo)
+	if err != nil {
+		return err
+	}
+	client, err := s.scmService.Client(repo.SCM)
+	if err != nil {
+		return err
+	}
+	git := client.Git()
+	branches, err := listAll(func(page core.Page) ([]string, string, error) {
+		return git.ListBranches(ctx, user, repo.FullName(), page)
+	})
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		return errNoBranch
+	}
+	tags, err := listAll(func(page core.Page) ([]string, string, error) {
+		return git.ListTags(ctx, user, repo.FullName(), page)
+	})
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool)
+	for _, name := range append(branches, tags...) {
+		exists[name] = true
+	}
+	for _, ref := range references {
+		deleted := !exists[ref.Name]
+		if ref.Name == repo.Branch || deleted == (ref.BranchDeletedAt != nil) {
+			// the default branch is never deleted, or the mark is up to date
+			continue
+		}
+		var deletedAt *time.Time
+		if deleted {
+			deletedAt = &now
+		}
+		ref.BranchDeletedAt = deletedAt
+		if dryRun {
+			continue
+		}
+		if err := s.reportStore.UpdateBranchDeleted(repo.ReportID, ref.Name, deletedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listAll names of the list following its cursors
+func listAll(list func(page core.Page) ([]string, string, error)) ([]string, error) {
+	var names []string
+	page := core.Page{Limit: core.MaxPageLimit}
+	for {
+		result, next, err := list(page)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, result...)
+		if next == "" {
+			return names, nil
+		}
+		page.Cursor = next
+	}
+}
+
+// retain the i-th latest report of the reference in the policy
+func retain(policy *core.RetentionPolicy, repo *core.Repo, ref *core.Reference, i int, now time.Time) bool {
+	report := ref.Reports[i]
+	switch {
+	case ref.Name == repo.Branch:
+		if i == 0 || policy.DefaultBranchDays == 0 {
+			return true
+		}
+		return report.CreatedAt.After(now.AddDate(0, 0, -policy.DefaultBranchDays))
+	case policy.DeletedBranchDays > 0 && ref.BranchDeletedAt != nil:
+		if !ref.BranchDeletedAt.After(now.AddDate(0, 0, -policy.DeletedBranchDays)) {
+			return false
+		}
+	}
+	return i == 0 || !policy.LatestOnly
+}
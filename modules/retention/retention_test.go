@@ -0,0 +1,159 @@
+package retention_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/retention"
+)
+
+func commits(reports []*core.Report) []string {
+	result := make([]string, len(reports))
+	for i, report := range reports {
+		result[i] = report.Commit
+	}
+	return result
+}
+
+func TestPrune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &core.User{Login: "user"}
+	repo := &core.Repo{
+		NameSpace: "org",
+		Name:      "repo",
+		SCM:       core.Github,
+		Branch:    "master",
+		ReportID:  "report",
+	}
+	now := time.Now()
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	deletedAt := daysAgo(10)
+	references := func() ([]*core.Reference, error) {
+		reference := func(name string, deletedAt *time.Time, reports ...*core.Report) *core.Reference {
+			return &core.Reference{Name: name, BranchDeletedAt: deletedAt, Reports: reports}
+		}
+		report := func(commit string, days int) *core.Report {
+			return &core.Report{ReportID: repo.ReportID, Commit: commit, CreatedAt: daysAgo(days)}
+		}
+		return []*core.Reference{
+			reference("master", nil, report("m1", 0), report("m2", 10), report("m3", 40), report("m4", 60)),
+			reference("feature", nil, report("f1", 1), report("f2", 2)),
+			reference("hotfix", nil, report("m4", 60)),
+			reference("old", &deletedAt, report("o1", 20)),
+			reference("gone", nil, report("g1", 20)),
+			reference("back", &deletedAt, report("b1", 20)),
+			reference("v1.0", nil, report("t1", 20)),
+		}, nil
+	}
+
+	mockSCM := mock.NewMockSCMService(ctrl)
+	mockClient := mock.NewMockClient(ctrl)
+	mockGit := mock.NewMockGitService(ctrl)
+	mockRepoStore := mock.NewMockRepoStore(ctrl)
+	mockReportStore := mock.NewMockReportStore(ctrl)
+	mockSCM.EXPECT().Client(gomock.Eq(repo.SCM)).AnyTimes().Return(mockClient, nil)
+	mockClient.EXPECT().Git().AnyTimes().Return(mockGit)
+	mockRepoStore.EXPECT().Creator(gomock.Eq(repo)).AnyTimes().Return(user, nil)
+	mockRepoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{
+		Retention: core.RetentionPolicy{
+			DefaultBranchDays: 30,
+			LatestOnly:        true,
+			DeletedBranchDays: 7,
+		},
+	}, nil)
+	mockGit.EXPECT().ListBranches(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(core.Page{Limit: core.MaxPageLimit}),
+	).AnyTimes().Return([]string{"master", "feature"}, "2", nil)
+	mockGit.EXPECT().ListBranches(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(core.Page{Cursor: "2", Limit: core.MaxPageLimit}),
+	).AnyTimes().Return([]string{"hotfix", "back"}, "", nil)
+	// tags are not deleted branches
+	mockGit.EXPECT().ListTags(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(core.Page{Limit: core.MaxPageLimit}),
+	).AnyTimes().Return([]string{"v1.0"}, "", nil)
+	mockReportStore.EXPECT().ListReferences(gomock.Eq(repo.ReportID)).AnyTimes().DoAndReturn(
+		func(string) ([]*core.Reference, error) { return references() },
+	)
+
+	service := retention.NewService(mockSCM, mockRepoStore, mockReportStore)
+	ctx := context.Background()
+	expect := []string{"m3", "f2", "o1"}
+
+	t.Run("dry run", func(t *testing.T) {
+		result, err := service.Prune(ctx, repo, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expect, commits(result.Reports)); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		mockReportStore.EXPECT().UpdateBranchDeleted(
+			gomock.Eq(repo.ReportID), gomock.Eq("gone"), gomock.Not(gomock.Nil()),
+		).Return(nil)
+		mockReportStore.EXPECT().UpdateBranchDeleted(
+			gomock.Eq(repo.ReportID), gomock.Eq("back"), gomock.Nil(),
+		).Return(nil)
+		mockReportStore.EXPECT().Delete(gomock.Eq(repo.ReportID), "m3", "f2", "o1").Return(nil)
+		result, err := service.Prune(ctx, repo, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(expect, commits(result.Reports)); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func TestPruneAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	disabled := &core.Repo{Name: "disabled", ReportID: "disabled"}
+	broken := &core.Repo{Name: "broken", ReportID: "broken"}
+	enabled := &core.Repo{Name: "enabled", ReportID: "enabled", Branch: "master"}
+
+	mockRepoStore := mock.NewMockRepoStore(ctrl)
+	mockReportStore := mock.NewMockReportStore(ctrl)
+	mockRepoStore.EXPECT().ListActivated().Return([]*core.Repo{disabled, broken, enabled}, nil)
+	mockRepoStore.EXPECT().Setting(gomock.Eq(disabled)).Return(&core.RepoSetting{}, nil)
+	mockRepoStore.EXPECT().Setting(gomock.Eq(broken)).Return(nil, errors.New("broken"))
+	mockRepoStore.EXPECT().Setting(gomock.Eq(enabled)).Return(&core.RepoSetting{
+		Retention: core.RetentionPolicy{LatestOnly: true},
+	}, nil)
+	mockReportStore.EXPECT().ListReferences(gomock.Eq(enabled.ReportID)).Return([]*core.Reference{
+		{
+			Name: "feature",
+			Reports: []*core.Report{
+				{ReportID: enabled.ReportID, Commit: "new"},
+				{ReportID: enabled.ReportID, Commit: "old"},
+			},
+		},
+	}, nil)
+	mockReportStore.EXPECT().Delete(gomock.Eq(enabled.ReportID), "old").Return(nil)
+
+	service := retention.NewService(mock.NewMockSCMService(ctrl), mockRepoStore, mockReportStore)
+	results, err := service.PruneAll(context.Background(), false)
+	if err == nil {
+		t.Fatal("should return error of broken repository")
+	}
+	if len(results) != 1 || results[0].Repo != enabled {
+		t.Fatal("should prune other repositories")
+	}
+	if diff := cmp.Diff([]string{"old"}, commits(results[0].Reports)); diff != "" {
+		t.Fatal(diff)
+	}
+}
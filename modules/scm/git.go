@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/drone/go-scm/scm"
@@ -17,6 +18,9 @@ import (
 	"github.com/covergates/covergates/core"
 )
 
+// giteaMaxPageSize is the default page size limit of Gitea
+const giteaMaxPageSize = 50
+
 type gitService struct {
 	git       core.Git
 	scm       core.SCMProvider
@@ -73,29 +77,44 @@ func (service *gitService) FindCommit(ctx context.Context, user *core.User, repo
 	return ref.Sha
 }
 
-func (service *gitService) ListCommits(ctx context.Context, user *core.User, repo string) ([]*core.Commit, error) {
-	ctx = withUser(ctx, service.scm, user)
-	if service.scm == core.Gitea {
-		return service.listGiteaCommits(ctx, repo, "")
-	}
-	return service.listCommits(ctx, repo, "")
+func (service *gitService) ListCommits(
+	ctx context.Context,
+	user *core.User,
+	repo string,
+	page core.Page,
+) ([]*core.Commit, string, error) {
+	return service.ListCommitsByRef(ctx, user, repo, "", page)
 }
 
-func (service *gitService) ListCommitsByRef(ctx context.Context, user *core.User, repo, ref string) ([]*core.Commit, error) {
+func (service *gitService) ListCommitsByRef(
+	ctx context.Context,
+	user *core.User,
+	repo, ref string,
+	page core.Page,
+) ([]*core.Commit, string, error) {
+	number, size, err := service.page(page)
+	if err != nil {
+		return nil, "", err
+	}
 	ctx = withUser(ctx, service.scm, user)
-	switch service.scm {
-	case core.Gitea:
-		return service.listGiteaCommits(ctx, repo, ref)
-	case core.Github:
-		return service.listGithubCommits(ctx, repo, ref)
+	var commits []*core.Commit
+	switch {
+	case service.scm == core.Gitea:
+		commits, err = service.listGiteaCommits(ctx, repo, ref, number, size)
+	case service.scm == core.Github && ref != "":
+		commits, err = service.listGithubCommits(ctx, repo, ref, number, size)
 	default:
-		return service.listCommits(ctx, repo, ref)
+		commits, err = service.listCommits(ctx, repo, ref, number, size)
+	}
+	if err != nil {
+		return nil, "", err
 	}
+	return commits, nextPage(number, size, len(commits)), nil
 }
 
-func (service *gitService) listCommits(ctx context.Context, repo, ref string) ([]*core.Commit, error) {
+func (service *gitService) listCommits(ctx context.Context, repo, ref string, page, size int) ([]*core.Commit, error) {
 	client := service.scmClient
-	options := scm.CommitListOptions{Size: 20}
+	options := scm.CommitListOptions{Page: page, Size: size}
 	if ref != "" {
 		options.Ref = ref
 	}
@@ -115,7 +134,7 @@ func (service *gitService) listCommits(ctx context.Context, repo, ref string) ([
 	return results, nil
 }
 
-func mustGetGiteaCommitsQuery(repo, ref string) string {
+func mustGetGiteaCommitsQuery(repo, ref string, page, size int) string {
 	u, err := url.Parse(fmt.Sprintf("api/v1/repos/%s/commits", repo))
 	if err != nil {
 		log.Fatal(err)
@@ -124,18 +143,22 @@ func mustGetGiteaCommitsQuery(repo, ref string) string {
 	if ref != "" {
 		query.Set("sha", ref)
 	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(size))
+	}
 	u.RawQuery = query.Encode()
 	return u.String()
 }
 
-func (service *gitService) listGiteaCommits(ctx context.Context, repo, ref string) ([]*core.Commit, error) {
+func (service *gitService) listGiteaCommits(ctx context.Context, repo, ref string, page, size int) ([]*core.Commit, error) {
 	client := service.scmClient
 	res, err := client.Do(ctx, &scm.Request{
 		Header: map[string][]string{
 			"Content-Type": {"application/json"},
 		},
 		Method: "GET",
-		Path:   mustGetGiteaCommitsQuery(repo, ref),
+		Path:   mustGetGiteaCommitsQuery(repo, ref, page, size),
 	})
 	if err != nil {
 		return nil, err
@@ -170,10 +193,11 @@ func (service *gitService) listGiteaCommits(ctx context.Context, repo, ref strin
 	return results, nil
 }
 
-func (service *gitService) listGithubCommits(ctx context.Context, repo, ref string) ([]*core.Commit, error) {
+func (service *gitService) listGithubCommits(ctx context.Context, repo, ref string, page, size int) ([]*core.Commit, error) {
 	params := url.Values{}
 	params.Add("sha", ref)
-	params.Add("per_page", "25")
+	params.Add("page", strconv.Itoa(page))
+	params.Add("per_page", strconv.Itoa(size))
 
 	res, err := service.scmClient.Do(ctx, &scm.Request{
 		Method: "GET",
@@ -204,18 +228,120 @@ func (service *gitService) listGithubCommits(ctx context.Context, repo, ref stri
 	return result, nil
 }
 
-func (service *gitService) ListBranches(ctx context.Context, user *core.User, repo string) ([]string, error) {
+func (service *gitService) ListBranches(
+	ctx context.Context,
+	user *core.User,
+	repo string,
+	page core.Page,
+) ([]string, string, error) {
+	number, size, err := service.page(page)
+	if err != nil {
+		return nil, "", err
+	}
 	client := service.scmClient
 	ctx = withUser(ctx, service.scm, user)
-	references, _, err := client.Git.ListBranches(ctx, repo, scm.ListOptions{})
+	references, _, err := client.Git.ListBranches(ctx, repo, scm.ListOptions{
+		Page: number,
+		Size: size,
+	})
 	if err != nil {
-		return []string{}, err
+		return []string{}, "", err
 	}
 	branches := make([]string, len(references))
 	for i, ref := range references {
 		branches[i] = ref.Name
 	}
-	return branches, nil
+	return branches, nextPage(number, size, len(branches)), nil
+}
+
+func (service *gitService) ListTags(
+	ctx context.Context,
+	user *core.User,
+	repo string,
+	page core.Page,
+) ([]string, string, error) {
+	number, size, err := service.page(page)
+	if err != nil {
+		return nil, "", err
+	}
+	ctx = withUser(ctx, service.scm, user)
+	var tags []string
+	if service.scm == core.Gitea {
+		tags, err = service.listGiteaTags(ctx, repo, number, size)
+	} else {
+		var references []*scm.Reference
+		references, _, err = service.scmClient.Git.ListTags(ctx, repo, scm.ListOptions{
+			Page: number,
+			Size: size,
+		})
+		tags = make([]string, len(references))
+		for i, ref := range references {
+			tags[i] = ref.Name
+		}
+	}
+	if err != nil {
+		return []string{}, "", err
+	}
+	return tags, nextPage(number, size, len(tags)), nil
+}
+
+// listGiteaTags with the API directly, as the Gitea driver does not support tags
+func (service *gitService) listGiteaTags(ctx context.Context, repo string, page, size int) ([]string, error) {
+	params := url.Values{}
+	params.Add("page", strconv.Itoa(page))
+	params.Add("limit", strconv.Itoa(size))
+	res, err := service.scmClient.Do(ctx, &scm.Request{
+		Header: map[string][]string{
+			"Content-Type": {"application/json"},
+		},
+		Method: "GET",
+		Path:   fmt.Sprintf("api/v1/repos/%s/tags?%s", repo, params.Encode()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.Status > 300 {
+		return nil, errors.New(http.StatusText(res.Status))
+	}
+	var tags []struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names, nil
+}
+
+// page number and size of the SCM API. The cursor is the page number.
+func (service *gitService) page(page core.Page) (int, int, error) {
+	number := 1
+	if page.Cursor != "" {
+		n, err := strconv.Atoi(page.Cursor)
+		if err != nil || n < 1 {
+			return 0, 0, core.ErrInvalidCursor
+		}
+		number = n
+	}
+	size := page.Size()
+	if service.scm == core.Gitea && size > giteaMaxPageSize {
+		size = giteaMaxPageSize
+	}
+	return number, size, nil
+}
+
+// nextPage cursor if the page is full
+func nextPage(number, size, count int) string {
+	if count < size {
+		return ""
+	}
+	return strconv.Itoa(number + 1)
 }
 
 // GitRepository clone
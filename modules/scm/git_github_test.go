@@ -39,7 +39,7 @@ func TestGitHubListBranch(t *testing.T) {
 	user := &core.User{
 		GithubToken: os.Getenv("GITHUB_SECRET"),
 	}
-	branches, err := service.ListBranches(ctx, user, "blueworrybear/livelogs")
+	branches, _, err := service.ListBranches(ctx, user, "blueworrybear/livelogs", core.Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -58,7 +58,7 @@ func TestGithubListCommits(t *testing.T) {
 	}
 	ctx := context.Background()
 
-	commits, err := service.ListCommitsByRef(ctx, user, "octocat/Hello-World", "test")
+	commits, _, err := service.ListCommitsByRef(ctx, user, "octocat/Hello-World", "test", core.Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,15 +1,77 @@
 package scm
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/drone/go-scm/scm/driver/gitea"
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
 )
 
 func TestGiteaCommitQuery(t *testing.T) {
-	u := mustGetGiteaCommitsQuery("gitea/test", "bear")
+	u := mustGetGiteaCommitsQuery("gitea/test", "bear", 0, 0)
 	expect := "api/v1/repos/gitea/test/commits?sha=bear"
 	if diff := cmp.Diff(expect, u); diff != "" {
 		t.Fatal(diff)
 	}
 }
+
+func TestGiteaCommitPageQuery(t *testing.T) {
+	u := mustGetGiteaCommitsQuery("gitea/test", "", 2, 50)
+	expect := "api/v1/repos/gitea/test/commits?limit=50&page=2"
+	if diff := cmp.Diff(expect, u); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestGitPage(t *testing.T) {
+	gitea := &gitService{scm: core.Gitea}
+	github := &gitService{scm: core.Github}
+	if number, size, err := github.page(core.Page{}); err != nil || number != 1 || size != core.DefaultPageLimit {
+		t.Fatal("should list the first page by default")
+	}
+	if number, size, err := github.page(core.Page{Cursor: "3", Limit: 100}); err != nil || number != 3 || size != 100 {
+		t.Fatal("should list the page of cursor")
+	}
+	if _, size, _ := gitea.page(core.Page{Limit: 100}); size != giteaMaxPageSize {
+		t.Fatal("should limit page size of Gitea")
+	}
+	for _, cursor := range []string{"0", "next"} {
+		if _, _, err := github.page(core.Page{Cursor: cursor}); err != core.ErrInvalidCursor {
+			t.Fatalf("cursor %q should be invalid", cursor)
+		}
+	}
+	if nextPage(3, 10, 10) != "4" || nextPage(3, 10, 9) != "" {
+		t.Fatal("should only have next page of a full page")
+	}
+}
+
+func TestGiteaListTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/org/repo/tags" || r.URL.Query().Get("page") != "2" {
+			w.WriteHeader(404)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"v1.0"},{"name":"v1.1"}]`))
+	}))
+	defer server.Close()
+	client, err := gitea.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := &gitService{scm: core.Gitea, scmClient: client}
+	tags, next, err := service.ListTags(context.Background(), &core.User{}, "org/repo", core.Page{Cursor: "2", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"v1.0", "v1.1"}, tags); diff != "" {
+		t.Fatal(diff)
+	}
+	if next != "3" {
+		t.Fatalf("expect next page 3, got %q", next)
+	}
+}
@@ -3,6 +3,7 @@ package scm
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/drone/go-scm/scm"
 	"github.com/rs/xid"
@@ -50,6 +51,7 @@ func (service *repoService) List(
 			URL:       r.Link,
 			SCM:       service.scm,
 			Branch:    r.Branch,
+			Perm:      toRepoPerm(r.Perm),
 		}
 	}
 	return repositories, nil
@@ -124,11 +126,29 @@ func (service *repoService) RemoveHook(ctx context.Context, user *core.User, nam
 	return err
 }
 
-func (service *repoService) IsAdmin(ctx context.Context, user *core.User, name string) bool {
+func (service *repoService) Permission(
+	ctx context.Context,
+	user *core.User,
+	name string,
+) (*core.RepoPerm, error) {
 	ctx = withUser(ctx, service.scm, user)
-	perm, _, err := service.client.Repositories.FindPerms(ctx, name)
+	perm, res, err := service.client.Repositories.FindPerms(ctx, name)
+	if err != nil && res != nil && (res.Status == http.StatusNotFound || res.Status == http.StatusForbidden) {
+		return nil, core.ErrNoRepoAccess
+	}
 	if err != nil {
-		return false
+		return nil, err
+	}
+	return toRepoPerm(perm), nil
+}
+
+func toRepoPerm(perm *scm.Perm) *core.RepoPerm {
+	if perm == nil {
+		return nil
+	}
+	return &core.RepoPerm{
+		Pull:  perm.Pull,
+		Push:  perm.Push,
+		Admin: perm.Admin,
 	}
-	return perm.Admin
 }
@@ -1,8 +1,13 @@
 package scm
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/drone/go-scm/scm/driver/github"
+
 	"github.com/covergates/covergates/core"
 )
 
@@ -19,3 +24,32 @@ func TestNewReportID(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestPermissionNoAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/missing":
+			w.WriteHeader(404)
+		case "/repos/org/forbidden":
+			w.WriteHeader(403)
+		default:
+			w.WriteHeader(502)
+		}
+		_, _ = w.Write([]byte(`{"message":"error"}`))
+	}))
+	defer server.Close()
+	client, err := github.New(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &repoService{client: client, scm: core.Github}
+	ctx := context.Background()
+	for _, name := range []string{"org/missing", "org/forbidden"} {
+		if _, err := s.Permission(ctx, &core.User{}, name); err != core.ErrNoRepoAccess {
+			t.Fatalf("%s should not be accessible, got %v", name, err)
+		}
+	}
+	if _, err := s.Permission(ctx, &core.User{}, "org/broken"); err == nil || err == core.ErrNoRepoAccess {
+		t.Fatalf("SCM failure should be returned, got %v", err)
+	}
+}
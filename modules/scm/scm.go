@@ -142,8 +142,9 @@ func scmClient(s core.SCMProvider, config *config.Config) (*scm.Client, error) {
 				},
 			},
 		}
+	default:
+		log.Debug("scm not supported")
+		return nil, &errClientNotFound{s}
 	}
-
-	log.Debug("scm not supported")
-	return nil, &errClientNotFound{s}
+	return client, nil
 }
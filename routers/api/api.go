@@ -9,6 +9,7 @@ import (
 
 	"github.com/covergates/covergates/config"
 	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/metrics"
 	"github.com/covergates/covergates/routers/api/repo"
 	"github.com/covergates/covergates/routers/api/report"
 	"github.com/covergates/covergates/routers/api/request"
@@ -34,18 +35,25 @@ type Router struct {
 	Config  *config.Config
 	Session core.Session
 	// service
-	CoverageService core.CoverageService
-	ChartService    core.ChartService
-	SCMService      core.SCMService
-	RepoService     core.RepoService
-	ReportService   core.ReportService
-	HookService     core.HookService
-	OAuthService    core.OAuthService
+	CoverageService   core.CoverageService
+	ChartService      core.ChartService
+	SCMService        core.SCMService
+	RepoService       core.RepoService
+	ReportService     core.ReportService
+	HookService       core.HookService
+	OAuthService      core.OAuthService
+	PermissionService core.PermissionService
+	ArchiveService    core.ArchiveService
+	CacheMetrics      core.CacheMetrics
+	// Cache of rendered content, nil if caching is disabled
+	Cache core.Cache
 	// store
-	UserStore   core.UserStore
-	ReportStore core.ReportStore
-	RepoStore   core.RepoStore
-	OAuthStore  core.OAuthStore
+	UserStore       core.UserStore
+	ReportStore     core.ReportStore
+	RepoStore       core.RepoStore
+	OAuthStore      core.OAuthStore
+	PermissionStore core.PermissionStore
+	AuditStore      core.AuditStore
 }
 
 func host(addr string) string {
@@ -60,22 +68,33 @@ func host(addr string) string {
 func (r *Router) RegisterRoutes(e *gin.Engine) {
 	docs.SwaggerInfo.Host = host(r.Config.Server.Addr)
 	checkLogin := request.CheckLogin(r.Session, r.OAuthService)
+	checkUser := request.CheckLogin(r.Session, r.OAuthService, core.ScopeUser)
+	checkRepoRead := request.CheckLogin(r.Session, r.OAuthService, core.ScopeRepoRead)
+	checkReportWrite := request.CheckLogin(r.Session, r.OAuthService, core.ScopeReportWrite)
+	optionalRepoRead := request.OptionalLogin(r.Session, r.OAuthService, core.ScopeRepoRead)
+	requireRepoAdmin := request.RequireScope(core.ScopeRepoAdmin)
+	withRepo := repo.WithRepo(r.RepoStore)
+	requireViewer := request.RequireRole(r.PermissionService, core.RoleViewer)
+	requireMaintainer := request.RequireRole(r.PermissionService, core.RoleMaintainer)
+	requireAdmin := request.RequireRole(r.PermissionService, core.RoleAdmin)
 	e.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	g := e.Group("/api/v1")
+	g.GET("/metrics", metrics.HandleGetCache(r.CacheMetrics))
 	{
 		// nolint:govet
 		g := g.Group("/user")
 		g.GET("", checkLogin, user.HandleGet())
 		g.POST("", user.HandleCreate())
 		g.GET("/scm", checkLogin, user.HandleGetSCM(r.Config))
-		g.GET("/owner/:scm/:namespace/:name", checkLogin, user.HandleGetOwner(r.RepoStore, r.SCMService))
+		g.GET("/owner/:scm/:namespace/:name", checkRepoRead, user.HandleGetOwner(r.RepoStore, r.PermissionService))
 		// tokens
-		g.POST("/tokens", checkLogin, user.HandleCreateToken(r.OAuthService))
-		g.GET("/tokens", checkLogin, user.HandleListTokens(r.OAuthService))
-		g.DELETE("tokens/:id", checkLogin, user.HandleDeleteToken(r.OAuthService, r.OAuthStore))
+		g.POST("/tokens", checkUser, user.HandleCreateToken(r.OAuthService, r.AuditStore))
+		g.GET("/tokens", checkUser, user.HandleListTokens(r.OAuthService))
+		g.DELETE("tokens/:id", checkUser, user.HandleDeleteToken(r.OAuthService, r.OAuthStore, r.AuditStore))
+		g.GET("/audit", checkUser, user.HandleListAudit(r.AuditStore))
 		// repo
-		g.PATCH("/repos", checkLogin, user.HandleSynchronizeRepo(r.RepoService))
-		g.GET("/repos", checkLogin, user.HandleListRepo(r.UserStore))
+		g.PATCH("/repos", checkUser, user.HandleSynchronizeRepo(r.RepoService))
+		g.GET("/repos", checkRepoRead, user.HandleListRepo(r.UserStore))
 	}
 	{
 		// nolint:govet
@@ -83,49 +102,138 @@ func (r *Router) RegisterRoutes(e *gin.Engine) {
 		g.POST("/:id",
 			report.InjectReportContext(r.RepoStore),
 			report.ProtectReport(
-				checkLogin,
+				checkReportWrite,
 				r.RepoStore,
-				r.SCMService,
+				r.AuditStore,
+				r.PermissionService,
 			),
 			report.HandleUpload(
 				r.CoverageService,
 				r.ReportStore,
 			))
-		g.POST("/:id/comment/:number", report.HandleComment(
-			r.Config,
-			r.SCMService,
-			r.RepoStore,
-			r.ReportStore,
-			r.ReportService,
-		))
-		g.GET("/:id", report.HandleGet(r.ReportStore, r.RepoStore, r.SCMService))
-		g.GET("/:id/treemap/*ref", report.HandleGetTreeMap(
-			r.ReportStore,
-			r.RepoStore,
-			r.ChartService,
-		))
-		g.GET("/:id/card", report.HandleGetCard(r.RepoStore, r.ReportStore, r.ChartService))
-		g.GET("/:id/badge", report.HandleGetBadge(r.ReportStore, r.RepoStore))
+		g.POST("/:id/comment/:number",
+			report.InjectReportContext(r.RepoStore),
+			report.ProtectReport(
+				checkReportWrite,
+				r.RepoStore,
+				r.AuditStore,
+				r.PermissionService,
+			),
+			report.HandleComment(
+				r.Config,
+				r.SCMService,
+				r.RepoStore,
+				r.ReportStore,
+				r.ReportService,
+			))
+		g.GET("/:id",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGet(r.ReportStore, r.RepoStore),
+		)
+		// treemaps are embedded as images in pull request comments, which are fetched without credentials
+		g.GET("/:id/treemap/*ref",
+			report.HandleGetTreeMap(
+				r.Config,
+				r.SCMService,
+				r.ReportStore,
+				r.RepoStore,
+				r.ChartService,
+				r.Cache,
+			),
+		)
+		g.GET("/:id/files/*path",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGetFile(r.SCMService, r.ReportStore, r.RepoStore),
+		)
+		g.GET("/:id/files-history/*path",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGetFileHistory(r.ReportStore, r.RepoStore),
+		)
+		g.GET("/:id/regressions",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGetRegressions(r.SCMService, r.ReportStore, r.RepoStore, r.Cache),
+		)
+		g.GET("/:id/compare",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleCompare(r.ReportStore, r.ReportService),
+		)
+		g.GET("/:id/history",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGetHistory(r.ReportStore, r.RepoStore),
+		)
+		g.GET("/:id/card", report.HandleGetCard(r.SCMService, r.RepoStore, r.ReportStore, r.ChartService, r.Cache))
+		g.GET("/:id/badge", report.HandleGetBadge(r.ReportStore, r.RepoStore, r.ChartService, r.Cache))
+		g.GET("/:id/badge.json", report.HandleGetShieldsBadge(r.ReportStore, r.RepoStore))
+		g.GET("/:id/trend.svg", report.HandleGetTrend(r.ReportStore, r.RepoStore, r.ChartService, r.Cache))
+		g.GET("/:id/hierarchy.svg",
+			optionalRepoRead,
+			report.InjectReportContext(r.RepoStore),
+			requireViewer,
+			report.HandleGetHierarchy(r.Config, r.ReportStore, r.RepoStore, r.ChartService, r.Cache),
+		)
 	}
 	{
 		// nolint:govet
 		g := g.Group("/repos")
-		g.Use(checkLogin)
+		g.Use(checkRepoRead)
 		g.GET("", repo.HandleListAll(r.Config, r.SCMService, r.RepoStore))
-		g.POST("", repo.HandleCreate(r.RepoStore, r.SCMService))
+		g.POST("", requireRepoAdmin, repo.HandleCreate(r.RepoStore, r.SCMService))
 		g.GET("/:scm", repo.HandleListSCM(r.SCMService, r.RepoStore))
 		{
 			// nolint:govet
 			g := g.Group("/:scm/:namespace/:name")
-			g.PATCH("", repo.HandleSync(r.SCMService, r.RepoStore))
-			g.GET("/setting", repo.HandleGetSetting(r.RepoStore))
-			g.POST("/setting", repo.WithRepo(r.RepoStore), repo.HandleUpdateSetting(r.RepoStore, r.SCMService))
-			g.PATCH("/report", repo.HandleReportIDRenew(r.RepoStore, r.SCMService))
-			g.GET("/files", repo.HandleGetFiles(r.SCMService))
-			g.GET("/content/*path", repo.HandleGetFileContent(r.SCMService))
-			g.POST("/hook/create", repo.WithRepo(r.RepoStore), repo.HandleHookCreate(r.HookService))
-			g.GET("/commits", repo.WithRepo(r.RepoStore), repo.HandleListCommits(r.SCMService))
-			g.GET("/branches", repo.WithRepo(r.RepoStore), repo.HandleListBranches(r.SCMService))
+			g.PATCH("", requireRepoAdmin, withRepo, requireMaintainer, repo.HandleSync(r.SCMService, r.RepoStore))
+			g.GET("/setting", withRepo, requireViewer, repo.HandleGetSetting(r.RepoStore))
+			g.POST("/setting",
+				requireRepoAdmin,
+				withRepo,
+				requireMaintainer,
+				repo.HandleUpdateSetting(r.RepoStore, r.AuditStore),
+			)
+			g.PATCH("/report", requireRepoAdmin, withRepo, requireAdmin, repo.HandleReportIDRenew(r.RepoStore, r.SCMService, r.AuditStore))
+			g.GET("/files", withRepo, requireViewer, repo.HandleGetFiles(r.SCMService))
+			g.GET("/content/*path", withRepo, requireViewer, repo.HandleGetFileContent(r.SCMService))
+			g.POST("/hook/create", requireRepoAdmin, withRepo, requireMaintainer, repo.HandleHookCreate(r.HookService, r.AuditStore))
+			g.DELETE("/hook", requireRepoAdmin, withRepo, requireMaintainer, repo.HandleHookDelete(r.HookService, r.AuditStore))
+			g.GET("/commits", withRepo, requireViewer, repo.HandleListCommits(r.SCMService))
+			g.GET("/branches", withRepo, requireViewer, repo.HandleListBranches(r.SCMService))
+			g.GET("/audit", withRepo, requireMaintainer, repo.HandleListAudit(r.AuditStore))
+			g.GET("/export", requireRepoAdmin, withRepo, requireAdmin, repo.HandleExport(r.ArchiveService, r.AuditStore))
+			g.POST("/import",
+				requireRepoAdmin,
+				withRepo,
+				requireAdmin,
+				repo.HandleImport(r.Config, r.ArchiveService, r.RepoStore, r.AuditStore),
+			)
+			{
+				// nolint:govet
+				g := g.Group("/tokens")
+				g.Use(requireRepoAdmin, withRepo, requireMaintainer)
+				g.GET("", repo.HandleListTokens(r.RepoStore))
+				g.POST("", repo.HandleCreateToken(r.RepoStore, r.AuditStore))
+				g.PATCH("/:id", repo.HandleRenewToken(r.RepoStore, r.AuditStore))
+				g.DELETE("/:id", repo.HandleDeleteToken(r.RepoStore, r.AuditStore))
+			}
+			{
+				// nolint:govet
+				g := g.Group("/roles")
+				g.Use(requireRepoAdmin, withRepo)
+				g.GET("", requireMaintainer, repo.HandleListRoles(r.PermissionStore))
+				g.PUT("/:login", requireAdmin, repo.HandleOverrideRole(r.UserStore, r.PermissionStore, r.AuditStore))
+				g.DELETE("/:login", requireAdmin, repo.HandleDeleteRole(r.UserStore, r.PermissionStore, r.AuditStore))
+			}
 		}
 	}
 	{
@@ -133,6 +241,6 @@ func (r *Router) RegisterRoutes(e *gin.Engine) {
 		// nolint:govet
 		g := g.Group("/repos/:scm/:namespace/:name")
 		g.GET("", repo.HandleGet(r.RepoStore))
-		g.POST("/hook", repo.WithRepo(r.RepoStore), repo.HandleHook(r.SCMService, r.HookService))
+		g.POST("/hook", withRepo, repo.HandleHook(r.SCMService, r.HookService))
 	}
 }
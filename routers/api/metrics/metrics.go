@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+// HandleGetCache metrics in Prometheus text format
+// @Summary get hits, misses and hit rates of cached stores
+// @Tags Metrics
+// @Produce plain
+// @Success 200 {string} string "metrics"
+// @Router /metrics [get]
+func HandleGetCache(metrics core.CacheMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := metrics.Stats()
+		buf := &bytes.Buffer{}
+		fmt.Fprintln(buf, "# HELP covergates_cache_hits_total Lookups served from the cache.")
+		fmt.Fprintln(buf, "# TYPE covergates_cache_hits_total counter")
+		for _, s := range stats {
+			fmt.Fprintf(buf, "covergates_cache_hits_total{store=%q} %d\n", s.Name, s.Hits)
+		}
+		fmt.Fprintln(buf, "# HELP covergates_cache_misses_total Lookups loaded from the database.")
+		fmt.Fprintln(buf, "# TYPE covergates_cache_misses_total counter")
+		for _, s := range stats {
+			fmt.Fprintf(buf, "covergates_cache_misses_total{store=%q} %d\n", s.Name, s.Misses)
+		}
+		fmt.Fprintln(buf, "# HELP covergates_cache_hit_rate Ratio of lookups served from the cache.")
+		fmt.Fprintln(buf, "# TYPE covergates_cache_hit_rate gauge")
+		for _, s := range stats {
+			fmt.Fprintf(buf, "covergates_cache_hit_rate{store=%q} %g\n", s.Name, s.HitRate())
+		}
+		c.Data(200, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+	}
+}
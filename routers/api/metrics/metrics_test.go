@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+type stubMetrics []*core.CacheStats
+
+func (m stubMetrics) Stats() []*core.CacheStats {
+	return m
+}
+
+func TestHandleGetCache(t *testing.T) {
+	r := gin.Default()
+	r.GET("/metrics", HandleGetCache(stubMetrics{
+		{Name: "report", Hits: 3, Misses: 1},
+		{Name: "repo"},
+	}))
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	result := w.Result()
+	defer result.Body.Close()
+	if result.StatusCode != 200 {
+		t.Fatal(result.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(result.Body)
+	for _, line := range []string{
+		`covergates_cache_hits_total{store="report"} 3`,
+		`covergates_cache_misses_total{store="report"} 1`,
+		`covergates_cache_hit_rate{store="report"} 0.75`,
+		`covergates_cache_hit_rate{store="repo"} 0`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Fatalf("missing %s in\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+// HandleExport repository archive
+// @Summary export the repository with its setting, reports, references and comments as a zip archive
+// @Tags Repository
+// @Produce application/zip
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Success 200 {file} file "repository archive"
+// @Router /repos/{scm}/{namespace}/{name}/export [get]
+func HandleExport(service core.ArchiveService, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.zip"`, repo.NameSpace, repo.Name))
+		c.Status(200)
+		if err := service.Export(c.Request.Context(), repo, c.Writer); err != nil {
+			_ = c.Error(err)
+			if !c.Writer.Written() {
+				c.Writer.Header().Del("Content-Type")
+				c.Writer.Header().Del("Content-Disposition")
+				c.String(500, err.Error())
+			}
+			// the archive is left incomplete if it is partially written
+			return
+		}
+		request.Audit(c, auditStore, repo, core.AuditRepoExport, nil, nil)
+	}
+}
+
+// HandleImport repository archive
+// @Summary import the repository archive, importing the same archive again does not duplicate data
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param file formData file true "repository archive"
+// @Success 200 {object} core.Repo imported repository
+// @Failure 400 {string} string "error message"
+// @Failure 413 {string} string "error message"
+// @Router /repos/{scm}/{namespace}/{name}/import [post]
+func HandleImport(
+	config *config.Config,
+	service core.ArchiveService,
+	repoStore core.RepoStore,
+	auditStore core.AuditStore,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		user := request.MustGetUserFrom(c)
+		limit := config.Server.MaxArchiveSize
+		if c.Request.ContentLength > limit {
+			c.String(413, fmt.Sprintf("archive should be %d bytes at most", limit))
+			return
+		}
+		// uploads of unknown length fail to parse once they exceed the limit
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		file, err := c.FormFile("file")
+		if err != nil {
+			_ = c.Error(err)
+			c.String(400, err.Error())
+			return
+		}
+		reader, err := file.Open()
+		if err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			return
+		}
+		defer reader.Close()
+		before := gin.H{"reportID": repo.ReportID}
+		activated := repo.ReportID != ""
+		imported, err := service.Import(c.Request.Context(), reader, repo)
+		if errors.Is(err, core.ErrInvalidArchive) {
+			_ = c.Error(err)
+			c.String(400, err.Error())
+			return
+		} else if err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			return
+		}
+		if !activated && imported.ReportID != "" {
+			if err := repoStore.UpdateCreator(imported, user); err != nil {
+				_ = c.Error(err)
+				c.String(500, err.Error())
+				return
+			}
+		}
+		request.Audit(c, auditStore, imported, core.AuditRepoImport, before, gin.H{"reportID": imported.ReportID})
+		c.JSON(200, imported)
+	}
+}
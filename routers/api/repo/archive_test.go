@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+func TestArchive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockArchiveService(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
+	repo := mockRepo(store)
+	user := &core.User{Login: "user"}
+
+	r := gin.Default()
+	g := r.Group("/repos/:scm/:namespace/:name")
+	g.Use(func(c *gin.Context) {
+		request.WithUser(c, user)
+	}, WithRepo(store))
+	g.GET("/export", HandleExport(service, auditStore))
+	cfg := &config.Config{Server: config.Server{MaxArchiveSize: 1024}}
+	g.POST("/import", HandleImport(cfg, service, store, auditStore))
+
+	t.Run("export", func(t *testing.T) {
+		service.EXPECT().Export(gomock.Any(), gomock.Eq(repo), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ *core.Repo, w io.Writer) error {
+				_, err := w.Write([]byte("archive"))
+				return err
+			},
+		)
+		auditStore.EXPECT().Create(gomock.Any()).Return(nil)
+		req, _ := http.NewRequest("GET", "/repos/gitea/space/name/export", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			data, _ := ioutil.ReadAll(rst.Body)
+			if rst.StatusCode != 200 || string(data) != "archive" || rst.Header.Get("Content-Type") != "application/zip" {
+				t.Fatal("should write the archive")
+			}
+		})
+	})
+
+	t.Run("export error", func(t *testing.T) {
+		service.EXPECT().Export(gomock.Any(), gomock.Eq(repo), gomock.Any()).Return(fmt.Errorf("error"))
+		req, _ := http.NewRequest("GET", "/repos/gitea/space/name/export", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 500 || rst.Header.Get("Content-Disposition") != "" {
+				t.Fatal("should response error before the archive is written")
+			}
+		})
+	})
+
+	importRequest := func(data []byte) *http.Request {
+		body := &bytes.Buffer{}
+		w := multipart.NewWriter(body)
+		file, _ := w.CreateFormFile("file", "archive.zip")
+		_, _ = file.Write(data)
+		_ = w.Close()
+		req, _ := http.NewRequest("POST", "/repos/gitea/space/name/import", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	t.Run("import", func(t *testing.T) {
+		service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Eq(repo)).Return(repo, nil)
+		auditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if log.Action != core.AuditRepoImport || log.Actor != "user" {
+				t.Fatalf("unexpected audit log %v", log)
+			}
+			return nil
+		})
+		testRequest(r, importRequest([]byte("archive")), func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("archive too large", func(t *testing.T) {
+		testRequest(r, importRequest(make([]byte, 2048)), func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 413 {
+				t.Fatalf("should reject archives over the limit, got %d", rst.StatusCode)
+			}
+		})
+		// chunked uploads are not known to be too large until read
+		req := importRequest(make([]byte, 2048))
+		req.ContentLength = -1
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 400 {
+				t.Fatalf("should fail to read archives over the limit, got %d", rst.StatusCode)
+			}
+		})
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		service.EXPECT().Import(gomock.Any(), gomock.Any(), gomock.Eq(repo)).Return(
+			nil, fmt.Errorf("%w: bad", core.ErrInvalidArchive),
+		)
+		testRequest(r, importRequest([]byte("archive")), func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 400 {
+				t.Fatal()
+			}
+		})
+	})
+}
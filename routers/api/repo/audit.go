@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+// HandleListAudit logs of the repository
+// @Summary list audit logs of security-relevant actions to the repository, the latest first
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Success 200 {object} []core.AuditLog "audit logs"
+// @Router /repos/{scm}/{namespace}/{name}/audit [get]
+func HandleListAudit(store core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		logs, err := store.List(repo)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, []*core.AuditLog{})
+			return
+		}
+		c.JSON(200, logs)
+	}
+}
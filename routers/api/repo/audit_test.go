@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+func TestAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockHookService(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
+	repo := mockRepo(store)
+	user := &core.User{Login: "user"}
+
+	r := gin.Default()
+	g := r.Group("/repos/:scm/:namespace/:name")
+	g.Use(func(c *gin.Context) {
+		request.WithUser(c, user)
+	}, WithRepo(store))
+	g.DELETE("/hook", HandleHookDelete(service, auditStore))
+	g.GET("/audit", HandleListAudit(auditStore))
+
+	t.Run("record", func(t *testing.T) {
+		service.EXPECT().Delete(gomock.Any(), gomock.Eq(repo)).Return(nil)
+		auditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if log.Action != core.AuditHookDelete || log.Actor != "user" || log.IP == "" {
+				t.Fatalf("unexpected audit log %v", log)
+			}
+			return nil
+		})
+		req, _ := http.NewRequest("DELETE", "/repos/gitea/space/name/hook", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		auditStore.EXPECT().List(gomock.Eq(repo)).Return([]*core.AuditLog{
+			{ID: 2, Action: core.AuditHookDelete, Actor: "user"},
+			{ID: 1, Action: core.AuditHookCreate, Actor: "user"},
+		}, nil)
+		req, _ := http.NewRequest("GET", "/repos/gitea/space/name/audit", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			var logs []*core.AuditLog
+			data, _ := ioutil.ReadAll(rst.Body)
+			_ = json.Unmarshal(data, &logs)
+			if rst.StatusCode != 200 || len(logs) != 2 || logs[0].Action != core.AuditHookDelete {
+				t.Fatal()
+			}
+		})
+	})
+}
@@ -4,10 +4,9 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
 )
 
-const keyRepo = "repository"
-
 // WithRepo in context
 func WithRepo(store core.RepoStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -21,6 +20,6 @@ func WithRepo(store core.RepoStore) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		c.Set(keyRepo, repo)
+		request.WithRepo(c, repo)
 	}
 }
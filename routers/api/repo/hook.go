@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
 )
 
 // HandleHookCreate for the repository
@@ -14,14 +15,37 @@ import (
 // @Param name path string true "name"
 // @Success 200 {object} string ok
 // @Router /repos/{scm}/{namespace}/{name}/hook/create [post]
-func HandleHookCreate(service core.HookService) gin.HandlerFunc {
+func HandleHookCreate(service core.HookService, auditStore core.AuditStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		repo, _ := c.MustGet(keyRepo).(*core.Repo)
+		repo := request.MustGetRepo(c)
 		ctx := c.Request.Context()
 		if err := service.Create(ctx, repo); err != nil {
 			c.String(500, err.Error())
 			return
 		}
+		request.Audit(c, auditStore, repo, core.AuditHookCreate, nil, nil)
+		c.String(200, "ok")
+	}
+}
+
+// HandleHookDelete of the repository
+// @Summary delete repository webhook
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Success 200 {object} string ok
+// @Router /repos/{scm}/{namespace}/{name}/hook [delete]
+func HandleHookDelete(service core.HookService, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		ctx := c.Request.Context()
+		if err := service.Delete(ctx, repo); err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			return
+		}
+		request.Audit(c, auditStore, repo, core.AuditHookDelete, nil, nil)
 		c.String(200, "ok")
 	}
 }
@@ -36,7 +60,7 @@ func HandleHookCreate(service core.HookService) gin.HandlerFunc {
 // @Router /repos/{scm}/{namespace}/{name}/hook [post]
 func HandleHook(scm core.SCMService, service core.HookService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		repo := c.MustGet(keyRepo).(*core.Repo)
+		repo := request.MustGetRepo(c)
 		ctx := c.Request.Context()
 		client, err := scm.Client(repo.SCM)
 		if err != nil {
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/covergates/covergates/routers/api/request"
 )
 
+// defaultCommitLimit of a page of commits, which are listed as recent builds
+const defaultCommitLimit = 20
+
 // HandleCreate a repository
 // @Summary Create new repository for code coverage
 // @Tags Repository
@@ -61,7 +65,7 @@ func HandleCreate(store core.RepoStore, service core.SCMService) gin.HandlerFunc
 // @Param name path string true "name"
 // @Success 200 {object} core.Repo "updated repository"
 // @Router /repos/{scm}/{namespace}/{name}/report [patch]
-func HandleReportIDRenew(store core.RepoStore, service core.SCMService) gin.HandlerFunc {
+func HandleReportIDRenew(store core.RepoStore, service core.SCMService, auditStore core.AuditStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := request.MustGetUserFrom(c)
 		scm := core.SCMProvider(c.Param("scm"))
@@ -80,6 +84,7 @@ func HandleReportIDRenew(store core.RepoStore, service core.SCMService) gin.Hand
 			c.String(500, err.Error())
 			return
 		}
+		before := gin.H{"reportID": repo.ReportID}
 		repo.ReportID = client.Repositories().NewReportID(repo)
 		if err := store.Update(repo); err != nil {
 			_ = c.Error(err)
@@ -91,6 +96,7 @@ func HandleReportIDRenew(store core.RepoStore, service core.SCMService) gin.Hand
 			c.String(500, err.Error())
 			return
 		}
+		request.Audit(c, auditStore, repo, core.AuditReportIDRenew, before, gin.H{"reportID": repo.ReportID})
 		c.JSON(200, repo)
 	}
 }
@@ -174,7 +180,11 @@ func HandleSync(service core.SCMService, store core.RepoStore) gin.HandlerFunc {
 // To list repository for user, using API /user/repos instead for fatser response.
 // @Summary List repositories for all available SCM providers
 // @Tags Repository
+// @Param cursor query string false "offset of the page in the repository list, from the X-Next-Cursor header"
+// @Param limit query int false "number of repositories in a page"
 // @Success 200 {object} []core.Repo "repositories"
+// @Header 200 {string} Link "URL of the next page"
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
 // @Router /repos [get]
 func HandleListAll(config *config.Config, service core.SCMService, store core.RepoStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -184,6 +194,12 @@ func HandleListAll(config *config.Config, service core.SCMService, store core.Re
 			c.JSON(401, repositories)
 			return
 		}
+		page, err := request.PageFrom(c)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(400, repositories)
+			return
+		}
 		ctx := c.Request.Context()
 		for _, provider := range config.Providers() {
 			client, err := service.Client(provider)
@@ -196,7 +212,7 @@ func HandleListAll(config *config.Config, service core.SCMService, store core.Re
 			}
 			repositories = append(repositories, result...)
 		}
-		c.JSON(200, repositories)
+		writeRepositories(c, page, repositories)
 	}
 }
 
@@ -204,7 +220,11 @@ func HandleListAll(config *config.Config, service core.SCMService, store core.Re
 // @Summary List repositories
 // @Tags Repository
 // @Param scm path string true "SCM source (github, gitea)"
+// @Param cursor query string false "offset of the page in the repository list, from the X-Next-Cursor header"
+// @Param limit query int false "number of repositories in a page"
 // @Success 200 {object} []core.Repo "repositories"
+// @Header 200 {string} Link "URL of the next page"
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
 // @Router /repos/{scm} [get]
 func HandleListSCM(service core.SCMService, store core.RepoStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -214,6 +234,12 @@ func HandleListSCM(service core.SCMService, store core.RepoStore) gin.HandlerFun
 			c.JSON(401, []*core.Repo{})
 			return
 		}
+		page, err := request.PageFrom(c)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(400, []*core.Repo{})
+			return
+		}
 		ctx := c.Request.Context()
 		client, err := service.Client(scm)
 		if err != nil {
@@ -226,7 +252,7 @@ func HandleListSCM(service core.SCMService, store core.RepoStore) gin.HandlerFun
 			c.JSON(500, []*core.Repo{})
 			return
 		}
-		c.JSON(200, repositories)
+		writeRepositories(c, page, repositories)
 	}
 }
 
@@ -346,38 +372,40 @@ func HandleGetSetting(store core.RepoStore) gin.HandlerFunc {
 // @Param setting body core.RepoSetting true "repository setting"
 // @Success 200 {object} core.RepoSetting repository setting
 // @Router /repos/{scm}/{namespace}/{name}/setting [post]
-func HandleUpdateSetting(store core.RepoStore, service core.SCMService) gin.HandlerFunc {
+func HandleUpdateSetting(store core.RepoStore, auditStore core.AuditStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
-		repo := c.MustGet(keyRepo).(*core.Repo)
+		repo := request.MustGetRepo(c)
 		setting := &core.RepoSetting{}
-		user, ok := request.UserFrom(c)
-		if !ok {
-			c.JSON(401, setting)
+		if err := c.BindJSON(setting); err != nil {
+			c.JSON(400, setting)
 			return
 		}
-		creator, err := store.Creator(repo)
-		if err != nil {
-			c.JSON(500, setting)
+		if err := setting.Retention.Validate(); err != nil {
+			_ = c.Error(err)
+			c.JSON(400, setting)
 			return
 		}
-		gitClient, err := service.Client(repo.SCM)
-		if err != nil {
-			c.JSON(500, setting)
+		if err := setting.Badge.Validate(); err != nil {
+			_ = c.Error(err)
+			c.JSON(400, setting)
 			return
 		}
-		if !gitClient.Repositories().IsAdmin(ctx, user, repo.FullName()) && user.Login != creator.Login {
-			c.JSON(401, setting)
+		if err := setting.Card.Validate(); err != nil {
+			_ = c.Error(err)
+			c.JSON(400, setting)
 			return
 		}
-		if err := c.BindJSON(setting); err != nil {
-			c.JSON(400, setting)
+		before, err := store.Setting(repo)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, setting)
 			return
 		}
 		if err := store.UpdateSetting(repo, setting); err != nil {
 			c.JSON(500, setting)
 			return
 		}
+		request.Audit(c, auditStore, repo, core.AuditSettingUpdate, before, setting)
 		c.JSON(200, setting)
 	}
 }
@@ -389,16 +417,29 @@ func HandleUpdateSetting(store core.RepoStore, service core.SCMService) gin.Hand
 // @Param namespace path string true "Namespace"
 // @Param name path string true "name"
 // @Param ref query string false "branch to list commits from"
+// @Param cursor query string false "cursor of the page from the X-Next-Cursor header"
+// @Param limit query int false "number of commits in a page, default 20"
 // @Success 200 {object} []core.Commit commits
+// @Header 200 {string} Link "URL of the next page"
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
 // @Router /repos/{scm}/{namespace}/{name}/commits [get]
 func HandleListCommits(service core.SCMService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		repo := c.MustGet(keyRepo).(*core.Repo)
+		repo := request.MustGetRepo(c)
 		user, ok := request.UserFrom(c)
 		if !ok {
 			c.JSON(401, []*core.Commit{})
 			return
 		}
+		page, err := request.PageFrom(c)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(400, []*core.Commit{})
+			return
+		}
+		if page.Limit == 0 {
+			page.Limit = defaultCommitLimit
+		}
 		client, err := service.Client(repo.SCM)
 		if err != nil {
 			c.JSON(500, []*core.Commit{})
@@ -406,15 +447,21 @@ func HandleListCommits(service core.SCMService) gin.HandlerFunc {
 		}
 		ctx := c.Request.Context()
 		var commits []*core.Commit
+		var next string
 		if c.Query("ref") == "" {
-			commits, err = client.Git().ListCommits(ctx, user, repo.FullName())
+			commits, next, err = client.Git().ListCommits(ctx, user, repo.FullName(), page)
 		} else {
-			commits, err = client.Git().ListCommitsByRef(ctx, user, repo.FullName(), c.Query("ref"))
+			commits, next, err = client.Git().ListCommitsByRef(ctx, user, repo.FullName(), c.Query("ref"), page)
 		}
-		if err != nil {
+		if errors.Is(err, core.ErrInvalidCursor) {
+			c.JSON(400, []*core.Commit{})
+			return
+		} else if err != nil {
 			_ = c.Error(err)
 			c.JSON(500, []*core.Commit{})
+			return
 		}
+		request.WithNextPage(c, page, next)
 		c.JSON(200, commits)
 	}
 }
@@ -425,12 +472,22 @@ func HandleListCommits(service core.SCMService) gin.HandlerFunc {
 // @Param scm path string true "SCM"
 // @Param namespace path string true "Namespace"
 // @Param name path string true "name"
+// @Param cursor query string false "cursor of the page from the X-Next-Cursor header"
+// @Param limit query int false "number of branches in a page"
 // @Success 200 {object} []string branch names
+// @Header 200 {string} Link "URL of the next page"
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
 // @Router /repos/{scm}/{namespace}/{name}/branches [get]
 func HandleListBranches(service core.SCMService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		repo := c.MustGet(keyRepo).(*core.Repo)
+		repo := request.MustGetRepo(c)
 		user := request.MustGetUserFrom(c)
+		page, err := request.PageFrom(c)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(400, []string{})
+			return
+		}
 		client, err := service.Client(repo.SCM)
 		if err != nil {
 			_ = c.Error(err)
@@ -438,16 +495,32 @@ func HandleListBranches(service core.SCMService) gin.HandlerFunc {
 			return
 		}
 		ctx := c.Request.Context()
-		branches, err := client.Git().ListBranches(ctx, user, repo.FullName())
-		if err != nil {
+		branches, next, err := client.Git().ListBranches(ctx, user, repo.FullName(), page)
+		if errors.Is(err, core.ErrInvalidCursor) {
+			c.JSON(400, []string{})
+			return
+		} else if err != nil {
 			_ = c.Error(err)
 			c.JSON(500, []string{})
 			return
 		}
+		request.WithNextPage(c, page, next)
 		c.JSON(200, branches)
 	}
 }
 
+// writeRepositories of the page, whose cursor is the offset of the list.
+// Repositories are listed from SCM as a whole, so a page may shift if they change between requests.
+func writeRepositories(c *gin.Context, page core.Page, repositories []*core.Repo) {
+	start, end, next, err := request.OffsetPage(page, len(repositories))
+	if err != nil {
+		c.JSON(400, []*core.Repo{})
+		return
+	}
+	request.WithNextPage(c, page, next)
+	c.JSON(200, repositories[start:end])
+}
+
 func getRef(c *gin.Context, client core.Client, user *core.User) (string, error) {
 	repoName := fmt.Sprintf("%s/%s", c.Param("namespace"), c.Param("name"))
 	ref := c.Query("ref")
@@ -134,6 +134,91 @@ func TestListSCM(t *testing.T) {
 			t.Fail()
 		}
 	})
+
+	// list the second page
+	mockService.EXPECT().Client(gomock.Eq(core.Github)).Return(mockClient, nil)
+	mockClient.EXPECT().Repositories().Return(mockRepoService)
+	mockRepoService.EXPECT().List(gomock.Any(), gomock.Eq(user)).Return(scmRepos, nil)
+	mockStore.EXPECT().Finds(gomock.Eq(urls)).Return(storeRepos, nil)
+	req, _ = http.NewRequest("GET", "/repos/github?limit=1", nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		data, _ := ioutil.ReadAll(rst.Body)
+		var repos []*core.Repo
+		_ = json.Unmarshal(data, &repos)
+		if len(repos) != 1 || repos[0].Name != "repo1" || rst.Header.Get("X-Next-Cursor") != "1" {
+			t.Fatal("should list the first page")
+		}
+	})
+}
+
+func TestListBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockSCMService(ctrl)
+	repo := mockRepo(store)
+	user := &core.User{Login: "user"}
+	client := mockSCM(ctrl, service)
+	git := mock.NewMockGitService(ctrl)
+	client.EXPECT().Git().Return(git)
+	git.EXPECT().ListBranches(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(core.Page{Cursor: "2", Limit: 10}),
+	).Return([]string{"master"}, "3", nil)
+
+	r := gin.Default()
+	r.GET("/repos/:scm/:namespace/:name/branches", func(c *gin.Context) {
+		request.WithUser(c, user)
+	}, WithRepo(store), HandleListBranches(service))
+
+	req, _ := http.NewRequest("GET", "/repos/gitea/space/name/branches?cursor=2&limit=10", nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 {
+			t.Fatal()
+		}
+		link := `</repos/gitea/space/name/branches?cursor=3&limit=10>; rel="next"`
+		if rst.Header.Get("Link") != link || rst.Header.Get("X-Next-Cursor") != "3" {
+			t.Fatal("should link to the next page")
+		}
+	})
+}
+
+func TestListCommits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockSCMService(ctrl)
+	repo := mockRepo(store)
+	user := &core.User{Login: "user"}
+	client := mockSCM(ctrl, service)
+	git := mock.NewMockGitService(ctrl)
+	client.EXPECT().Git().Return(git)
+	git.EXPECT().ListCommits(
+		gomock.Any(), gomock.Eq(user), gomock.Eq(repo.FullName()), gomock.Eq(core.Page{Limit: defaultCommitLimit}),
+	).Return([]*core.Commit{{Sha: "sha"}}, "2", nil)
+
+	r := gin.Default()
+	r.GET("/repos/:scm/:namespace/:name/commits", func(c *gin.Context) {
+		request.WithUser(c, user)
+	}, WithRepo(store), HandleListCommits(service))
+
+	req, _ := http.NewRequest("GET", "/repos/gitea/space/name/commits", nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 {
+			t.Fatal()
+		}
+		link := `</repos/gitea/space/name/commits?cursor=2&limit=20>; rel="next"`
+		if rst.Header.Get("Link") != link {
+			t.Fatal("should list recent commits in the default page")
+		}
+	})
 }
 
 func TestReportIDRenew(t *testing.T) {
@@ -165,12 +250,21 @@ func TestReportIDRenew(t *testing.T) {
 	mockService.EXPECT().Client(gomock.Eq(core.Github)).Return(mockClient, nil)
 	mockClient.EXPECT().Repositories().Return(mockRepositories)
 	mockRepositories.EXPECT().NewReportID(gomock.Eq(repo)).Return("123")
+	mockAuditStore := mock.NewMockAuditStore(ctrl)
+	mockAuditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+		if log.Action != core.AuditReportIDRenew ||
+			string(log.Before) != `{"reportID":""}` ||
+			string(log.After) != `{"reportID":"123"}` {
+			t.Fatalf("unexpected audit log %v", log)
+		}
+		return nil
+	})
 
 	r := gin.Default()
 	r.Use(func(c *gin.Context) {
 		request.WithUser(c, user)
 	})
-	r.PATCH("/repos/:scm/:namespace/:name/report", HandleReportIDRenew(mockStore, mockService))
+	r.PATCH("/repos/:scm/:namespace/:name/report", HandleReportIDRenew(mockStore, mockService, mockAuditStore))
 
 	req, _ := http.NewRequest("PATCH", "/repos/github/github/repo/report", nil)
 	testRequest(r, req, func(h *httptest.ResponseRecorder) {
@@ -181,3 +275,53 @@ func TestReportIDRenew(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateSetting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
+	repo := mockRepo(store)
+
+	r := gin.Default()
+	r.POST("/repos/:scm/:namespace/:name/setting", WithRepo(store), HandleUpdateSetting(store, auditStore))
+
+	post := func(setting *core.RepoSetting) int {
+		data, _ := json.Marshal(setting)
+		req, _ := http.NewRequest("POST", "/repos/gitea/space/name/setting", bytes.NewReader(data))
+		status := 0
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			status = rst.StatusCode
+		})
+		return status
+	}
+
+	invalid := &core.RepoSetting{Retention: core.RetentionPolicy{DefaultBranchDays: -1}}
+	if post(invalid) != 400 {
+		t.Fatal("should reject negative retention days")
+	}
+	invalid = &core.RepoSetting{Badge: core.BadgeSetting{Low: 80, High: 50}}
+	if post(invalid) != 400 {
+		t.Fatal("should reject badge thresholds out of order")
+	}
+	for _, card := range []core.CardSetting{
+		{Theme: "neon"},
+		{Colors: core.CardTheme{Title: "red"}},
+		{Metrics: []core.CardMetric{"stars"}},
+	} {
+		if post(&core.RepoSetting{Card: card}) != 400 {
+			t.Fatalf("should reject card setting %v", card)
+		}
+	}
+
+	setting := &core.RepoSetting{Retention: core.RetentionPolicy{DefaultBranchDays: 30, LatestOnly: true}}
+	store.EXPECT().Setting(gomock.Eq(repo)).Return(&core.RepoSetting{}, nil)
+	store.EXPECT().UpdateSetting(gomock.Eq(repo), gomock.Eq(setting)).Return(nil)
+	auditStore.EXPECT().Create(gomock.Any()).Return(nil)
+	if post(setting) != 200 {
+		t.Fatal("should update retention policy")
+	}
+}
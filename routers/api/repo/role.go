@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+// HandleListRoles of users to the repository
+// @Summary list roles of users to the repository
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Success 200 {object} []core.RepoPermission "roles"
+// @Router /repos/{scm}/{namespace}/{name}/roles [get]
+func HandleListRoles(store core.PermissionStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		permissions, err := store.List(repo)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, []*core.RepoPermission{})
+			return
+		}
+		c.JSON(200, permissions)
+	}
+}
+
+// HandleOverrideRole of the user to the repository
+// @Summary override role of the user to the repository locally
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param login path string true "user login"
+// @Param role formData string true "viewer, uploader, maintainer or admin"
+// @Success 200 {object} string ok
+// @Router /repos/{scm}/{namespace}/{name}/roles/{login} [put]
+func HandleOverrideRole(userStore core.UserStore, store core.PermissionStore, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := core.Role(c.PostForm("role"))
+		if !role.IsValid() {
+			c.String(400, "invalid role")
+			return
+		}
+		overrideRole(c, userStore, store, auditStore, role)
+	}
+}
+
+// HandleDeleteRole override of the user to the repository
+// @Summary remove local role override, the role synchronized from SCM is used instead
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param login path string true "user login"
+// @Success 200 {object} string ok
+// @Router /repos/{scm}/{namespace}/{name}/roles/{login} [delete]
+func HandleDeleteRole(userStore core.UserStore, store core.PermissionStore, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overrideRole(c, userStore, store, auditStore, core.RoleNone)
+	}
+}
+
+func overrideRole(
+	c *gin.Context,
+	userStore core.UserStore,
+	store core.PermissionStore,
+	auditStore core.AuditStore,
+	role core.Role,
+) {
+	repo := request.MustGetRepo(c)
+	user, err := userStore.FindByLogin(c.Param("login"))
+	if err != nil {
+		c.String(404, "user not found")
+		return
+	}
+	before := gin.H{"login": user.Login, "override": core.RoleNone}
+	if permission, err := store.Find(repo, user); err == nil {
+		before["override"] = permission.Override
+	}
+	if err := store.Override(repo, user, role); err != nil {
+		_ = c.Error(err)
+		c.String(500, err.Error())
+		return
+	}
+	after := gin.H{"login": user.Login, "override": role}
+	request.Audit(c, auditStore, repo, core.AuditRoleOverride, before, after)
+	c.String(200, "ok")
+}
@@ -0,0 +1,158 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+func TestRequireRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := &core.User{Login: "user"}
+	store := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockPermissionService(ctrl)
+	repo := mockRepo(store)
+
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		request.WithUser(c, user)
+	})
+	r.GET("/repos/:scm/:namespace/:name/tokens",
+		WithRepo(store),
+		request.RequireRole(service, core.RoleMaintainer),
+		func(c *gin.Context) {
+			c.String(200, "ok")
+		},
+	)
+
+	req, _ := http.NewRequest("GET", "/repos/gitea/space/name/tokens", nil)
+
+	service.EXPECT().Role(gomock.Any(), gomock.Eq(repo), gomock.Eq(user)).Return(core.RoleUploader, nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 401 {
+			t.Fatal("should deny uploader")
+		}
+	})
+
+	service.EXPECT().Role(gomock.Any(), gomock.Eq(repo), gomock.Eq(user)).Return(core.RoleAdmin, nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 {
+			t.Fatal("should allow admin")
+		}
+	})
+}
+
+func TestRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	userStore := mock.NewMockUserStore(ctrl)
+	permissionStore := mock.NewMockPermissionStore(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
+	repo := mockRepo(store)
+	user := &core.User{Login: "user"}
+
+	r := gin.Default()
+	g := r.Group("/repos/:scm/:namespace/:name/roles")
+	g.Use(WithRepo(store))
+	g.GET("", HandleListRoles(permissionStore))
+	g.PUT("/:login", HandleOverrideRole(userStore, permissionStore, auditStore))
+	g.DELETE("/:login", HandleDeleteRole(userStore, permissionStore, auditStore))
+
+	newRequest := func(method, role string) *http.Request {
+		buf := &bytes.Buffer{}
+		w := multipart.NewWriter(buf)
+		_ = w.WriteField("role", role)
+		_ = w.Close()
+		req, _ := http.NewRequest(method, "/repos/gitea/space/name/roles/user", buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	t.Run("list", func(t *testing.T) {
+		permissionStore.EXPECT().List(gomock.Eq(repo)).Return([]*core.RepoPermission{
+			{Login: "user", Role: core.RoleViewer, Override: core.RoleMaintainer},
+		}, nil)
+		req, _ := http.NewRequest("GET", "/repos/gitea/space/name/roles", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			var permissions []*core.RepoPermission
+			data, _ := ioutil.ReadAll(rst.Body)
+			_ = json.Unmarshal(data, &permissions)
+			if rst.StatusCode != 200 || len(permissions) != 1 {
+				t.Fatal()
+			}
+			if permissions[0].Effective() != core.RoleMaintainer {
+				t.Fatal("override should take precedence")
+			}
+		})
+	})
+
+	t.Run("override", func(t *testing.T) {
+		testRequest(r, newRequest("PUT", "owner"), func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 400 {
+				t.Fatal("should check role")
+			}
+		})
+		userStore.EXPECT().FindByLogin(gomock.Eq("user")).Return(user, nil)
+		permissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(nil, gorm.ErrRecordNotFound)
+		permissionStore.EXPECT().Override(gomock.Eq(repo), gomock.Eq(user), gomock.Eq(core.RoleUploader)).Return(nil)
+		auditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if log.Action != core.AuditRoleOverride || string(log.After) != `{"login":"user","override":"uploader"}` {
+				t.Fatalf("unexpected audit log %s", log.After)
+			}
+			return nil
+		})
+		testRequest(r, newRequest("PUT", "uploader"), func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		userStore.EXPECT().FindByLogin(gomock.Eq("user")).Return(user, nil)
+		permissionStore.EXPECT().Find(gomock.Eq(repo), gomock.Eq(user)).Return(
+			&core.RepoPermission{Login: "user", Override: core.RoleUploader}, nil,
+		)
+		permissionStore.EXPECT().Override(gomock.Eq(repo), gomock.Eq(user), gomock.Eq(core.RoleNone)).Return(nil)
+		auditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if string(log.Before) != `{"login":"user","override":"uploader"}` {
+				t.Fatalf("unexpected audit log %s", log.Before)
+			}
+			return nil
+		})
+		req, _ := http.NewRequest("DELETE", "/repos/gitea/space/name/roles/user", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+}
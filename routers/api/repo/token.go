@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/routers/api/request"
+)
+
+// HandleListTokens of the repository
+// @Summary list repository upload tokens
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Success 200 {object} []core.RepoToken "list of tokens"
+// @Router /repos/{scm}/{namespace}/{name}/tokens [get]
+func HandleListTokens(store core.RepoStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		tokens, err := store.ListTokens(repo)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, []*core.RepoToken{})
+			return
+		}
+		c.JSON(200, tokens)
+	}
+}
+
+// HandleCreateToken for the repository
+// @Summary create repository upload token
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param name formData string false "token name"
+// @Success 200 {object} core.RepoToken "created token with secret"
+// @Router /repos/{scm}/{namespace}/{name}/tokens [post]
+func HandleCreateToken(store core.RepoStore, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		token, err := store.CreateToken(repo, c.PostForm("name"))
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, &core.RepoToken{})
+			return
+		}
+		request.Audit(c, auditStore, repo, core.AuditTokenCreate, nil, tokenSnapshot(token))
+		c.JSON(200, token)
+	}
+}
+
+// HandleRenewToken of the repository
+// @Summary regenerate secret of the repository upload token
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param id path integer true "token id"
+// @Success 200 {object} core.RepoToken "renewed token with secret"
+// @Router /repos/{scm}/{namespace}/{name}/tokens/{id} [patch]
+func HandleRenewToken(store core.RepoStore, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(400, &core.RepoToken{})
+			return
+		}
+		token, err := store.RenewToken(repo, &core.RepoToken{ID: uint(id)})
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(404, &core.RepoToken{})
+			return
+		}
+		request.Audit(c, auditStore, repo, core.AuditTokenRenew, nil, tokenSnapshot(token))
+		c.JSON(200, token)
+	}
+}
+
+// HandleDeleteToken of the repository
+// @Summary delete repository upload token
+// @Tags Repository
+// @Param scm path string true "SCM"
+// @Param namespace path string true "Namespace"
+// @Param name path string true "name"
+// @Param id path integer true "token id"
+// @Success 200 {object} string ok
+// @Failure 404 {object} string error message
+// @Router /repos/{scm}/{namespace}/{name}/tokens/{id} [delete]
+func HandleDeleteToken(store core.RepoStore, auditStore core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo := request.MustGetRepo(c)
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		tokens, err := store.ListTokens(repo)
+		if err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			return
+		}
+		var token *core.RepoToken
+		for _, t := range tokens {
+			if t.ID == uint(id) {
+				token = t
+			}
+		}
+		if token == nil {
+			c.String(404, "token not found")
+			return
+		}
+		if err := store.DeleteToken(repo, token); err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			return
+		}
+		request.Audit(c, auditStore, repo, core.AuditTokenDelete, tokenSnapshot(token), nil)
+		c.String(200, "ok")
+	}
+}
+
+// tokenSnapshot for audit log without the secret
+func tokenSnapshot(token *core.RepoToken) gin.H {
+	return gin.H{"id": token.ID, "name": token.Name}
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestRepoTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockRepoStore(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
+	repo := mockRepo(store)
+	expectAudit := func(action core.AuditAction) {
+		auditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if log.Action != action || log.Repo != repo {
+				t.Fatalf("unexpected audit log %v", log)
+			}
+			if string(log.After)+string(log.Before) == "" {
+				t.Fatal("should snapshot token")
+			}
+			return nil
+		})
+	}
+
+	r := gin.Default()
+	g := r.Group("/repos/:scm/:namespace/:name/tokens")
+	g.Use(WithRepo(store))
+	g.GET("", HandleListTokens(store))
+	g.POST("", HandleCreateToken(store, auditStore))
+	g.PATCH("/:id", HandleRenewToken(store, auditStore))
+	g.DELETE("/:id", HandleDeleteToken(store, auditStore))
+
+	t.Run("create", func(t *testing.T) {
+		expect := &core.RepoToken{ID: 1, Name: "ci", Token: "secret"}
+		store.EXPECT().CreateToken(gomock.Eq(repo), gomock.Eq("ci")).Return(expect, nil)
+		expectAudit(core.AuditTokenCreate)
+		buf := &bytes.Buffer{}
+		w := multipart.NewWriter(buf)
+		_ = w.WriteField("name", "ci")
+		_ = w.Close()
+		req, _ := http.NewRequest("POST", "/repos/gitea/space/name/tokens", buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+			token := &core.RepoToken{}
+			data, _ := ioutil.ReadAll(rst.Body)
+			_ = json.Unmarshal(data, token)
+			if diff := cmp.Diff(expect, token); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		store.EXPECT().ListTokens(gomock.Eq(repo)).Return([]*core.RepoToken{{ID: 1}, {ID: 2}}, nil)
+		req, _ := http.NewRequest("GET", "/repos/gitea/space/name/tokens", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			var tokens []*core.RepoToken
+			data, _ := ioutil.ReadAll(rst.Body)
+			_ = json.Unmarshal(data, &tokens)
+			if rst.StatusCode != 200 || len(tokens) != 2 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("renew", func(t *testing.T) {
+		store.EXPECT().RenewToken(
+			gomock.Eq(repo),
+			gomock.Eq(&core.RepoToken{ID: 1}),
+		).Return(&core.RepoToken{ID: 1, Token: "new"}, nil)
+		expectAudit(core.AuditTokenRenew)
+		req, _ := http.NewRequest("PATCH", "/repos/gitea/space/name/tokens/1", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		token := &core.RepoToken{ID: 1, Name: "ci"}
+		store.EXPECT().ListTokens(gomock.Eq(repo)).Return([]*core.RepoToken{token}, nil)
+		store.EXPECT().DeleteToken(gomock.Eq(repo), gomock.Eq(token)).Return(nil)
+		expectAudit(core.AuditTokenDelete)
+		req, _ := http.NewRequest("DELETE", "/repos/gitea/space/name/tokens/1", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+		store.EXPECT().ListTokens(gomock.Eq(repo)).Return([]*core.RepoToken{}, nil)
+		req, _ = http.NewRequest("DELETE", "/repos/gitea/space/name/tokens/1", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 404 {
+				t.Fatal("should not find deleted token")
+			}
+		})
+		req, _ = http.NewRequest("DELETE", "/repos/gitea/space/name/tokens/abc", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			rst := w.Result()
+			defer rst.Body.Close()
+			if rst.StatusCode != 400 {
+				t.Fatal()
+			}
+		})
+	})
+}
@@ -1,42 +1,227 @@
 package report
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/narqo/go-badge"
 
 	"github.com/covergates/covergates/core"
 )
 
+const (
+	defaultBadgeLabel = "Covergates"
+	unknownBadge      = "unknown"
+)
+
+var (
+	errBadgeReportNotFound = errors.New("report not found")
+	errBadgeFlagNotFound   = errors.New("report of the flag not found")
+)
+
+type badgeOptions struct {
+	Branch string `form:"branch"`
+	Flag   string `form:"flag"`
+	Style  string `form:"style"`
+	Metric string `form:"metric"`
+}
+
+// ShieldsBadge is the endpoint badge schema of Shields.io
+type ShieldsBadge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+	Style         string `json:"style,omitempty"`
+	CacheSeconds  int    `json:"cacheSeconds,omitempty"`
+}
+
+// badgeContent of the request with the reports it is made from
+type badgeContent struct {
+	badge   *core.Badge
+	repo    *core.Repo
+	setting *core.RepoSetting
+	reports []*core.Report
+}
+
+// getBadge in the options of the request, it writes the error response if failed
+func getBadge(c *gin.Context, reportStore core.ReportStore, repoStore core.RepoStore) (*badgeContent, bool) {
+	reportID := c.Param("id")
+	option := &badgeOptions{}
+	if err := c.BindQuery(option); err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	style, err := core.ParseBadgeStyle(option.Style)
+	if err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+	if err != nil {
+		c.String(404, "repository not found")
+		return nil, false
+	}
+	branch := option.Branch
+	if branch == "" {
+		branch = repo.Branch
+	}
+	// line coverage is kept in summaries, patch coverage needs files of the reports
+	find := reportStore.FindSummary
+	if option.Metric == "patch" {
+		find = reportStore.Find
+	}
+	report, err := findFlag(find, reportID, branch, option.Flag)
+	if err != nil {
+		c.String(404, err.Error())
+		return nil, false
+	}
+	content := &badgeContent{
+		repo:    repo,
+		setting: getSetting(c, repoStore, repo),
+		reports: []*core.Report{report},
+	}
+
+	var coverage float64
+	var ok bool
+	switch option.Metric {
+	case "", "line":
+		coverage, ok = summaryCoverage(report), true
+	case "patch":
+		if branch == repo.Branch {
+			break
+		}
+		base, err := findFlag(find, reportID, repo.Branch, option.Flag)
+		if err != nil {
+			base = &core.Report{}
+		}
+		content.reports = append(content.reports, base)
+		coverage, ok = report.PatchCoverage(base)
+	default:
+		c.String(400, fmt.Sprintf("metric %q not support", option.Metric))
+		return nil, false
+	}
+
+	label, exist := c.GetQuery("label")
+	if !exist {
+		label = defaultBadgeLabel
+	}
+	content.badge = &core.Badge{
+		Label:   label,
+		Message: unknownBadge,
+		Color:   core.BadgeGrey,
+		Style:   style,
+	}
+	if ok {
+		content.badge.Message = fmt.Sprintf("%d%%", int(coverage*100))
+		content.badge.Color = content.setting.Badge.Color(coverage)
+	}
+	return content, true
+}
+
+// findFlag finds the report of the branch, narrowed to the coverage of the flag if given
+func findFlag(
+	find func(r *core.Report) (*core.Report, error),
+	reportID, branch, flag string,
+) (*core.Report, error) {
+	report, err := find(&core.Report{ReportID: reportID, Reference: branch})
+	if err != nil {
+		return nil, errBadgeReportNotFound
+	}
+	if flag == "" {
+		return report, nil
+	}
+	coverage, ok := report.Find(core.ReportType(flag))
+	if !ok {
+		return nil, errBadgeFlagNotFound
+	}
+	flagged := *report
+	flagged.Coverages = []*core.CoverageReport{coverage}
+	return &flagged, nil
+}
+
 // HandleGetBadge for the report id
 // @Summary get badge for the report id
 // @Tags Report
+// @Produce image/svg+xml,image/png
 // @Param id path string true "report id"
-// @Param latest query bool false "get latest report in main branch"
-// @Success 200 {object} string "badge svg"
+// @Param branch query string false "branch of the report, default branch if empty"
+// @Param flag query string false "report type to show, such as go or perl"
+// @Param label query string false "label of the badge, default Covergates"
+// @Param style query string false "flat, flat-square or for-the-badge"
+// @Param metric query string false "line or patch coverage, default line"
+// @Param format query string false "svg or png, default svg"
+// @Param width query int false "width of png, 2048 at most"
+// @Param height query int false "height of png, 2048 at most"
+// @Success 200 {object} string "badge svg or png"
+// @Header 200 {string} ETag "entity tag of the badge"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
 // @Router /reports/{id}/badge [get]
 func HandleGetBadge(
 	reportStore core.ReportStore,
 	repoStore core.RepoStore,
+	chartService core.ChartService,
+	cache core.Cache,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		reportID := c.Param("id")
-		report, err := getLatest(reportStore, repoStore, reportID)
-		if err != nil {
-			c.String(500, err.Error())
+		option, ok := getImageOptions(c)
+		if !ok {
 			return
 		}
-		data, err := badge.RenderBytes(
-			"Covergates",
-			fmt.Sprintf("%d%%", int(report.StatementCoverage()*100)),
-			"#00838F",
-		)
-		if err != nil {
-			c.String(500, err.Error())
+		content, ok := getBadge(c, reportStore, repoStore)
+		if !ok {
 			return
 		}
+		tag := entityTag(c, content.repo, content.setting, content.reports...)
 		c.Header("Cache-Control", "max-age=600")
-		c.Data(200, "image/svg+xml", data)
+		if notModified(c, tag, lastModified(content.setting, content.reports...)) {
+			return
+		}
+		renderChart(c, chartService, cache, tag, option, func() core.Chart {
+			return chartService.Badge(content.badge)
+		})
+	}
+}
+
+// HandleGetShieldsBadge for the report id
+// @Summary get Shields.io endpoint badge for the report id
+// @Tags Report
+// @Param id path string true "report id"
+// @Param branch query string false "branch of the report, default branch if empty"
+// @Param flag query string false "report type to show, such as go or perl"
+// @Param label query string false "label of the badge, default Covergates"
+// @Param style query string false "flat, flat-square or for-the-badge"
+// @Param metric query string false "line or patch coverage, default line"
+// @Success 200 {object} ShieldsBadge "badge"
+// @Header 200 {string} ETag "entity tag of the badge"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/badge.json [get]
+func HandleGetShieldsBadge(
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		content, ok := getBadge(c, reportStore, repoStore)
+		if !ok {
+			return
+		}
+		tag := entityTag(c, content.repo, content.setting, content.reports...)
+		c.Header("Cache-Control", "max-age=600")
+		if notModified(c, tag, lastModified(content.setting, content.reports...)) {
+			return
+		}
+		c.JSON(200, &ShieldsBadge{
+			SchemaVersion: 1,
+			Label:         content.badge.Label,
+			Message:       content.badge.Message,
+			Color:         strings.TrimPrefix(content.badge.Color, "#"),
+			Style:         string(content.badge.Style),
+			CacheSeconds:  600,
+		})
 	}
 }
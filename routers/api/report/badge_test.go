@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jinzhu/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/charts"
+)
+
+func TestGetBadge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+
+	repo := &core.Repo{ID: 1, Branch: "master", ReportID: "report_id"}
+	hits := func(hits ...int) []*core.StatementHit {
+		result := make([]*core.StatementHit, len(hits))
+		for i, hit := range hits {
+			result[i] = &core.StatementHit{LineNumber: i + 1, Hits: hit}
+		}
+		return result
+	}
+	master := &core.Report{ReportID: repo.ReportID, Commit: "m", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{
+			{Name: "a.go", StatementCoverage: 0.9, StatementHits: hits(1, 1)},
+		}},
+		{Type: core.ReportPerl, Files: []*core.File{
+			{Name: "a.pl", StatementCoverage: 0.3},
+		}},
+	}}
+	feature := &core.Report{ReportID: repo.ReportID, Commit: "f", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{
+			{Name: "a.go", StatementCoverage: 0.5, StatementHits: hits(1, 0)},
+			{Name: "b.go", StatementCoverage: 0.5, StatementHits: hits(1, 0, 1, 1)},
+		}},
+	}}
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: repo.ReportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{
+		Badge: core.BadgeSetting{Low: 40, High: 60},
+	}, nil)
+	find := func(r *core.Report) (*core.Report, error) {
+		switch r.Reference {
+		case "master":
+			return master, nil
+		case "feature":
+			return feature, nil
+		}
+		return nil, gorm.ErrRecordNotFound
+	}
+	reportStore.EXPECT().Find(gomock.Any()).AnyTimes().DoAndReturn(find)
+	reportStore.EXPECT().FindSummary(gomock.Any()).AnyTimes().DoAndReturn(func(r *core.Report) (*core.Report, error) {
+		report, err := find(r)
+		if err != nil {
+			return nil, err
+		}
+		summary := *report
+		summary.Coverages = make([]*core.CoverageReport, len(report.Coverages))
+		for i, coverage := range report.Coverages {
+			summary.Coverages[i] = &core.CoverageReport{
+				Type:              coverage.Type,
+				StatementCoverage: coverage.ComputeStatementCoverage(),
+			}
+		}
+		return &summary, nil
+	})
+
+	r := gin.Default()
+	r.GET("/reports/:id/badge", HandleGetBadge(reportStore, repoStore, &charts.ChartService{}, nil))
+	r.GET("/reports/:id/badge.json", HandleGetShieldsBadge(reportStore, repoStore))
+
+	get := func(path string) (int, string) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		result := w.Result()
+		defer result.Body.Close()
+		data, _ := ioutil.ReadAll(result.Body)
+		return result.StatusCode, string(data)
+	}
+
+	tests := []struct {
+		query  string
+		status int
+		badge  *ShieldsBadge
+	}{
+		{query: "", status: 200, badge: &ShieldsBadge{Label: "Covergates", Message: "60%", Color: "44cc11"}},
+		{query: "?flag=perl&label=perl", status: 200, badge: &ShieldsBadge{Label: "perl", Message: "30%", Color: "e05d44"}},
+		{query: "?branch=feature&label=", status: 200, badge: &ShieldsBadge{Label: "", Message: "50%", Color: "a4a61d"}},
+		{query: "?branch=feature&metric=patch", status: 200, badge: &ShieldsBadge{Label: "Covergates", Message: "75%", Color: "44cc11"}},
+		{query: "?metric=patch", status: 200, badge: &ShieldsBadge{Label: "Covergates", Message: "unknown", Color: "9f9f9f"}},
+		{query: "?style=for-the-badge", status: 200, badge: &ShieldsBadge{
+			Label: "Covergates", Message: "60%", Color: "44cc11", Style: "for-the-badge",
+		}},
+		{query: "?metric=function", status: 400},
+		{query: "?metric=branch", status: 400},
+		{query: "?style=plastic", status: 400},
+		{query: "?branch=unknown", status: 404},
+		{query: "?flag=python", status: 404},
+	}
+	for _, test := range tests {
+		status, body := get("/reports/report_id/badge.json" + test.query)
+		if status != test.status {
+			t.Fatalf("%s: expect %d, got %d %s", test.query, test.status, status, body)
+		}
+		if status != 200 {
+			continue
+		}
+		badge := &ShieldsBadge{}
+		if err := json.Unmarshal([]byte(body), badge); err != nil {
+			t.Fatal(err)
+		}
+		if badge.Style == "" {
+			badge.Style = "flat"
+		}
+		test.badge.SchemaVersion = 1
+		test.badge.CacheSeconds = 600
+		if test.badge.Style == "" {
+			test.badge.Style = "flat"
+		}
+		if *badge != *test.badge {
+			t.Fatalf("%s: expect %v, got %v", test.query, test.badge, badge)
+		}
+
+		status, svg := get("/reports/report_id/badge" + test.query)
+		if status != 200 || !strings.Contains(svg, ">"+badge.Message+"</text>") || !strings.Contains(svg, "#"+badge.Color) {
+			t.Fatalf("%s: unexpected badge %d %s", test.query, status, svg)
+		}
+	}
+}
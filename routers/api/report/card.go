@@ -0,0 +1,249 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/covergates/covergates/core"
+)
+
+const unknownCard = "unknown"
+
+var cardLabels = map[core.CardMetric]string{
+	core.CardFiles:     "Total Files",
+	core.CardHits:      "Hit Lines",
+	core.CardBuild:     "Recent Build",
+	core.CardUncovered: "Uncovered Lines",
+	core.CardTrend:     "Trend",
+	core.CardCommit:    "Last Commit",
+}
+
+type cardOptions struct {
+	Theme      string `form:"theme"`
+	Metrics    string `form:"metrics"`
+	Background string `form:"bg_color"`
+	Footer     string `form:"footer_color"`
+	Title      string `form:"title_color"`
+	Text       string `form:"text_color"`
+	Icon       string `form:"icon_color"`
+	Accent     string `form:"accent_color"`
+}
+
+// hexColor of query values, which may omit the leading # as it has to be escaped in URLs
+func hexColor(color string) string {
+	if color == "" || strings.HasPrefix(color, "#") {
+		return color
+	}
+	return "#" + color
+}
+
+// cardTheme of the query options, which override the repository setting
+func (option *cardOptions) theme(setting *core.CardSetting) (core.CardTheme, error) {
+	name := option.Theme
+	colors := setting.Colors
+	if name == "" {
+		name = setting.Theme
+	} else {
+		// colors of the setting are made for its own theme
+		colors = core.CardTheme{}
+	}
+	theme, err := core.ParseCardTheme(name)
+	if err != nil {
+		return theme, err
+	}
+	query := core.CardTheme{
+		Background: hexColor(option.Background),
+		Footer:     hexColor(option.Footer),
+		Title:      hexColor(option.Title),
+		Text:       hexColor(option.Text),
+		Icon:       hexColor(option.Icon),
+		Accent:     hexColor(option.Accent),
+	}
+	if err := query.Validate(); err != nil {
+		return theme, err
+	}
+	return theme.Merge(colors).Merge(query), nil
+}
+
+// metrics of the query options, which override the repository setting
+func (option *cardOptions) metrics(setting *core.CardSetting) ([]core.CardMetric, error) {
+	if option.Metrics != "" {
+		return core.ParseCardMetrics(option.Metrics)
+	}
+	// settings may keep metrics no longer supported, such as branch
+	metrics := make([]core.CardMetric, 0, len(setting.Metrics))
+	for _, metric := range setting.Metrics {
+		if _, ok := cardLabels[metric]; ok {
+			metrics = append(metrics, metric)
+		}
+	}
+	if len(metrics) > 0 {
+		return metrics, nil
+	}
+	return core.DefaultCardMetrics, nil
+}
+
+// countLines of the report files into the hit and uncovered items,
+// which are left unknown if files of the report cannot be loaded
+func countLines(store core.ReportStore, report *core.Report, items map[core.CardMetric]*core.CardItem) {
+	full, err := store.Find(&core.Report{ReportID: report.ReportID, Commit: report.Commit})
+	if err != nil {
+		return
+	}
+	hits, misses := 0, 0
+	for _, coverage := range full.Coverages {
+		for _, file := range coverage.Files {
+			for _, line := range file.StatementHits {
+				if line.Hits > 0 {
+					hits++
+				} else {
+					misses++
+				}
+			}
+		}
+	}
+	p := message.NewPrinter(language.English)
+	if item, ok := items[core.CardHits]; ok {
+		item.Value = p.Sprintf("%d", hits)
+	}
+	if item, ok := items[core.CardUncovered]; ok {
+		item.Value = p.Sprintf("%d", misses)
+	}
+}
+
+// previousReport on the branch of the report, nil if it is the first one
+func previousReport(store core.ReportStore, report *core.Report, branch string) *core.Report {
+	reports, _, err := store.List(report.ReportID, branch, core.Page{Limit: 2})
+	if err != nil {
+		return nil
+	}
+	for _, r := range reports {
+		if r.Commit != report.Commit {
+			return r
+		}
+	}
+	return nil
+}
+
+// summaryCoverage of report summaries, which carry statement coverages without files
+func summaryCoverage(report *core.Report) float64 {
+	if len(report.Coverages) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, coverage := range report.Coverages {
+		sum += coverage.StatementCoverage
+	}
+	return sum / float64(len(report.Coverages))
+}
+
+// commitMessage of the report in a single line, unknown if it cannot be loaded from the SCM
+func commitMessage(
+	ctx context.Context,
+	service core.SCMService,
+	repoStore core.RepoStore,
+	repo *core.Repo,
+	report *core.Report,
+) string {
+	user, err := repoStore.Creator(repo)
+	if err != nil {
+		return unknownCard
+	}
+	client, err := service.Client(repo.SCM)
+	if err != nil {
+		return unknownCard
+	}
+	ref := report.Commit
+	if ref == "" {
+		ref = repo.Branch
+	}
+	commits, _, err := client.Git().ListCommitsByRef(ctx, user, repo.FullName(), ref, core.Page{Limit: 1})
+	if err != nil || len(commits) == 0 {
+		return unknownCard
+	}
+	message := strings.TrimSpace(commits[0].Message)
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = strings.TrimSpace(message[:i])
+	}
+	return message
+}
+
+// cardContent of the request with the reports it is made from
+type cardContent struct {
+	card    *core.Card
+	reports []*core.Report
+	// commit item is loaded from the SCM only when the card is rendered
+	commit *core.CardItem
+	// line items are counted from files of the report only when the card is rendered
+	lines map[core.CardMetric]*core.CardItem
+}
+
+// getCard of the report in the options of the request, it writes the error response if invalid
+func getCard(
+	c *gin.Context,
+	reportStore core.ReportStore,
+	repo *core.Repo,
+	setting *core.RepoSetting,
+	report *core.Report,
+) (*cardContent, bool) {
+	option := &cardOptions{}
+	if err := c.BindQuery(option); err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	theme, err := option.theme(&setting.Card)
+	if err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	metrics, err := option.metrics(&setting.Card)
+	if err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	content := &cardContent{
+		card: &core.Card{
+			Title:    repo.FullName(),
+			Coverage: summaryCoverage(report),
+			Theme:    theme,
+			Items:    make([]*core.CardItem, 0, len(metrics)),
+		},
+		reports: []*core.Report{report},
+		lines:   make(map[core.CardMetric]*core.CardItem),
+	}
+	p := message.NewPrinter(language.English)
+	for _, metric := range metrics {
+		value := unknownCard
+		switch metric {
+		case core.CardFiles:
+			value = p.Sprintf("%d", len(report.Files))
+		case core.CardBuild:
+			value = fmt.Sprintf("%s, %s", repo.Branch, humanize.Time(report.CreatedAt))
+		case core.CardTrend:
+			if previous := previousReport(reportStore, report, repo.Branch); previous != nil {
+				delta := (summaryCoverage(report) - summaryCoverage(previous)) * 100
+				value = fmt.Sprintf("%+.1f%%", delta)
+				content.reports = append(content.reports, previous)
+			}
+		}
+		item := &core.CardItem{
+			Metric: metric,
+			Label:  cardLabels[metric],
+			Value:  value,
+		}
+		switch metric {
+		case core.CardCommit:
+			content.commit = item
+		case core.CardHits, core.CardUncovered:
+			content.lines[metric] = item
+		}
+		content.card.Items = append(content.card.Items, item)
+	}
+	return content, true
+}
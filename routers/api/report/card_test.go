@@ -0,0 +1,114 @@
+package report
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestGetCardMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	gitService := mock.NewMockGitService(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	chartService := mock.NewMockChartService(ctrl)
+	chart := mock.NewMockChart(ctrl)
+
+	repo := &core.Repo{ReportID: "report_id", NameSpace: "org", Name: "repo", Branch: "master", SCM: core.Github}
+	user := &core.User{Login: "creator"}
+	report := &core.Report{ReportID: repo.ReportID, Commit: "new", CreatedAt: time.Now(), Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, StatementCoverage: 0.5, Files: []*core.File{
+			{Name: "a.go", StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: 1}, {LineNumber: 2}, {LineNumber: 3}}},
+		}},
+	}}
+	previous := &core.Report{ReportID: repo.ReportID, Commit: "old", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, StatementCoverage: 0.25},
+	}}
+	setting := &core.RepoSetting{Card: core.CardSetting{
+		Theme:   core.CardLight,
+		Colors:  core.CardTheme{Title: "#111111"},
+		Metrics: []core.CardMetric{core.CardFiles},
+	}}
+
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(setting, nil)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).Return(user, nil)
+	summary := &core.Report{ReportID: repo.ReportID, Commit: "new", CreatedAt: report.CreatedAt, Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, StatementCoverage: 0.5},
+	}}
+	reportStore.EXPECT().FindSummary(gomock.Any()).AnyTimes().Return(summary, nil)
+	// files are loaded only to count lines of the rendered card
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Commit: "new"})).Return(report, nil)
+	reportStore.EXPECT().List(
+		gomock.Eq(repo.ReportID),
+		gomock.Eq(repo.Branch),
+		gomock.Eq(core.Page{Limit: 2}),
+	).Return([]*core.Report{summary, previous}, "", nil)
+	service.EXPECT().Client(gomock.Eq(core.Github)).Return(client, nil)
+	client.EXPECT().Git().Return(gitService)
+	gitService.EXPECT().ListCommitsByRef(
+		gomock.Any(),
+		gomock.Eq(user),
+		gomock.Eq("org/repo"),
+		gomock.Eq("new"),
+		gomock.Eq(core.Page{Limit: 1}),
+	).Return([]*core.Commit{{Sha: "new", Message: "fix card\n\nlong description"}}, "", nil)
+
+	var card *core.Card
+	chartService.EXPECT().RepoCard(gomock.Any()).DoAndReturn(func(c *core.Card) core.Chart {
+		card = c
+		return chart
+	})
+	chart.EXPECT().Render(gomock.Any()).Return(nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/card", HandleGetCard(service, repoStore, reportStore, chartService, nil))
+	get := func(query string) int {
+		req, _ := http.NewRequest("GET", "/reports/report_id/card"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result().StatusCode
+	}
+
+	if status := get("?metrics=uncovered,trend,commit&accent_color=abcdef"); status != 200 {
+		t.Fatalf("unexpected status %d", status)
+	}
+	theme, _ := core.ParseCardTheme(core.CardLight)
+	expect := &core.Card{
+		Title:    "org/repo",
+		Coverage: 0.5,
+		Theme:    theme.Merge(core.CardTheme{Title: "#111111", Accent: "#abcdef"}),
+		Items: []*core.CardItem{
+			{Metric: core.CardUncovered, Label: "Uncovered Lines", Value: "2"},
+			{Metric: core.CardTrend, Label: "Trend", Value: "+25.0%"},
+			{Metric: core.CardCommit, Label: "Last Commit", Value: "fix card"},
+		},
+	}
+	if diff := cmp.Diff(expect, card); diff != "" {
+		t.Fatal(diff)
+	}
+
+	for _, query := range []string{
+		"?theme=neon",
+		"?metrics=stars",
+		"?metrics=files,hits,build,uncovered,trend,commit",
+		"?metrics=branch",
+		"?bg_color=red",
+	} {
+		if status := get(query); status != 400 {
+			t.Fatalf("%s should be bad request, got %d", query, status)
+		}
+	}
+}
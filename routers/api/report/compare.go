@@ -0,0 +1,90 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+)
+
+type compareOptions struct {
+	Base   string `form:"base" binding:"required"`
+	Head   string `form:"head" binding:"required"`
+	Format string `form:"format"`
+}
+
+// HandleCompare reports of two refs, such as tags of releases
+// @Summary Compare coverage of the head report against the base report
+// @Tags Report
+// @Produce json,text/markdown
+// @Param id path string true "report id"
+// @Param base query string true "commit or branch of the base report"
+// @Param head query string true "commit or branch of the head report"
+// @Param format query string false "json or markdown, default json"
+// @Success 200 {object} core.ReportComparison "comparison of reports"
+// @Header 200 {string} ETag "entity tag of the comparison"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/compare [get]
+func HandleCompare(
+	reportStore core.ReportStore,
+	reportService core.ReportService,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		option := &compareOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		switch option.Format {
+		case "":
+			option.Format = formatJSON
+		case formatJSON, formatMarkdown:
+		default:
+			c.String(400, fmt.Sprintf("format %q not support", option.Format))
+			return
+		}
+		base, err := getRef(reportStore, reportID, option.Base)
+		if err != nil {
+			c.String(404, "base report not found")
+			return
+		}
+		head, err := getRef(reportStore, reportID, option.Head)
+		if err != nil {
+			c.String(404, "head report not found")
+			return
+		}
+		if notModified(c, entityTag(c, nil, nil, base, head), lastModified(nil, base, head)) {
+			return
+		}
+		comparison, err := reportService.CompareReports(base, head)
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		if option.Format == formatJSON {
+			c.JSON(200, comparison)
+			return
+		}
+		reader, err := reportService.MarkdownComparison(comparison)
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		c.Data(200, "text/markdown; charset=utf-8", data)
+	}
+}
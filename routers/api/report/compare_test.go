@@ -0,0 +1,70 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jinzhu/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	reportService "github.com/covergates/covergates/modules/report"
+)
+
+func TestCompare(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reportStore := mock.NewMockReportStore(ctrl)
+	base := &core.Report{ReportID: "report_id", Commit: "sha1", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{{Name: "a.go", StatementCoverage: 0.5}}},
+	}}
+	head := &core.Report{ReportID: "report_id", Commit: "sha2", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{{Name: "a.go", StatementCoverage: 1}}},
+	}}
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: "report_id", Commit: "sha1"})).AnyTimes().Return(base, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: "report_id", Commit: "v2"})).AnyTimes().Return(nil, gorm.ErrRecordNotFound)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: "report_id", Reference: "v2"})).AnyTimes().Return(head, nil)
+	reportStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(nil, gorm.ErrRecordNotFound)
+
+	r := gin.Default()
+	r.GET("/reports/:id/compare", HandleCompare(reportStore, &reportService.Service{}))
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/reports/report_id/compare"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?base=sha1&head=v2")
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	comparison := &core.ReportComparison{}
+	if err := json.Unmarshal(w.Body.Bytes(), comparison); err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Base != "sha1" || comparison.Head != "sha2" || comparison.StatementCoverageDiff != 0.5 {
+		t.Fatalf("unexpected comparison %+v", comparison)
+	}
+
+	w = get("?base=sha1&head=v2&format=markdown")
+	if w.Code != 200 || !strings.HasPrefix(w.Body.String(), "### Coverage: 100.0% (+50.0%) from `sha1` to `sha2`") {
+		t.Fatalf("unexpected markdown %d %s", w.Code, w.Body.String())
+	}
+
+	for query, status := range map[string]int{
+		"?base=sha1":                     400,
+		"?base=sha1&head=v2&format=html": 400,
+		"?base=v0&head=v2":               404,
+	} {
+		if w := get(query); w.Code != status {
+			t.Fatalf("%s should be %d, got %d", query, status, w.Code)
+		}
+	}
+}
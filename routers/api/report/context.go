@@ -6,20 +6,7 @@ import (
 	"github.com/covergates/covergates/core"
 )
 
-const (
-	keyRepo    = "report_repo"
-	keySetting = "report_setting"
-)
-
-// WithRepo context
-func WithRepo(c *gin.Context, repo *core.Repo) {
-	c.Set(keyRepo, repo)
-}
-
-// MustGetRepo from context
-func MustGetRepo(c *gin.Context) *core.Repo {
-	return c.MustGet(keyRepo).(*core.Repo)
-}
+const keySetting = "report_setting"
 
 // WithSetting context
 func WithSetting(c *gin.Context, setting *core.RepoSetting) {
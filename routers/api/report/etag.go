@@ -0,0 +1,129 @@
+package report
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/covergates/covergates/core"
+)
+
+// renderTTL of rendered content in the cache, which is keyed by its entity tag
+const renderTTL = 10 * time.Minute
+
+// entityTag of the content of the request made from the reports.
+// It changes with the request URI, the reports, and the repository with its setting if given.
+func entityTag(c *gin.Context, repo *core.Repo, setting *core.RepoSetting, reports ...*core.Report) string {
+	h := sha1.New()
+	fmt.Fprintln(h, c.Request.URL.RequestURI())
+	if repo != nil {
+		fmt.Fprintln(h, repo.SCM, repo.NameSpace, repo.Name, repo.Branch, repo.Private)
+	}
+	if setting != nil {
+		data, _ := json.Marshal(setting)
+		h.Write(data)
+		fmt.Fprintln(h)
+	}
+	for _, report := range reports {
+		fmt.Fprintln(h, report.ReportID, report.Commit, report.Reference, report.CreatedAt.UnixNano(), len(report.Files))
+		// coverages of the same commit are replaced or appended by uploads
+		for _, coverage := range report.Coverages {
+			fmt.Fprintln(h, coverage.Type, coverage.StatementCoverage, len(coverage.Files))
+		}
+	}
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(h.Sum(nil)))
+}
+
+// changesTag of the content made from the tag and files changed in a pull request,
+// which change with new commits of the pull request even if no report is uploaded
+func changesTag(tag string, changes []*core.FileChange) string {
+	h := sha1.New()
+	fmt.Fprintln(h, tag)
+	for _, change := range changes {
+		fmt.Fprintln(h, change.Path, change.Added, change.Renamed, change.Deleted)
+	}
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(h.Sum(nil)))
+}
+
+// lastModified of the reports, and the repository setting if given
+func lastModified(setting *core.RepoSetting, reports ...*core.Report) time.Time {
+	var modified time.Time
+	if setting != nil {
+		modified = setting.UpdatedAt
+	}
+	for _, report := range reports {
+		if report.CreatedAt.After(modified) {
+			modified = report.CreatedAt
+		}
+	}
+	return modified
+}
+
+// notModified sets validators of the content and answers 304 if the client has it already.
+// If-None-Match takes precedence over If-Modified-Since.
+func notModified(c *gin.Context, tag string, modified time.Time) bool {
+	c.Header("ETag", tag)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	match := false
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == tag || candidate == "*" {
+				match = true
+				break
+			}
+		}
+	} else if header := c.GetHeader("If-Modified-Since"); header != "" && !modified.IsZero() {
+		if since, err := http.ParseTime(header); err == nil {
+			match = !modified.Truncate(time.Second).After(since)
+		}
+	}
+	if match {
+		c.Status(http.StatusNotModified)
+	}
+	return match
+}
+
+// render the content of the tag, or load it from the cache if it is rendered already
+func render(cache core.Cache, tag string, f func(w io.Writer) error) ([]byte, error) {
+	key := "render:" + tag
+	if cache != nil {
+		if data, err := cache.Get(key); err == nil {
+			return data, nil
+		}
+	}
+	buffer := &bytes.Buffer{}
+	if err := f(buffer); err != nil {
+		return nil, err
+	}
+	data := buffer.Bytes()
+	if cache != nil {
+		if err := cache.Set(key, data, renderTTL); err != nil {
+			log.Warningf("fail to cache rendered content: %v", err)
+		}
+	}
+	return data, nil
+}
+
+// getSetting of the repository from the context, or from the store if it is not in the context
+func getSetting(c *gin.Context, store core.RepoStore, repo *core.Repo) *core.RepoSetting {
+	if setting, ok := c.Get(keySetting); ok {
+		return setting.(*core.RepoSetting)
+	}
+	setting, err := store.Setting(repo)
+	if err != nil {
+		return &core.RepoSetting{}
+	}
+	return setting
+}
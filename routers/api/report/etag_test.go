@@ -0,0 +1,121 @@
+package report
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/cache"
+)
+
+func TestConditionalRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	chartService := mock.NewMockChartService(ctrl)
+	chart := mock.NewMockChart(ctrl)
+
+	repo := &core.Repo{ID: 1, Branch: "master", ReportID: "report_id"}
+	createdAt := time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)
+	report := &core.Report{ReportID: repo.ReportID, Commit: "sha", CreatedAt: createdAt}
+	setting := &core.RepoSetting{}
+
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: repo.ReportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().DoAndReturn(
+		func(*core.Repo) (*core.RepoSetting, error) {
+			return setting, nil
+		},
+	)
+	reportStore.EXPECT().FindSummary(gomock.Eq(
+		&core.Report{ReportID: repo.ReportID, Reference: repo.Branch},
+	)).AnyTimes().Return(report, nil)
+	reportStore.EXPECT().Find(gomock.Eq(
+		&core.Report{ReportID: repo.ReportID, Commit: report.Commit},
+	)).AnyTimes().Return(report, nil)
+	chartService.EXPECT().RepoCard(gomock.Any()).AnyTimes().Return(chart)
+	// rendered once for each setting
+	chart.EXPECT().Render(gomock.Any()).Times(2).DoAndReturn(func(w io.Writer) error {
+		_, err := io.WriteString(w, "<svg></svg>")
+		return err
+	})
+
+	r := gin.Default()
+	r.GET("/reports/:id/card", HandleGetCard(nil, repoStore, reportStore, chartService, cache.NewMemory(10)))
+	get := func(header, value string) *http.Response {
+		req, _ := http.NewRequest("GET", "/reports/report_id/card", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	result := get("", "")
+	result.Body.Close()
+	tag := result.Header.Get("ETag")
+	if result.StatusCode != 200 || tag == "" {
+		t.Fatal(result.StatusCode, tag)
+	}
+	if modified := result.Header.Get("Last-Modified"); modified != "Sat, 01 Aug 2020 10:00:00 GMT" {
+		t.Fatal(modified)
+	}
+
+	tests := []struct {
+		header string
+		value  string
+		status int
+	}{
+		{header: "If-None-Match", value: tag, status: 304},
+		{header: "If-None-Match", value: `"other", W/` + tag, status: 304},
+		{header: "If-None-Match", value: `"other"`, status: 200},
+		{header: "If-Modified-Since", value: "Sat, 01 Aug 2020 10:00:00 GMT", status: 304},
+		{header: "If-Modified-Since", value: "Sat, 01 Aug 2020 09:59:59 GMT", status: 200},
+	}
+	for _, test := range tests {
+		result := get(test.header, test.value)
+		result.Body.Close()
+		if result.StatusCode != test.status {
+			t.Fatalf("%s: %s should answer %d, got %d", test.header, test.value, test.status, result.StatusCode)
+		}
+	}
+
+	setting = &core.RepoSetting{
+		Filters:   core.FileNameFilters{"vendor"},
+		UpdatedAt: createdAt.Add(time.Hour),
+	}
+	result = get("If-None-Match", tag)
+	result.Body.Close()
+	if result.StatusCode != 200 || result.Header.Get("ETag") == tag {
+		t.Fatal("tag should change with the setting")
+	}
+	if modified := result.Header.Get("Last-Modified"); modified != "Sat, 01 Aug 2020 11:00:00 GMT" {
+		t.Fatalf("should be modified with the setting, got %s", modified)
+	}
+	result = get("If-Modified-Since", "Sat, 01 Aug 2020 10:00:00 GMT")
+	result.Body.Close()
+	if result.StatusCode != 200 {
+		t.Fatal("should not answer not modified after the setting is updated")
+	}
+
+	// saving the setting without changes
+	tag = result.Header.Get("ETag")
+	setting = &core.RepoSetting{
+		Filters:   core.FileNameFilters{"vendor"},
+		UpdatedAt: createdAt.Add(2 * time.Hour),
+	}
+	result = get("If-None-Match", tag)
+	result.Body.Close()
+	if result.StatusCode != 304 {
+		t.Fatal("tag should not change with the update time of the setting")
+	}
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+// FileReport is the coverage of a single file in a report with its source at the report commit
+type FileReport struct {
+	Path      string          `json:"path"`
+	Commit    string          `json:"commit"`
+	Coverages []*FileCoverage `json:"coverages"`
+	Source    string          `json:"source"`
+}
+
+// FileCoverage of a file in a report type
+type FileCoverage struct {
+	Type              core.ReportType `json:"type"`
+	StatementCoverage float64         `json:"statementCoverage"`
+	Hits              []*LineHit      `json:"hits"`
+	UncoveredRanges   []*LineRange    `json:"uncoveredRanges"`
+	// PartialBranches are lines with branches not all taken,
+	// which is empty as branch coverage is not collected from reports yet
+	PartialBranches []*BranchHit `json:"partialBranches"`
+}
+
+// LineHit is the hit count of a statement line
+type LineHit struct {
+	Line int `json:"line"`
+	Hits int `json:"hits"`
+}
+
+// LineRange of lines from start to end, both inclusive
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// BranchHit is the number of branches taken in a line
+type BranchHit struct {
+	Line     int `json:"line"`
+	Covered  int `json:"covered"`
+	Branches int `json:"branches"`
+}
+
+// uncoveredRanges of statement lines, adjacent uncovered statements are merged
+// even if lines without statements are in between
+func uncoveredRanges(hits []*LineHit) []*LineRange {
+	ranges := make([]*LineRange, 0)
+	var last *LineRange
+	for _, hit := range hits {
+		if hit.Hits > 0 {
+			last = nil
+			continue
+		}
+		if last == nil {
+			last = &LineRange{Start: hit.Line}
+			ranges = append(ranges, last)
+		}
+		last.End = hit.Line
+	}
+	return ranges
+}
+
+func newFileCoverage(t core.ReportType, file *core.File) *FileCoverage {
+	hits := make([]*LineHit, 0, len(file.StatementHits))
+	for _, hit := range file.StatementHits {
+		hits = append(hits, &LineHit{Line: hit.LineNumber, Hits: hit.Hits})
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Line < hits[j].Line
+	})
+	return &FileCoverage{
+		Type:              t,
+		StatementCoverage: file.StatementCoverage,
+		Hits:              hits,
+		UncoveredRanges:   uncoveredRanges(hits),
+		PartialBranches:   make([]*BranchHit, 0),
+	}
+}
+
+// findFileCoverages of the path in each report type
+func findFileCoverages(report *core.Report, path string) []*FileCoverage {
+	coverages := make([]*FileCoverage, 0)
+	for _, coverage := range report.Coverages {
+		for _, file := range coverage.Files {
+			if file.Name == path {
+				coverages = append(coverages, newFileCoverage(coverage.Type, file))
+				break
+			}
+		}
+	}
+	return coverages
+}
+
+// HandleGetFile coverage of a file in the report with its source
+// @Summary Get coverage of a file in the report with its source at the report commit
+// @Tags Report
+// @Param id path string true "report id"
+// @Param path path string true "file path"
+// @Param ref query string false "commit or branch of the report, default branch if empty"
+// @Success 200 {object} FileReport "file coverage"
+// @Header 200 {string} ETag "entity tag of the file"
+// @Success 304 {string} string "not modified"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/files/{path} [get]
+func HandleGetFile(
+	service core.SCMService,
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		path := strings.TrimLeft(c.Param("path"), "/")
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		ref := c.Query("ref")
+		var report *core.Report
+		if ref == "" {
+			report, err = reportStore.Find(&core.Report{ReportID: reportID, Reference: repo.Branch})
+		} else {
+			report, err = getRef(reportStore, reportID, ref)
+		}
+		if err != nil {
+			c.String(404, "report not found")
+			return
+		}
+		coverages := findFileCoverages(report, path)
+		if len(coverages) == 0 {
+			c.String(404, "file not found in the report")
+			return
+		}
+		// source at the commit never changes, so the report decides the tag
+		tag := entityTag(c, repo, nil, report)
+		if notModified(c, tag, report.CreatedAt) {
+			return
+		}
+		user, err := repoStore.Creator(repo)
+		if err != nil {
+			c.String(500, "user not found")
+			return
+		}
+		client, err := service.Client(repo.SCM)
+		if err != nil {
+			c.String(500, "cannot new git client")
+			return
+		}
+		source, err := client.Contents().Find(c.Request.Context(), user, repo.FullName(), path, report.Commit)
+		if err != nil {
+			_ = c.Error(err)
+			c.String(404, "file content not found")
+			return
+		}
+		c.JSON(200, &FileReport{
+			Path:      path,
+			Commit:    report.Commit,
+			Coverages: coverages,
+			Source:    string(source),
+		})
+	}
+}
+
+type fileHistoryOptions struct {
+	Branch string `form:"branch"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+}
+
+// HandleGetFileHistory of a file coverage
+// @Summary Get coverage history of a file in a branch from summaries of reports
+// @Tags Report
+// @Produce json
+// @Param id path string true "report id"
+// @Param path path string true "file path"
+// @Param branch query string false "branch of reports, default branch if empty"
+// @Param from query string false "start time in RFC 3339 or date, 90 days before the end by default"
+// @Param to query string false "end time in RFC 3339 or date, exclusive, now by default"
+// @Success 200 {array} core.FileSummary "summaries of the file from the oldest"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/files-history/{path} [get]
+func HandleGetFileHistory(
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		path := strings.TrimLeft(c.Param("path"), "/")
+		option := &fileHistoryOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		if path == "" {
+			c.String(400, "file path is required")
+			return
+		}
+		from, to, ok := getTimeRange(c, option.From, option.To)
+		if !ok {
+			return
+		}
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		branch := option.Branch
+		if branch == "" {
+			branch = repo.Branch
+		}
+		history, err := reportStore.FileHistory(reportID, branch, path, from, to)
+		if err != nil {
+			c.String(404, "reports not found")
+			return
+		}
+		c.JSON(200, history)
+	}
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jinzhu/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestUncoveredRanges(t *testing.T) {
+	hits := []*LineHit{
+		{Line: 1, Hits: 1},
+		{Line: 2, Hits: 0},
+		{Line: 5, Hits: 0},
+		{Line: 6, Hits: 2},
+		{Line: 8, Hits: 0},
+	}
+	expect := []*LineRange{{Start: 2, End: 5}, {Start: 8, End: 8}}
+	if diff := cmp.Diff(expect, uncoveredRanges(hits)); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestGetFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	contentService := mock.NewMockContentService(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+
+	repo := &core.Repo{ReportID: "report_id", NameSpace: "org", Name: "repo", Branch: "master", SCM: core.Github}
+	user := &core.User{Login: "creator"}
+	report := &core.Report{ReportID: repo.ReportID, Commit: "sha", Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, Files: []*core.File{
+			{Name: "main.go", StatementCoverage: 0.5, StatementHits: []*core.StatementHit{
+				{LineNumber: 3, Hits: 0},
+				{LineNumber: 1, Hits: 2},
+			}},
+		}},
+	}}
+
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).Return(user, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Commit: "v1"})).AnyTimes().Return(nil, gorm.ErrRecordNotFound)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Reference: "v1"})).AnyTimes().Return(report, nil)
+	service.EXPECT().Client(gomock.Eq(core.Github)).Return(client, nil)
+	client.EXPECT().Contents().Return(contentService)
+	contentService.EXPECT().Find(
+		gomock.Any(),
+		gomock.Eq(user),
+		gomock.Eq("org/repo"),
+		gomock.Eq("main.go"),
+		gomock.Eq("sha"),
+	).Return([]byte("package main\n"), nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/files/*path", HandleGetFile(service, reportStore, repoStore))
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/reports/report_id/files/main.go?ref=v1")
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	file := &FileReport{}
+	if err := json.Unmarshal(w.Body.Bytes(), file); err != nil {
+		t.Fatal(err)
+	}
+	expect := &FileReport{
+		Path:   "main.go",
+		Commit: "sha",
+		Coverages: []*FileCoverage{{
+			Type:              core.ReportGo,
+			StatementCoverage: 0.5,
+			Hits:              []*LineHit{{Line: 1, Hits: 2}, {Line: 3, Hits: 0}},
+			UncoveredRanges:   []*LineRange{{Start: 3, End: 3}},
+			PartialBranches:   []*BranchHit{},
+		}},
+		Source: "package main\n",
+	}
+	if diff := cmp.Diff(expect, file); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if w := get("/reports/report_id/files/other.go?ref=v1"); w.Code != 404 {
+		t.Fatalf("file not in the report should not be found, got %d", w.Code)
+	}
+}
+
+func TestGetFileHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	repo := &core.Repo{ReportID: "report_id", Branch: "master"}
+	from := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	history := []*core.FileSummary{
+		{Commit: "a", CreatedAt: from, Type: core.ReportGo, Path: "dir/main.go", Lines: 2, Covered: 1},
+	}
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	reportStore.EXPECT().FileHistory(
+		gomock.Eq(repo.ReportID),
+		gomock.Eq("master"),
+		gomock.Eq("dir/main.go"),
+		gomock.Eq(from),
+		gomock.Eq(time.Time{}),
+	).Return(history, nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/files-history/*path", HandleGetFileHistory(reportStore, repoStore))
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/reports/report_id/files-history/dir/main.go?from=2020-08-01")
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	var result []*core.FileSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(history, result); diff != "" {
+		t.Fatal(diff)
+	}
+	if w := get("/reports/report_id/files-history/"); w.Code != 400 {
+		t.Fatalf("history without file path should be bad request, got %d", w.Code)
+	}
+}
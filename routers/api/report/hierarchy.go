@@ -0,0 +1,98 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+)
+
+// codeLink to the web report page of the path at the ref, the default branch if ref is empty.
+// Directories link to the file list searched by their path.
+func codeLink(config *config.Config, repo *core.Repo, ref string) core.ChartLink {
+	base := fmt.Sprintf(
+		"%s/report/%s/%s/%s/code",
+		config.Server.URL(),
+		repo.SCM,
+		url.PathEscape(repo.NameSpace),
+		url.PathEscape(repo.Name),
+	)
+	return func(path string, dir bool) string {
+		query := url.Values{}
+		if ref != "" {
+			query.Set("ref", ref)
+		}
+		link := base
+		if dir {
+			query.Set("path", path)
+		} else {
+			segments := strings.Split(path, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			link += "/" + strings.Join(segments, "/")
+		}
+		if len(query) > 0 {
+			link += "?" + query.Encode()
+		}
+		return link
+	}
+}
+
+// HandleGetHierarchy of the report coverage
+// @Summary Get nested treemap of the report coverage grouped by directory
+// @Tags Report
+// @Produce image/svg+xml
+// @Param id path string true "report id"
+// @Param ref query string false "commit or branch of the report, default branch if empty"
+// @Success 200 {object} string "hierarchy svg"
+// @Header 200 {string} ETag "entity tag of the chart"
+// @Success 304 {string} string "not modified"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/hierarchy.svg [get]
+func HandleGetHierarchy(
+	config *config.Config,
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+	chartService core.ChartService,
+	cache core.Cache,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		ref := c.Query("ref")
+		var report *core.Report
+		if ref == "" {
+			report, err = reportStore.Find(&core.Report{ReportID: reportID, Reference: repo.Branch})
+		} else {
+			report, err = getRef(reportStore, reportID, ref)
+		}
+		if err != nil {
+			c.String(404, "report not found")
+			return
+		}
+		setting := getSetting(c, repoStore, repo)
+		tag := entityTag(c, repo, setting, report)
+		c.Header("Cache-Control", "private, max-age=600")
+		if notModified(c, tag, lastModified(setting, report)) {
+			return
+		}
+		data, err := render(cache, tag, func(w io.Writer) error {
+			return chartService.CoverageHierarchy(report, codeLink(config, repo, ref)).Render(w)
+		})
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		c.Data(200, "image/svg+xml", data)
+	}
+}
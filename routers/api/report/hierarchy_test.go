@@ -0,0 +1,86 @@
+package report
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jinzhu/gorm"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestCodeLink(t *testing.T) {
+	cfg := &config.Config{Server: config.Server{Addr: "http://localhost:8080"}}
+	repo := &core.Repo{SCM: core.Github, NameSpace: "org", Name: "repo"}
+	link := codeLink(cfg, repo, "")
+	if s := link("src/a b.go", false); s != "http://localhost:8080/report/github/org/repo/code/src/a%20b.go" {
+		t.Fatal(s)
+	}
+	link = codeLink(cfg, repo, "feature")
+	if s := link("src", true); s != "http://localhost:8080/report/github/org/repo/code?path=src&ref=feature" {
+		t.Fatal(s)
+	}
+	if s := link("src/a.go", false); s != "http://localhost:8080/report/github/org/repo/code/src/a.go?ref=feature" {
+		t.Fatal(s)
+	}
+}
+
+func TestGetHierarchy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	chartService := mock.NewMockChartService(ctrl)
+	chart := mock.NewMockChart(ctrl)
+
+	repo := &core.Repo{ID: 1, Branch: "master", ReportID: "report_id", SCM: core.Github, NameSpace: "org", Name: "repo"}
+	master := &core.Report{ReportID: repo.ReportID, Commit: "m"}
+	feature := &core.Report{ReportID: repo.ReportID, Commit: "f"}
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: repo.ReportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{}, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Reference: "master"})).Return(master, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Commit: "feature"})).Return(nil, gorm.ErrRecordNotFound)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: repo.ReportID, Reference: "feature"})).Return(feature, nil)
+	reportStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(nil, gorm.ErrRecordNotFound)
+	chart.EXPECT().Render(gomock.Any()).AnyTimes().Return(nil)
+	chartService.EXPECT().CoverageHierarchy(gomock.Eq(master), gomock.Any()).Return(chart)
+	chartService.EXPECT().CoverageHierarchy(gomock.Eq(feature), gomock.Any()).DoAndReturn(
+		func(_ *core.Report, link core.ChartLink) core.Chart {
+			if s := link("a.go", false); s != "http://localhost:8080/report/github/org/repo/code/a.go?ref=feature" {
+				t.Fatal(s)
+			}
+			return chart
+		},
+	)
+
+	cfg := &config.Config{Server: config.Server{Addr: "http://localhost:8080"}}
+	r := gin.Default()
+	r.GET("/reports/:id/hierarchy.svg", HandleGetHierarchy(cfg, reportStore, repoStore, chartService, nil))
+	var header http.Header
+	get := func(query string) int {
+		req, _ := http.NewRequest("GET", "/reports/report_id/hierarchy.svg"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		header = w.Result().Header
+		return w.Result().StatusCode
+	}
+	if status := get(""); status != 200 {
+		t.Fatal(status)
+	}
+	// hierarchy of private repositories should not be kept by shared caches
+	if cache := header.Get("Cache-Control"); cache != "private, max-age=600" {
+		t.Fatal(cache)
+	}
+	if status := get("?ref=feature"); status != 200 {
+		t.Fatal(status)
+	}
+	if status := get("?ref=gone"); status != 404 {
+		t.Fatal(status)
+	}
+}
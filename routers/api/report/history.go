@@ -0,0 +1,157 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	intervalDay  = "day"
+	intervalWeek = "week"
+	// defaultHistoryDays of history if the start time is not given
+	defaultHistoryDays = 90
+)
+
+type historyOptions struct {
+	Branch   string `form:"branch"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Interval string `form:"interval"`
+}
+
+// parseTime of RFC 3339 or a date, which is zero if empty
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// getTimeRange of the request in [from, to), it writes the error response if invalid.
+// The range starts defaultHistoryDays before the end if from is empty, and to is zero if empty.
+func getTimeRange(c *gin.Context, fromValue, toValue string) (from, to time.Time, ok bool) {
+	var err error
+	if from, err = parseTime(fromValue); err != nil {
+		c.String(400, "invalid from time")
+		return from, to, false
+	}
+	if to, err = parseTime(toValue); err != nil {
+		c.String(400, "invalid to time")
+		return from, to, false
+	}
+	if from.IsZero() {
+		end := to
+		if end.IsZero() {
+			end = time.Now()
+		}
+		from = end.AddDate(0, 0, -defaultHistoryDays)
+	}
+	if !to.IsZero() && !from.Before(to) {
+		c.String(400, "from time should be before to time")
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// bucket of the time in the interval, which is the start day or the Monday of the week in UTC
+func bucket(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == intervalWeek {
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day
+}
+
+// sampleHistory keeps the latest report in each interval, history is from the oldest
+func sampleHistory(history []*core.ReportSummary, interval string) []*core.ReportSummary {
+	if interval == "" {
+		return history
+	}
+	result := make([]*core.ReportSummary, 0)
+	for _, summary := range history {
+		n := len(result)
+		if n > 0 && bucket(result[n-1].CreatedAt, interval).Equal(bucket(summary.CreatedAt, interval)) {
+			result[n-1] = summary
+			continue
+		}
+		result = append(result, summary)
+	}
+	return result
+}
+
+// HandleGetHistory of coverage summaries
+// @Summary Get coverage history of a branch from summaries of reports
+// @Tags Report
+// @Produce json
+// @Param id path string true "report id"
+// @Param branch query string false "branch of reports, default branch if empty"
+// @Param from query string false "start time in RFC 3339 or date, 90 days before the end by default"
+// @Param to query string false "end time in RFC 3339 or date, exclusive, now by default"
+// @Param interval query string false "day or week to keep the latest report in each interval"
+// @Success 200 {array} core.ReportSummary "summaries from the oldest"
+// @Header 200 {string} ETag "entity tag of the history"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/history [get]
+func HandleGetHistory(
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		option := &historyOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		switch option.Interval {
+		case "", intervalDay, intervalWeek:
+		default:
+			c.String(400, fmt.Sprintf("interval %q not support", option.Interval))
+			return
+		}
+		from, to, ok := getTimeRange(c, option.From, option.To)
+		if !ok {
+			return
+		}
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		branch := option.Branch
+		if branch == "" {
+			branch = repo.Branch
+		}
+		history, err := reportStore.History(reportID, branch, from, to)
+		if err != nil {
+			c.String(404, "reports not found")
+			return
+		}
+		history = sampleHistory(history, option.Interval)
+		// coverages of a commit may be replaced by uploads without changing its created time
+		reports := make([]*core.Report, len(history))
+		for i, summary := range history {
+			reports[i] = &core.Report{ReportID: reportID, Commit: summary.Commit, CreatedAt: summary.CreatedAt}
+			for _, coverage := range summary.Coverages {
+				reports[i].Coverages = append(reports[i].Coverages, &core.CoverageReport{
+					Type:              coverage.Type,
+					StatementCoverage: coverage.StatementCoverage,
+				})
+			}
+		}
+		if notModified(c, entityTag(c, repo, nil, reports...), lastModified(nil, reports...)) {
+			return
+		}
+		c.JSON(200, history)
+	}
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestSampleHistory(t *testing.T) {
+	// 2020-08-03 is Monday
+	monday := time.Date(2020, 8, 3, 10, 0, 0, 0, time.UTC)
+	history := []*core.ReportSummary{
+		{Commit: "a", CreatedAt: monday},
+		{Commit: "b", CreatedAt: monday.Add(time.Hour)},
+		{Commit: "c", CreatedAt: monday.AddDate(0, 0, 1)},
+		{Commit: "d", CreatedAt: monday.AddDate(0, 0, 6)},
+		{Commit: "e", CreatedAt: monday.AddDate(0, 0, 7)},
+	}
+	commits := func(history []*core.ReportSummary) []string {
+		result := make([]string, len(history))
+		for i, summary := range history {
+			result[i] = summary.Commit
+		}
+		return result
+	}
+	tests := map[string][]string{
+		"":           {"a", "b", "c", "d", "e"},
+		intervalDay:  {"b", "c", "d", "e"},
+		intervalWeek: {"d", "e"},
+	}
+	for interval, expect := range tests {
+		if diff := cmp.Diff(expect, commits(sampleHistory(history, interval))); diff != "" {
+			t.Fatalf("%s: %s", interval, diff)
+		}
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	repo := &core.Repo{ReportID: "report_id", Branch: "master"}
+	from := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 8, 10, 12, 0, 0, 0, time.UTC)
+	history := []*core.ReportSummary{
+		{Commit: "a", CreatedAt: from, Files: 1, Lines: 2, Covered: 1, Coverages: []*core.CoverageSummary{
+			{Type: core.ReportGo, StatementCoverage: 0.5, Files: 1, Lines: 2, Covered: 1},
+		}},
+	}
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	reportStore.EXPECT().History(
+		gomock.Eq(repo.ReportID),
+		gomock.Eq("dev"),
+		gomock.Eq(from),
+		gomock.Eq(to),
+	).Return(history, nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/history", HandleGetHistory(reportStore, repoStore))
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/reports/report_id/history"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?branch=dev&from=2020-08-01&to=2020-08-10T12:00:00Z&interval=week")
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	var result []*core.ReportSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(history, result); diff != "" {
+		t.Fatal(diff)
+	}
+
+	for _, query := range []string{
+		"?interval=month",
+		"?from=yesterday",
+		"?from=2020-08-10&to=2020-08-01",
+	} {
+		if w := get(query); w.Code != 400 {
+			t.Fatalf("%s should be bad request, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	formatSVG = "svg"
+	formatPNG = "png"
+	// maxImageSize of each side of PNG images
+	maxImageSize = 2048
+)
+
+type imageOptions struct {
+	Format string `form:"format"`
+	Width  int    `form:"width"`
+	Height int    `form:"height"`
+}
+
+// getImageOptions of the request, it writes the error response if invalid
+func getImageOptions(c *gin.Context) (*imageOptions, bool) {
+	option := &imageOptions{}
+	if err := c.BindQuery(option); err != nil {
+		c.String(400, err.Error())
+		return nil, false
+	}
+	switch option.Format {
+	case "":
+		option.Format = formatSVG
+	case formatSVG, formatPNG:
+	default:
+		c.String(400, fmt.Sprintf("format %q not support", option.Format))
+		return nil, false
+	}
+	if option.Width < 0 || option.Width > maxImageSize || option.Height < 0 || option.Height > maxImageSize {
+		c.String(400, fmt.Sprintf("width and height should be between 0 and %d", maxImageSize))
+		return nil, false
+	}
+	return option, true
+}
+
+// renderChart in the format of options, rendered images are cached by the entity tag,
+// which is different for each format and size as it covers the request URI
+func renderChart(
+	c *gin.Context,
+	chartService core.ChartService,
+	cache core.Cache,
+	tag string,
+	option *imageOptions,
+	chart func() core.Chart,
+) {
+	contentType := "image/svg+xml"
+	data, err := render(cache, tag, func(w io.Writer) error {
+		if option.Format == formatPNG {
+			return chartService.PNG(chart(), option.Width, option.Height).Render(w)
+		}
+		return chart().Render(w)
+	})
+	if err != nil {
+		c.String(500, err.Error())
+		return
+	}
+	if option.Format == formatPNG {
+		contentType = "image/png"
+	}
+	c.Data(200, contentType, data)
+}
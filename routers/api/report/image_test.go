@@ -0,0 +1,96 @@
+package report
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/charts"
+)
+
+func TestGetImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	cache := mock.NewMockCache(ctrl)
+
+	repo := &core.Repo{ID: 1, NameSpace: "org", Name: "repo", Branch: "master", ReportID: "report_id"}
+	report := &core.Report{ReportID: repo.ReportID, Commit: "m", CreatedAt: time.Now(), Coverages: []*core.CoverageReport{
+		{Type: core.ReportGo, StatementCoverage: 0.5, Files: []*core.File{
+			{Name: "a.go", StatementCoverage: 0.5, StatementHits: []*core.StatementHit{{LineNumber: 1, Hits: 1}, {LineNumber: 2}}},
+		}},
+	}}
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Any()).AnyTimes().Return(&core.RepoSetting{}, nil)
+	reportStore.EXPECT().FindSummary(gomock.Any()).AnyTimes().Return(report, nil)
+	reportStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(report, nil)
+
+	cached := make(map[string][]byte)
+	cache.EXPECT().Get(gomock.Any()).AnyTimes().DoAndReturn(func(key string) ([]byte, error) {
+		if data, ok := cached[key]; ok {
+			return data, nil
+		}
+		return nil, core.ErrCacheMiss
+	})
+	cache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).DoAndReturn(
+		func(key string, data []byte, ttl time.Duration) error {
+			cached[key] = data
+			return nil
+		},
+	)
+
+	chartService := &charts.ChartService{}
+	r := gin.Default()
+	r.GET("/reports/:id/card", HandleGetCard(nil, repoStore, reportStore, chartService, cache))
+	r.GET("/reports/:id/badge", HandleGetBadge(reportStore, repoStore, chartService, cache))
+
+	get := func(path string) (int, string, []byte) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		result := w.Result()
+		defer result.Body.Close()
+		data, _ := ioutil.ReadAll(result.Body)
+		return result.StatusCode, result.Header.Get("Content-Type"), data
+	}
+
+	status, contentType, data := get("/reports/report_id/card?format=png&width=990")
+	if status != 200 || contentType != "image/png" {
+		t.Fatalf("unexpected response %d %s", status, contentType)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 990 || size.Y != 390 {
+		t.Fatalf("card should be scaled to 990x390, got %v", size)
+	}
+	if _, _, again := get("/reports/report_id/card?format=png&width=990"); !bytes.Equal(data, again) {
+		t.Fatal("bitmap should be cached")
+	}
+
+	if status, contentType, _ = get("/reports/report_id/badge?format=png&height=40"); status != 200 || contentType != "image/png" {
+		t.Fatalf("unexpected response %d %s", status, contentType)
+	}
+
+	for _, path := range []string{
+		"/reports/report_id/card?format=gif",
+		"/reports/report_id/card?format=png&width=5000",
+		"/reports/report_id/badge?format=png&height=-1",
+	} {
+		if status, _, _ = get(path); status != 400 {
+			t.Fatalf("%s should be bad request, got %d", path, status)
+		}
+	}
+}
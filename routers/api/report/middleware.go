@@ -1,42 +1,55 @@
 package report
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/covergates/covergates/core"
 	"github.com/covergates/covergates/routers/api/request"
 )
 
-// ProtectReport from modifying by unauthorized users
-func ProtectReport(checkLogin gin.HandlerFunc, repoStore core.RepoStore, service core.SCMService) gin.HandlerFunc {
+// ProtectReport from modifying by unauthorized users.
+//
+// A request carrying an upload token of the repository is always allowed.
+// Otherwise, a login user with the uploader role is required, unless the
+// repository opts in to upload with the report ID only and is neither
+// protected nor has any upload token. Rejected uploads are audited.
+func ProtectReport(
+	checkLogin gin.HandlerFunc,
+	repoStore core.RepoStore,
+	auditStore core.AuditStore,
+	service core.PermissionService,
+) gin.HandlerFunc {
+	requireUploader := request.RequireRole(service, core.RoleUploader)
 	return func(c *gin.Context) {
 		setting := MustGetSetting(c)
-		if !setting.Protected {
-			return
-		}
-		checkLogin(c)
-		if c.IsAborted() {
-			return
+		repo := request.MustGetRepo(c)
+		if secret := bearerToken(c); secret != "" {
+			if _, err := repoStore.FindToken(repo, secret); err == nil {
+				return
+			}
 		}
-		ctx := c.Request.Context()
-		user := request.MustGetUserFrom(c)
-		repo := MustGetRepo(c)
-		client, err := service.Client(repo.SCM)
-		if err != nil {
-			c.String(500, err.Error())
-			c.Abort()
-			return
+		if setting.ReportIDUpload && !setting.Protected {
+			tokens, err := repoStore.ListTokens(repo)
+			if err != nil {
+				c.String(500, err.Error())
+				c.Abort()
+				return
+			}
+			if len(tokens) == 0 {
+				return
+			}
 		}
-		creator, err := repoStore.Creator(repo)
-		if err != nil {
-			c.String(500, err.Error())
-			c.Abort()
-			return
+		checkLogin(c)
+		if !c.IsAborted() {
+			requireUploader(c)
 		}
-		if !client.Repositories().IsAdmin(ctx, user, repo.FullName()) && user.Login != creator.Login {
-			c.String(401, "permission denied")
-			c.Abort()
-			return
+		if c.IsAborted() {
+			request.Audit(c, auditStore, repo, core.AuditUploadRejected, nil, gin.H{
+				"status": c.Writer.Status(),
+				"path":   c.Request.URL.Path,
+			})
 		}
 	}
 }
@@ -47,11 +60,22 @@ func InjectReportContext(repoStore core.RepoStore) gin.HandlerFunc {
 		reportID := c.Param("id")
 		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
 		if err != nil {
+			c.String(404, "repository not found")
+			c.Abort()
 			return
 		}
-		WithRepo(c, repo)
+		request.WithRepo(c, repo)
 		if setting, err := repoStore.Setting(repo); err == nil {
 			WithSetting(c, setting)
 		}
 	}
 }
+
+func bearerToken(c *gin.Context) string {
+	auth := c.GetHeader("Authorization")
+	prefix := "Bearer "
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return strings.TrimSpace(auth[len(prefix):])
+	}
+	return ""
+}
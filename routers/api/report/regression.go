@@ -0,0 +1,246 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	// defaultRegressionThreshold of coverage drops in percentage
+	defaultRegressionThreshold = 5.0
+	// maxRegressionReports of the latest reports in the range to compare
+	maxRegressionReports = 50
+	// maxCommitLookups on SCM for committers of regressions in a request
+	maxCommitLookups = 20
+	// commitTTL of commits cached for committers, which do not change once pushed
+	commitTTL = 24 * time.Hour
+)
+
+// Regression of a file, whose coverage dropped from the previous report of the default branch
+type Regression struct {
+	Path         string          `json:"path"`
+	Type         core.ReportType `json:"type"`
+	Commit       string          `json:"commit"`
+	Base         string          `json:"base"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	BaseCoverage float64         `json:"baseCoverage"`
+	Coverage     float64         `json:"coverage"`
+	// Committer and Message of the commit, which are empty if the commit is not found
+	Committer string `json:"committer"`
+	Message   string `json:"message"`
+}
+
+type regressionOptions struct {
+	Threshold *float64 `form:"threshold"`
+	From      string   `form:"from"`
+	To        string   `form:"to"`
+}
+
+// findRegressions of files from the base report to the head report
+func findRegressions(base, head []*core.FileSummary, threshold float64) []*Regression {
+	type fileKey struct {
+		t    core.ReportType
+		path string
+	}
+	previousOf := make(map[fileKey]*core.FileSummary)
+	for _, file := range base {
+		previousOf[fileKey{file.Type, file.Path}] = file
+	}
+	regressions := make([]*Regression, 0)
+	for _, file := range head {
+		previous, ok := previousOf[fileKey{file.Type, file.Path}]
+		if !ok || previous.Lines == 0 || file.Lines == 0 {
+			continue
+		}
+		if (previous.Coverage()-file.Coverage())*100 <= threshold {
+			continue
+		}
+		regressions = append(regressions, &Regression{
+			Path:         file.Path,
+			Type:         file.Type,
+			Commit:       file.Commit,
+			Base:         previous.Commit,
+			CreatedAt:    file.CreatedAt,
+			BaseCoverage: previous.Coverage(),
+			Coverage:     file.Coverage(),
+		})
+	}
+	return regressions
+}
+
+func commitKey(repo *core.Repo, sha string) string {
+	return fmt.Sprintf("commit:%s:%s", repo.ReportID, sha)
+}
+
+// findCommit of the SHA on SCM, which is cached as commits do not change once pushed
+func findCommit(
+	ctx context.Context,
+	client core.Client,
+	user *core.User,
+	cache core.Cache,
+	repo *core.Repo,
+	sha string,
+) (*core.Commit, error) {
+	commits, _, err := client.Git().ListCommitsByRef(ctx, user, repo.FullName(), sha, core.Page{Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commit %s not found", sha)
+	}
+	if cache != nil {
+		data, _ := json.Marshal(commits[0])
+		if err := cache.Set(commitKey(repo, sha), data, commitTTL); err != nil {
+			log.Warningf("fail to cache commit: %v", err)
+		}
+	}
+	return commits[0], nil
+}
+
+// cachedCommit of the SHA, nil if it is not cached
+func cachedCommit(cache core.Cache, repo *core.Repo, sha string) *core.Commit {
+	if cache == nil {
+		return nil
+	}
+	data, err := cache.Get(commitKey(repo, sha))
+	if err != nil {
+		return nil
+	}
+	commit := &core.Commit{}
+	if err := json.Unmarshal(data, commit); err != nil {
+		return nil
+	}
+	return commit
+}
+
+// fillCommitters of regressions from their commits. Commits not cached are looked up on SCM
+// from the latest regression, up to maxCommitLookups, so requests do not use up the rate limit.
+func fillCommitters(
+	ctx context.Context,
+	service core.SCMService,
+	repoStore core.RepoStore,
+	cache core.Cache,
+	repo *core.Repo,
+	regressions []*Regression,
+) error {
+	commits := make(map[string]*core.Commit)
+	lookups := 0
+	var client core.Client
+	var user *core.User
+	var lastErr error
+	for i := len(regressions) - 1; i >= 0; i-- {
+		sha := regressions[i].Commit
+		if _, ok := commits[sha]; ok {
+			continue
+		}
+		if commit := cachedCommit(cache, repo, sha); commit != nil {
+			commits[sha] = commit
+			continue
+		}
+		if lookups >= maxCommitLookups {
+			continue
+		}
+		lookups++
+		if client == nil {
+			var err error
+			if user, err = repoStore.Creator(repo); err != nil {
+				return err
+			}
+			if client, err = service.Client(repo.SCM); err != nil {
+				return err
+			}
+		}
+		commit, err := findCommit(ctx, client, user, cache, repo, sha)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		commits[sha] = commit
+	}
+	for _, regression := range regressions {
+		if commit, ok := commits[regression.Commit]; ok {
+			regression.Committer = commit.Committer
+			regression.Message = commit.Message
+		}
+	}
+	return lastErr
+}
+
+// HandleGetRegressions of files in the default branch
+// @Summary List files whose coverage dropped between consecutive reports of the default branch
+// @Tags Report
+// @Produce json
+// @Param id path string true "report id"
+// @Param threshold query number false "coverage drop in percentage to report, default 5"
+// @Param from query string false "start time in RFC 3339 or date, 90 days before the end by default"
+// @Param to query string false "end time in RFC 3339 or date, exclusive, now by default"
+// @Success 200 {array} Regression "regressions of the latest 50 reports in the range from the oldest"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/regressions [get]
+func HandleGetRegressions(
+	service core.SCMService,
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+	cache core.Cache,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		option := &regressionOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		threshold := defaultRegressionThreshold
+		if option.Threshold != nil {
+			threshold = *option.Threshold
+		}
+		if threshold < 0 || threshold > 100 {
+			c.String(400, "threshold should be between 0 and 100")
+			return
+		}
+		from, to, ok := getTimeRange(c, option.From, option.To)
+		if !ok {
+			return
+		}
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		history, err := reportStore.History(reportID, repo.Branch, from, to)
+		if err != nil {
+			c.String(404, "reports not found")
+			return
+		}
+		if len(history) > maxRegressionReports {
+			history = history[len(history)-maxRegressionReports:]
+		}
+		// compare reports pair by pair, so only files of two reports are kept at once
+		regressions := make([]*Regression, 0)
+		var base []*core.FileSummary
+		for i, report := range history {
+			files, err := reportStore.FileSummaries(reportID, report.Commit)
+			if err != nil {
+				c.String(500, err.Error())
+				return
+			}
+			if i > 0 {
+				regressions = append(regressions, findRegressions(base, files, threshold)...)
+			}
+			base = files
+		}
+		if err := fillCommitters(c.Request.Context(), service, repoStore, cache, repo, regressions); err != nil {
+			// regressions are still useful without committers
+			_ = c.Error(err)
+		}
+		c.JSON(200, regressions)
+	}
+}
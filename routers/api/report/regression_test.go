@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/cache"
+)
+
+func TestGetRegressions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	gitService := mock.NewMockGitService(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+
+	repo := &core.Repo{ReportID: "report_id", NameSpace: "org", Name: "repo", Branch: "master", SCM: core.Github}
+	user := &core.User{Login: "creator"}
+	day := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	history := []*core.ReportSummary{
+		{Commit: "a", CreatedAt: day},
+		{Commit: "b", CreatedAt: day.AddDate(0, 0, 1)},
+		// the report without summaries of files breaks the sequence
+		{Commit: "c", CreatedAt: day.AddDate(0, 0, 2)},
+		{Commit: "d", CreatedAt: day.AddDate(0, 0, 3)},
+	}
+	file := func(commit string, days, covered int) *core.FileSummary {
+		return &core.FileSummary{
+			Commit:    commit,
+			CreatedAt: day.AddDate(0, 0, days),
+			Type:      core.ReportGo,
+			Path:      "main.go",
+			Lines:     10,
+			Covered:   covered,
+		}
+	}
+	files := map[string][]*core.FileSummary{
+		"a": {file("a", 0, 10)},
+		"b": {
+			file("b", 1, 8),
+			{Commit: "b", CreatedAt: day.AddDate(0, 0, 1), Type: core.ReportGo, Path: "new.go", Lines: 1},
+		},
+		"d": {file("d", 3, 0)},
+	}
+	repoStore.EXPECT().Find(gomock.Any()).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).Return(user, nil)
+	reportStore.EXPECT().History(
+		gomock.Eq(repo.ReportID), gomock.Eq("master"), gomock.Any(), gomock.Any(),
+	).AnyTimes().Return(history, nil)
+	reportStore.EXPECT().FileSummaries(gomock.Eq(repo.ReportID), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_, commit string) ([]*core.FileSummary, error) {
+			return files[commit], nil
+		},
+	)
+	service.EXPECT().Client(gomock.Eq(core.Github)).Return(client, nil)
+	client.EXPECT().Git().AnyTimes().Return(gitService)
+	// the commit is looked up once, and then cached
+	gitService.EXPECT().ListCommitsByRef(
+		gomock.Any(), gomock.Eq(user), gomock.Eq("org/repo"), gomock.Eq("b"), gomock.Eq(core.Page{Limit: 1}),
+	).Return([]*core.Commit{{Sha: "b", Committer: "alice", Message: "refactor"}}, "", nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/regressions", HandleGetRegressions(service, reportStore, repoStore, cache.NewMemory(10)))
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/reports/report_id/regressions"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("?threshold=10")
+	if w.Code != 200 {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	var result []*Regression
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	expect := []*Regression{
+		{
+			Path:         "main.go",
+			Type:         core.ReportGo,
+			Commit:       "b",
+			Base:         "a",
+			CreatedAt:    day.AddDate(0, 0, 1),
+			BaseCoverage: 1,
+			Coverage:     0.8,
+			Committer:    "alice",
+			Message:      "refactor",
+		},
+	}
+	if diff := cmp.Diff(expect, result); diff != "" {
+		t.Fatal(diff)
+	}
+	w = get("?threshold=10")
+	result = nil
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, result); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// no regression over the threshold needs no committers
+	if w := get("?threshold=20"); w.Code != 200 || w.Body.String() != "[]" {
+		t.Fatalf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if w := get("?threshold=-1"); w.Code != 400 {
+		t.Fatalf("negative threshold should be bad request, got %d", w.Code)
+	}
+}
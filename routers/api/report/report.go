@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -134,12 +136,17 @@ type getOptions struct {
 // @Param id path string true "report id"
 // @Param latest query bool false "get only the latest report"
 // @Param ref query string false "get report for git ref"
+// @Param cursor query string false "cursor of the page from the X-Next-Cursor header"
+// @Param limit query int false "number of reports in a page"
 // @Success 200 {object} core.Report "coverage report"
+// @Header 200 {string} Link "URL of the next page"
+// @Header 200 {string} X-Next-Cursor "cursor of the next page"
+// @Header 200 {string} ETag "entity tag of the reports"
+// @Success 304 {string} string "not modified"
 // @Router /reports/{id} [get]
 func HandleGet(
 	reportStore core.ReportStore,
 	repoStore core.RepoStore,
-	service core.SCMService,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reportID := c.Param("id")
@@ -149,12 +156,14 @@ func HandleGet(
 			c.JSON(400, []*core.Report{})
 			return
 		}
-		if !hasPermission(c, repoStore, service, reportID) {
-			c.JSON(401, []*core.Report{})
+		page, err := request.PageFrom(c)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(400, []*core.Report{})
 			return
 		}
 		// TODO: support multiple type (language) reports in one repository
-		var err error
+		var next string
 		var reports []*core.Report
 		switch {
 		case option.Latest && option.Ref == "":
@@ -168,90 +177,249 @@ func HandleGet(
 				reports = []*core.Report{report}
 			}
 		case option.Ref != "":
-			reports, err = reportStore.List(reportID, option.Ref)
+			reports, next, err = reportStore.List(reportID, option.Ref, page)
 		default:
-			reports, err = getAll(reportStore, reportID)
+			reports, next, err = getAll(reportStore, reportID, page)
 		}
 
-		if err != nil {
+		if errors.Is(err, core.ErrInvalidCursor) {
+			c.JSON(400, []*core.Report{})
+			return
+		} else if err != nil {
 			_ = c.Error(err)
 			c.JSON(404, []*core.Report{})
 			return
 		}
+		request.WithNextPage(c, page, next)
+		if notModified(c, entityTag(c, nil, nil, reports...), lastModified(nil, reports...)) {
+			return
+		}
 		c.JSON(200, reports)
 	}
 }
 
+// maxTreeMapCells of the pull request treemap
+const maxTreeMapCells = 500
+
+// changesTTL of changes of pull requests in the cache
+const changesTTL = 10 * time.Minute
+
+type treeMapOptions struct {
+	Base  string `form:"base"`
+	PR    int    `form:"pr"`
+	Cells int    `form:"cells"`
+}
+
+// cells of the pull request treemap, clamped to maxTreeMapCells
+func (option *treeMapOptions) cells(config *config.Config) int {
+	cells := option.Cells
+	if cells <= 0 {
+		cells = config.Server.TreeMapCells
+	}
+	if cells > maxTreeMapCells {
+		cells = maxTreeMapCells
+	}
+	return cells
+}
+
+// listChanges of the pull request with the repository creator's token.
+// Changes are cached by the pull request and the commit of its report,
+// so requests of public treemaps do not use up the rate limit of SCM.
+func listChanges(
+	c *gin.Context,
+	service core.SCMService,
+	repoStore core.RepoStore,
+	cache core.Cache,
+	repo *core.Repo,
+	report *core.Report,
+	number int,
+) ([]*core.FileChange, error) {
+	key := fmt.Sprintf("changes:%s:%d:%s", repo.ReportID, number, report.Commit)
+	if cache != nil {
+		if data, err := cache.Get(key); err == nil {
+			var changes []*core.FileChange
+			if err := json.Unmarshal(data, &changes); err == nil {
+				return changes, nil
+			}
+		}
+	}
+	user, err := repoStore.Creator(repo)
+	if err != nil {
+		return nil, err
+	}
+	client, err := service.Client(repo.SCM)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := client.PullRequests().ListChanges(c.Request.Context(), user, repo.FullName(), number)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		data, _ := json.Marshal(changes)
+		if err := cache.Set(key, data, changesTTL); err != nil {
+			log.Warningf("fail to cache changes of pull request: %v", err)
+		}
+	}
+	return changes, nil
+}
+
 // HandleGetTreeMap for coverage difference with main branch
 // @Summary Get coverage difference treemap with main branch
 // @Tags Report
-// @Produce image/svg+xml
+// @Produce image/svg+xml,image/png
 // @Param id path string true "report id"
 // @param source path string true "source branch"
-// @Success 200 {object} string "treemap svg"
+// @Param base query string false "base commit or branch, default branch if empty"
+// @Param pr query int false "only show files changed in the pull request, which is commented with the treemap"
+// @Param cells query int false "most cells of the pull request treemap before collapsing into directories"
+// @Param format query string false "svg or png, default svg"
+// @Param width query int false "width of png, 2048 at most"
+// @Param height query int false "height of png, 2048 at most"
+// @Success 200 {object} string "treemap svg or png"
+// @Header 200 {string} ETag "entity tag of the treemap"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
 // @Router /reports/{id}/treemap/{ref} [get]
 func HandleGetTreeMap(
+	config *config.Config,
+	service core.SCMService,
 	reportStore core.ReportStore,
 	repoStore core.RepoStore,
 	chartService core.ChartService,
+	cache core.Cache,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reportID := c.Param("id")
 		ref := strings.Trim(c.Param("ref"), "/")
+		option := &treeMapOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		imageOption, ok := getImageOptions(c)
+		if !ok {
+			return
+		}
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
 		new, err := getRef(reportStore, reportID, ref)
 		if err != nil {
 			c.String(500, err.Error())
 			return
 		}
-		old, err := getLatest(reportStore, repoStore, reportID)
+		var old *core.Report
+		if option.Base == "" {
+			old, err = reportStore.Find(&core.Report{ReportID: reportID, Reference: repo.Branch})
+		} else {
+			old, err = getRef(reportStore, reportID, option.Base)
+		}
 		if err != nil {
 			old = &core.Report{
 				Coverages: []*core.CoverageReport{},
 			}
 		}
-		chart := chartService.CoverageDiffTreeMap(old, new)
-		buffer := bytes.NewBuffer([]byte{})
-		if err := chart.Render(buffer); err != nil {
-			c.String(500, err.Error())
-			return
+		var changes []*core.FileChange
+		if option.PR > 0 {
+			// the treemap is public, so only pull requests commented with it are listed from SCM
+			if _, err := reportStore.FindComment(&core.Report{ReportID: reportID}, option.PR); err != nil {
+				c.String(404, "pull request not found")
+				return
+			}
+			changes, err = listChanges(c, service, repoStore, cache, repo, new, option.PR)
+			if err != nil {
+				c.String(400, "cannot list changes of pull request")
+				return
+			}
+		}
+		setting := getSetting(c, repoStore, repo)
+		tag := entityTag(c, repo, setting, old, new)
+		modified := lastModified(setting, old, new)
+		if option.PR > 0 {
+			tag = changesTag(tag, changes)
+			// changes of the pull request are not dated
+			modified = time.Time{}
 		}
 		c.Header("Cache-Control", "max-age=600")
-		c.Data(200, "image/svg+xml", buffer.Bytes())
+		if notModified(c, tag, modified) {
+			return
+		}
+		renderChart(c, chartService, cache, tag, imageOption, func() core.Chart {
+			if option.PR > 0 {
+				return chartService.CoverageChangeTreeMap(old, new, changes, option.cells(config))
+			}
+			return chartService.CoverageDiffTreeMap(old, new)
+		})
 	}
 }
 
 // HandleGetCard of the repository status
 // @Summary Get status card of the repository
 // @Tags Report
-// @Produce image/svg+xml
+// @Produce image/svg+xml,image/png
 // @Param id path string true "report id"
-// @Success 200 {object} string "treemap svg"
+// @Param theme query string false "dark, light or high-contrast, default from repository setting"
+// @Param metrics query string false "comma separated files, hits, build, uncovered, trend or commit"
+// @Param bg_color query string false "hex color of background"
+// @Param footer_color query string false "hex color of footer"
+// @Param title_color query string false "hex color of title"
+// @Param text_color query string false "hex color of text"
+// @Param icon_color query string false "hex color of icons"
+// @Param accent_color query string false "hex color of coverage score"
+// @Param format query string false "svg or png, default svg"
+// @Param width query int false "width of png, 2048 at most"
+// @Param height query int false "height of png, 2048 at most"
+// @Success 200 {object} string "card svg or png"
+// @Header 200 {string} ETag "entity tag of the card"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
 // @Router /reports/{id}/card [get]
 func HandleGetCard(
+	service core.SCMService,
 	repoStore core.RepoStore,
 	reportStore core.ReportStore,
 	chartService core.ChartService,
+	cache core.Cache,
 ) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		reportID := c.Param("id")
+		option, ok := getImageOptions(c)
+		if !ok {
+			return
+		}
 		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
 		if err != nil {
 			c.String(404, "repository not found")
 			return
 		}
-		report, err := reportStore.Find(&core.Report{ReportID: reportID, Reference: repo.Branch})
+		report, err := reportStore.FindSummary(&core.Report{ReportID: reportID, Reference: repo.Branch})
 		if err != nil {
 			c.String(404, "report not found")
 			return
 		}
-		chart := chartService.RepoCard(repo, report)
-		buffer := bytes.NewBuffer([]byte{})
-		if err := chart.Render(buffer); err != nil {
-			c.String(500, err.Error())
+		setting := getSetting(c, repoStore, repo)
+		content, ok := getCard(c, reportStore, repo, setting, report)
+		if !ok {
 			return
 		}
+		tag := entityTag(c, repo, setting, content.reports...)
 		c.Header("Cache-Control", "max-age=600")
-		c.Data(200, "image/svg+xml", buffer.Bytes())
+		if notModified(c, tag, lastModified(setting, content.reports...)) {
+			return
+		}
+		renderChart(c, chartService, cache, tag, option, func() core.Chart {
+			if content.commit != nil {
+				content.commit.Value = commitMessage(c.Request.Context(), service, repoStore, repo, report)
+			}
+			if len(content.lines) > 0 {
+				countLines(reportStore, report, content.lines)
+			}
+			return chartService.RepoCard(content.card)
+		})
 	}
 }
 
@@ -269,7 +437,6 @@ func HandleComment(
 	reportStore core.ReportStore,
 	reportService core.ReportService,
 ) gin.HandlerFunc {
-	// TODO: Add handle comment unit test
 	// TODO: Need to test comment with SHA or branch
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -322,11 +489,13 @@ func HandleComment(
 		buf := &bytes.Buffer{}
 
 		buf.WriteString(fmt.Sprintf(
-			"![treemap](%s/api/v1/reports/%s/treemap/%s?base=%s)\n\n",
+			"![treemap](%s/api/v1/reports/%s/treemap/%s?base=%s&pr=%d&cells=%d)\n\n",
 			config.Server.URL(),
 			reportID,
 			source.Reference,
 			target.Reference,
+			number,
+			config.Server.TreeMapCells,
 		))
 
 		if _, err = io.Copy(buf, r); err != nil {
@@ -335,7 +504,15 @@ func HandleComment(
 		}
 
 		comment, err := reportStore.FindComment(&core.Report{ReportID: reportID}, number)
-		if err == nil {
+		if err != nil {
+			// the treemap in the comment is served only for commented pull requests,
+			// so the pull request is kept before the comment is posted and fetched by SCM
+			comment = &core.ReportComment{Number: number}
+			if err := reportStore.CreateComment(&core.Report{ReportID: reportID}, comment); err != nil {
+				c.String(500, err.Error())
+				return
+			}
+		} else if comment.Comment > 0 {
 			_ = client.PullRequests().RemoveComment(ctx, user, repo.FullName(), number, comment.Comment)
 		}
 
@@ -363,31 +540,6 @@ func HandleComment(
 	}
 }
 
-func hasPermission(
-	c *gin.Context,
-	store core.RepoStore,
-	service core.SCMService,
-	reportID string,
-) bool {
-	repo, err := store.Find(&core.Repo{ReportID: reportID})
-	if err != nil {
-		return false
-	}
-	if !repo.Private {
-		return true
-	}
-	user, ok := request.UserFrom(c)
-	if !ok {
-		return false
-	}
-	client, err := service.Client(repo.SCM)
-	if err != nil {
-		return false
-	}
-	_, err = client.Repositories().Find(c.Request.Context(), user, repo.FullName())
-	return err == nil
-}
-
 func getLatest(reportStore core.ReportStore, repoStore core.RepoStore, reportID string) (*core.Report, error) {
 	repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
 	if err != nil {
@@ -414,10 +566,10 @@ func getRef(store core.ReportStore, reportID, ref string) (*core.Report, error)
 }
 
 // getAll reports related to given reportID
-func getAll(store core.ReportStore, reportID string) ([]*core.Report, error) {
+func getAll(store core.ReportStore, reportID string, page core.Page) ([]*core.Report, string, error) {
 	return store.Finds(&core.Report{
 		ReportID: reportID,
-	})
+	}, page)
 }
 
 // loadCoverageReort from io reader and apply repository wide setting
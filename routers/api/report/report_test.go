@@ -2,6 +2,7 @@ package report
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,9 +20,12 @@ import (
 	"github.com/jinzhu/gorm"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/covergates/covergates/config"
 	"github.com/covergates/covergates/core"
 	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/cache"
 	"github.com/covergates/covergates/modules/charts"
+	reportService "github.com/covergates/covergates/modules/report"
 	"github.com/covergates/covergates/routers/api/request"
 )
 
@@ -156,21 +160,45 @@ func TestProtectReport(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepoStore := mock.NewMockRepoStore(ctrl)
-	mockSCMService := mock.NewMockSCMService(ctrl)
+	mockPermissionService := mock.NewMockPermissionService(ctrl)
+	mockAuditStore := mock.NewMockAuditStore(ctrl)
 
-	t.Run("test protected report", func(t *testing.T) {
+	repo := &core.Repo{
+		ID:       1,
+		ReportID: "1234",
+	}
+	user := &core.User{Login: "user"}
+	expectRejection := func(actor string) {
+		mockAuditStore.EXPECT().Create(gomock.Any()).DoAndReturn(func(log *core.AuditLog) error {
+			if log.Action != core.AuditUploadRejected || log.Actor != actor || log.Repo != repo {
+				t.Fatalf("unexpected audit log %v", log)
+			}
+			return nil
+		})
+	}
+
+	newLoginRouter := func(setting *core.RepoSetting, checkLogin gin.HandlerFunc) *gin.Engine {
 		r := gin.Default()
 		r.POST("/",
 			func(c *gin.Context) {
-				WithSetting(c, &core.RepoSetting{
-					Protected: true,
-				})
+				request.WithRepo(c, repo)
+				WithSetting(c, setting)
 			},
-			ProtectReport(func(c *gin.Context) {
-				c.String(401, "")
-				c.Abort()
-			}, mockRepoStore, mockSCMService),
+			ProtectReport(checkLogin, mockRepoStore, mockAuditStore, mockPermissionService),
 		)
+		return r
+	}
+
+	newRouter := func(setting *core.RepoSetting) *gin.Engine {
+		return newLoginRouter(setting, func(c *gin.Context) {
+			c.String(401, "")
+			c.Abort()
+		})
+	}
+
+	t.Run("test protected report", func(t *testing.T) {
+		expectRejection("")
+		r := newRouter(&core.RepoSetting{Protected: true})
 		req, _ := http.NewRequest("POST", "/", nil)
 		testRequest(r, req, func(w *httptest.ResponseRecorder) {
 			response := w.Result()
@@ -182,19 +210,66 @@ func TestProtectReport(t *testing.T) {
 	})
 
 	t.Run("test unprotected report", func(t *testing.T) {
-		r := gin.Default()
-		r.POST("/",
-			func(c *gin.Context) {
-				WithSetting(c, &core.RepoSetting{
-					Protected: false,
-				})
-			},
-			ProtectReport(func(c *gin.Context) {
-				c.String(401, "")
-				c.Abort()
-			}, mockRepoStore, mockSCMService),
-		)
+		expectRejection("")
+		r := newRouter(&core.RepoSetting{Protected: false})
+		req, _ := http.NewRequest("POST", "/", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			response := w.Result()
+			defer response.Body.Close()
+			if response.StatusCode != 401 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("test report ID upload", func(t *testing.T) {
+		mockRepoStore.EXPECT().ListTokens(gomock.Eq(repo)).Return([]*core.RepoToken{}, nil)
+		r := newRouter(&core.RepoSetting{ReportIDUpload: true})
+		req, _ := http.NewRequest("POST", "/", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			response := w.Result()
+			defer response.Body.Close()
+			if response.StatusCode != 200 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("test report ID upload of protected report", func(t *testing.T) {
+		expectRejection("")
+		r := newRouter(&core.RepoSetting{ReportIDUpload: true, Protected: true})
+		req, _ := http.NewRequest("POST", "/", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			response := w.Result()
+			defer response.Body.Close()
+			if response.StatusCode != 401 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("test report ID upload with upload tokens", func(t *testing.T) {
+		mockRepoStore.EXPECT().ListTokens(gomock.Eq(repo)).Return([]*core.RepoToken{{ID: 1}}, nil)
+		expectRejection("")
+		r := newRouter(&core.RepoSetting{ReportIDUpload: true})
 		req, _ := http.NewRequest("POST", "/", nil)
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			response := w.Result()
+			defer response.Body.Close()
+			if response.StatusCode != 401 {
+				t.Fatal()
+			}
+		})
+	})
+
+	t.Run("test protected report with upload token", func(t *testing.T) {
+		mockRepoStore.EXPECT().FindToken(
+			gomock.Eq(repo),
+			gomock.Eq("secret"),
+		).Return(&core.RepoToken{ID: 1}, nil)
+		r := newRouter(&core.RepoSetting{Protected: true})
+		req, _ := http.NewRequest("POST", "/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
 		testRequest(r, req, func(w *httptest.ResponseRecorder) {
 			response := w.Result()
 			defer response.Body.Close()
@@ -203,6 +278,52 @@ func TestProtectReport(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("test protected report with login user", func(t *testing.T) {
+		r := newLoginRouter(&core.RepoSetting{Protected: true}, func(c *gin.Context) {
+			request.WithUser(c, user)
+		})
+		req, _ := http.NewRequest("POST", "/", nil)
+		for role, code := range map[core.Role]int{
+			core.RoleViewer:     401,
+			core.RoleUploader:   200,
+			core.RoleMaintainer: 200,
+		} {
+			mockPermissionService.EXPECT().Role(
+				gomock.Any(),
+				gomock.Eq(repo),
+				gomock.Eq(user),
+			).Return(role, nil)
+			if code != 200 {
+				expectRejection(user.Login)
+			}
+			testRequest(r, req, func(w *httptest.ResponseRecorder) {
+				response := w.Result()
+				defer response.Body.Close()
+				if response.StatusCode != code {
+					t.Fatalf("expect %d for %s, got %d", code, role, response.StatusCode)
+				}
+			})
+		}
+	})
+
+	t.Run("test protected report with invalid token", func(t *testing.T) {
+		mockRepoStore.EXPECT().FindToken(
+			gomock.Eq(repo),
+			gomock.Eq("invalid"),
+		).Return(nil, gorm.ErrRecordNotFound)
+		expectRejection("")
+		r := newRouter(&core.RepoSetting{Protected: true})
+		req, _ := http.NewRequest("POST", "/", nil)
+		req.Header.Set("Authorization", "Bearer invalid")
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			response := w.Result()
+			defer response.Body.Close()
+			if response.StatusCode != 401 {
+				t.Fatal()
+			}
+		})
+	})
 }
 
 func TestGetRepo(t *testing.T) {
@@ -253,7 +374,6 @@ func TestGet(t *testing.T) {
 
 	reportStore := mock.NewMockReportStore(ctrl)
 	repoStore := mock.NewMockRepoStore(ctrl)
-	service := mock.NewMockSCMService(ctrl)
 
 	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{
 		ReportID: repo.ReportID,
@@ -263,7 +383,7 @@ func TestGet(t *testing.T) {
 		Reference: repo.Branch,
 	})).Return(report, nil)
 	r := gin.Default()
-	r.GET("/reports/:id", HandleGet(reportStore, repoStore, service))
+	r.GET("/reports/:id", HandleGet(reportStore, repoStore))
 
 	req, _ := http.NewRequest("GET", "/reports/1234", nil)
 	query := req.URL.Query()
@@ -284,6 +404,57 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestGetPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reportStore := mock.NewMockReportStore(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+
+	reportStore.EXPECT().Finds(
+		gomock.Eq(&core.Report{ReportID: "1234"}),
+		gomock.Eq(core.Page{Limit: 2}),
+	).Return([]*core.Report{{Commit: "c2"}, {Commit: "c1"}}, "next", nil)
+	reportStore.EXPECT().List(
+		gomock.Eq("1234"), gomock.Eq("master"), gomock.Eq(core.Page{Cursor: "next", Limit: 2}),
+	).Return([]*core.Report{{Commit: "c0"}}, "", nil)
+	reportStore.EXPECT().Finds(
+		gomock.Any(), gomock.Eq(core.Page{Cursor: "invalid"}),
+	).Return(nil, "", core.ErrInvalidCursor)
+
+	r := gin.Default()
+	r.GET("/reports/:id", HandleGet(reportStore, repoStore))
+
+	get := func(url string) *http.Response {
+		req, _ := http.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	rst := get("/reports/1234?limit=2")
+	defer rst.Body.Close()
+	if rst.StatusCode != 200 || rst.Header.Get("X-Next-Cursor") != "next" {
+		t.Fatal("should have next cursor")
+	}
+	if link := rst.Header.Get("Link"); link != `</reports/1234?cursor=next&limit=2>; rel="next"` {
+		t.Fatalf("unexpected link %s", link)
+	}
+
+	rst = get("/reports/1234?ref=master&cursor=next&limit=2")
+	defer rst.Body.Close()
+	if rst.StatusCode != 200 || rst.Header.Get("Link") != "" || rst.Header.Get("X-Next-Cursor") != "" {
+		t.Fatal("should not have next page")
+	}
+
+	for _, url := range []string{"/reports/1234?cursor=invalid", "/reports/1234?limit=-1"} {
+		rst = get(url)
+		defer rst.Body.Close()
+		if rst.StatusCode != 400 {
+			t.Fatalf("%s should be bad request", url)
+		}
+	}
+}
+
 func TestGetPrivate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -297,29 +468,24 @@ func TestGetPrivate(t *testing.T) {
 	}
 	reportStore := mock.NewMockReportStore(ctrl)
 	repoStore := mock.NewMockRepoStore(ctrl)
-	service := mock.NewMockSCMService(ctrl)
-	client := mock.NewMockClient(ctrl)
-	repoService := mock.NewMockGitRepoService(ctrl)
+	service := mock.NewMockPermissionService(ctrl)
 
 	repoStore.EXPECT().Find(
 		gomock.Eq(&core.Repo{ReportID: repo.ReportID}),
 	).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{}, nil)
 
-	service.EXPECT().Client(
-		gomock.Eq(repo.SCM),
-	).Return(client, nil)
-
-	client.EXPECT().Repositories().Return(repoService)
-
-	repoService.EXPECT().Find(
-		gomock.Any(),
-		gomock.Any(),
-		gomock.Eq(repo.FullName()),
-	).Return(repo, fmt.Errorf(""))
+	user := &core.User{Login: "user"}
+	service.EXPECT().Role(gomock.Any(), gomock.Eq(repo), gomock.Nil()).Return(core.RoleNone, nil)
+	service.EXPECT().Role(gomock.Any(), gomock.Eq(repo), gomock.Eq(user)).Return(core.RoleNone, nil)
 
 	// test if no user login
 	r := gin.Default()
-	r.GET("/reports/:id", HandleGet(reportStore, repoStore, service))
+	r.GET("/reports/:id",
+		InjectReportContext(repoStore),
+		request.RequireRole(service, core.RoleViewer),
+		HandleGet(reportStore, repoStore),
+	)
 
 	req, _ := http.NewRequest("GET", "/reports/1234", nil)
 
@@ -334,9 +500,13 @@ func TestGetPrivate(t *testing.T) {
 	// test if user login but without repository access right
 	r = gin.Default()
 	r.Use(func(c *gin.Context) {
-		request.WithUser(c, &core.User{})
+		request.WithUser(c, user)
 	})
-	r.GET("/reports/:id", HandleGet(reportStore, repoStore, service))
+	r.GET("/reports/:id",
+		InjectReportContext(repoStore),
+		request.RequireRole(service, core.RoleViewer),
+		HandleGet(reportStore, repoStore),
+	)
 	testRequest(r, req, func(w *httptest.ResponseRecorder) {
 		rst := w.Result()
 		defer rst.Body.Close()
@@ -356,7 +526,6 @@ func TestGetNotFound(t *testing.T) {
 
 	repoStore := mock.NewMockRepoStore(ctrl)
 	reportStore := mock.NewMockReportStore(ctrl)
-	service := mock.NewMockSCMService(ctrl)
 
 	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{
 		ReportID: repo.ReportID,
@@ -365,7 +534,7 @@ func TestGetNotFound(t *testing.T) {
 	reportStore.EXPECT().Find(gomock.Any()).Return(nil, gorm.ErrRecordNotFound)
 
 	r := gin.Default()
-	r.GET("/reports/:id", HandleGet(reportStore, repoStore, service))
+	r.GET("/reports/:id", HandleGet(reportStore, repoStore))
 
 	req, _ := http.NewRequest("GET", "/reports/1234", nil)
 	query := req.URL.Query()
@@ -416,6 +585,7 @@ func TestGetTreeMap(t *testing.T) {
 	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{
 		ReportID: reportID,
 	})).Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).Return(&core.RepoSetting{}, nil)
 
 	reportStore.EXPECT().Find(gomock.Eq(
 		&core.Report{
@@ -449,9 +619,12 @@ func TestGetTreeMap(t *testing.T) {
 
 	r := gin.Default()
 	r.GET("/reports/:id/treemap/*ref", HandleGetTreeMap(
+		&config.Config{},
+		nil,
 		reportStore,
 		repoStore,
 		chartService,
+		nil,
 	))
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf(
@@ -477,6 +650,193 @@ func TestGetTreeMap(t *testing.T) {
 	})
 }
 
+func TestGetTreeMapPullRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reportStore := mock.NewMockReportStore(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+	chartService := mock.NewMockChartService(ctrl)
+	chart := mock.NewMockChart(ctrl)
+	service := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	prService := mock.NewMockPullRequestService(ctrl)
+	reportID := "report_id"
+	repo := &core.Repo{
+		ReportID:  reportID,
+		Branch:    "master",
+		NameSpace: "org",
+		Name:      "repo",
+		SCM:       core.Github,
+	}
+	user := &core.User{Login: "creator"}
+	old := &core.Report{ReportID: reportID, Reference: "base", Commit: "base"}
+	newReport := &core.Report{ReportID: reportID, Reference: "new", Commit: "new"}
+	changes := []*core.FileChange{{Path: "main.go"}}
+
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: reportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{}, nil)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).AnyTimes().Return(user, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: reportID, Commit: "new"})).AnyTimes().Return(newReport, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: reportID, Commit: "base"})).AnyTimes().Return(old, nil)
+	reportStore.EXPECT().FindComment(gomock.Eq(&core.Report{ReportID: reportID}), gomock.Eq(3)).AnyTimes().Return(
+		&core.ReportComment{Number: 3, Comment: 1}, nil,
+	)
+	reportStore.EXPECT().FindComment(gomock.Eq(&core.Report{ReportID: reportID}), gomock.Eq(4)).AnyTimes().Return(
+		nil, gorm.ErrRecordNotFound,
+	)
+	service.EXPECT().Client(gomock.Eq(core.Github)).AnyTimes().Return(client, nil)
+	client.EXPECT().PullRequests().AnyTimes().Return(prService)
+	listed := 0
+	prService.EXPECT().ListChanges(gomock.Any(), gomock.Eq(user), gomock.Eq("org/repo"), gomock.Eq(3)).AnyTimes().DoAndReturn(
+		func(context.Context, *core.User, string, int) ([]*core.FileChange, error) {
+			listed++
+			return changes, nil
+		},
+	)
+	chartService.EXPECT().CoverageChangeTreeMap(
+		gomock.Eq(old),
+		gomock.Eq(newReport),
+		gomock.Any(),
+		gomock.Eq(maxTreeMapCells),
+	).Times(3).Return(chart)
+	chart.EXPECT().Render(gomock.Any()).Times(3).Do(func(w io.Writer) {
+		_, _ = w.Write([]byte("<svg></svg>"))
+	}).Return(nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/treemap/*ref", HandleGetTreeMap(
+		&config.Config{},
+		service,
+		reportStore,
+		repoStore,
+		chartService,
+		nil,
+	))
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf(
+		"/reports/%s/treemap/new?base=base&pr=3&cells=1000",
+		reportID,
+	), nil)
+	var tag string
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		data, _ := ioutil.ReadAll(rst.Body)
+		if rst.StatusCode != 200 || string(data) != "<svg></svg>" {
+			t.Fatalf("unexpected response %d %s", rst.StatusCode, data)
+		}
+		tag = rst.Header.Get("ETag")
+	})
+
+	// new commits of the pull request change files without new reports
+	changes = []*core.FileChange{{Path: "main.go"}, {Path: "util.go", Added: true}}
+	req.Header.Set("If-None-Match", tag)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 || rst.Header.Get("ETag") == tag {
+			t.Fatalf("tag should change with changes of the pull request, got %d", rst.StatusCode)
+		}
+	})
+
+	// changes are listed from SCM once for the report while they are cached
+	listed = 0
+	r = gin.Default()
+	r.GET("/reports/:id/treemap/*ref", HandleGetTreeMap(
+		&config.Config{},
+		service,
+		reportStore,
+		repoStore,
+		chartService,
+		cache.NewMemory(10),
+	))
+	req.Header.Del("If-None-Match")
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 {
+			t.Fatalf("unexpected response %d", rst.StatusCode)
+		}
+		tag = rst.Header.Get("ETag")
+	})
+	req.Header.Set("If-None-Match", tag)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 304 {
+			t.Fatalf("should answer not modified, got %d", rst.StatusCode)
+		}
+	})
+	if listed != 1 {
+		t.Fatalf("should list changes once, got %d", listed)
+	}
+
+	// pull requests not commented with the treemap are never listed from SCM
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/reports/%s/treemap/new?base=base&pr=4", reportID), nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 404 {
+			t.Fatalf("should not find pull request, got %d", rst.StatusCode)
+		}
+	})
+}
+func TestComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	reportStore := mock.NewMockReportStore(ctrl)
+	repoStore := mock.NewMockRepoStore(ctrl)
+	service := mock.NewMockSCMService(ctrl)
+	client := mock.NewMockClient(ctrl)
+	prService := mock.NewMockPullRequestService(ctrl)
+	reportID := "report_id"
+	repo := &core.Repo{ReportID: reportID, Branch: "master", NameSpace: "org", Name: "repo", SCM: core.Github}
+	user := &core.User{Login: "creator"}
+	source := &core.Report{ReportID: reportID, Reference: "feature", Commit: "new"}
+	target := &core.Report{ReportID: reportID, Reference: "master", Commit: "base"}
+
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: reportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Creator(gomock.Eq(repo)).Return(user, nil)
+	service.EXPECT().Client(gomock.Eq(core.Github)).Return(client, nil)
+	client.EXPECT().PullRequests().AnyTimes().Return(prService)
+	prService.EXPECT().Find(gomock.Any(), gomock.Eq(user), gomock.Eq("org/repo"), gomock.Eq(5)).Return(
+		&core.PullRequest{Number: 5, Commit: "new", Source: "feature", Target: "master"}, nil,
+	)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: reportID, Commit: "new"})).Return(source, nil)
+	reportStore.EXPECT().Find(gomock.Eq(&core.Report{ReportID: reportID, Reference: "master"})).Return(target, nil)
+	reportStore.EXPECT().FindComment(gomock.Eq(&core.Report{ReportID: reportID}), gomock.Eq(5)).Return(
+		nil, gorm.ErrRecordNotFound,
+	)
+	// the pull request is kept before the comment with its treemap is posted
+	gomock.InOrder(
+		reportStore.EXPECT().CreateComment(
+			gomock.Eq(&core.Report{ReportID: reportID}), gomock.Eq(&core.ReportComment{Number: 5}),
+		).Return(nil),
+		prService.EXPECT().CreateComment(gomock.Any(), gomock.Eq(user), gomock.Eq("org/repo"), gomock.Eq(5), gomock.Any()).Return(7, nil),
+		reportStore.EXPECT().CreateComment(
+			gomock.Eq(&core.Report{ReportID: reportID}), gomock.Eq(&core.ReportComment{Number: 5, Comment: 7}),
+		).Return(nil),
+	)
+
+	cfg := &config.Config{}
+	r := gin.Default()
+	r.POST("/reports/:id/comment/:number", HandleComment(
+		cfg,
+		service,
+		repoStore,
+		reportStore,
+		&reportService.Service{Config: cfg, RepoStore: repoStore},
+	))
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/reports/%s/comment/5", reportID), nil)
+	testRequest(r, req, func(w *httptest.ResponseRecorder) {
+		rst := w.Result()
+		defer rst.Body.Close()
+		if rst.StatusCode != 200 {
+			t.Fatalf("unexpected status %d", rst.StatusCode)
+		}
+	})
+}
+
 func TestGetCard(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -496,19 +856,24 @@ func TestGetCard(t *testing.T) {
 	mockRepo.EXPECT().Find(
 		gomock.Eq(&core.Repo{ReportID: repo.ReportID}),
 	).Return(repo, nil)
-	mockReport.EXPECT().Find(
+	mockReport.EXPECT().FindSummary(
 		gomock.Eq(&core.Report{ReportID: repo.ReportID, Reference: repo.Branch}),
 	).Return(report, nil)
-	mockChart.EXPECT().RepoCard(
-		gomock.Eq(repo),
-		gomock.Eq(report),
-	).Return(charts.NewRepoCard(repo, report))
+	mockReport.EXPECT().Find(
+		gomock.Eq(&core.Report{ReportID: repo.ReportID}),
+	).Return(report, nil)
+	mockRepo.EXPECT().Setting(gomock.Eq(repo)).Return(&core.RepoSetting{}, nil)
+	mockChart.EXPECT().RepoCard(gomock.Any()).DoAndReturn(func(card *core.Card) core.Chart {
+		return charts.NewRepoCard(card)
+	})
 
 	r := gin.Default()
 	r.GET("/reports/:id/card", HandleGetCard(
+		nil,
 		mockRepo,
 		mockReport,
 		mockChart,
+		nil,
 	))
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf(
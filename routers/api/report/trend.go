@@ -0,0 +1,112 @@
+package report
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+const (
+	defaultTrendLimit = 30
+	// maxTrendReports in a date range
+	maxTrendReports = 500
+)
+
+type trendOptions struct {
+	Branch string `form:"branch"`
+	Days   int    `form:"days"`
+	Limit  int    `form:"limit"`
+}
+
+// listTrend reports of the branch, in the last days if days is positive,
+// or the latest limit reports otherwise
+func listTrend(store core.ReportStore, reportID, branch string, days, limit int) ([]*core.Report, error) {
+	if days <= 0 {
+		if limit <= 0 {
+			limit = defaultTrendLimit
+		}
+		reports, _, err := store.List(reportID, branch, core.Page{Limit: limit})
+		return reports, err
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	result := make([]*core.Report, 0)
+	page := core.Page{Limit: core.MaxPageLimit}
+	for len(result) < maxTrendReports {
+		reports, next, err := store.List(reportID, branch, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, report := range reports {
+			if report.CreatedAt.Before(since) {
+				return result, nil
+			}
+			result = append(result, report)
+		}
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	return result, nil
+}
+
+// HandleGetTrend of the coverage
+// @Summary Get coverage trend line chart of a branch
+// @Tags Report
+// @Produce image/svg+xml
+// @Param id path string true "report id"
+// @Param branch query string false "branch of reports, default branch if empty"
+// @Param days query int false "show reports in the last days"
+// @Param limit query int false "show the latest reports if days is not given, default 30 and 100 at most"
+// @Success 200 {object} string "trend svg"
+// @Header 200 {string} ETag "entity tag of the trend"
+// @Success 304 {string} string "not modified"
+// @Failure 400 {string} string "error message"
+// @Failure 404 {string} string "error message"
+// @Router /reports/{id}/trend.svg [get]
+func HandleGetTrend(
+	reportStore core.ReportStore,
+	repoStore core.RepoStore,
+	chartService core.ChartService,
+	cache core.Cache,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		option := &trendOptions{}
+		if err := c.BindQuery(option); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		repo, err := repoStore.Find(&core.Repo{ReportID: reportID})
+		if err != nil {
+			c.String(404, "repository not found")
+			return
+		}
+		branch := option.Branch
+		if branch == "" {
+			branch = repo.Branch
+		}
+		reports, err := listTrend(reportStore, reportID, branch, option.Days, option.Limit)
+		if err != nil {
+			c.String(404, "reports not found")
+			return
+		}
+		setting := getSetting(c, repoStore, repo)
+		tag := entityTag(c, repo, setting, reports...)
+		c.Header("Cache-Control", "max-age=600")
+		if notModified(c, tag, lastModified(setting, reports...)) {
+			return
+		}
+		data, err := render(cache, tag, func(w io.Writer) error {
+			return chartService.CoverageTrend(reports).Render(w)
+		})
+		if err != nil {
+			c.String(500, err.Error())
+			return
+		}
+		c.Data(200, "image/svg+xml", data)
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/jinzhu/gorm"
+
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+)
+
+func TestGetTrend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoStore := mock.NewMockRepoStore(ctrl)
+	reportStore := mock.NewMockReportStore(ctrl)
+	chartService := mock.NewMockChartService(ctrl)
+	chart := mock.NewMockChart(ctrl)
+
+	repo := &core.Repo{ID: 1, Branch: "master", ReportID: "report_id"}
+	now := time.Now()
+	report := func(commit string, days int) *core.Report {
+		return &core.Report{ReportID: repo.ReportID, Commit: commit, CreatedAt: now.AddDate(0, 0, -days)}
+	}
+	repoStore.EXPECT().Find(gomock.Eq(&core.Repo{ReportID: repo.ReportID})).AnyTimes().Return(repo, nil)
+	repoStore.EXPECT().Setting(gomock.Eq(repo)).AnyTimes().Return(&core.RepoSetting{}, nil)
+	chart.EXPECT().Render(gomock.Any()).AnyTimes().Return(nil)
+
+	r := gin.Default()
+	r.GET("/reports/:id/trend.svg", HandleGetTrend(reportStore, repoStore, chartService, nil))
+	get := func(query string) int {
+		req, _ := http.NewRequest("GET", "/reports/report_id/trend.svg"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Result().StatusCode
+	}
+
+	t.Run("latest", func(t *testing.T) {
+		reports := []*core.Report{report("b", 1), report("a", 2)}
+		reportStore.EXPECT().List(repo.ReportID, "master", core.Page{Limit: defaultTrendLimit}).Return(reports, "next", nil)
+		chartService.EXPECT().CoverageTrend(gomock.Eq(reports)).Return(chart)
+		if status := get(""); status != 200 {
+			t.Fatal(status)
+		}
+	})
+
+	t.Run("days", func(t *testing.T) {
+		reportStore.EXPECT().List(repo.ReportID, "dev", core.Page{Limit: core.MaxPageLimit}).Return(
+			[]*core.Report{report("d", 1), report("c", 2)}, "cursor", nil,
+		)
+		reportStore.EXPECT().List(repo.ReportID, "dev", core.Page{Cursor: "cursor", Limit: core.MaxPageLimit}).Return(
+			[]*core.Report{report("b", 5), report("a", 20)}, "more", nil,
+		)
+		chartService.EXPECT().CoverageTrend(gomock.Any()).DoAndReturn(func(reports []*core.Report) core.Chart {
+			if len(reports) != 3 || reports[2].Commit != "b" {
+				t.Fatalf("unexpected reports %v", reports)
+			}
+			return chart
+		})
+		if status := get("?branch=dev&days=10"); status != 200 {
+			t.Fatal(status)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		reportStore.EXPECT().List(repo.ReportID, "gone", gomock.Any()).Return(nil, "", gorm.ErrRecordNotFound)
+		if status := get("?branch=gone"); status != 404 {
+			t.Fatal(status)
+		}
+	})
+}
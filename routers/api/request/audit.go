@@ -0,0 +1,40 @@
+package request
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+// Audit records the action to the repository by the current user,
+// repo is nil for actions to the account of the user.
+// Before and after are snapshots of the changed object, which can be nil.
+// Failure to record is reported to the context without aborting the request.
+func Audit(c *gin.Context, store core.AuditStore, repo *core.Repo, action core.AuditAction, before, after interface{}) {
+	log := &core.AuditLog{
+		Repo:   repo,
+		Action: action,
+		IP:     c.ClientIP(),
+		Before: snapshot(before),
+		After:  snapshot(after),
+	}
+	if user, ok := UserFrom(c); ok {
+		log.Actor = user.Login
+	}
+	if err := store.Create(log); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func snapshot(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
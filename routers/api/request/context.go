@@ -8,7 +8,9 @@ import (
 )
 
 const (
-	keyUser = "user"
+	keyUser  = "user"
+	keyToken = "token"
+	keyRepo  = "repo"
 )
 
 // WithUser context
@@ -38,3 +40,56 @@ func MustGetUserFrom(c *gin.Context) *core.User {
 	}
 	return user
 }
+
+// WithToken authorizes the current request
+func WithToken(c *gin.Context, token *core.OAuthToken) {
+	c.Set(keyToken, token)
+}
+
+// TokenFrom context. It is not found if the request is authorized by session.
+func TokenFrom(c *gin.Context) (*core.OAuthToken, bool) {
+	data, ok := c.Get(keyToken)
+	if !ok {
+		return nil, false
+	}
+	token, ok := data.(*core.OAuthToken)
+	return token, ok
+}
+
+// WithRepo context
+func WithRepo(c *gin.Context, repo *core.Repo) {
+	c.Set(keyRepo, repo)
+}
+
+// RepoFrom context
+func RepoFrom(c *gin.Context) (*core.Repo, bool) {
+	data, ok := c.Get(keyRepo)
+	if !ok {
+		return nil, false
+	}
+	repo, ok := data.(*core.Repo)
+	return repo, ok
+}
+
+// MustGetRepo from current context, otherwise panic
+func MustGetRepo(c *gin.Context) *core.Repo {
+	return c.MustGet(keyRepo).(*core.Repo)
+}
+
+// RequireScope aborts the request authorized by OAuth token without the given scopes.
+// Requests authorized by session are always allowed.
+func RequireScope(scopes ...core.TokenScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := TokenFrom(c)
+		if !ok {
+			return
+		}
+		for _, scope := range scopes {
+			if !token.HasScope(scope) {
+				c.String(403, "token requires scope %s", scope)
+				c.Abort()
+				return
+			}
+		}
+	}
+}
@@ -8,20 +8,41 @@ import (
 	"github.com/covergates/covergates/core"
 )
 
-// CheckLogin session
-func CheckLogin(session core.Session, oauth core.OAuthService) gin.HandlerFunc {
+// CheckLogin session or OAuth token. The OAuth token is required to have all the given scopes.
+func CheckLogin(session core.Session, oauth core.OAuthService, scopes ...core.TokenScope) gin.HandlerFunc {
+	requireScope := RequireScope(scopes...)
 	return func(c *gin.Context) {
-		user, err := oauth.Validate(c.Request)
-		if err == nil && user != nil && user.Login != "" {
-			WithUser(c, user)
-			return
-		}
-		user = session.GetUser(c)
-		if user.Login == "" {
+		if !login(c, session, oauth) {
 			c.String(401, "Unauthorized")
 			c.Abort()
 			return
 		}
-		WithUser(c, user)
+		requireScope(c)
+	}
+}
+
+// OptionalLogin loads the user of session or OAuth token, but allows anonymous requests.
+// The OAuth token is required to have all the given scopes.
+func OptionalLogin(session core.Session, oauth core.OAuthService, scopes ...core.TokenScope) gin.HandlerFunc {
+	requireScope := RequireScope(scopes...)
+	return func(c *gin.Context) {
+		if login(c, session, oauth) {
+			requireScope(c)
+		}
+	}
+}
+
+func login(c *gin.Context, session core.Session, oauth core.OAuthService) bool {
+	token, err := oauth.Validate(c.Request)
+	if err == nil && token.Owner != nil && token.Owner.Login != "" {
+		WithUser(c, token.Owner)
+		WithToken(c, token)
+		return true
+	}
+	user := session.GetUser(c)
+	if user.Login == "" {
+		return false
 	}
+	WithUser(c, user)
+	return true
 }
@@ -9,22 +9,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func CheckLogin(session core.Session, oauth core.OAuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		user := &core.User{
-			Login:       os.Getenv("DEBUG_LOGIN"),
-			Email:       os.Getenv("DEBUG_EMAIL"),
-			Avatar:      os.Getenv("DEBUG_AVATAR"),
-			GithubLogin: os.Getenv("DEBUG_GITHUB_LOGIN"),
-			GithubEmail: os.Getenv("DEBUG_EMAIL"),
-			GithubToken: os.Getenv("DEBUG_GITHUB_TOKEN"),
-			GiteaLogin:  os.Getenv("DEBUG_GITEA_LOGIN"),
-			GiteaEmail:  os.Getenv("DEBUG_EMAIL"),
-			GiteaToken:  os.Getenv("DEBUG_GITEA_TOKEN"),
-			GitLabLogin: os.Getenv("DEBUG_GITLAB_LOGIN"),
-			GitLabEmail: os.Getenv("DEBUG_EMAIL"),
-			GitLabToken: os.Getenv("DEBUG_GITLAB_TOKEN"),
-		}
-		WithUser(c, user)
+func CheckLogin(session core.Session, oauth core.OAuthService, scopes ...core.TokenScope) gin.HandlerFunc {
+	return debugLogin
+}
+
+func OptionalLogin(session core.Session, oauth core.OAuthService, scopes ...core.TokenScope) gin.HandlerFunc {
+	return debugLogin
+}
+
+func debugLogin(c *gin.Context) {
+	user := &core.User{
+		Login:       os.Getenv("DEBUG_LOGIN"),
+		Email:       os.Getenv("DEBUG_EMAIL"),
+		Avatar:      os.Getenv("DEBUG_AVATAR"),
+		GithubLogin: os.Getenv("DEBUG_GITHUB_LOGIN"),
+		GithubEmail: os.Getenv("DEBUG_EMAIL"),
+		GithubToken: os.Getenv("DEBUG_GITHUB_TOKEN"),
+		GiteaLogin:  os.Getenv("DEBUG_GITEA_LOGIN"),
+		GiteaEmail:  os.Getenv("DEBUG_EMAIL"),
+		GiteaToken:  os.Getenv("DEBUG_GITEA_TOKEN"),
+		GitLabLogin: os.Getenv("DEBUG_GITLAB_LOGIN"),
+		GitLabEmail: os.Getenv("DEBUG_EMAIL"),
+		GitLabToken: os.Getenv("DEBUG_GITLAB_TOKEN"),
 	}
+	WithUser(c, user)
 }
@@ -0,0 +1,63 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+var errPageLimit = errors.New("page limit should not be negative")
+
+type pageQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+}
+
+// PageFrom cursor and limit query parameters
+func PageFrom(c *gin.Context) (core.Page, error) {
+	query := &pageQuery{}
+	if err := c.ShouldBindQuery(query); err != nil {
+		return core.Page{}, err
+	}
+	if query.Limit < 0 {
+		return core.Page{}, errPageLimit
+	}
+	return core.Page{Cursor: query.Cursor, Limit: query.Limit}, nil
+}
+
+// WithNextPage sets the next cursor to X-Next-Cursor header
+// and the URL of the next page to Link header. Nothing is set at the last page.
+func WithNextPage(c *gin.Context, page core.Page, next string) {
+	if next == "" {
+		return
+	}
+	u := *c.Request.URL
+	query := u.Query()
+	query.Set("cursor", next)
+	query.Set("limit", strconv.Itoa(page.Size()))
+	u.RawQuery = query.Encode()
+	c.Header("X-Next-Cursor", next)
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.RequestURI()))
+}
+
+// OffsetPage of a list with the length. The cursor is the offset of the page.
+// It returns the range of the page and the next cursor.
+func OffsetPage(page core.Page, length int) (start, end int, next string, err error) {
+	if page.Cursor != "" {
+		if start, err = strconv.Atoi(page.Cursor); err != nil || start < 0 {
+			return 0, 0, "", core.ErrInvalidCursor
+		}
+	}
+	if start > length {
+		start = length
+	}
+	end = start + page.Size()
+	if end >= length {
+		return start, length, "", nil
+	}
+	return start, end, strconv.Itoa(end), nil
+}
@@ -0,0 +1,33 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/covergates/covergates/core"
+)
+
+// RequireRole aborts the request if the user does not have the role to the repository in context.
+// Anonymous requests are treated as viewers of public repositories.
+func RequireRole(service core.PermissionService, role core.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo, ok := RepoFrom(c)
+		if !ok {
+			c.String(404, "repository not found")
+			c.Abort()
+			return
+		}
+		user, _ := UserFrom(c)
+		current, err := service.Role(c.Request.Context(), repo, user)
+		if err != nil {
+			_ = c.Error(err)
+			c.String(500, err.Error())
+			c.Abort()
+			return
+		}
+		if !current.Includes(role) {
+			c.String(401, "permission denied")
+			c.Abort()
+			return
+		}
+	}
+}
@@ -12,18 +12,25 @@ import (
 
 // Token for API
 type Token struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID     uint              `json:"id"`
+	Name   string            `json:"name"`
+	Scopes []core.TokenScope `json:"scopes"`
+	// Expires is omitted if the token never expires
+	Expires    *time.Time `json:"expires,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
 }
 
 // HandleCreateToken for user
 // @Summary create OAuth token
 // @Tags User
 // @Param name formData string false "token name"
+// @Param scope formData string true "token scope, repeat for multiple scopes"
+// @Param expires formData integer false "days before the token expires, never expires if empty"
 // @Success 200 {object} string access token
+// @Failure 400 {object} string error message
 // @Router /user/tokens [post]
-func HandleCreateToken(service core.OAuthService) gin.HandlerFunc {
+func HandleCreateToken(service core.OAuthService, auditStore core.AuditStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, ok := request.UserFrom(c)
 		if !ok {
@@ -31,13 +38,35 @@ func HandleCreateToken(service core.OAuthService) gin.HandlerFunc {
 			return
 		}
 		tokenName := c.PostForm("name")
+		scopes := make([]core.TokenScope, 0)
+		for _, scope := range c.PostFormArray("scope") {
+			if !core.IsValidTokenScope(core.TokenScope(scope)) {
+				c.String(400, "invalid scope %s", scope)
+				return
+			}
+			scopes = append(scopes, core.TokenScope(scope))
+		}
+		if len(scopes) == 0 {
+			c.String(400, "require scope")
+			return
+		}
+		var expiresIn time.Duration
+		if expires := c.PostForm("expires"); expires != "" {
+			days, err := strconv.Atoi(expires)
+			if err != nil || days < 0 {
+				c.String(400, "invalid expires")
+				return
+			}
+			expiresIn = time.Duration(days) * 24 * time.Hour
+		}
 		ctx := service.WithUser(c.Request.Context(), user)
-		token, err := service.CreateToken(ctx, tokenName)
+		token, err := service.CreateToken(ctx, tokenName, scopes, expiresIn)
 		if err != nil {
 			_ = c.Error(err)
 			c.String(500, "")
 			return
 		}
+		request.Audit(c, auditStore, nil, core.AuditUserTokenCreate, nil, toToken(token))
 		c.String(200, token.Access)
 	}
 }
@@ -63,11 +92,7 @@ func HandleListTokens(service core.OAuthService) gin.HandlerFunc {
 		}
 		result := make([]*Token, len(tokens))
 		for i, token := range tokens {
-			result[i] = &Token{
-				ID:        token.ID,
-				Name:      token.Name,
-				CreatedAt: token.CreatedAt,
-			}
+			result[i] = toToken(token)
 		}
 		c.JSON(200, result)
 	}
@@ -79,7 +104,7 @@ func HandleListTokens(service core.OAuthService) gin.HandlerFunc {
 // @Param id path integer true "token id"
 // @Success 200 {object} Token "deleted token"
 // @Router /user/tokens/{id} [delete]
-func HandleDeleteToken(service core.OAuthService, store core.OAuthStore) gin.HandlerFunc {
+func HandleDeleteToken(service core.OAuthService, store core.OAuthStore, auditStore core.AuditStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, ok := request.UserFrom(c)
 		if !ok {
@@ -104,6 +129,50 @@ func HandleDeleteToken(service core.OAuthService, store core.OAuthStore) gin.Han
 			c.JSON(500, &Token{})
 			return
 		}
+		request.Audit(c, auditStore, nil, core.AuditUserTokenDelete, toToken(token), nil)
 		c.JSON(200, &Token{ID: token.ID, Name: token.Name})
 	}
 }
+
+func toToken(token *core.OAuthToken) *Token {
+	t := &Token{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt,
+	}
+	if t.Scopes == nil {
+		t.Scopes = []core.TokenScope{}
+	}
+	if !token.Expires.IsZero() {
+		expires := token.Expires
+		t.Expires = &expires
+	}
+	if !token.LastUsedAt.IsZero() {
+		lastUsedAt := token.LastUsedAt
+		t.LastUsedAt = &lastUsedAt
+	}
+	return t
+}
+
+// HandleListAudit logs of the user
+// @Summary list audit logs of actions to the account of the user, such as token changes, the latest first
+// @Tags User
+// @Success 200 {object} []core.AuditLog "audit logs"
+// @Router /user/audit [get]
+func HandleListAudit(store core.AuditStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := request.UserFrom(c)
+		if !ok {
+			c.JSON(401, []*core.AuditLog{})
+			return
+		}
+		logs, err := store.ListUser(user)
+		if err != nil {
+			_ = c.Error(err)
+			c.JSON(500, []*core.AuditLog{})
+			return
+		}
+		c.JSON(200, logs)
+	}
+}
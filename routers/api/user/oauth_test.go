@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -43,6 +44,8 @@ func TestTokenCreate(t *testing.T) {
 	mockService.EXPECT().CreateToken(
 		gomock.Any(),
 		gomock.Eq(mockToken.Name),
+		gomock.Eq([]core.TokenScope{core.ScopeReportWrite}),
+		gomock.Eq(30*24*time.Hour),
 	).Return(
 		mockToken, nil,
 	)
@@ -57,11 +60,19 @@ func TestTokenCreate(t *testing.T) {
 		request.WithUser(c, mockUser)
 	})
 
-	r.POST("/tokens", user.HandleCreateToken(mockService))
+	auditStore := mock.NewMockAuditStore(ctrl)
+	auditStore.EXPECT().Create(gomock.Any()).Do(func(log *core.AuditLog) {
+		if log.Action != core.AuditUserTokenCreate || log.Actor != "user" || log.Repo != nil {
+			t.Fatalf("unexpected audit log %v", log)
+		}
+	}).Return(nil)
+	r.POST("/tokens", user.HandleCreateToken(mockService, auditStore))
 
 	buf := &bytes.Buffer{}
 	w := multipart.NewWriter(buf)
 	_ = w.WriteField("name", mockToken.Name)
+	_ = w.WriteField("scope", string(core.ScopeReportWrite))
+	_ = w.WriteField("expires", "30")
 	_ = w.Close()
 	req, _ := http.NewRequest("POST", "/tokens", buf)
 	req.Header.Set("Content-Type", w.FormDataContentType())
@@ -78,6 +89,42 @@ func TestTokenCreate(t *testing.T) {
 	})
 }
 
+func TestTokenCreateInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock.NewMockOAuthService(ctrl)
+	r := gin.Default()
+	r.Use(func(c *gin.Context) {
+		request.WithUser(c, &core.User{Login: "user"})
+	})
+	r.POST("/tokens", user.HandleCreateToken(mockService, mock.NewMockAuditStore(ctrl)))
+
+	fields := []map[string]string{
+		{"name": "token", "scope": "invalid"},
+		{"name": "token"},
+		{"name": "token", "scope": "user", "expires": "-1"},
+		{"name": "token", "scope": "user", "expires": "day"},
+	}
+	for _, field := range fields {
+		buf := &bytes.Buffer{}
+		w := multipart.NewWriter(buf)
+		for key, value := range field {
+			_ = w.WriteField(key, value)
+		}
+		_ = w.Close()
+		req, _ := http.NewRequest("POST", "/tokens", buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		testRequest(r, req, func(w *httptest.ResponseRecorder) {
+			respond := w.Result()
+			defer respond.Body.Close()
+			if respond.StatusCode != 400 {
+				t.Fatalf("expect 400 for %v, got %d", field, respond.StatusCode)
+			}
+		})
+	}
+}
+
 func TestListTokens(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -156,10 +203,11 @@ func TestDeleteToken(t *testing.T) {
 
 	mockService := mock.NewMockOAuthService(ctrl)
 	mockStore := mock.NewMockOAuthStore(ctrl)
+	auditStore := mock.NewMockAuditStore(ctrl)
 
 	t.Run("should check user", func(t *testing.T) {
 		r := gin.Default()
-		r.DELETE("/tokens/:id", user.HandleDeleteToken(mockService, mockStore))
+		r.DELETE("/tokens/:id", user.HandleDeleteToken(mockService, mockStore, auditStore))
 		req, _ := http.NewRequest("DELETE", "/tokens/1", nil)
 		testRequest(r, req, func(w *httptest.ResponseRecorder) {
 			response := w.Result()
@@ -174,7 +222,7 @@ func TestDeleteToken(t *testing.T) {
 	r.Use(func(c *gin.Context) {
 		request.WithUser(c, &core.User{Login: "login"})
 	})
-	r.DELETE("/tokens/:id", user.HandleDeleteToken(mockService, mockStore))
+	r.DELETE("/tokens/:id", user.HandleDeleteToken(mockService, mockStore, auditStore))
 
 	t.Run("should check id", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/tokens/bear", nil)
@@ -223,6 +271,11 @@ func TestDeleteToken(t *testing.T) {
 			&core.OAuthToken{ID: token.ID},
 		).Return(token, nil)
 		mockService.EXPECT().DeleteToken(gomock.Any(), token).Return(nil)
+		auditStore.EXPECT().Create(gomock.Any()).Do(func(log *core.AuditLog) {
+			if log.Action != core.AuditUserTokenDelete || log.Actor != "login" || string(log.Before) == "" {
+				t.Fatalf("unexpected audit log %v", log)
+			}
+		}).Return(nil)
 		req, _ := http.NewRequest("DELETE", "/tokens/1", nil)
 		testRequest(r, req, func(w *httptest.ResponseRecorder) {
 			response := w.Result()
@@ -89,13 +89,12 @@ func HandleGetSCM(config *config.Config) gin.HandlerFunc {
 // @Param name path string true "name"
 // @Success 200 {object} User "owner"
 // @Router /user/owner/{scm}/{namespace}/{name} [get]
-func HandleGetOwner(store core.RepoStore, service core.SCMService) gin.HandlerFunc {
+func HandleGetOwner(store core.RepoStore, service core.PermissionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		provider := core.SCMProvider(c.Param("scm"))
 		repo, err := store.Find(&core.Repo{
 			NameSpace: c.Param("namespace"),
 			Name:      c.Param("name"),
-			SCM:       provider,
+			SCM:       core.SCMProvider(c.Param("scm")),
 		})
 		if err != nil {
 			c.JSON(404, &User{})
@@ -106,36 +105,20 @@ func HandleGetOwner(store core.RepoStore, service core.SCMService) gin.HandlerFu
 			c.JSON(401, &User{})
 			return
 		}
-
-		client, err := service.Client(provider)
+		role, err := service.Role(c.Request.Context(), repo, user)
 		if err != nil {
 			_ = c.Error(err)
 			c.JSON(500, &User{})
 			return
 		}
-
-		if client.Repositories().IsAdmin(c.Request.Context(), user, repo.FullName()) {
-			c.JSON(200, &User{
-				Avatar: user.Avatar,
-				Email:  user.Email,
-				Login:  user.Login,
-			})
-			return
-		}
-
-		owner, err := store.Creator(repo)
-		if err != nil {
-			c.JSON(404, &User{})
-			return
-		}
-		if owner.Login != user.Login {
+		if !role.Includes(core.RoleAdmin) {
 			c.JSON(401, &User{})
 			return
 		}
 		c.JSON(200, &User{
-			Avatar: owner.Avatar,
-			Email:  owner.Email,
-			Login:  owner.Login,
+			Avatar: user.Avatar,
+			Email:  user.Email,
+			Login:  user.Login,
 		})
 	}
 }
@@ -29,6 +29,25 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/metrics": {
+            "get": {
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Metrics"
+                ],
+                "summary": "get hits, misses and hit rates of cached stores",
+                "responses": {
+                    "200": {
+                        "description": "metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/reports/{id}": {
             "get": {
                 "tags": [
@@ -54,6 +73,18 @@ var doc = `{
                         "description": "get report for git ref",
                         "name": "ref",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the page from the X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of reports in a page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -61,6 +92,26 @@ var doc = `{
                         "description": "coverage report",
                         "schema": {
                             "$ref": "#/definitions/core.Report"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the reports"
+                            },
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "cursor of the next page"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
@@ -105,6 +156,12 @@ var doc = `{
                         "name": "ref",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "description": "git worktree root path",
+                        "name": "root",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "description": "files list of the repository",
@@ -130,6 +187,10 @@ var doc = `{
         },
         "/reports/{id}/badge": {
             "get": {
+                "produces": [
+                    "image/svg+xml",
+                    "image/png"
+                ],
                 "tags": [
                     "Report"
                 ],
@@ -143,15 +204,160 @@ var doc = `{
                         "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "get latest report in main branch",
-                        "name": "latest",
+                        "type": "string",
+                        "description": "branch of the report, default branch if empty",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report type to show, such as go or perl",
+                        "name": "flag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "label of the badge, default Covergates",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "flat, flat-square or for-the-badge",
+                        "name": "style",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "line or patch coverage, default line",
+                        "name": "metric",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "svg or png, default svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "width of png, 2048 at most",
+                        "name": "width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "height of png, 2048 at most",
+                        "name": "height",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "badge svg",
+                        "description": "badge svg or png",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the badge"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/badge.json": {
+            "get": {
+                "tags": [
+                    "Report"
+                ],
+                "summary": "get Shields.io endpoint badge for the report id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch of the report, default branch if empty",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "report type to show, such as go or perl",
+                        "name": "flag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "label of the badge, default Covergates",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "flat, flat-square or for-the-badge",
+                        "name": "style",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "line or patch coverage, default line",
+                        "name": "metric",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "badge",
+                        "schema": {
+                            "$ref": "#/definitions/report.ShieldsBadge"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the badge"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
                         "schema": {
                             "type": "string"
                         }
@@ -162,7 +368,8 @@ var doc = `{
         "/reports/{id}/card": {
             "get": {
                 "produces": [
-                    "image/svg+xml"
+                    "image/svg+xml",
+                    "image/png"
                 ],
                 "tags": [
                     "Report"
@@ -175,11 +382,95 @@ var doc = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "dark, light or high-contrast, default from repository setting",
+                        "name": "theme",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated files, hits, build, uncovered, trend or commit",
+                        "name": "metrics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of background",
+                        "name": "bg_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of footer",
+                        "name": "footer_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of title",
+                        "name": "title_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of text",
+                        "name": "text_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of icons",
+                        "name": "icon_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "hex color of coverage score",
+                        "name": "accent_color",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "svg or png, default svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "width of png, 2048 at most",
+                        "name": "width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "height of png, 2048 at most",
+                        "name": "height",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "treemap svg",
+                        "description": "card svg or png",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the card"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
                         "schema": {
                             "type": "string"
                         }
@@ -219,12 +510,16 @@ var doc = `{
                 }
             }
         },
-        "/reports/{id}/repo": {
+        "/reports/{id}/compare": {
             "get": {
+                "produces": [
+                    "application/json",
+                    "text/markdown"
+                ],
                 "tags": [
                     "Report"
                 ],
-                "summary": "get repository of the report id",
+                "summary": "Compare coverage of the head report against the base report",
                 "parameters": [
                     {
                         "type": "string",
@@ -232,13 +527,45 @@ var doc = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "commit or branch of the base report",
+                        "name": "base",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "commit or branch of the head report",
+                        "name": "head",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or markdown, default json",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "repository",
+                        "description": "comparison of reports",
                         "schema": {
-                            "$ref": "#/definitions/core.Repo"
+                            "$ref": "#/definitions/core.ReportComparison"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the comparison"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -246,19 +573,25 @@ var doc = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
         },
-        "/reports/{id}/treemap/{ref}": {
+        "/reports/{id}/files-history/{path}": {
             "get": {
                 "produces": [
-                    "image/svg+xml"
+                    "application/json"
                 ],
                 "tags": [
                     "Report"
                 ],
-                "summary": "Get coverage difference treemap with main branch",
+                "summary": "Get coverage history of a file in a branch from summaries of reports",
                 "parameters": [
                     {
                         "type": "string",
@@ -269,100 +602,1133 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "source branch",
-                        "name": "source",
+                        "description": "file path",
+                        "name": "path",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch of reports, default branch if empty",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "start time in RFC 3339 or date, 90 days before the end by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "end time in RFC 3339 or date, exclusive, now by default",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "treemap svg",
+                        "description": "summaries of the file from the oldest",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.FileSummary"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/repos": {
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/files/{path}": {
+            "get": {
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Get coverage of a file in the report with its source at the report commit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "file path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "commit or branch of the report, default branch if empty",
+                        "name": "ref",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "file coverage",
+                        "schema": {
+                            "$ref": "#/definitions/report.FileReport"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the file"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/hierarchy.svg": {
+            "get": {
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Get nested treemap of the report coverage grouped by directory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "commit or branch of the report, default branch if empty",
+                        "name": "ref",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "hierarchy svg",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the chart"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Get coverage history of a branch from summaries of reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch of reports, default branch if empty",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "start time in RFC 3339 or date, 90 days before the end by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "end time in RFC 3339 or date, exclusive, now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "day or week to keep the latest report in each interval",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "summaries from the oldest",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ReportSummary"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the history"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/regressions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "List files whose coverage dropped between consecutive reports of the default branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "coverage drop in percentage to report, default 5",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "start time in RFC 3339 or date, 90 days before the end by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "end time in RFC 3339 or date, exclusive, now by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "regressions of the latest 50 reports in the range from the oldest",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/report.Regression"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/repo": {
+            "get": {
+                "tags": [
+                    "Report"
+                ],
+                "summary": "get repository of the report id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "repository",
+                        "schema": {
+                            "$ref": "#/definitions/core.Repo"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/treemap/{ref}": {
+            "get": {
+                "produces": [
+                    "image/svg+xml",
+                    "image/png"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Get coverage difference treemap with main branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "source branch",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "base commit or branch, default branch if empty",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only show files changed in the pull request, which is commented with the treemap",
+                        "name": "pr",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "most cells of the pull request treemap before collapsing into directories",
+                        "name": "cells",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "svg or png, default svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "width of png, 2048 at most",
+                        "name": "width",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "height of png, 2048 at most",
+                        "name": "height",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "treemap svg or png",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the treemap"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/{id}/trend.svg": {
+            "get": {
+                "produces": [
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Get coverage trend line chart of a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch of reports, default branch if empty",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "show reports in the last days",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "show the latest reports if days is not given, default 30 and 100 at most",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "trend svg",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "entity tag of the trend"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "not modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "List repositories for all available SCM providers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "offset of the page in the repository list, from the X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of repositories in a page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "repositories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.Repo"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "cursor of the next page"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Create new repository for code coverage",
+                "parameters": [
+                    {
+                        "description": "repository to create",
+                        "name": "repo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.Repo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Created repository",
+                        "schema": {
+                            "$ref": "#/definitions/core.Repo"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "List repositories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM source (github, gitea)",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "offset of the page in the repository list, from the X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of repositories in a page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "repositories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.Repo"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "cursor of the next page"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "get repository",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.Repo"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "sync repository information with SCM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.Repo"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/audit": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "list audit logs of security-relevant actions to the repository, the latest first",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "audit logs",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.AuditLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/branches": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "list repository branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the page from the X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of branches in a page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "cursor of the next page"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/commits": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "list recent commits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch to list commits from",
+                        "name": "ref",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the page from the X-Next-Cursor header",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of commits in a page, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.Commit"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "URL of the next page"
+                            },
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "cursor of the next page"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/content/{path}": {
+            "get": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get a file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "file path",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "specify git ref, default main branch",
+                        "name": "ref",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "file content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/export": {
+            "get": {
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "export the repository with its setting, reports, references and comments as a zip archive",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "repository archive",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/files": {
             "get": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "List repositories for all available SCM providers",
+                "summary": "List all files in repository",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "specify git ref, default main branch",
+                        "name": "ref",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "files",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/hook": {
+            "post": {
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "handle webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "repositories",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/core.Repo"
-                            }
+                            "type": "string"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "Create new repository for code coverage",
+                "summary": "delete repository webhook",
                 "parameters": [
                     {
-                        "description": "repository to create",
-                        "name": "repo",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.Repo"
-                        }
+                        "type": "string",
+                        "description": "SCM",
+                        "name": "scm",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Created repository",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.Repo"
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/repos/{scm}": {
-            "get": {
+        "/repos/{scm}/{namespace}/{name}/hook/create": {
+            "post": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "List repositories",
+                "summary": "create repository webhook",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "SCM source (github, gitea)",
+                        "description": "SCM",
                         "name": "scm",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "repositories",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/core.Repo"
-                            }
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}": {
-            "get": {
+        "/repos/{scm}/{namespace}/{name}/import": {
+            "post": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "get repository",
+                "summary": "import the repository archive, importing the same archive again does not duplicate data",
                 "parameters": [
                     {
                         "type": "string",
@@ -384,6 +1750,13 @@ var doc = `{
                         "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "repository archive",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -392,14 +1765,28 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/core.Repo"
                         }
+                    },
+                    "400": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/repos/{scm}/{namespace}/{name}/report": {
             "patch": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "sync repository information with SCM",
+                "summary": "renew repository report id",
                 "parameters": [
                     {
                         "type": "string",
@@ -425,7 +1812,7 @@ var doc = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "updated repository",
                         "schema": {
                             "$ref": "#/definitions/core.Repo"
                         }
@@ -433,12 +1820,12 @@ var doc = `{
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}/branches": {
+        "/repos/{scm}/{namespace}/{name}/roles": {
             "get": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "list repository branches",
+                "summary": "list roles of users to the repository",
                 "parameters": [
                     {
                         "type": "string",
@@ -464,23 +1851,23 @@ var doc = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "roles",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/core.RepoPermission"
                             }
                         }
                     }
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}/commits": {
-            "get": {
+        "/repos/{scm}/{namespace}/{name}/roles/{login}": {
+            "put": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "list recent commits",
+                "summary": "override role of the user to the repository locally",
                 "parameters": [
                     {
                         "type": "string",
@@ -505,30 +1892,33 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "branch to list commits from",
-                        "name": "ref",
-                        "in": "query"
+                        "description": "user login",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "viewer, uploader, maintainer or admin",
+                        "name": "role",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/core.Commit"
-                            }
+                            "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/repos/{scm}/{namespace}/{name}/content/{path}": {
-            "get": {
+            },
+            "delete": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "Get a file content",
+                "summary": "remove local role override, the role synchronized from SCM is used instead",
                 "parameters": [
                     {
                         "type": "string",
@@ -553,21 +1943,15 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "file path",
-                        "name": "path",
+                        "description": "user login",
+                        "name": "login",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "specify git ref, default main branch",
-                        "name": "ref",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "file content",
+                        "description": "OK",
                         "schema": {
                             "type": "string"
                         }
@@ -575,12 +1959,12 @@ var doc = `{
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}/files": {
+        "/repos/{scm}/{namespace}/{name}/setting": {
             "get": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "List all files in repository",
+                "summary": "get repository setting",
                 "parameters": [
                     {
                         "type": "string",
@@ -602,33 +1986,22 @@ var doc = `{
                         "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "specify git ref, default main branch",
-                        "name": "ref",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "files",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/core.RepoSetting"
                         }
                     }
                 }
-            }
-        },
-        "/repos/{scm}/{namespace}/{name}/hook": {
+            },
             "post": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "handle webhook event",
+                "summary": "update repository setting",
                 "parameters": [
                     {
                         "type": "string",
@@ -650,24 +2023,33 @@ var doc = `{
                         "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "repository setting",
+                        "name": "setting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.RepoSetting"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/core.RepoSetting"
                         }
                     }
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}/hook/create": {
-            "post": {
+        "/repos/{scm}/{namespace}/{name}/tokens": {
+            "get": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "create repository webhook",
+                "summary": "list repository upload tokens",
                 "parameters": [
                     {
                         "type": "string",
@@ -693,20 +2075,21 @@ var doc = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "list of tokens",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.RepoToken"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/repos/{scm}/{namespace}/{name}/report": {
-            "patch": {
+            },
+            "post": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "renew repository report id",
+                "summary": "create repository upload token",
                 "parameters": [
                     {
                         "type": "string",
@@ -728,24 +2111,30 @@ var doc = `{
                         "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "token name",
+                        "name": "name",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "updated repository",
+                        "description": "created token with secret",
                         "schema": {
-                            "$ref": "#/definitions/core.Repo"
+                            "$ref": "#/definitions/core.RepoToken"
                         }
                     }
                 }
             }
         },
-        "/repos/{scm}/{namespace}/{name}/setting": {
-            "get": {
+        "/repos/{scm}/{namespace}/{name}/tokens/{id}": {
+            "delete": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "get repository setting",
+                "summary": "delete repository upload token",
                 "parameters": [
                     {
                         "type": "string",
@@ -767,22 +2156,35 @@ var doc = `{
                         "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/core.RepoSetting"
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "error message",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             },
-            "post": {
+            "patch": {
                 "tags": [
                     "Repository"
                 ],
-                "summary": "update repository setting",
+                "summary": "regenerate secret of the repository upload token",
                 "parameters": [
                     {
                         "type": "string",
@@ -806,20 +2208,18 @@ var doc = `{
                         "required": true
                     },
                     {
-                        "description": "repository setting",
-                        "name": "setting",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.RepoSetting"
-                        }
+                        "type": "integer",
+                        "description": "token id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "renewed token with secret",
                         "schema": {
-                            "$ref": "#/definitions/core.RepoSetting"
+                            "$ref": "#/definitions/core.RepoToken"
                         }
                     }
                 }
@@ -833,15 +2233,34 @@ var doc = `{
                 "summary": "Get login user",
                 "responses": {
                     "200": {
-                        "description": "user",
-                        "schema": {
-                            "$ref": "#/definitions/user.User"
-                        }
-                    },
-                    "404": {
-                        "description": "error",
+                        "description": "user",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "404": {
+                        "description": "error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/audit": {
+            "get": {
+                "tags": [
+                    "User"
+                ],
+                "summary": "list audit logs of actions to the account of the user, such as token changes, the latest first",
+                "responses": {
+                    "200": {
+                        "description": "audit logs",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.AuditLog"
+                            }
                         }
                     }
                 }
@@ -970,6 +2389,19 @@ var doc = `{
                         "description": "token name",
                         "name": "name",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "token scope, repeat for multiple scopes",
+                        "name": "scope",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "days before the token expires, never expires if empty",
+                        "name": "expires",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -978,6 +2410,12 @@ var doc = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -1009,6 +2447,32 @@ var doc = `{
         }
     },
     "definitions": {
+        "core.AuditLog": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "AuditAction"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "string"
+                },
+                "before": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                }
+            }
+        },
         "core.Commit": {
             "type": "object",
             "properties": {
@@ -1026,6 +2490,29 @@ var doc = `{
                 }
             }
         },
+        "core.FileSummary": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "covered": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "ReportType"
+                }
+            }
+        },
         "core.Repo": {
             "type": "object",
             "properties": {
@@ -1055,9 +2542,32 @@ var doc = `{
                 }
             }
         },
+        "core.RepoPermission": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "override": {
+                    "type": "Role"
+                },
+                "role": {
+                    "type": "Role"
+                },
+                "syncedAt": {
+                    "type": "string"
+                }
+            }
+        },
         "core.RepoSetting": {
             "type": "object",
             "properties": {
+                "badge": {
+                    "type": "BadgeSetting"
+                },
+                "card": {
+                    "type": "CardSetting"
+                },
                 "filters": {
                     "type": "FileNameFilters"
                 },
@@ -1067,11 +2577,34 @@ var doc = `{
                 "protected": {
                     "type": "boolean"
                 },
+                "reportIDUpload": {
+                    "type": "boolean"
+                },
+                "retention": {
+                    "type": "RetentionPolicy"
+                },
                 "updateAction": {
                     "type": "ReportUpdateAction"
                 }
             }
         },
+        "core.RepoToken": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "core.Report": {
             "type": "object",
             "properties": {
@@ -1101,6 +2634,224 @@ var doc = `{
                 }
             }
         },
+        "core.ReportComparison": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "baseCoverage": {
+                    "type": "number"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "type": "FileComparison"
+                    }
+                },
+                "head": {
+                    "type": "string"
+                },
+                "headCoverage": {
+                    "type": "number"
+                },
+                "newlyCoveredLines": {
+                    "type": "array",
+                    "items": {
+                        "type": "FileLines"
+                    }
+                },
+                "newlyUncoveredFiles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removedFiles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "statementCoverageDiff": {
+                    "type": "number"
+                },
+                "types": {
+                    "type": "array",
+                    "items": {
+                        "type": "TypeComparison"
+                    }
+                }
+            }
+        },
+        "core.ReportSummary": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "coverages": {
+                    "type": "array",
+                    "items": {
+                        "type": "CoverageSummary"
+                    }
+                },
+                "covered": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "files": {
+                    "type": "integer"
+                },
+                "lines": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.BranchHit": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "integer"
+                },
+                "covered": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.FileCoverage": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.LineHit"
+                    }
+                },
+                "partialBranches": {
+                    "description": "PartialBranches are lines with branches not all taken,\nwhich is empty as branch coverage is not collected from reports yet",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.BranchHit"
+                    }
+                },
+                "statementCoverage": {
+                    "type": "number"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "uncoveredRanges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.LineRange"
+                    }
+                }
+            }
+        },
+        "report.FileReport": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "coverages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/report.FileCoverage"
+                    }
+                },
+                "path": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.LineHit": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.LineRange": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "integer"
+                }
+            }
+        },
+        "report.Regression": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "baseCoverage": {
+                    "type": "number"
+                },
+                "commit": {
+                    "type": "string"
+                },
+                "committer": {
+                    "description": "Committer and Message of the commit, which are empty if the commit is not found in the default branch",
+                    "type": "string"
+                },
+                "coverage": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "report.ShieldsBadge": {
+            "type": "object",
+            "properties": {
+                "cacheSeconds": {
+                    "type": "integer"
+                },
+                "color": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "type": "integer"
+                },
+                "style": {
+                    "type": "string"
+                }
+            }
+        },
         "user.Providers": {
             "type": "object",
             "additionalProperties": {
@@ -1113,11 +2864,21 @@ var doc = `{
                 "createdAt": {
                     "type": "string"
                 },
+                "expires": {
+                    "description": "Expires is omitted if the token never expires",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "lastUsedAt": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
+                },
+                "scopes": {
+                    "type": "string"
                 }
             }
         },
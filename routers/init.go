@@ -19,18 +19,26 @@ type Routers struct {
 	Session         core.Session
 	LoginMiddleware core.LoginMiddleware
 	// service
-	SCMService      core.SCMService
-	CoverageService core.CoverageService
-	ChartService    core.ChartService
-	RepoService     core.RepoService
-	ReportService   core.ReportService
-	HookService     core.HookService
-	OAuthService    core.OAuthService
+	SCMService        core.SCMService
+	CoverageService   core.CoverageService
+	ChartService      core.ChartService
+	RepoService       core.RepoService
+	ReportService     core.ReportService
+	HookService       core.HookService
+	OAuthService      core.OAuthService
+	PermissionService core.PermissionService
+	OIDCService       core.OIDCService
+	ArchiveService    core.ArchiveService
+	CacheMetrics      core.CacheMetrics
+	// Cache of rendered content, nil if caching is disabled
+	Cache core.Cache
 	// store
-	UserStore   core.UserStore
-	ReportStore core.ReportStore
-	RepoStore   core.RepoStore
-	OAuthStore  core.OAuthStore
+	UserStore       core.UserStore
+	ReportStore     core.ReportStore
+	RepoStore       core.RepoStore
+	OAuthStore      core.OAuthStore
+	PermissionStore core.PermissionStore
+	AuditStore      core.AuditStore
 }
 
 // RegisterRoutes for Gin engine
@@ -43,22 +51,30 @@ func (r *Routers) RegisterRoutes(e *gin.Engine) {
 		Config:          r.Config,
 		LoginMiddleware: r.LoginMiddleware,
 		SCMService:      r.SCMService,
+		OIDCService:     r.OIDCService,
+		UserStore:       r.UserStore,
 		Session:         r.Session,
 	}
 	apiRoute := &api.Router{
-		Config:          r.Config,
-		Session:         r.Session,
-		CoverageService: r.CoverageService,
-		ChartService:    r.ChartService,
-		SCMService:      r.SCMService,
-		RepoService:     r.RepoService,
-		ReportService:   r.ReportService,
-		HookService:     r.HookService,
-		OAuthService:    r.OAuthService,
-		UserStore:       r.UserStore,
-		ReportStore:     r.ReportStore,
-		RepoStore:       r.RepoStore,
-		OAuthStore:      r.OAuthStore,
+		Config:            r.Config,
+		Session:           r.Session,
+		CoverageService:   r.CoverageService,
+		ChartService:      r.ChartService,
+		SCMService:        r.SCMService,
+		RepoService:       r.RepoService,
+		ReportService:     r.ReportService,
+		HookService:       r.HookService,
+		OAuthService:      r.OAuthService,
+		PermissionService: r.PermissionService,
+		ArchiveService:    r.ArchiveService,
+		CacheMetrics:      r.CacheMetrics,
+		Cache:             r.Cache,
+		UserStore:         r.UserStore,
+		ReportStore:       r.ReportStore,
+		RepoStore:         r.RepoStore,
+		OAuthStore:        r.OAuthStore,
+		PermissionStore:   r.PermissionStore,
+		AuditStore:        r.AuditStore,
 	}
 	webRoute.RegisterRoutes(e)
 	apiRoute.RegisterRoutes(e)
@@ -72,22 +72,82 @@ func createOrUpdateUser(ctx context.Context, client core.Client, token *core.Tok
 // MiddlewareLogin context
 func MiddlewareLogin(scm core.SCMProvider, m core.LoginMiddleware) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		middleware := m.Handler(scm)
-		h := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := r.Context()
-			err := login.ErrorFrom(ctx)
-			if err != nil {
-				_ = c.Error(err)
-				c.Abort()
-				return
-			}
-			tok := login.TokenFrom(ctx)
-			c.Set(keyLogin, true)
-			c.Set(keyAccess, tok.Access)
-			c.Set(keyExpires, tok.Expires)
-			c.Set(keyRefresh, tok.Refresh)
-		}))
-		h.ServeHTTP(c.Writer, c.Request)
+		loginWith(c, m.Handler(scm))
+	}
+}
+
+// MiddlewareOIDCLogin context
+func MiddlewareOIDCLogin(service core.OIDCService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !service.Enabled() {
+			c.String(404, "OpenID Connect login is not configured")
+			c.Abort()
+			return
+		}
+		loginWith(c, service.Handler())
+	}
+}
+
+func loginWith(c *gin.Context, middleware login.Middleware) {
+	h := middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		err := login.ErrorFrom(ctx)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		tok := login.TokenFrom(ctx)
+		// keep values of the login flow, such as the verified identity, for the next handlers
+		c.Request = r
+		c.Set(keyLogin, true)
+		c.Set(keyAccess, tok.Access)
+		c.Set(keyExpires, tok.Expires)
+		c.Set(keyRefresh, tok.Refresh)
+	}))
+	h.ServeHTTP(c.Writer, c.Request)
+}
+
+// HandleOIDCLogin user with OpenID Connect provider.
+// A new user is created for the first login, who could bind SCM accounts later,
+// unless a logged in user is binding the OpenID Connect identity.
+func HandleOIDCLogin(
+	config *config.Config,
+	service core.OIDCService,
+	store core.UserStore,
+	session core.Session,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(keyLogin) {
+			return
+		}
+		ctx := c.Request.Context()
+		oidcUser, err := service.User(ctx, TokenFrom(c))
+		if err != nil {
+			log.Error(err)
+			c.String(400, err.Error())
+			return
+		}
+		var user *core.User
+		if session.ShouldBindUser(c) {
+			user, err = store.BindOIDC(session.GetUser(c), oidcUser)
+			_ = session.EndBindUser(c)
+		} else if _, err = store.FindOIDC(oidcUser.Subject); err == nil {
+			user, err = store.UpdateOIDC(oidcUser)
+		} else {
+			user, err = store.CreateOIDC(oidcUser)
+		}
+		if err != nil {
+			log.Error(err)
+			c.String(400, err.Error())
+			return
+		}
+		if err := session.CreateUser(c, user); err != nil {
+			log.Error(err)
+			c.String(400, err.Error())
+			return
+		}
+		c.Redirect(301, config.Server.BaseURL())
 	}
 }
 
@@ -1 +1,112 @@
 package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+
+	"github.com/covergates/covergates/config"
+	"github.com/covergates/covergates/core"
+	"github.com/covergates/covergates/mock"
+	"github.com/covergates/covergates/modules/session"
+)
+
+func TestHandleOIDCLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock.NewMockOIDCService(ctrl)
+	store := mock.NewMockUserStore(ctrl)
+	oidcUser := &core.OIDCUser{Subject: "1234", Login: "user"}
+	user := &core.User{Login: "user"}
+
+	r := gin.Default()
+	r.Use(sessions.Sessions("test", cookie.NewStore([]byte("secret"))))
+	r.GET("/login/oidc",
+		func(c *gin.Context) {
+			c.Set(keyLogin, true)
+			c.Set(keyAccess, "access")
+		},
+		HandleOIDCLogin(&config.Config{}, service, store, &session.Session{}),
+	)
+	req, _ := http.NewRequest("GET", "/login/oidc", nil)
+
+	t.Run("create user", func(t *testing.T) {
+		service.EXPECT().User(gomock.Any(), gomock.Eq(&core.Token{Token: "access"})).Return(oidcUser, nil)
+		store.EXPECT().FindOIDC(gomock.Eq(oidcUser.Subject)).Return(nil, errors.New("not found"))
+		store.EXPECT().CreateOIDC(gomock.Eq(oidcUser)).Return(user, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 301 {
+			t.Fatalf("expect redirect, got %d", w.Code)
+		}
+		if len(w.Result().Cookies()) == 0 {
+			t.Fatal("should create user session")
+		}
+	})
+
+	t.Run("update user", func(t *testing.T) {
+		service.EXPECT().User(gomock.Any(), gomock.Any()).Return(oidcUser, nil)
+		store.EXPECT().FindOIDC(gomock.Eq(oidcUser.Subject)).Return(user, nil)
+		store.EXPECT().UpdateOIDC(gomock.Eq(oidcUser)).Return(user, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 301 {
+			t.Fatalf("expect redirect, got %d", w.Code)
+		}
+	})
+
+	t.Run("bind user", func(t *testing.T) {
+		bound := &core.User{Login: "scm_user"}
+		sess := &session.Session{}
+		r.GET("/login/oidc/bind",
+			func(c *gin.Context) {
+				c.Set(keyLogin, true)
+				_ = sess.CreateUser(c, bound)
+				_ = sess.StartBindUser(c)
+			},
+			HandleOIDCLogin(&config.Config{}, service, store, sess),
+		)
+		service.EXPECT().User(gomock.Any(), gomock.Any()).Return(oidcUser, nil)
+		store.EXPECT().BindOIDC(gomock.Eq(bound), gomock.Eq(oidcUser)).Return(bound, nil)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/login/oidc/bind", nil)
+		r.ServeHTTP(w, req)
+		if w.Code != 301 {
+			t.Fatalf("expect redirect, got %d", w.Code)
+		}
+	})
+
+	t.Run("login taken", func(t *testing.T) {
+		service.EXPECT().User(gomock.Any(), gomock.Any()).Return(oidcUser, nil)
+		store.EXPECT().FindOIDC(gomock.Eq(oidcUser.Subject)).Return(nil, errors.New("not found"))
+		store.EXPECT().CreateOIDC(gomock.Eq(oidcUser)).Return(nil, errors.New("user already exist"))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != 400 {
+			t.Fatalf("expect 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestMiddlewareOIDCLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := mock.NewMockOIDCService(ctrl)
+	service.EXPECT().Enabled().Return(false)
+	r := gin.Default()
+	r.GET("/login/oidc", MiddlewareOIDCLogin(service))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/login/oidc", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != 404 {
+		t.Fatalf("expect 404 if not configured, got %d", w.Code)
+	}
+}
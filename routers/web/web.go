@@ -15,6 +15,8 @@ type Router struct {
 	Config          *config.Config
 	LoginMiddleware core.LoginMiddleware
 	SCMService      core.SCMService
+	OIDCService     core.OIDCService
+	UserStore       core.UserStore
 	Session         core.Session
 }
 
@@ -48,6 +50,15 @@ func (r *Router) RegisterRoutes(e *gin.Engine) {
 				core.GitLab,
 				r.SCMService,
 				r.Session))
+		g.Any("/oidc",
+			MiddlewareOIDCLogin(r.OIDCService),
+			HandleOIDCLogin(
+				r.Config,
+				r.OIDCService,
+				r.UserStore,
+				r.Session,
+			),
+		)
 	}
 	e.Any("/logoff", HandleLogout(r.Config, r.Session))
 	h := gin.WrapH(http.FileServer(web.New()))
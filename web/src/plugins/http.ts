@@ -15,6 +15,22 @@ export function reasonToError(reason: Error | AxiosError): Error {
   return new Error('Unknown Error');
 }
 
+/**
+ * fetch items of all pages from a paginated listing,
+ * following the X-Next-Cursor header until the last page
+ */
+export function fetchAllPages<T>(url: string, cursor = '', items: T[] = []): Promise<T[]> {
+  const params: Record<string, string | number> = { limit: 100 };
+  if (cursor) {
+    params.cursor = cursor;
+  }
+  return Axios.get<T[]>(url, { params: params }).then(response => {
+    const all = items.concat(response.data);
+    const next = response.headers['x-next-cursor'];
+    return next ? fetchAllPages(url, next, all) : all;
+  });
+}
+
 // eslint-disable-next-line
 export function AxiosPlugin(Vue: typeof _Vue, options?: any): void {
   Vue.prototype.$http = Axios;
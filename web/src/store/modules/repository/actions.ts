@@ -2,7 +2,7 @@ import { ActionContext } from 'vuex';
 import Axios from 'axios';
 import { RepoState, Mutations, Actions } from '.';
 import { RootState } from '@/store';
-import { reasonToError } from '@/plugins/http';
+import { reasonToError, fetchAllPages } from '@/plugins/http';
 
 const errUndefinedCurrentRepo = new Error('current repository is undefined');
 
@@ -219,9 +219,9 @@ export function fetchRepositoryBranches<S extends RepoState, R extends RootState
       return;
     }
     const { SCM: scm, Name: name, NameSpace: nameSpace } = (repo as Repository);
-    Axios.get<string[]>(`${base}/api/v1/repos/${scm}/${nameSpace}/${name}/branches`)
-      .then(response => {
-        context.commit(Mutations.SET_REPOSITORY_BRANCHES, response.data);
+    fetchAllPages<string>(`${base}/api/v1/repos/${scm}/${nameSpace}/${name}/branches`)
+      .then(branches => {
+        context.commit(Mutations.SET_REPOSITORY_BRANCHES, branches);
       })
       .catch(() => {
         context.commit(Mutations.SET_REPOSITORY_BRANCHES, []);
@@ -25,6 +25,7 @@ declare interface RepositorySetting {
   filters?: string[];
   mergePR?: boolean;
   protected?: boolean;
+  reportIDUpload?: boolean;
 }
 
 declare interface Commit {